@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Sum get the sum of the column of records matching conditions, it is 0 if no records match, e.g:
+//    var total float64
+//    db.Model(&Order{}).Where("user_id = ?", 1).Sum("amount", &total)
+//    // SELECT COALESCE(SUM(amount), 0) FROM orders WHERE (user_id = 1);
+//
+// Decimal values are scanned into float64, int64 or string values, or any sql.Scanner, like Avg, Min and Max
+func (s *DB) Sum(column string, value interface{}) *DB {
+	return s.NewScope(s.Value).aggregate("SUM", column, value).db
+}
+
+// Avg get the average of the column of records matching conditions, value is set to zero if no records match,
+// scan it into a pointer like **float64 or sql.NullFloat64 to tell it from zero
+func (s *DB) Avg(column string, value interface{}) *DB {
+	return s.NewScope(s.Value).aggregate("AVG", column, value).db
+}
+
+// Min get the minimum value of the column of records matching conditions, value is set to zero if no records match, refer Avg
+func (s *DB) Min(column string, value interface{}) *DB {
+	return s.NewScope(s.Value).aggregate("MIN", column, value).db
+}
+
+// Max get the maximum value of the column of records matching conditions, value is set to zero if no records match, refer Avg
+//    var latest *time.Time
+//    db.Model(&Order{}).Max("created_at", &latest)
+func (s *DB) Max(column string, value interface{}) *DB {
+	return s.NewScope(s.Value).aggregate("MAX", column, value).db
+}
+
+func (scope *Scope) aggregate(function, column string, value interface{}) *Scope {
+	expr := fmt.Sprintf("%v(%v)", function, scope.quoteIfPossible(column))
+	if function == "SUM" {
+		expr = fmt.Sprintf("COALESCE(%v, 0)", expr)
+	}
+	scope.Search.Select(expr)
+	scope.Search.ignoreOrderQuery = true
+	scope.Err(scanNullable(scope.row(), value))
+	return scope
+}
+
+// scanNullable scan the row into value, value is set to zero if the column is NULL and value can't hold NULL
+func scanNullable(row *sql.Row, value interface{}) error {
+	dest := reflect.ValueOf(value)
+	if _, ok := value.(sql.Scanner); ok || dest.Kind() != reflect.Ptr || dest.IsNil() || dest.Elem().Kind() == reflect.Ptr {
+		return row.Scan(value)
+	}
+
+	// scan into a pointer to value, which is nil if the column is NULL
+	holder := reflect.New(reflect.PtrTo(dest.Elem().Type()))
+	if err := row.Scan(holder.Interface()); err != nil {
+		return err
+	}
+	if holder.Elem().IsNil() {
+		dest.Elem().Set(reflect.Zero(dest.Elem().Type()))
+	} else {
+		dest.Elem().Set(holder.Elem().Elem())
+	}
+	return nil
+}
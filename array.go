@@ -0,0 +1,257 @@
+package gorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fields of slices of integers, floats, strings or bools are mapped to arrays of postgres and cockroachdb, e.g. []int64 to bigint[], []string to text[],
+// they are converted to array literals when creating, updating and querying, index them with `gin_index` to query them by containment
+//    type Post struct {
+//      ID   uint
+//      Tags []string `gorm:"gin_index"`
+//    }
+//
+//    db.Where(gorm.ArrayContains("tags", []string{"go"})).Find(&posts)
+//    // SELECT * FROM posts WHERE (tags @> '{"go"}');
+
+// Array wrap a pointer of slice to scan array columns, or a slice to use it as an array arg, like pq.Array, e.g:
+//    db.Raw("SELECT tags FROM posts WHERE id = ?", 1).Row().Scan(gorm.Array(&tags))
+//    db.Where("id = ANY(?)", gorm.Array([]int64{1, 2, 3})).Find(&posts)
+func Array(value interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return pgArray{value: value}
+}
+
+// Any build condition matching records whose array column contains the value, e.g:
+//    db.Where(gorm.Any("tags", "go")).Find(&posts)
+//    // SELECT * FROM posts WHERE ('go' = ANY(tags));
+func Any(column string, value interface{}) *SqlExpr {
+	return Expr(fmt.Sprintf("? = ANY(%v)", column), value)
+}
+
+// ArrayContains build condition matching records whose array column contains all values
+func ArrayContains(column string, values interface{}) *SqlExpr {
+	return Expr(fmt.Sprintf("%v @> ?", column), Array(values))
+}
+
+// ArrayOverlaps build condition matching records whose array column contains any of values
+func ArrayOverlaps(column string, values interface{}) *SqlExpr {
+	return Expr(fmt.Sprintf("%v && ?", column), Array(values))
+}
+
+// pgArray convert slices to postgres array literals like `{1,2,3}` and `{"a","b"}`, and scan them back
+type pgArray struct {
+	value interface{}
+}
+
+// Value return the array literal of the slice, nil slices are NULL
+func (array pgArray) Value() (driver.Value, error) {
+	slice := reflect.Indirect(reflect.ValueOf(array.value))
+	if slice.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported array value %T, should be a slice", array.value)
+	}
+	if slice.IsNil() {
+		return nil, nil
+	}
+
+	var elems []string
+	for i := 0; i < slice.Len(); i++ {
+		switch elem := slice.Index(i); elem.Kind() {
+		case reflect.String:
+			elems = append(elems, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem.String())+`"`)
+		case reflect.Bool:
+			elems = append(elems, strconv.FormatBool(elem.Bool()))
+		default:
+			elems = append(elems, fmt.Sprint(elem.Interface()))
+		}
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// Scan parse the array literal into the pointer of slice, NULL is scanned as a nil slice
+func (array pgArray) Scan(src interface{}) error {
+	dest := reflect.ValueOf(array.value)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() || dest.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported array destination %T, should be a pointer of slice", array.value)
+	}
+	slice := dest.Elem()
+
+	var literal string
+	switch src := src.(type) {
+	case nil:
+		slice.Set(reflect.Zero(slice.Type()))
+		return nil
+	case []byte:
+		literal = string(src)
+	case string:
+		literal = src
+	default:
+		return fmt.Errorf("failed to scan array from %T", src)
+	}
+
+	elems, err := parseArrayLiteral(literal)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(slice.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			continue
+		}
+		if err := setArrayElem(result.Index(i), *elem); err != nil {
+			return fmt.Errorf("failed to scan array element %q: %v", *elem, err)
+		}
+	}
+	slice.Set(result)
+	return nil
+}
+
+func setArrayElem(value reflect.Value, elem string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(elem)
+	case reflect.Bool:
+		value.SetBool(elem == "t" || elem == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(elem, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(elem, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(elem, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported array element type %v", value.Type())
+	}
+	return nil
+}
+
+// parseArrayLiteral parse elements of one-dimensional array literals, NULL elements are nil
+func parseArrayLiteral(literal string) ([]*string, error) {
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return nil, fmt.Errorf("invalid array literal %q", literal)
+	}
+	literal = literal[1 : len(literal)-1]
+	if literal == "" {
+		return []*string{}, nil
+	}
+
+	var (
+		elems   []*string
+		elem    strings.Builder
+		quoted  bool
+		inQuote bool
+	)
+	for i := 0; i < len(literal); i++ {
+		switch c := literal[i]; {
+		case inQuote && c == '\\' && i+1 < len(literal):
+			i++
+			elem.WriteByte(literal[i])
+		case c == '"':
+			inQuote, quoted = !inQuote, true
+		case c == '{' && !inQuote:
+			return nil, errors.New("multi-dimensional arrays are not supported")
+		case c == ',' && !inQuote:
+			elems = append(elems, arrayElem(elem.String(), quoted))
+			elem.Reset()
+			quoted = false
+		default:
+			elem.WriteByte(c)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("invalid array literal %q", literal)
+	}
+	return append(elems, arrayElem(elem.String(), quoted)), nil
+}
+
+func arrayElem(elem string, quoted bool) *string {
+	if !quoted && strings.EqualFold(elem, "NULL") {
+		return nil
+	}
+	return &elem
+}
+
+// isArrayType return true if the type is a slice of integers, floats, strings or bools, which isn't a driver.Valuer or sql.Scanner
+func isArrayType(reflectType reflect.Type) bool {
+	if reflectType.Kind() != reflect.Slice || reflectType.Implements(valuerType) || reflect.PtrTo(reflectType).Implements(scannerType) {
+		return false
+	}
+
+	switch reflectType.Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// supportArray return true if the dialect supports array columns
+func supportArray(dialect Dialect) bool {
+	switch dialect.GetName() {
+	case "postgres", "cockroachdb":
+		return true
+	}
+	return false
+}
+
+// arrayDataType return the array type of postgres of the slice type
+func arrayDataType(reflectType reflect.Type) string {
+	if !isArrayType(reflectType) {
+		return ""
+	}
+
+	switch reflectType.Elem().Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "bigint[]"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint16:
+		return "integer[]"
+	case reflect.Float32, reflect.Float64:
+		return "numeric[]"
+	case reflect.Bool:
+		return "boolean[]"
+	}
+	return "text[]"
+}
+
+// addGinIndex create GIN index of postgres and cockroachdb for array and jsonb columns
+func (scope *Scope) addGinIndex(indexName string, column ...string) {
+	if !supportArray(scope.Dialect()) {
+		scope.db.print("warning", fileWithLineNum(), fmt.Sprintf("%v doesn't support GIN indexes, ignored adding GIN index %v", scope.Dialect().GetName(), indexName))
+		return
+	}
+	if scope.Dialect().HasIndex(scope.TableName(), indexName) {
+		return
+	}
+
+	var columns []string
+	for _, name := range column {
+		columns = append(columns, scope.quoteIfPossible(name))
+	}
+	scope.Raw(fmt.Sprintf("CREATE INDEX %v ON %v USING GIN (%v)", indexName, scope.QuotedTableName(), strings.Join(columns, ", "))).Exec()
+}
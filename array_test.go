@@ -0,0 +1,59 @@
+package gorm_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type TaggedPost struct {
+	ID      uint
+	Tags    []string `gorm:"gin_index"`
+	Ratings []int64
+	Flags   []bool
+}
+
+func TestArrayColumns(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.AutoMigrate(&TaggedPost{})
+	var created, indexed bool
+	for _, statement := range recorder.Statements() {
+		created = created || strings.Contains(statement.SQL, `"tags" text[],"ratings" bigint[],"flags" boolean[]`)
+		indexed = indexed || strings.TrimSpace(statement.SQL) == `CREATE INDEX gin_tagged_posts_tags ON "tagged_posts" USING GIN ("tags")`
+	}
+	if !created || !indexed {
+		t.Errorf("array columns should be migrated with GIN index, got %v", recorder.Statements())
+	}
+
+	post := TaggedPost{Tags: []string{"go", `say "hi"`}, Ratings: []int64{1, 2}}
+	recorder.Reply(`INSERT INTO "tagged_posts" ("tags","ratings","flags") VALUES ($1,$2,$3) RETURNING "tagged_posts"."id"`, []string{"id"}, []interface{}{1})
+	if err := db.Create(&post).Error; err != nil {
+		t.Errorf("failed to create post, got %v", err)
+	}
+	if vars := recorder.Statements()[len(recorder.Statements())-2].Vars; !reflect.DeepEqual(vars, []interface{}{`{"go","say \"hi\""}`, "{1,2}", nil}) {
+		t.Errorf("slices should be created as array literals, but got %#v", vars)
+	}
+
+	db.Where(gorm.ArrayContains("tags", []string{"go"})).Or(gorm.Any("ratings", 2)).Find(&[]TaggedPost{})
+	statement := recorder.LastStatement()
+	if statement.SQL != `SELECT * FROM "tagged_posts" WHERE (tags @> $1) OR ($2 = ANY(ratings))` || !reflect.DeepEqual(statement.Vars, []interface{}{`{"go"}`, int64(2)}) {
+		t.Errorf("array conditions are wrong, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	recorder.Reply(`SELECT * FROM "tagged_posts" WHERE ("tagged_posts"."id" = 1) ORDER BY "tagged_posts"."id" ASC LIMIT 1`,
+		[]string{"id", "tags", "ratings", "flags"}, []interface{}{1, `{go,"a,b",NULL,"say \"hi\""}`, "{3,4}", nil})
+	var found TaggedPost
+	if err := db.First(&found, 1).Error; err != nil {
+		t.Fatalf("failed to find post, got %v", err)
+	}
+	if !reflect.DeepEqual(found.Tags, []string{"go", "a,b", "", `say "hi"`}) || !reflect.DeepEqual(found.Ratings, []int64{3, 4}) || found.Flags != nil {
+		t.Errorf("arrays should be scanned into slices, but got %#v", found)
+	}
+}
@@ -4,18 +4,88 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Association Mode contains some helper methods to handle relationship things easily.
+// If the source is a slice, it works in batch mode, which handles relationships of all records with as few statements as possible
+//    db.Model(&users).Association("Languages").Append(&user1Languages, &user2Languages)
 type Association struct {
 	Error  error
 	scope  *Scope
 	column string
 	field  *Field
+
+	conditions       [][]interface{}
+	batch            *Association
+	joinTableRecords [][2]interface{}
+}
+
+// Where add conditions for associations when Find, Count, Delete and Clear
+//    db.Model(&user).Association("Languages").Where("name LIKE ?", "deprecated%").Clear()
+func (association *Association) Where(query interface{}, args ...interface{}) *Association {
+	association.conditions = append(association.conditions, append([]interface{}{query}, args...))
+	return association
+}
+
+// JoinTableAttrs set values of many2many join table's extra columns for new relationships when Append or Replace
+//    db.Model(&user).Association("Groups").JoinTableAttrs(map[string]interface{}{"role": "admin"}).Append(&group)
+func (association *Association) JoinTableAttrs(attrs map[string]interface{}) *Association {
+	if association.Error == nil {
+		association.scope.db = association.scope.db.Set("gorm:join_table_attrs", attrs)
+	}
+	return association
+}
+
+// FindJoinTable find out many2many join table records of the source, which could be used to read extra columns
+//    var memberships []UserGroup
+//    db.Model(&user).Association("Groups").FindJoinTable(&memberships)
+func (association *Association) FindJoinTable(value interface{}) *Association {
+	if association.Error != nil {
+		return association
+	}
+
+	var (
+		scope             = association.scope
+		relationship      = association.field.Relationship
+		foreignFieldNames []string
+		foreignDBNames    []string
+	)
+
+	if relationship.Kind != "many_to_many" {
+		return association.setErr(fmt.Errorf("%v isn't a many2many association", association.column))
+	}
+
+	joinTableHandler := relationship.JoinTableHandler
+	for _, foreignKey := range joinTableHandler.SourceForeignKeys() {
+		if field, ok := scope.FieldByName(foreignKey.AssociationDBName); ok {
+			foreignFieldNames = append(foreignFieldNames, field.Name)
+			foreignDBNames = append(foreignDBNames, foreignKey.DBName)
+		}
+	}
+
+	query := scope.NewDB().Table(joinTableHandler.Table(scope.db))
+	for _, condition := range association.conditions {
+		query = query.Where(condition[0], condition[1:]...)
+	}
+
+	primaryKeys := scope.getColumnAsArray(foreignFieldNames, scope.Value)
+	return association.setErr(query.Where(
+		fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, foreignDBNames), toQueryMarks(primaryKeys)),
+		toQueryValues(primaryKeys)...,
+	).Find(value).Error)
 }
 
 // Find find out all related associations
 func (association *Association) Find(value interface{}) *Association {
+	if association.Error != nil {
+		return association
+	}
+
+	if association.isBatch() || len(association.conditions) > 0 {
+		return association.setErr(association.relatedDB().Find(value).Error)
+	}
+
 	association.scope.related(value, association.column)
 	return association.setErr(association.scope.db.Error)
 }
@@ -29,15 +99,44 @@ func (association *Association) Append(values ...interface{}) *Association {
 	if relationship := association.field.Relationship; relationship.Kind == "has_one" {
 		return association.Replace(values...)
 	}
+
+	if association.isBatch() {
+		sources, err := association.batchSources(values)
+		if err != nil {
+			return association.setErr(err)
+		}
+
+		for idx, source := range sources {
+			association.setErr(source.saveAssociations(values[idx]).Error)
+		}
+		return association.saveJoinTableRecords()
+	}
 	return association.saveAssociations(values...)
 }
 
-// Replace replace current associations with new one
+// Replace replace current associations with new one, in batch mode, values are used for records in the same order
 func (association *Association) Replace(values ...interface{}) *Association {
 	if association.Error != nil {
 		return association
 	}
 
+	if association.isBatch() {
+		sources, err := association.batchSources(values)
+		if err != nil {
+			return association.setErr(err)
+		}
+
+		for idx, source := range sources {
+			source.field.Set(reflect.Zero(source.field.Field.Type()))
+			association.setErr(source.saveAssociations(values[idx]).Error)
+		}
+
+		if association.saveJoinTableRecords().Error != nil {
+			return association
+		}
+		return association.removeRelationsExcept(sources)
+	}
+
 	var (
 		relationship = association.field.Relationship
 		scope        = association.scope
@@ -63,7 +162,7 @@ func (association *Association) Replace(values ...interface{}) *Association {
 	} else {
 		// Polymorphic Relations
 		if relationship.PolymorphicDBName != "" {
-			newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.PolymorphicValue)
+			newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.polymorphicValue())
 		}
 
 		// Delete Relations except new created
@@ -128,48 +227,74 @@ func (association *Association) Replace(values ...interface{}) *Association {
 
 // Delete remove relationship between source & passed arguments, but won't delete those arguments
 func (association *Association) Delete(values ...interface{}) *Association {
-	if association.Error != nil {
+	if association.Error != nil || len(values) == 0 {
 		return association
 	}
 
+	if len(association.conditions) > 0 {
+		// only remove associations matching conditions
+		var (
+			associationScope     = association.scope.New(reflect.New(association.field.Struct.Type).Interface())
+			primaryFieldNames    []string
+			quotedPrimaryDBNames []string
+		)
+
+		for _, field := range associationScope.PrimaryFields() {
+			primaryFieldNames = append(primaryFieldNames, field.Name)
+			quotedPrimaryDBNames = append(quotedPrimaryDBNames, associationScope.QuotedTableName()+"."+associationScope.Quote(field.DBName))
+		}
+
+		primaryKeys := association.scope.getColumnAsArray(primaryFieldNames, values...)
+		if len(primaryKeys) == 0 {
+			return association
+		}
+
+		condition := strings.Join(quotedPrimaryDBNames, ",")
+		if len(quotedPrimaryDBNames) > 1 {
+			condition = "(" + condition + ")"
+		}
+
+		matched := association.newAssociationSlice()
+		if association.setErr(association.relatedDB().Where(fmt.Sprintf("%v IN (%v)", condition, toQueryMarks(primaryKeys)), toQueryValues(primaryKeys)...).Find(matched).Error).Error != nil {
+			return association
+		}
+		values = []interface{}{matched}
+	}
+
+	return association.deleteRelations(values...)
+}
+
+// deleteRelations remove relationship between sources & passed arguments
+func (association *Association) deleteRelations(values ...interface{}) *Association {
 	var (
 		relationship = association.field.Relationship
 		scope        = association.scope
-		field        = association.field.Field
+		fieldType    = association.field.Struct.Type
 		newDB        = scope.NewDB()
 	)
 
-	if len(values) == 0 {
-		return association
-	}
-
 	var deletingResourcePrimaryFieldNames, deletingResourcePrimaryDBNames []string
-	for _, field := range scope.New(reflect.New(field.Type()).Interface()).PrimaryFields() {
+	for _, field := range scope.New(reflect.New(fieldType).Interface()).PrimaryFields() {
 		deletingResourcePrimaryFieldNames = append(deletingResourcePrimaryFieldNames, field.Name)
 		deletingResourcePrimaryDBNames = append(deletingResourcePrimaryDBNames, field.DBName)
 	}
 
 	deletingPrimaryKeys := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, values...)
+	if len(deletingPrimaryKeys) == 0 {
+		return association
+	}
 
 	if relationship.Kind == "many_to_many" {
 		// source value's foreign keys
-		for idx, foreignKey := range relationship.ForeignDBNames {
-			if field, ok := scope.FieldByName(relationship.ForeignFieldNames[idx]); ok {
-				newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(foreignKey)), field.Field.Interface())
-			}
-		}
-
-		// get association's foreign fields name
-		var associationScope = scope.New(reflect.New(field.Type()).Interface())
-		var associationForeignFieldNames []string
-		for _, associationDBName := range relationship.AssociationForeignFieldNames {
-			if field, ok := associationScope.FieldByName(associationDBName); ok {
-				associationForeignFieldNames = append(associationForeignFieldNames, field.Name)
-			}
-		}
+		sourcePrimaryKeys := scope.getColumnAsArray(fieldNamesOf(scope, relationship.ForeignFieldNames), scope.Value)
+		newDB = newDB.Where(
+			fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(sourcePrimaryKeys)),
+			toQueryValues(sourcePrimaryKeys)...,
+		)
 
 		// association value's foreign keys
-		deletingPrimaryKeys := scope.getColumnAsArray(associationForeignFieldNames, values...)
+		associationScope := scope.New(reflect.New(fieldType).Interface())
+		deletingPrimaryKeys := scope.getColumnAsArray(fieldNamesOf(associationScope, relationship.AssociationForeignFieldNames), values...)
 		sql := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.AssociationForeignDBNames), toQueryMarks(deletingPrimaryKeys))
 		newDB = newDB.Where(sql, toQueryValues(deletingPrimaryKeys)...)
 
@@ -188,11 +313,31 @@ func (association *Association) Delete(values ...interface{}) *Association {
 				toQueryValues(primaryKeys)...,
 			)
 
+			// only update source records
+			var sourcePrimaryFieldNames, sourcePrimaryDBNames []string
+			for _, field := range scope.PrimaryFields() {
+				sourcePrimaryFieldNames = append(sourcePrimaryFieldNames, field.Name)
+				sourcePrimaryDBNames = append(sourcePrimaryDBNames, field.DBName)
+			}
+			sourcePrimaryKeys := scope.getColumnAsArray(sourcePrimaryFieldNames, scope.Value)
+			newDB = newDB.Where(
+				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, sourcePrimaryDBNames), toQueryMarks(sourcePrimaryKeys)),
+				toQueryValues(sourcePrimaryKeys)...,
+			)
+
 			// set foreign key to be null if there are some records affected
 			modelValue := reflect.New(scope.GetModelStruct().ModelType).Interface()
 			if results := newDB.Model(modelValue).UpdateColumn(foreignKeyMap); results.Error == nil {
 				if results.RowsAffected > 0 {
-					scope.updatedAttrsWithValues(foreignKeyMap)
+					for _, source := range association.sources() {
+						foreignKeys := scope.getColumnAsArray(relationship.ForeignFieldNames, source.scope.Value)
+						for _, pk := range primaryKeys {
+							if len(foreignKeys) > 0 && equalAsString(foreignKeys[0], pk) {
+								source.scope.updatedAttrsWithValues(foreignKeyMap)
+								break
+							}
+						}
+					}
 				}
 			} else {
 				association.setErr(results.Error)
@@ -212,38 +357,43 @@ func (association *Association) Delete(values ...interface{}) *Association {
 			)
 
 			// set matched relation's foreign key to be null
-			fieldValue := reflect.New(association.field.Field.Type()).Interface()
+			fieldValue := reflect.New(fieldType).Interface()
 			association.setErr(newDB.Model(fieldValue).UpdateColumn(foreignKeyMap).Error)
 		}
 	}
 
 	// Remove deleted records from source's field
 	if association.Error == nil {
-		if field.Kind() == reflect.Slice {
-			leftValues := reflect.Zero(field.Type())
-
-			for i := 0; i < field.Len(); i++ {
-				reflectValue := field.Index(i)
-				primaryKey := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, reflectValue.Interface())[0]
-				var isDeleted = false
-				for _, pk := range deletingPrimaryKeys {
-					if equalAsString(primaryKey, pk) {
-						isDeleted = true
-						break
+		for _, source := range association.sources() {
+			field := source.field.Field
+
+			if field.Kind() == reflect.Slice {
+				leftValues := reflect.Zero(field.Type())
+
+				for i := 0; i < field.Len(); i++ {
+					reflectValue := field.Index(i)
+					primaryKey := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, reflectValue.Interface())[0]
+					var isDeleted = false
+					for _, pk := range deletingPrimaryKeys {
+						if equalAsString(primaryKey, pk) {
+							isDeleted = true
+							break
+						}
+					}
+					if !isDeleted {
+						leftValues = reflect.Append(leftValues, reflectValue)
 					}
 				}
-				if !isDeleted {
-					leftValues = reflect.Append(leftValues, reflectValue)
-				}
-			}
 
-			association.field.Set(leftValues)
-		} else if field.Kind() == reflect.Struct {
-			primaryKey := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, field.Interface())[0]
-			for _, pk := range deletingPrimaryKeys {
-				if equalAsString(primaryKey, pk) {
-					association.field.Set(reflect.Zero(field.Type()))
-					break
+				source.field.Set(leftValues)
+			} else if field.Kind() == reflect.Struct {
+				if primaryKeys := scope.getColumnAsArray(deletingResourcePrimaryFieldNames, field.Interface()); len(primaryKeys) > 0 {
+					for _, pk := range deletingPrimaryKeys {
+						if equalAsString(primaryKeys[0], pk) {
+							source.field.Set(reflect.Zero(field.Type()))
+							break
+						}
+					}
 				}
 			}
 		}
@@ -252,18 +402,51 @@ func (association *Association) Delete(values ...interface{}) *Association {
 	return association
 }
 
-// Clear remove relationship between source & current associations, won't delete those associations
+// Clear remove relationship between source & current associations, won't delete those associations,
+// only associations matching conditions are removed if there are any
+//    db.Model(&user).Association("Languages").Where("name = ?", "ZH").Clear()
 func (association *Association) Clear() *Association {
+	if association.Error != nil {
+		return association
+	}
+
+	if len(association.conditions) > 0 {
+		matched := association.newAssociationSlice()
+		if association.setErr(association.relatedDB().Find(matched).Error).Error != nil {
+			return association
+		}
+		return association.deleteRelations(matched)
+	}
+
+	if association.isBatch() {
+		sources := association.sources()
+		for _, source := range sources {
+			source.field.Set(reflect.Zero(source.field.Field.Type()))
+		}
+		return association.removeRelationsExcept(sources)
+	}
 	return association.Replace()
 }
 
 // Count return the count of current associations
 func (association *Association) Count() int {
+	var count = 0
+	if association.Error != nil {
+		return count
+	}
+
+	if err := association.relatedDB().Count(&count).Error; err != nil {
+		association.Error = err
+	}
+	return count
+}
+
+// relatedDB return DB to query current associations of sources with conditions
+func (association *Association) relatedDB() *DB {
 	var (
-		count        = 0
 		relationship = association.field.Relationship
 		scope        = association.scope
-		fieldValue   = association.field.Field.Interface()
+		fieldValue   = reflect.New(association.field.Struct.Type).Interface()
 		query        = scope.DB()
 	)
 
@@ -277,24 +460,207 @@ func (association *Association) Count() int {
 			toQueryValues(primaryKeys)...,
 		)
 	case "belongs_to":
-		primaryKeys := scope.getColumnAsArray(relationship.ForeignFieldNames, scope.Value)
+		primaryKeys := scope.getColumnAsArray(relationship.ForeignFieldNames, relationship.polymorphicSources(scope.Value)...)
 		query = query.Where(
 			fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.AssociationForeignDBNames), toQueryMarks(primaryKeys)),
 			toQueryValues(primaryKeys)...,
 		)
 	}
 
-	if relationship.PolymorphicType != "" {
+	if relationship.PolymorphicType != "" && relationship.Kind != "belongs_to" {
 		query = query.Where(
 			fmt.Sprintf("%v.%v = ?", scope.New(fieldValue).QuotedTableName(), scope.Quote(relationship.PolymorphicDBName)),
-			relationship.PolymorphicValue,
+			relationship.polymorphicValue(),
 		)
 	}
 
-	if err := query.Model(fieldValue).Count(&count).Error; err != nil {
-		association.Error = err
+	for _, condition := range association.conditions {
+		query = query.Where(condition[0], condition[1:]...)
 	}
-	return count
+	return query.Model(fieldValue)
+}
+
+// removeRelationsExcept remove relationships of sources except their current associations with one statement
+func (association *Association) removeRelationsExcept(sources []*Association) *Association {
+	var (
+		relationship = association.field.Relationship
+		scope        = association.scope
+		fieldType    = association.field.Struct.Type
+		newDB        = scope.NewDB()
+		conditions   []string
+		values       []interface{}
+	)
+
+	var foreignKeyMap = map[string]interface{}{}
+	for _, foreignKey := range relationship.ForeignDBNames {
+		foreignKeyMap[foreignKey] = nil
+	}
+
+	switch relationship.Kind {
+	case "belongs_to":
+		// set foreign key to be null for sources without association
+		var primaryFieldNames, primaryDBNames []string
+		for _, field := range scope.PrimaryFields() {
+			primaryFieldNames = append(primaryFieldNames, field.Name)
+			primaryDBNames = append(primaryDBNames, field.DBName)
+		}
+
+		var clearingSources []*Association
+		var clearingValues []interface{}
+		for _, source := range sources {
+			if isBlank(source.field.Field) {
+				clearingSources = append(clearingSources, source)
+				clearingValues = append(clearingValues, source.scope.Value)
+			}
+		}
+
+		if primaryKeys := scope.getColumnAsArray(primaryFieldNames, clearingValues...); len(primaryKeys) > 0 {
+			modelValue := reflect.New(scope.GetModelStruct().ModelType).Interface()
+			association.setErr(newDB.Model(modelValue).Where(
+				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, primaryDBNames), toQueryMarks(primaryKeys)),
+				toQueryValues(primaryKeys)...,
+			).UpdateColumn(foreignKeyMap).Error)
+
+			for _, source := range clearingSources {
+				source.scope.updatedAttrsWithValues(foreignKeyMap)
+			}
+		}
+	case "many_to_many":
+		associationScope := scope.New(reflect.New(fieldType).Interface())
+		sourceForeignFieldNames := fieldNamesOf(scope, relationship.ForeignFieldNames)
+		associationForeignFieldNames := fieldNamesOf(associationScope, relationship.AssociationForeignFieldNames)
+
+		for _, source := range sources {
+			sourceKeys := scope.getColumnAsArray(sourceForeignFieldNames, source.scope.Value)
+			if len(sourceKeys) == 0 {
+				continue
+			}
+
+			condition := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(sourceKeys))
+			values = append(values, toQueryValues(sourceKeys)...)
+			if keys := scope.getColumnAsArray(associationForeignFieldNames, source.field.Field.Interface()); len(keys) > 0 {
+				condition += fmt.Sprintf(" AND %v NOT IN (%v)", toQueryCondition(scope, relationship.AssociationForeignDBNames), toQueryMarks(keys))
+				values = append(values, toQueryValues(keys)...)
+			}
+			conditions = append(conditions, "("+condition+")")
+		}
+
+		if len(conditions) > 0 {
+			newDB = newDB.Where(strings.Join(conditions, " OR "), values...)
+			association.setErr(relationship.JoinTableHandler.Delete(relationship.JoinTableHandler, newDB))
+		}
+	case "has_one", "has_many":
+		var primaryFieldNames, primaryDBNames []string
+		for _, field := range scope.New(reflect.New(fieldType).Interface()).PrimaryFields() {
+			primaryFieldNames = append(primaryFieldNames, field.Name)
+			primaryDBNames = append(primaryDBNames, field.DBName)
+		}
+
+		for _, source := range sources {
+			sourceKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, source.scope.Value)
+			if len(sourceKeys) == 0 {
+				continue
+			}
+
+			condition := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(sourceKeys))
+			values = append(values, toQueryValues(sourceKeys)...)
+			if keys := scope.getColumnAsArray(primaryFieldNames, source.field.Field.Interface()); len(keys) > 0 {
+				condition += fmt.Sprintf(" AND %v NOT IN (%v)", toQueryCondition(scope, primaryDBNames), toQueryMarks(keys))
+				values = append(values, toQueryValues(keys)...)
+			}
+			conditions = append(conditions, "("+condition+")")
+		}
+
+		if len(conditions) > 0 {
+			if relationship.PolymorphicDBName != "" {
+				newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.polymorphicValue())
+			}
+
+			fieldValue := reflect.New(fieldType).Interface()
+			association.setErr(newDB.Model(fieldValue).Where(strings.Join(conditions, " OR "), values...).UpdateColumn(foreignKeyMap).Error)
+		}
+	}
+	return association
+}
+
+// saveJoinTableRecords create many2many relationships collected in batch mode
+func (association *Association) saveJoinTableRecords() *Association {
+	var (
+		records          = association.joinTableRecords
+		joinTableHandler = association.field.Relationship.JoinTableHandler
+		sources          []interface{}
+		destinations     []interface{}
+	)
+	association.joinTableRecords = nil
+
+	if len(records) == 0 || association.Error != nil {
+		return association
+	}
+
+	for _, record := range records {
+		sources = append(sources, record[0])
+		destinations = append(destinations, record[1])
+	}
+
+	// custom join table handlers might save extra data, so create relationships one by one for them
+	if handler, ok := joinTableHandler.(*JoinTableHandler); ok {
+		return association.setErr(handler.AddBatch(handler, association.scope.NewDB(), sources, destinations))
+	}
+
+	for idx := range sources {
+		association.setErr(joinTableHandler.Add(joinTableHandler, association.scope.NewDB(), sources[idx], destinations[idx]))
+	}
+	return association
+}
+
+// isBatch return true if the source is a slice
+func (association *Association) isBatch() bool {
+	return association.scope.IndirectValue().Kind() == reflect.Slice
+}
+
+// sources return associations of each record in batch mode, otherwise return itself
+func (association *Association) sources() []*Association {
+	if !association.isBatch() {
+		return []*Association{association}
+	}
+
+	var (
+		sources       []*Association
+		scope         = association.scope
+		indirectValue = scope.IndirectValue()
+	)
+
+	for i := 0; i < indirectValue.Len(); i++ {
+		value := indirectValue.Index(i)
+		if value.Kind() != reflect.Ptr && value.CanAddr() {
+			value = value.Addr()
+		}
+
+		sourceScope := scope.New(value.Interface())
+		sourceScope.db = sourceScope.db.Set("gorm:association:source", value.Interface())
+		if field, ok := sourceScope.FieldByName(association.column); ok {
+			sources = append(sources, &Association{scope: sourceScope, column: association.column, field: field, batch: association})
+		}
+	}
+	return sources
+}
+
+// batchSources return associations of each record, values should be given for each record
+func (association *Association) batchSources(values []interface{}) ([]*Association, error) {
+	sources := association.sources()
+	if len(sources) != len(values) {
+		return nil, fmt.Errorf("got %v values for %v records, values should be given for each record in batch mode", len(values), len(sources))
+	}
+	return sources, nil
+}
+
+// newAssociationSlice return a pointer of slice to find associations
+func (association *Association) newAssociationSlice() interface{} {
+	fieldType := association.field.Struct.Type
+	for fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return reflect.New(reflect.SliceOf(fieldType)).Interface()
 }
 
 // saveAssociations save passed values as associations
@@ -340,7 +706,11 @@ func (association *Association) saveAssociations(values ...interface{}) *Associa
 		}
 
 		if relationship.Kind == "many_to_many" {
-			association.setErr(relationship.JoinTableHandler.Add(relationship.JoinTableHandler, scope.NewDB(), scope.Value, reflectValue.Interface()))
+			if association.batch != nil {
+				association.batch.joinTableRecords = append(association.batch.joinTableRecords, [2]interface{}{scope.Value, reflectValue.Interface()})
+			} else {
+				association.setErr(relationship.JoinTableHandler.Add(relationship.JoinTableHandler, scope.NewDB(), scope.Value, reflectValue.Interface()))
+			}
 		} else {
 			association.setErr(scope.NewDB().Select(field.Name).Save(scope.Value).Error)
 
@@ -359,7 +729,12 @@ func (association *Association) saveAssociations(values ...interface{}) *Associa
 			saveAssociation(reflectValue)
 		} else if indirectReflectValue.Kind() == reflect.Slice {
 			for i := 0; i < indirectReflectValue.Len(); i++ {
-				saveAssociation(indirectReflectValue.Index(i))
+				// use addressable elements' pointer, so primary keys of new records could be set back
+				if elem := indirectReflectValue.Index(i); elem.Kind() != reflect.Ptr && elem.CanAddr() {
+					saveAssociation(elem.Addr())
+				} else {
+					saveAssociation(elem)
+				}
 			}
 		} else {
 			association.setErr(errors.New("invalid value type"))
@@ -375,3 +750,26 @@ func (association *Association) setErr(err error) *Association {
 	}
 	return association
 }
+
+// fieldNamesOf convert field names or db names to field names of the scope
+func fieldNamesOf(scope *Scope, names []string) (fieldNames []string) {
+	for _, name := range names {
+		if field, ok := scope.FieldByName(name); ok {
+			fieldNames = append(fieldNames, field.path())
+		}
+	}
+	return
+}
+
+// isPrimaryKeyBlank check source's primary key, or primary key of each record in batch mode
+func isPrimaryKeyBlank(scope *Scope) bool {
+	if indirectValue := scope.IndirectValue(); indirectValue.Kind() == reflect.Slice {
+		for i := 0; i < indirectValue.Len(); i++ {
+			if scope.New(indirectValue.Index(i).Interface()).PrimaryKeyZero() {
+				return true
+			}
+		}
+		return false
+	}
+	return scope.PrimaryField().IsBlank
+}
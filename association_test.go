@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
 )
 
 func TestBelongsTo(t *testing.T) {
@@ -1048,3 +1049,96 @@ func TestAutoSaveMany2ManyAssociation(t *testing.T) {
 		t.Errorf("Relationship should been updated")
 	}
 }
+
+func TestMany2ManyInBatchMode(t *testing.T) {
+	users := []User{{Name: "batch-user1"}, {Name: "batch-user2"}}
+	DB.Save(&users[0]).Save(&users[1])
+
+	languages1 := []Language{{Name: "batch-EN"}, {Name: "batch-ZH"}}
+	languages2 := []Language{{Name: "batch-DE"}}
+	DB.Model(&users).Association("Languages").Append(&languages1, &languages2)
+
+	if len(users[0].Languages) != 2 || len(users[1].Languages) != 1 {
+		t.Errorf("languages should be appended to each user, but got %v, %v", len(users[0].Languages), len(users[1].Languages))
+	}
+
+	if count := DB.Model(&users).Association("Languages").Count(); count != 3 {
+		t.Errorf("should find 3 languages for users, but got %v", count)
+	}
+
+	if err := DB.Model(&users).Association("Languages").Append(&languages1).Error; err == nil {
+		t.Errorf("should return error if values aren't given for each record")
+	}
+
+	// Replace
+	DB.Model(&users).Association("Languages").Replace(&languages2, []Language{languages1[0], languages1[1]})
+	if DB.Model(&users[0]).Association("Languages").Count() != 1 || DB.Model(&users[1]).Association("Languages").Count() != 2 {
+		t.Errorf("languages of each user should be replaced")
+	}
+
+	// Delete with conditions
+	DB.Model(&users).Association("Languages").Where("name = ?", "batch-ZH").Delete(&languages1)
+	if DB.Model(&users[1]).Association("Languages").Count() != 1 || len(users[1].Languages) != 1 {
+		t.Errorf("only languages matching conditions should be deleted")
+	}
+
+	// Clear with conditions
+	DB.Model(&users).Association("Languages").Where("name = ?", "batch-DE").Clear()
+	if DB.Model(&users[0]).Association("Languages").Count() != 0 || DB.Model(&users[1]).Association("Languages").Count() != 1 {
+		t.Errorf("only languages matching conditions should be cleared")
+	}
+
+	// Clear
+	DB.Model(&users).Association("Languages").Clear()
+	if count := DB.Model(&users).Association("Languages").Count(); count != 0 || len(users[1].Languages) != 0 {
+		t.Errorf("languages of all users should be cleared, but got %v", count)
+	}
+}
+
+func TestHasManyInBatchMode(t *testing.T) {
+	users := []*User{{Name: "batch-user3"}, {Name: "batch-user4"}}
+	DB.Save(users[0]).Save(users[1])
+
+	DB.Model(&users).Association("Emails").Append(
+		[]Email{{Email: "batch-user3@example.com"}, {Email: "batch-user3@example.org"}},
+		Email{Email: "batch-user4@example.com"},
+	)
+
+	var emails []Email
+	DB.Model(&users).Association("Emails").Find(&emails)
+	if len(emails) != 3 {
+		t.Errorf("should find emails of all users, but got %v", len(emails))
+	}
+
+	DB.Model(&users).Association("Emails").Where("email LIKE ?", "%.org").Clear()
+	if DB.Model(users[0]).Association("Emails").Count() != 1 {
+		t.Errorf("only emails matching conditions should be cleared")
+	}
+
+	DB.Model(&users).Association("Emails").Replace(Email{Email: "batch-user3@example.net"}, []Email{})
+	if DB.Model(users[0]).Association("Emails").Count() != 1 || DB.Model(users[1]).Association("Emails").Count() != 0 {
+		t.Errorf("emails of each user should be replaced")
+	}
+}
+
+func TestBatchAppendManyToManyStatements(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	// the first relationship exists, values of the insert statement are typed by columns of the join table
+	recorder.Reply(`SELECT "language_id","user_id" FROM "user_languages" WHERE ("language_id" = $1 AND "user_id" = $2) OR ("language_id" = $3 AND "user_id" = $4) OR ("language_id" = $5 AND "user_id" = $6)`,
+		[]string{"language_id", "user_id"}, []interface{}{int64(1), int64(1)})
+
+	users := []User{{Id: 1}, {Id: 2}, {Id: 3}}
+	languages := []interface{}{&Language{Model: gorm.Model{ID: 1}}, &Language{Model: gorm.Model{ID: 2}}, &Language{Model: gorm.Model{ID: 3}}}
+	if err := db.Model(&users).Association("Languages").Append(languages...).Error; err != nil {
+		t.Fatalf("failed to append languages, got %v", err)
+	}
+
+	statement := recorder.LastStatement()
+	if statement.SQL != `INSERT INTO "user_languages" ("language_id","user_id") VALUES ($1,$2),($3,$4)` || fmt.Sprint(statement.Vars) != "[2 2 3 3]" {
+		t.Errorf("missing relationships should be inserted with values, but got %v %v", statement.SQL, statement.Vars)
+	}
+}
@@ -0,0 +1,290 @@
+package gorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type actorKey struct{}
+
+// WithActor return a context carrying the actor who is changing records, e.g. current user's id,
+// it is recorded by Auditor, and filled into `CreatedBy`, `UpdatedBy` fields like `CreatedAt`, `UpdatedAt`
+//    db.WithContext(gorm.WithActor(ctx, currentUser.ID)).Save(&product)
+func WithActor(ctx context.Context, actor interface{}) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext return the actor set with WithActor
+func ActorFromContext(ctx context.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+// AuditLog records a created, updated or deleted record
+type AuditLog struct {
+	ID         uint64 `gorm:"primary_key"`
+	Model      string `gorm:"size:255;index:idx_audit_logs_record"` // table name of the record
+	PrimaryKey string `gorm:"size:255;index:idx_audit_logs_record"`
+	Action     string `gorm:"size:16"`
+	Changes    string `gorm:"type:text"` // JSON of changed columns, e.g. {"name": {"old": "jinzhu", "new": "hello"}}
+	Actor      string `gorm:"size:255"`
+	CreatedAt  time.Time
+}
+
+// AuditMask value recorded for columns tagged with `encrypted` instead of their plaintext
+const AuditMask = "[ENCRYPTED]"
+
+// AuditChange old and new value of a changed column, values of encrypted columns are recorded as AuditMask
+type AuditChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// Auditor plugin records every creating, updating and deleting into `audit_logs` in the same transaction of the change,
+// with changed columns and the actor set by WithActor, migrate the table before using it
+//    db.AutoMigrate(&gorm.AuditLog{})
+//    db.Use(&gorm.Auditor{})
+//
+// Records changed in bulk by conditions are loaded before changing them, an audit log is recorded for each of them
+type Auditor struct {
+	// Tables audited tables, audit all tables if it is blank
+	Tables []string
+}
+
+// Name return plugin name
+func (auditor *Auditor) Name() string {
+	return "gorm:audit"
+}
+
+// Initialize register callbacks to record changes
+func (auditor *Auditor) Initialize(db *DB) error {
+	callback := db.Callback()
+	callback.Create().After("gorm:create").Register("gorm:audit", auditor.auditCreateCallback)
+	callback.Update().Before("gorm:update").Register("gorm:audit_snapshot", auditor.snapshotCallback)
+	callback.Update().After("gorm:update").Register("gorm:audit", auditor.auditUpdateCallback)
+	callback.Delete().Before("gorm:delete").Register("gorm:audit_snapshot", auditor.snapshotCallback)
+	callback.Delete().After("gorm:delete").Register("gorm:audit", auditor.auditDeleteCallback)
+	return nil
+}
+
+func (auditor *Auditor) auditable(scope *Scope) bool {
+	if _, ok := scope.Value.(*AuditLog); ok || scope.HasError() {
+		return false
+	}
+	return len(auditor.Tables) == 0 || strInSlice(scope.TableName(), auditor.Tables)
+}
+
+// snapshotCallback load current record before updating or deleting it to find out changed values,
+// records matched by conditions are loaded if the model doesn't have primary keys, e.g. `db.Model(&Product{}).Where("price > ?", 100).Updates(...)`
+func (auditor *Auditor) snapshotCallback(scope *Scope) {
+	if !auditor.auditable(scope) || scope.IndirectValue().Kind() != reflect.Struct || len(scope.PrimaryFields()) == 0 {
+		return
+	}
+
+	if scope.PrimaryKeyZero() {
+		snapshots := reflect.New(reflect.SliceOf(scope.GetModelStruct().ModelType))
+		db := scope.NewDB()
+		db.search = scope.Search.clone()
+		db.search.db = db
+		db.search.selects, db.search.omits, db.search.preload = nil, nil, nil
+
+		if scope.Err(db.Find(snapshots.Interface()).Error) == nil {
+			scope.InstanceSet("gorm:audit_snapshots", snapshots.Elem())
+		}
+		return
+	}
+
+	snapshot := reflect.New(scope.GetModelStruct().ModelType)
+	db := scope.NewDB().Unscoped().Table(scope.TableName())
+	for _, field := range scope.PrimaryFields() {
+		db = db.Where(fmt.Sprintf("%v = ?", scope.Quote(field.DBName)), field.Field.Interface())
+	}
+
+	if db = db.Take(snapshot.Interface()); db.Error == nil && db.RowsAffected > 0 {
+		scope.InstanceSet("gorm:audit_snapshot", snapshot.Elem())
+	}
+}
+
+// snapshots return scopes of records loaded by snapshotCallback, the second result is false if they are not loaded in bulk
+func (auditor *Auditor) snapshots(scope *Scope) ([]*Scope, bool) {
+	if value, ok := scope.InstanceGet("gorm:audit_snapshots"); ok {
+		var scopes []*Scope
+		for records, i := value.(reflect.Value), 0; i < records.Len(); i++ {
+			scopes = append(scopes, scope.New(records.Index(i).Addr().Interface()))
+		}
+		return scopes, true
+	}
+
+	if value, ok := scope.InstanceGet("gorm:audit_snapshot"); ok {
+		return []*Scope{scope.New(value.(reflect.Value).Addr().Interface())}, false
+	}
+	return nil, false
+}
+
+func (auditor *Auditor) auditCreateCallback(scope *Scope) {
+	if !auditor.auditable(scope) {
+		return
+	}
+
+	changes := map[string]AuditChange{}
+	for _, field := range scope.Fields() {
+		if field.IsNormal && !field.IsIgnored && !field.IsBlank {
+			changes[field.DBName] = AuditChange{New: auditFieldValue(field.StructField, field.Field.Interface())}
+		}
+	}
+	auditor.record(scope, "create", auditPrimaryKey(scope), changes)
+}
+
+func (auditor *Auditor) auditUpdateCallback(scope *Scope) {
+	if !auditor.auditable(scope) || scope.db.RowsAffected == 0 {
+		return
+	}
+
+	snapshots, bulk := auditor.snapshots(scope)
+	if !bulk {
+		var snapshot *Scope
+		if len(snapshots) > 0 {
+			snapshot = snapshots[0]
+		}
+		if changes := auditor.updateChanges(scope, snapshot); len(changes) > 0 {
+			auditor.record(scope, "update", auditPrimaryKey(scope), changes)
+		}
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		if changes := auditor.updateChanges(scope, snapshot); len(changes) > 0 {
+			auditor.record(scope, "update", auditPrimaryKey(snapshot), changes)
+		}
+	}
+}
+
+// updateChanges return changed columns of the update, old values are taken from the snapshot if it isn't nil
+func (auditor *Auditor) updateChanges(scope *Scope, snapshot *Scope) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	addChange := func(column string, value interface{}) {
+		var structField *StructField
+		if field, ok := scope.FieldByName(column); ok {
+			structField = field.StructField
+		}
+
+		change := AuditChange{New: auditFieldValue(structField, value)}
+		if snapshot != nil {
+			if field, ok := snapshot.FieldByName(column); ok {
+				if equalAsString(field.Field.Interface(), value) {
+					return
+				}
+				change.Old = auditFieldValue(field.StructField, field.Field.Interface())
+			}
+		}
+		changes[column] = change
+	}
+
+	if updateAttrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		for column, value := range updateAttrs.(map[string]interface{}) {
+			if expr, ok := value.(*SqlExpr); ok {
+				value = expr.expr
+			}
+			addChange(column, value)
+		}
+	} else {
+		for _, field := range scope.Fields() {
+			if field.IsNormal && !field.IsIgnored && !field.IsPrimaryKey && scope.changeableField(field) {
+				addChange(field.DBName, field.Field.Interface())
+			}
+		}
+	}
+	return changes
+}
+
+func (auditor *Auditor) auditDeleteCallback(scope *Scope) {
+	if !auditor.auditable(scope) || scope.db.RowsAffected == 0 {
+		return
+	}
+
+	snapshots, bulk := auditor.snapshots(scope)
+	if !bulk {
+		changes := map[string]AuditChange{}
+		if len(snapshots) > 0 {
+			changes = deleteChanges(snapshots[0])
+		}
+		auditor.record(scope, "delete", auditPrimaryKey(scope), changes)
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		auditor.record(scope, "delete", auditPrimaryKey(snapshot), deleteChanges(snapshot))
+	}
+}
+
+// deleteChanges return old values of the deleted record
+func deleteChanges(snapshot *Scope) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	for _, field := range snapshot.Fields() {
+		if field.IsNormal && !field.IsIgnored && !field.IsBlank {
+			changes[field.DBName] = AuditChange{Old: auditFieldValue(field.StructField, field.Field.Interface())}
+		}
+	}
+	return changes
+}
+
+// record save audit log with the same transaction of the change
+func (auditor *Auditor) record(scope *Scope, action, primaryKey string, changes map[string]AuditChange) {
+	if scope.HasError() {
+		return
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		scope.Err(fmt.Errorf("failed to audit %v, %v", scope.TableName(), err))
+		return
+	}
+
+	auditLog := AuditLog{Model: scope.TableName(), PrimaryKey: primaryKey, Action: action, Changes: string(data)}
+	if actor, ok := ActorFromContext(scope.Context()); ok {
+		auditLog.Actor = fmt.Sprint(actor)
+	}
+
+	scope.Err(scope.NewDB().Create(&auditLog).Error)
+}
+
+// auditPrimaryKey return primary keys of the record joined with comma, blank if it isn't a record with primary keys
+func auditPrimaryKey(scope *Scope) string {
+	if scope.IndirectValue().Kind() != reflect.Struct || scope.PrimaryKeyZero() {
+		return ""
+	}
+
+	var primaryKeys []string
+	for _, field := range scope.PrimaryFields() {
+		primaryKeys = append(primaryKeys, toString(field.Field.Interface()))
+	}
+	return strings.Join(primaryKeys, ",")
+}
+
+// auditFieldValue return value of the field to audit, encrypted fields are masked to not leak their plaintext into audit logs
+func auditFieldValue(field *StructField, value interface{}) interface{} {
+	if field != nil {
+		if encrypted, _ := isEncryptedField(field); encrypted {
+			return AuditMask
+		}
+	}
+	return auditValue(value)
+}
+
+// auditValue return value saved into database
+func auditValue(value interface{}) interface{} {
+	if valuer, ok := value.(driver.Valuer); ok && !isNilPointer(value) {
+		if v, err := valuer.Value(); err == nil {
+			return v
+		}
+	}
+	return value
+}
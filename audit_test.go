@@ -0,0 +1,203 @@
+package gorm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type AuditedProduct struct {
+	ID    uint
+	Code  string
+	Price int
+}
+
+func TestAuditor(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&AuditedProduct{}, &gorm.AuditLog{})
+	if err := db.AutoMigrate(&AuditedProduct{}, &gorm.AuditLog{}).Error; err != nil {
+		t.Fatalf("Failed to migrate audit tables, got %v", err)
+	}
+
+	if err := db.Use(&gorm.Auditor{Tables: []string{"audited_products"}}); err != nil {
+		t.Fatalf("Failed to use auditor, got %v", err)
+	}
+
+	ctxDB := db.WithContext(gorm.WithActor(context.Background(), 42))
+	product := AuditedProduct{Code: "L1212", Price: 1000}
+	ctxDB.Create(&product)
+	ctxDB.Model(&product).Updates(map[string]interface{}{"code": "L1212", "price": 2000})
+	ctxDB.Delete(&product)
+	db.Create(&User{Name: "not_audited"})
+
+	var logs []gorm.AuditLog
+	db.Order("id").Find(&logs)
+	if len(logs) != 3 {
+		t.Fatalf("Should record 3 audit logs, but got %v", len(logs))
+	}
+
+	for idx, action := range []string{"create", "update", "delete"} {
+		if logs[idx].Action != action || logs[idx].Model != "audited_products" || logs[idx].Actor != "42" || logs[idx].PrimaryKey != fmt.Sprint(product.ID) {
+			t.Errorf("Audit log of %v is not correct, got %#v", action, logs[idx])
+		}
+	}
+
+	var changes map[string]gorm.AuditChange
+	json.Unmarshal([]byte(logs[1].Changes), &changes)
+	if len(changes) != 1 || fmt.Sprint(changes["price"].Old) != "1000" || fmt.Sprint(changes["price"].New) != "2000" {
+		t.Errorf("Should only record changed columns with old and new values, but got %v", logs[1].Changes)
+	}
+
+	json.Unmarshal([]byte(logs[2].Changes), &changes)
+	if fmt.Sprint(changes["code"].Old) != "L1212" {
+		t.Errorf("Should record old values of deleted record, but got %v", logs[2].Changes)
+	}
+
+	// audit logs are rolled back with the change
+	db.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&AuditedProduct{Code: "L1213"})
+		return errors.New("rollback")
+	})
+
+	var count int
+	if db.Model(&gorm.AuditLog{}).Count(&count); count != 3 {
+		t.Errorf("Audit logs should be rolled back with the transaction, but got %v logs", count)
+	}
+}
+
+type AuditedCustomer struct {
+	ID    uint
+	Name  string
+	Email string `gorm:"encrypted;size:255"`
+}
+
+func TestAuditorMasksEncryptedColumns(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&AuditedCustomer{}, &gorm.AuditLog{})
+	if err := db.AutoMigrate(&AuditedCustomer{}, &gorm.AuditLog{}).Error; err != nil {
+		t.Fatalf("Failed to migrate audit tables, got %v", err)
+	}
+
+	if err := db.Use(&gorm.Encryption{Keys: gorm.StaticKey("0123456789abcdef0123456789abcdef")}); err != nil {
+		t.Fatalf("Failed to use encryption, got %v", err)
+	}
+	if err := db.Use(&gorm.Auditor{Tables: []string{"audited_customers"}}); err != nil {
+		t.Fatalf("Failed to use auditor, got %v", err)
+	}
+
+	customer := AuditedCustomer{Name: "jinzhu", Email: "jinzhu@example.org"}
+	db.Create(&customer)
+	db.Model(&customer).Updates(map[string]interface{}{"email": "hello@example.org"})
+	customer.Email = "world@example.org"
+	db.Save(&customer)
+	db.Delete(&customer)
+
+	var logs []gorm.AuditLog
+	db.Order("id").Find(&logs)
+	if len(logs) != 4 {
+		t.Fatalf("Should record 4 audit logs, but got %v", len(logs))
+	}
+
+	for _, log := range logs {
+		if strings.Contains(log.Changes, "example.org") {
+			t.Errorf("Audit log of %v should not contain plaintext of encrypted columns, got %v", log.Action, log.Changes)
+		}
+
+		var changes map[string]gorm.AuditChange
+		json.Unmarshal([]byte(log.Changes), &changes)
+		if change := changes["email"]; change.Old != gorm.AuditMask && change.New != gorm.AuditMask {
+			t.Errorf("Audit log of %v should record encrypted column as masked, got %v", log.Action, log.Changes)
+		}
+	}
+}
+
+func TestAuditorWithBulkChanges(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&AuditedProduct{}, &gorm.AuditLog{})
+	if err := db.AutoMigrate(&AuditedProduct{}, &gorm.AuditLog{}).Error; err != nil {
+		t.Fatalf("Failed to migrate audit tables, got %v", err)
+	}
+
+	products := []AuditedProduct{{Code: "B1", Price: 100}, {Code: "B2", Price: 200}, {Code: "B3", Price: 300}}
+	for i := range products {
+		db.Create(&products[i])
+	}
+
+	if err := db.Use(&gorm.Auditor{Tables: []string{"audited_products"}}); err != nil {
+		t.Fatalf("Failed to use auditor, got %v", err)
+	}
+
+	db.Model(&AuditedProduct{}).Where("price > ?", 100).Updates(map[string]interface{}{"price": 500})
+	db.Model(&AuditedProduct{}).Where("code = ?", "B1").UpdateColumn("code", "B0")
+	db.Where("price = ?", 500).Delete(&AuditedProduct{})
+
+	var logs []gorm.AuditLog
+	db.Order("id").Find(&logs)
+	if len(logs) != 5 {
+		t.Fatalf("Should record an audit log for each changed record, but got %v", len(logs))
+	}
+
+	expects := []struct {
+		action     string
+		primaryKey uint
+		column     string
+		old        string
+	}{
+		{"update", products[1].ID, "price", "200"},
+		{"update", products[2].ID, "price", "300"},
+		{"update", products[0].ID, "code", "B1"},
+		{"delete", products[1].ID, "code", "B2"},
+		{"delete", products[2].ID, "code", "B3"},
+	}
+	for idx, expect := range expects {
+		var changes map[string]gorm.AuditChange
+		json.Unmarshal([]byte(logs[idx].Changes), &changes)
+		if logs[idx].Action != expect.action || logs[idx].PrimaryKey != fmt.Sprint(expect.primaryKey) || fmt.Sprint(changes[expect.column].Old) != expect.old {
+			t.Errorf("Audit log %v should record primary key and old value of the record, got %#v", idx, logs[idx])
+		}
+	}
+}
+
+type AuditedInvoice struct {
+	ID        uint
+	Amount    int
+	UpdatedBy []int
+}
+
+func TestUpdatedByError(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	ctxDB := db.WithContext(gorm.WithActor(context.Background(), "jinzhu"))
+	if err := ctxDB.Model(&AuditedInvoice{ID: 1}).Updates(map[string]interface{}{"amount": 10}).Error; err == nil {
+		t.Errorf("Should get error when the actor can't be set to UpdatedBy")
+	}
+	for _, statement := range recorder.Statements() {
+		if strings.HasPrefix(statement.SQL, "UPDATE") {
+			t.Errorf("Should not update the record when failed to set UpdatedBy, but got %v", statement.SQL)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// batchPrimaryKeysSize max number of primary keys in one statement when deleting or updating a slice of records
+const batchPrimaryKeysSize = 1000
+
+// byPrimaryKeys return true if the value is a slice of structs with primary keys and no conditions are given,
+// records of the slice will be deleted or updated by their primary keys
+func (s *DB) byPrimaryKeys(value interface{}, where ...interface{}) bool {
+	if value == nil || indirect(reflect.ValueOf(value)).Kind() != reflect.Slice || len(where) > 0 {
+		return false
+	}
+
+	scope := s.NewScope(value)
+	if scope.hasConditions() {
+		return false
+	}
+	modelStruct := scope.GetModelStruct()
+	return modelStruct.ModelType != nil && len(modelStruct.PrimaryFields) > 0
+}
+
+// batchByPrimaryKeys run fc with chunks of the slice of records, each chunk is deleted or updated by primary keys of its records in one statement,
+// RowsAffected is the sum of all chunks
+func (s *DB) batchByPrimaryKeys(value interface{}, fc func(chunk interface{}) *DB) *DB {
+	var (
+		records = indirect(reflect.ValueOf(value))
+		result  = s.clone()
+	)
+
+	for i := 0; i < records.Len(); i++ {
+		scope := s.NewScope(records.Index(i).Interface())
+		for _, field := range scope.PrimaryFields() {
+			if field.IsBlank {
+				result.AddError(fmt.Errorf("primary key %v of record %v is blank", field.Name, i))
+				return result
+			}
+		}
+	}
+
+	for start := 0; start < records.Len(); start += batchPrimaryKeysSize {
+		end := start + batchPrimaryKeysSize
+		if end > records.Len() {
+			end = records.Len()
+		}
+
+		chunk := reflect.New(records.Type())
+		chunk.Elem().Set(records.Slice(start, end))
+
+		db := fc(chunk.Interface())
+		result.RowsAffected += db.RowsAffected
+		if db.Error != nil {
+			result.Error = db.Error
+			break
+		}
+	}
+	return result
+}
+
+// batchPrimaryCondition return condition of primary keys of the slice of records, e.g. `"users"."id" IN (1,2,3)`
+func (scope *Scope) batchPrimaryCondition() string {
+	var (
+		quotedTableName = scope.QuotedTableName()
+		records         = scope.IndirectValue()
+		primaryFields   = scope.GetModelStruct().PrimaryFields
+		conditions      []string
+	)
+
+	if len(primaryFields) == 1 {
+		var keys []string
+		for i := 0; i < records.Len(); i++ {
+			if field, ok := scope.New(records.Index(i).Interface()).FieldByName(primaryFields[0].Name); ok {
+				keys = append(keys, scope.AddToVars(field.Field.Interface()))
+			}
+		}
+		if len(keys) == 0 {
+			return "1 <> 1"
+		}
+		return fmt.Sprintf("%v.%v IN (%v)", quotedTableName, scope.Quote(primaryFields[0].DBName), strings.Join(keys, ","))
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		var keyConditions []string
+		for _, field := range scope.New(records.Index(i).Interface()).PrimaryFields() {
+			keyConditions = append(keyConditions, fmt.Sprintf("%v.%v = %v", quotedTableName, scope.Quote(field.DBName), scope.AddToVars(field.Field.Interface())))
+		}
+		conditions = append(conditions, "("+strings.Join(keyConditions, " AND ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "1 <> 1"
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
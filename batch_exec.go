@@ -0,0 +1,261 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// BatchOptions options of batches, refer Batch
+type BatchOptions struct {
+	// MultiStatements join consecutive Exec statements with `;` and send them in one round trip,
+	// the driver should support multiple statements with args, e.g. sqlite3, or mysql with `multiStatements=true&interpolateParams=true`,
+	// joined statements aren't prepared, and their RowsAffected depends on the driver
+	MultiStatements bool
+	// Size max number of statements joined in one round trip with MultiStatements, it is 100 by default
+	Size int
+}
+
+// Batch queue of operations flushed together in a transaction, it isn't safe for concurrent use
+type Batch struct {
+	db         *DB
+	options    BatchOptions
+	operations []batchOperation
+}
+
+type batchOperation struct {
+	sql  string // SQL of Exec
+	vars []interface{}
+	fc   func(tx *DB) *DB
+}
+
+// Batch return a batch queuing operations until Flush, they are run in a transaction, or a nested transaction if the db is in a transaction,
+// statements repeated by operations are prepared once and reused, so backfills save round trips of parsing, e.g:
+//    batch := db.Batch()
+//    for _, user := range users {
+//      batch.Exec("UPDATE users SET score = ? WHERE id = ?", score(user), user.ID)
+//    }
+//    batch.Create(&AuditLog{Action: "backfill scores"})
+//    result := batch.Flush()
+//    // result.Error, result.RowsAffected
+//
+// Exec statements are sent in round trips of 100 statements with MultiStatements, if the driver supports multiple statements:
+//    db.Batch(gorm.BatchOptions{MultiStatements: true})
+func (s *DB) Batch(options ...BatchOptions) *Batch {
+	batch := &Batch{db: s}
+	if len(options) > 0 {
+		batch.options = options[0]
+	}
+	if batch.options.Size <= 0 {
+		batch.options.Size = 100
+	}
+	return batch
+}
+
+// Exec queue a statement like `db.Exec`
+func (batch *Batch) Exec(sql string, values ...interface{}) *Batch {
+	batch.operations = append(batch.operations, batchOperation{sql: sql, vars: values, fc: func(tx *DB) *DB {
+		return tx.Exec(sql, values...)
+	}})
+	return batch
+}
+
+// Create queue creating the value like `db.Create`, its primary key is set after flushing
+func (batch *Batch) Create(value interface{}) *Batch {
+	return batch.Do(func(tx *DB) *DB {
+		return tx.Create(value)
+	})
+}
+
+// Updates queue updating the model with values like `db.Model(model).Updates(values)`
+func (batch *Batch) Updates(model interface{}, values interface{}) *Batch {
+	return batch.Do(func(tx *DB) *DB {
+		return tx.Model(model).Updates(values)
+	})
+}
+
+// Delete queue deleting the value like `db.Delete`
+func (batch *Batch) Delete(value interface{}, where ...interface{}) *Batch {
+	return batch.Do(func(tx *DB) *DB {
+		return tx.Delete(value, where...)
+	})
+}
+
+// Do queue an operation run with the transaction of the batch
+//    batch.Do(func(tx *gorm.DB) *gorm.DB {
+//      return tx.Model(&User{}).Where("active = ?", false).Update("archived", true)
+//    })
+func (batch *Batch) Do(fc func(tx *DB) *DB) *Batch {
+	batch.operations = append(batch.operations, batchOperation{fc: fc})
+	return batch
+}
+
+// Len return the number of queued operations
+func (batch *Batch) Len() int {
+	return len(batch.operations)
+}
+
+// Flush run queued operations in a transaction, it is rolled back if any operation fails, the queue is cleared in both cases,
+// RowsAffected of the returned db is the sum of all operations
+func (batch *Batch) Flush() *DB {
+	var (
+		operations   = batch.operations
+		rowsAffected int64
+		result       = batch.db.clone()
+	)
+	batch.operations = nil
+
+	if len(operations) == 0 {
+		return result
+	}
+
+	err := batch.db.Transaction(func(tx *DB) error {
+		if tx.Error != nil {
+			return tx.Error
+		}
+
+		stmts := &preparedTx{SQLCommon: tx.db.dbSQL, stmts: map[string]*sql.Stmt{}, executed: map[string]bool{}}
+		defer stmts.Close()
+
+		// multiple statements can't be prepared, they are executed with the transaction directly
+		joined := tx
+		tx = tx.clone()
+		tx.db.dbSQL = stmts
+		tx.db.dbSQLSlave = nil // the wrapper isn't *sql.Tx, queries of operations shouldn't be routed to the slave
+		tx.refreshDialect()
+
+		for i := 0; i < len(operations); i++ {
+			db := batch.joinedExec(joined, operations, &i)
+			if db == nil {
+				db = operations[i].fc(tx)
+			}
+			if db.Error != nil {
+				return db.Error
+			}
+			rowsAffected += db.RowsAffected
+		}
+		return nil
+	})
+
+	result.AddError(err)
+	if err == nil {
+		result.RowsAffected = rowsAffected
+	}
+	return result
+}
+
+// joinedExec run consecutive Exec statements from the i-th operation in one round trip with MultiStatements, i is moved to the last joined one,
+// it returns nil if statements aren't joined
+func (batch *Batch) joinedExec(tx *DB, operations []batchOperation, i *int) *DB {
+	if !batch.options.MultiStatements || operations[*i].sql == "" {
+		return nil
+	}
+
+	var (
+		statements []string
+		vars       []interface{}
+	)
+	for j := *i; j < len(operations) && operations[j].sql != "" && len(statements) < batch.options.Size; j++ {
+		statements = append(statements, strings.TrimRight(strings.TrimSpace(operations[j].sql), ";"))
+		vars = append(vars, operations[j].vars...)
+	}
+	if len(statements) < 2 {
+		return nil
+	}
+
+	*i += len(statements) - 1
+	return tx.Exec(strings.Join(statements, "; "), vars...)
+}
+
+// maxPreparedStatements max statements kept prepared by a batch, the least recently prepared one is closed when exceeding it
+const maxPreparedStatements = 100
+
+// preparedTx a transaction reusing prepared statements of Exec, statements are prepared when they are executed the second time,
+// so statements executed once don't cost another round trip
+type preparedTx struct {
+	SQLCommon
+	stmts    map[string]*sql.Stmt
+	queries  []string // prepared queries in preparing order
+	executed map[string]bool
+}
+
+// stmt return the prepared statement of the query, it returns nil if the query is executed the first time
+func (tx *preparedTx) stmt(query string) (*sql.Stmt, error) {
+	if stmt, ok := tx.stmts[query]; ok {
+		return stmt, nil
+	}
+	if !tx.executed[query] {
+		tx.executed[query] = true
+		return nil, nil
+	}
+
+	stmt, err := tx.SQLCommon.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.queries) >= maxPreparedStatements {
+		oldest := tx.queries[0]
+		tx.stmts[oldest].Close()
+		delete(tx.stmts, oldest)
+		tx.queries = tx.queries[1:]
+	}
+	tx.stmts[query] = stmt
+	tx.queries = append(tx.queries, query)
+	return stmt, nil
+}
+
+func (tx *preparedTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := tx.stmt(query)
+	if err != nil {
+		return nil, err
+	} else if stmt == nil {
+		return tx.SQLCommon.Exec(query, args...)
+	}
+	return stmt.Exec(args...)
+}
+
+func (tx *preparedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := tx.stmt(query)
+	if err != nil {
+		return nil, err
+	} else if stmt == nil {
+		if execer, ok := tx.SQLCommon.(sqlExecContext); ok {
+			return execer.ExecContext(ctx, query, args...)
+		}
+		return tx.SQLCommon.Exec(query, args...)
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext query with the context of the transaction, so queries of operations keep statement timeouts
+func (tx *preparedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if queryer, ok := tx.SQLCommon.(sqlQueryContext); ok {
+		return queryer.QueryContext(ctx, query, args...)
+	}
+	return tx.SQLCommon.Query(query, args...)
+}
+
+// QueryRowContext query row with the context of the transaction
+func (tx *preparedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if queryer, ok := tx.SQLCommon.(sqlQueryContext); ok {
+		return queryer.QueryRowContext(ctx, query, args...)
+	}
+	return tx.SQLCommon.QueryRow(query, args...)
+}
+
+// Commit commit the transaction, it makes the batch work as a transaction for callbacks
+func (tx *preparedTx) Commit() error {
+	return tx.SQLCommon.(sqlTx).Commit()
+}
+
+// Rollback rollback the transaction
+func (tx *preparedTx) Rollback() error {
+	return tx.SQLCommon.(sqlTx).Rollback()
+}
+
+// Close close prepared statements
+func (tx *preparedTx) Close() {
+	for _, stmt := range tx.stmts {
+		stmt.Close()
+	}
+}
@@ -0,0 +1,99 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type preparingDB struct {
+	*sql.DB
+	prepared []string
+}
+
+func (db *preparingDB) Prepare(query string) (*sql.Stmt, error) {
+	db.prepared = append(db.prepared, query)
+	return db.DB.Prepare(query)
+}
+
+func TestPreparedTx(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+
+	db := &preparingDB{DB: sqlDB}
+	tx := &preparedTx{SQLCommon: db, stmts: map[string]*sql.Stmt{}, executed: map[string]bool{}}
+	defer tx.Close()
+
+	if _, err := tx.Exec("CREATE TABLE prepared_values (value integer)"); err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := tx.Exec("INSERT INTO prepared_values (value) VALUES (?)", i); err != nil {
+			t.Fatalf("failed to insert, got %v", err)
+		}
+	}
+	if fmt.Sprint(db.prepared) != "[INSERT INTO prepared_values (value) VALUES (?)]" {
+		t.Errorf("only repeated statements should be prepared, but got %v", db.prepared)
+	}
+
+	for i := 0; i < maxPreparedStatements+1; i++ {
+		for j := 0; j < 2; j++ {
+			tx.Exec(fmt.Sprintf("UPDATE prepared_values SET value = %v", i))
+		}
+	}
+	if len(tx.stmts) != maxPreparedStatements || len(tx.queries) != maxPreparedStatements {
+		t.Errorf("prepared statements should be at most %v, but got %v", maxPreparedStatements, len(tx.stmts))
+	}
+}
+
+type contextQueryingDB struct {
+	*sql.DB
+	contexts []context.Context
+}
+
+func (db *contextQueryingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.contexts = append(db.contexts, ctx)
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *contextQueryingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	db.contexts = append(db.contexts, ctx)
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestPreparedTxQueryContext(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer sqlDB.Close()
+
+	db := &contextQueryingDB{DB: sqlDB}
+	tx := &preparedTx{SQLCommon: db, stmts: map[string]*sql.Stmt{}, executed: map[string]bool{}}
+	defer tx.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var value int
+	if err := tx.QueryRowContext(ctx, "SELECT 1").Scan(&value); err != nil || value != 1 {
+		t.Errorf("failed to query row, got %v, %v", value, err)
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatalf("failed to query, got %v", err)
+	}
+	rows.Close()
+
+	if len(db.contexts) != 2 || db.contexts[0] != ctx || db.contexts[1] != ctx {
+		t.Errorf("queries should be sent with the context, but got %v", db.contexts)
+	}
+}
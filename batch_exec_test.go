@@ -0,0 +1,144 @@
+package gorm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestBatch(t *testing.T) {
+	user1, user2 := User{Name: "batch_1", Age: 10}, User{Name: "batch_2", Age: 10}
+	DB.Save(&user1)
+
+	batch := DB.Batch()
+	for _, age := range []int{11, 12, 13} {
+		batch.Exec("UPDATE users SET age = ? WHERE id = ?", age, user1.Id)
+	}
+	batch.Create(&user2).Updates(&user2, map[string]interface{}{"age": 20})
+	if batch.Len() != 5 {
+		t.Errorf("batch should queue 5 operations, but got %v", batch.Len())
+	}
+
+	if result := batch.Flush(); result.Error != nil || result.RowsAffected != 5 {
+		t.Errorf("batch should be flushed with 5 rows affected, but got %v, %v", result.Error, result.RowsAffected)
+	}
+	if batch.Len() != 0 {
+		t.Errorf("queue should be cleared after flushing")
+	}
+	if user2.Id == 0 {
+		t.Errorf("primary key of created records should be set")
+	}
+
+	var users []User
+	DB.Where("name IN (?)", []string{"batch_1", "batch_2"}).Order("name").Find(&users)
+	if len(users) != 2 || users[0].Age != 13 || users[1].Age != 20 {
+		t.Errorf("batch operations should be run in order, but got %#v", users)
+	}
+
+	if result := DB.Batch().Flush(); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("flushing empty batches should do nothing, but got %v", result.Error)
+	}
+}
+
+func TestBatchRollback(t *testing.T) {
+	user := User{Name: "batch_rollback", Age: 10}
+	DB.Save(&user)
+
+	boom := errors.New("boom")
+	result := DB.Batch().
+		Exec("UPDATE users SET age = ? WHERE id = ?", 11, user.Id).
+		Create(&User{Name: "batch_rollback_created"}).
+		Do(func(tx *gorm.DB) *gorm.DB {
+			tx.AddError(boom)
+			return tx
+		}).
+		Flush()
+	if result.Error != boom {
+		t.Errorf("batch should fail with the error of the operation, but got %v", result.Error)
+	}
+
+	var count int
+	DB.Model(&User{}).Where("name = ?", "batch_rollback_created").Count(&count)
+	DB.First(&user, user.Id)
+	if count != 0 || user.Age != 10 {
+		t.Errorf("batch should be rolled back, but got %v created, age %v", count, user.Age)
+	}
+
+	tx := DB.Begin()
+	tx.Batch().Exec("UPDATE users SET age = ? WHERE id = ?", 12, user.Id).Flush()
+	tx.Batch().Do(func(tx *gorm.DB) *gorm.DB {
+		return tx.Exec("UPDATE users SET age = ? WHERE id = ?", 13, user.Id).Exec("UPDATE not_exists SET age = 1")
+	}).Flush()
+	tx.First(&user, user.Id)
+	tx.Rollback()
+	if user.Age != 12 {
+		t.Errorf("failed batches in transactions should only roll back their own operations, but got age %v", user.Age)
+	}
+}
+
+func TestBatchMultiStatements(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	result := db.Batch(gorm.BatchOptions{MultiStatements: true, Size: 2}).
+		Exec(`UPDATE "users" SET "age" = $1 WHERE "id" = $2;`, 1, 1).
+		Exec(`UPDATE "users" SET "age" = $1 WHERE "id" = $2`, 2, 2).
+		Exec(`UPDATE "users" SET "age" = $1 WHERE "id" = $2`, 3, 3).
+		Do(func(tx *gorm.DB) *gorm.DB {
+			return tx.Exec(`DELETE FROM "users"`)
+		}).
+		Exec(`UPDATE "users" SET "age" = $1 WHERE "id" = $2`, 4, 4).
+		Flush()
+	if result.Error != nil {
+		t.Fatalf("batch should be flushed, but got %v", result.Error)
+	}
+
+	var executed []string
+	for _, statement := range recorder.Statements() {
+		if strings.HasPrefix(statement.SQL, "UPDATE") || strings.HasPrefix(statement.SQL, "DELETE") {
+			executed = append(executed, statement.SQL)
+		}
+	}
+	expected := []string{
+		`UPDATE "users" SET "age" = $1 WHERE "id" = $2; UPDATE "users" SET "age" = $1 WHERE "id" = $2`,
+		`UPDATE "users" SET "age" = $1 WHERE "id" = $2`,
+		`DELETE FROM "users"`,
+		`UPDATE "users" SET "age" = $1 WHERE "id" = $2`,
+	}
+	if strings.Join(executed, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("consecutive statements should be joined by the size, but got\n%v", strings.Join(executed, "\n"))
+	}
+
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" {
+		return
+	}
+
+	user := User{Name: "batch_multi_statements", Age: 10}
+	DB.Save(&user)
+	err = DB.Batch(gorm.BatchOptions{MultiStatements: true}).
+		Exec("UPDATE users SET age = ? WHERE id = ?", 11, user.Id).
+		Exec("UPDATE users SET name = ? WHERE id = ?", "batch_multi_statements_updated", user.Id).
+		Flush().Error
+	DB.First(&user, user.Id)
+	if err != nil || user.Age != 11 || user.Name != "batch_multi_statements_updated" {
+		t.Errorf("multiple statements should be executed, but got %v, %#v", err, user)
+	}
+}
+
+func TestBatchQueryWithTimeout(t *testing.T) {
+	DB.Save(&User{Name: "batch_query", Age: 10})
+
+	var count int
+	result := DB.Timeout(time.Minute).Batch().Do(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Where("name = ?", "batch_query").Count(&count)
+	}).Flush()
+	if result.Error != nil || count != 1 {
+		t.Errorf("queries of batches should be run with timeout, but got %v, %v", count, result.Error)
+	}
+}
@@ -0,0 +1,175 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+func init() {
+	DefaultCallback.Query().Before("gorm:query").Register("gorm:batch_load", batchLoadCallback)
+}
+
+type batchLoaderKey struct{}
+
+// batchLoader coalesces primary key lookups of a context
+type batchLoader struct {
+	window  time.Duration
+	mutex   sync.Mutex
+	batches map[string]*batchLoad
+}
+
+// batchLoad primary key lookups of same model loaded with one query
+type batchLoad struct {
+	scope   *Scope
+	keys    []interface{}
+	results map[string]reflect.Value
+	err     error
+	done    chan struct{}
+}
+
+// WithBatchLoader return a context coalescing primary key lookups of same model within window into one `WHERE id IN (...)` query,
+// so concurrent lookups like GraphQL resolvers won't fire a query for each record, e.g:
+//    ctx = gorm.WithBatchLoader(ctx, 5*time.Millisecond)
+//    db.WithContext(ctx).First(&user, userID)
+//
+// Only lookups with primary key as the only condition are batched, lookups inside transactions won't be batched
+func WithBatchLoader(ctx context.Context, window time.Duration) context.Context {
+	return context.WithValue(ctx, batchLoaderKey{}, &batchLoader{window: window, batches: map[string]*batchLoad{}})
+}
+
+// batchLoadCallback wait for the batch query of its primary key and skip querying database
+func batchLoadCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	loader, ok := scope.Context().Value(batchLoaderKey{}).(*batchLoader)
+	if !ok {
+		return
+	}
+
+	if _, ok := scope.db.db.dbSQL.(sqlTx); ok {
+		return
+	}
+
+	primaryKey, ok := batchLoadablePrimaryKey(scope)
+	if !ok {
+		return
+	}
+
+	// stop waiting if the context of the lookup is done, the batch is still loaded for other lookups
+	batch := loader.add(scope, primaryKey)
+	select {
+	case <-batch.done:
+	case <-scope.Context().Done():
+		scope.InstanceSet("gorm:skip_query_callback", true)
+		scope.Err(scope.Context().Err())
+		return
+	}
+
+	scope.InstanceSet("gorm:skip_query_callback", true)
+	if batch.err != nil {
+		scope.Err(batch.err)
+	} else if result, ok := batch.results[toString(primaryKey)]; ok {
+		scope.IndirectValue().Set(result)
+		scope.db.RowsAffected = 1
+	} else if returnErrRecordNotFound(scope) {
+		scope.Err(ErrRecordNotFound)
+	}
+}
+
+// batchLoadablePrimaryKey return the primary key if it is the only condition of querying a record
+func batchLoadablePrimaryKey(scope *Scope) (interface{}, bool) {
+	var search = scope.Search
+	if len(search.whereConditions) != 1 || len(search.orConditions) > 0 || len(search.notConditions) > 0 ||
+		len(search.havingConditions) > 0 || len(search.joinConditions) > 0 || len(search.selects) > 0 || len(search.omits) > 0 ||
+		len(search.orders) > 0 || len(search.preload) > 0 || search.group != "" || search.tableName != "" || search.asOfSystemTime != "" ||
+		search.raw || search.Unscoped {
+		return nil, false
+	}
+
+	if offset, ok := search.offset.(int); search.offset != nil && (!ok || offset > 0) {
+		return nil, false
+	}
+
+	// First, Take and Last limit the query to 1 record
+	if limit, ok := search.limit.(int); search.limit != nil && (!ok || limit != 1) {
+		return nil, false
+	}
+
+	if _, ok := scope.Get("gorm:query_destination"); ok {
+		return nil, false
+	}
+
+	if scope.IndirectValue().Kind() != reflect.Struct || len(scope.PrimaryFields()) != 1 || !scope.PrimaryKeyZero() {
+		return nil, false
+	}
+
+	clause := search.whereConditions[0]
+	if args, ok := clause["args"].([]interface{}); ok && len(args) > 0 {
+		return nil, false
+	}
+
+	switch value := clause["query"].(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return value, true
+	case string:
+		if isNumberRegexp.MatchString(value) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// add add primary key into the batch of its model, the batch will be loaded after the window
+func (loader *batchLoader) add(scope *Scope, primaryKey interface{}) *batchLoad {
+	key := fmt.Sprintf("%v|%v", scope.GetModelStruct().ModelType, scope.TableName())
+
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	batch, ok := loader.batches[key]
+	if !ok {
+		batch = &batchLoad{scope: scope, results: map[string]reflect.Value{}, done: make(chan struct{})}
+		loader.batches[key] = batch
+
+		time.AfterFunc(loader.window, func() {
+			loader.mutex.Lock()
+			delete(loader.batches, key)
+			loader.mutex.Unlock()
+			batch.load()
+		})
+	}
+
+	batch.keys = append(batch.keys, primaryKey)
+	return batch
+}
+
+// load find records of all primary keys in the batch
+func (batch *batchLoad) load() {
+	defer close(batch.done)
+
+	var (
+		scope        = batch.scope
+		primaryField = scope.PrimaryField()
+		results      = reflect.New(reflect.SliceOf(scope.GetModelStruct().ModelType))
+		db           = scope.NewDB()
+	)
+
+	sql := fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(primaryField.DBName))
+	if batch.err = db.Where(sql, batch.keys).Find(results.Interface()).Error; batch.err != nil {
+		return
+	}
+
+	for i := 0; i < results.Elem().Len(); i++ {
+		result := results.Elem().Index(i)
+		batch.results[toString(result.FieldByName(primaryField.Name).Interface())] = result
+	}
+}
@@ -0,0 +1,97 @@
+package gorm_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestBatchLoader(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var queries int
+	var mutex sync.Mutex
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", func(scope *gorm.Scope) {
+		if _, skipped := scope.InstanceGet("gorm:skip_query_callback"); !skipped {
+			mutex.Lock()
+			queries++
+			mutex.Unlock()
+		}
+	})
+
+	users := []User{{Name: "batch_loader1"}, {Name: "batch_loader2"}, {Name: "batch_loader3"}}
+	for i := range users {
+		db.Save(&users[i])
+	}
+
+	ctx := gorm.WithBatchLoader(context.Background(), 20*time.Millisecond)
+	var (
+		wg      sync.WaitGroup
+		results = make([]User, len(users)+1)
+		errs    = make([]error, len(users)+1)
+	)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := int64(0)
+			if i < len(users) {
+				id = users[i].Id
+			}
+			errs[i] = db.WithContext(ctx).First(&results[i], id).Error
+		}(i)
+	}
+	wg.Wait()
+
+	if queries != 1 {
+		t.Errorf("Primary key lookups should be coalesced into one query, but got %v", queries)
+	}
+
+	for i, user := range users {
+		if errs[i] != nil || results[i].Name != user.Name {
+			t.Errorf("Should find user %v, but got %v, %v", user.Name, results[i].Name, errs[i])
+		}
+	}
+
+	if !gorm.IsRecordNotFoundError(errs[len(users)]) {
+		t.Errorf("Should get record not found error for nonexistent primary key, but got %v", errs[len(users)])
+	}
+
+	var user User
+	if db.WithContext(ctx).Where("name = ?", "batch_loader1").First(&user); user.Id != users[0].Id || queries != 2 {
+		t.Errorf("Lookups with other conditions should not be batched, got %v, %v", user.Id, queries)
+	}
+}
+
+func TestBatchLoaderWithCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(gorm.WithBatchLoader(context.Background(), time.Second), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var user User
+	if err := DB.WithContext(ctx).First(&user, 1).Error; err != context.DeadlineExceeded {
+		t.Errorf("Should get the error of the context, but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Should stop waiting for the batch when the context is done, but waited %v", elapsed)
+	}
+}
+
+func TestBatchLoaderWithPreload(t *testing.T) {
+	user := User{Name: "batch_loader_preload", Emails: []Email{{Email: "batch_loader@example.com"}}}
+	DB.Save(&user)
+
+	ctx := gorm.WithBatchLoader(context.Background(), time.Millisecond)
+	var loaded User
+	if err := DB.WithContext(ctx).Preload("Emails").First(&loaded, user.Id).Error; err != nil || len(loaded.Emails) != 1 {
+		t.Errorf("Lookups with Preload should not be batched without preloading, but got %v, %#v", err, loaded.Emails)
+	}
+}
@@ -0,0 +1,160 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Bits flags stored as BIT(n) on mysql and tidb, n is the `size` tag or 64, and bigint on others,
+// zero values of struct fields are skipped when updating with structs, tag them with `update_zero` to clear all flags
+//    type User struct {
+//      ID          uint
+//      Permissions gorm.Bits `gorm:"size:8;update_zero"`
+//    }
+//
+//    user.Permissions = user.Permissions.Set(CanRead | CanWrite)
+//    db.Model(&user).Updates(User{Permissions: user.Permissions.Clear(CanWrite)})
+type Bits uint64
+
+// Has return true if all flags are set
+func (b Bits) Has(flags Bits) bool {
+	return b&flags == flags
+}
+
+// Set return the bits with flags set
+func (b Bits) Set(flags Bits) Bits {
+	return b | flags
+}
+
+// Clear return the bits with flags cleared
+func (b Bits) Clear(flags Bits) Bits {
+	return b &^ flags
+}
+
+// Value return bits as an integer
+func (b Bits) Value() (driver.Value, error) {
+	return int64(b), nil
+}
+
+// Scan scan integers, and big-endian bytes of BIT columns of mysql, NULL is scanned as no flags
+func (b *Bits) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*b = 0
+	case int64:
+		*b = Bits(src)
+	case []byte:
+		if len(src) > 8 {
+			return fmt.Errorf("failed to scan bits from %v bytes", len(src))
+		}
+		var value uint64
+		for _, c := range src {
+			value = value<<8 | uint64(c)
+		}
+		*b = Bits(value)
+	case string:
+		value, err := strconv.ParseUint(src, 10, 64)
+		if err != nil {
+			return err
+		}
+		*b = Bits(value)
+	default:
+		return fmt.Errorf("failed to scan bits from %T", src)
+	}
+	return nil
+}
+
+// Set members stored as SET of mysql and tidb with members listed in the `set` tag, and comma separated strings on others,
+// members shouldn't contain commas, the empty set is stored as an empty string
+//    type User struct {
+//      ID    uint
+//      Roles gorm.Set `gorm:"set:admin,editor,viewer;update_zero"`
+//    }
+//
+//    db.Where("FIND_IN_SET(?, roles)", "admin").Find(&users)
+type Set []string
+
+// Has return true if the member is in the set
+func (s Set) Has(member string) bool {
+	for _, m := range s {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+// Value return comma separated members
+func (s Set) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan split comma separated members, NULL is scanned as a nil set
+func (s *Set) Scan(src interface{}) error {
+	var members string
+	switch src := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		members = string(src)
+	case string:
+		members = src
+	default:
+		return fmt.Errorf("failed to scan set from %T", src)
+	}
+
+	if members == "" {
+		*s = Set{}
+	} else {
+		*s = strings.Split(members, ",")
+	}
+	return nil
+}
+
+var (
+	bitsType = reflect.TypeOf(Bits(0))
+	setType  = reflect.TypeOf(Set{})
+)
+
+func bitsDataTypeOf(dialect Dialect, field *StructField) string {
+	switch dialect.GetName() {
+	case "mysql", "tidb":
+		size := 64
+		if value, ok := field.TagSettingsGet("SIZE"); ok {
+			if n, err := strconv.Atoi(value); err == nil && n > 0 && n < 64 {
+				size = n
+			}
+		}
+		return fmt.Sprintf("BIT(%d)", size)
+	}
+	return "bigint"
+}
+
+func setDataTypeOf(dialect Dialect, field *StructField) string {
+	members, hasMembers := field.TagSettingsGet("SET")
+	switch dialect.GetName() {
+	case "mysql", "tidb":
+		if hasMembers {
+			var quoted []string
+			for _, member := range strings.Split(members, ",") {
+				quoted = append(quoted, "'"+strings.Replace(strings.TrimSpace(member), "'", "''", -1)+"'")
+			}
+			return fmt.Sprintf("SET(%v)", strings.Join(quoted, ","))
+		}
+	}
+
+	size := 255
+	if value, ok := field.TagSettingsGet("SIZE"); ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			size = n
+		}
+	}
+	if dialect.GetName() == "mssql" {
+		return fmt.Sprintf("nvarchar(%d)", size)
+	}
+	return fmt.Sprintf("varchar(%d)", size)
+}
@@ -0,0 +1,71 @@
+package gorm_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+const (
+	CanRead gorm.Bits = 1 << iota
+	CanWrite
+	CanDelete
+)
+
+type Member struct {
+	ID          uint
+	Name        string
+	Permissions gorm.Bits `gorm:"size:8;update_zero"`
+	Roles       gorm.Set  `gorm:"set:admin,editor;update_zero"`
+	Checksum    []byte    `gorm:"size:16"`
+}
+
+func TestBitsAndSetColumns(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.CreateTable(&Member{})
+	if sql := recorder.LastStatement().SQL; !strings.Contains(sql, "`permissions` BIT(8),`roles` SET('admin','editor'),`checksum` varbinary(16)") {
+		t.Errorf("bits, set and binary columns are wrong, got %v", sql)
+	}
+
+	recorder.Reply("SELECT * FROM `members` WHERE (`members`.`id` = 1) ORDER BY `members`.`id` ASC LIMIT 1",
+		[]string{"id", "permissions", "roles"}, []interface{}{1, []byte{0, byte(CanRead | CanDelete)}, "admin,editor"})
+	var member Member
+	if err := db.First(&member, 1).Error; err != nil {
+		t.Fatalf("failed to find member, got %v", err)
+	}
+	if !member.Permissions.Has(CanRead|CanDelete) || member.Permissions.Has(CanWrite) || !reflect.DeepEqual(member.Roles, gorm.Set{"admin", "editor"}) {
+		t.Errorf("bits and set should be scanned, but got %#v", member)
+	}
+}
+
+func TestUpdateZeroFlags(t *testing.T) {
+	DB.DropTableIfExists(&Member{})
+	if err := DB.AutoMigrate(&Member{}).Error; err != nil {
+		t.Fatalf("failed to migrate members, got %v", err)
+	}
+
+	member := Member{Name: "jinzhu", Permissions: CanRead.Set(CanWrite), Roles: gorm.Set{"admin"}}
+	DB.Create(&member)
+
+	var found Member
+	DB.First(&found, member.ID)
+	if found.Permissions != CanRead|CanWrite || !found.Roles.Has("admin") {
+		t.Errorf("bits and set should be saved, but got %#v", found)
+	}
+
+	if err := DB.Model(&member).Updates(Member{Name: "jinzhu 2"}).Error; err != nil {
+		t.Errorf("failed to update member, got %v", err)
+	}
+	found = Member{}
+	DB.First(&found, member.ID)
+	if found.Name != "jinzhu 2" || found.Permissions != 0 || len(found.Roles) != 0 || found.Roles == nil {
+		t.Errorf("zero values of fields tagged with update_zero should be updated, but got %#v", found)
+	}
+}
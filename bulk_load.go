@@ -0,0 +1,391 @@
+package gorm
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RegisterMySQLReaderHandler and DeregisterMySQLReaderHandler register readers of `LOAD DATA LOCAL INFILE 'Reader::name'`,
+// they are set by importing github.com/lun-zhang/gorm/dialects/mysql
+var (
+	RegisterMySQLReaderHandler   func(name string, handler func() io.Reader)
+	DeregisterMySQLReaderHandler func(name string)
+)
+
+var bulkLoadID uint64
+
+// BulkLoad insert the slice of records with the bulk loader of the dialect, columns are mapped from fields of the model,
+// it is much faster than creating records one by one for large imports:
+//    * postgres loads records with `COPY ... FROM STDIN` of lib/pq
+//    * mysql loads records with `LOAD DATA LOCAL INFILE`, it requires importing github.com/lun-zhang/gorm/dialects/mysql and `local_infile` enabled by the server
+//    * other dialects insert records in batches with multi-row `INSERT` statements
+//
+// Records are loaded in a transaction, or a nested transaction if the db is in a transaction, RowsAffected is the number of loaded records.
+// Bulk loaders don't run callbacks, save associations or set primary keys of records, blank CreatedAt, UpdatedAt are stamped like creating,
+// blank keys tagged with `default:uuid`, `default:ulid`, `default:snowflake` or `id_gen` are generated like creating,
+// fields are encrypted by the Encryption plugin and tenants are filled by the TenantGuard plugin, loading records of tables audited by
+// the Auditor plugin or not allowed by the WriteGuard plugin is refused.
+// Bulk loaders don't route records, loading records of tables split by the Sharding plugin is refused,
+// so is loading records of tables registered to other databases of the DBResolver plugin, bulk load them with the registered db
+//    db.BulkLoad(users)
+//    // COPY "users" ("name","age","created_at","updated_at") FROM STDIN
+func (s *DB) BulkLoad(models interface{}) *DB {
+	var (
+		result  = s.clone()
+		records = indirect(reflect.ValueOf(models))
+	)
+	if records.Kind() != reflect.Slice {
+		result.AddError(errors.New("bulk load requires a slice of records"))
+		return result
+	}
+	if records.Len() == 0 {
+		return result
+	}
+
+	scope := s.NewScope(bulkLoadRecord(records, 0))
+	if scope.GetModelStruct().ModelType == nil {
+		result.AddError(errors.New("bulk load requires a slice of structs"))
+		return result
+	}
+
+	var load func(scope *Scope, group *bulkLoadGroup) error
+	switch s.Dialect().GetName() {
+	case "postgres":
+		load = copyIn
+	case "mysql":
+		load = loadDataLocalInfile
+	default:
+		load = insertInBatches
+	}
+
+	if result.AddError(prepareBulkLoad(scope, records)) != nil {
+		return result
+	}
+
+	groups, err := bulkLoadGroups(scope, records)
+	if result.AddError(err) != nil {
+		return result
+	}
+
+	err = s.Transaction(func(tx *DB) error {
+		if tx.Error != nil {
+			return tx.Error
+		}
+		for _, group := range groups {
+			if err := load(tx.NewScope(scope.Value), group); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.AddError(err) == nil {
+		result.RowsAffected = int64(records.Len())
+	}
+	return result
+}
+
+// bulkLoadGroup records loaded with the same columns
+type bulkLoadGroup struct {
+	records    reflect.Value
+	rows       []int    // indexes of records in the group
+	columns    []string // quoted columns
+	fields     []int    // indexes of fields of columns
+	encryption *Encryption
+}
+
+// prepareBulkLoad check plugins guarding creating records, then stamp CreatedAt, UpdatedAt and fill tenants of records like creating
+func prepareBulkLoad(scope *Scope, records reflect.Value) error {
+	table, plugins := scope.TableName(), scope.db.Plugins()
+	if guard, ok := plugins["gorm:write_guard"].(*WriteGuard); ok && !guard.Allowed(table, OperationCreate) {
+		return &WriteGuardError{Table: table, Operation: OperationCreate}
+	}
+	if auditor, ok := plugins["gorm:audit"].(*Auditor); ok && auditor.auditable(scope) {
+		return fmt.Errorf("bulk load can't record audit logs of %v, create records instead", table)
+	}
+	if sharding, ok := plugins["gorm:sharding"].(*Sharding); ok {
+		if _, sharded := sharding.Tables[table]; sharded {
+			return fmt.Errorf("bulk load can't route records of %v to shards, create records instead", table)
+		}
+	}
+	if resolver, ok := plugins["gorm:db_resolver"].(*DBResolver); ok {
+		dbSQL := scope.db.db.dbSQL
+		if _, ok := dbSQL.(sqlTx); ok {
+			dbSQL = scope.db.db.txSource
+		}
+		if target := resolver.resolve(table); target != nil && target.db.dbSQL != dbSQL {
+			return fmt.Errorf("bulk load can't route records of %v to its database, bulk load them with the registered db", table)
+		}
+	}
+
+	var (
+		guarded      bool
+		tenant       interface{}
+		guardedField *StructField
+	)
+	if _, ok := plugins["gorm:tenant_guard"]; ok {
+		if guardedField, tenant, guarded = tenantField(scope); scope.HasError() {
+			return scope.db.Error
+		}
+	}
+
+	now := scope.db.nowFunc()
+	for i := 0; i < records.Len(); i++ {
+		recordScope := scope.New(bulkLoadRecord(records, i))
+		if guarded && !fillTenant(recordScope, guardedField, tenant) {
+			return recordScope.db.Error
+		}
+
+		for _, field := range recordScope.Fields() {
+			if !field.IsBlank {
+				continue
+			}
+			if generator := keyGenerator(field.StructField); generator != "" {
+				value, err := generateKey(recordScope, generator)
+				if err == nil {
+					err = field.Set(value)
+				}
+				if err != nil {
+					return err
+				}
+			} else if unit, ok := autoTimeUnit(field.StructField, "AUTOCREATETIME", "CreatedAt"); ok {
+				field.Set(autoTimeValue(field, unit, now))
+			} else if unit, ok := autoTimeUnit(field.StructField, "AUTOUPDATETIME", "UpdatedAt"); ok {
+				field.Set(autoTimeValue(field, unit, now))
+			}
+		}
+	}
+	return nil
+}
+
+// bulkLoadGroups group records by their columns, columns are mapped like creating records,
+// blank primary keys and blank fields with default values are left to the database
+func bulkLoadGroups(scope *Scope, records reflect.Value) ([]*bulkLoadGroup, error) {
+	var (
+		groups     []*bulkLoadGroup
+		groupIndex = map[string]*bulkLoadGroup{}
+	)
+
+	encryption, _ := scope.db.Plugins()["gorm:encryption"].(*Encryption)
+	for i := 0; i < records.Len(); i++ {
+		var (
+			columns []string
+			fields  []int
+		)
+		for j, field := range scope.New(bulkLoadRecord(records, i)).Fields() {
+			if !field.IsNormal || field.IsIgnored || field.IsBlank && (field.IsPrimaryKey || field.HasDefaultValue) {
+				continue
+			}
+			columns = append(columns, scope.Quote(field.DBName))
+			fields = append(fields, j)
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("bulk load requires columns of %v, but all fields of the record %v are blank", scope.TableName(), i)
+		}
+
+		key := strings.Join(columns, ",")
+		group, ok := groupIndex[key]
+		if !ok {
+			group = &bulkLoadGroup{records: records, columns: columns, fields: fields, encryption: encryption}
+			groupIndex[key] = group
+			groups = append(groups, group)
+		}
+		group.rows = append(group.rows, i)
+	}
+	return groups, nil
+}
+
+// bulkLoadRecord return the pointer of the i-th record, so fields of records are addressable
+func bulkLoadRecord(records reflect.Value, i int) interface{} {
+	record := records.Index(i)
+	if record.Kind() != reflect.Ptr {
+		record = record.Addr()
+	}
+	return record.Interface()
+}
+
+// values return values of mapped fields of the i-th record like values of creating statements, encrypted fields are encrypted
+func (group *bulkLoadGroup) values(scope *Scope, i int) ([]interface{}, error) {
+	var (
+		values = make([]interface{}, len(group.fields))
+		fields = scope.New(bulkLoadRecord(group.records, i)).Fields()
+	)
+	for j, index := range group.fields {
+		field := fields[index]
+		values[j] = field.Field.Interface()
+		if encrypted, deterministic := isEncryptedField(field.StructField); encrypted && group.encryption != nil {
+			value, err := group.encryption.encryptValue(scope.TableName(), field.DBName, values[j], deterministic)
+			if err != nil {
+				return nil, err
+			}
+			values[j] = value
+		}
+	}
+	return values, nil
+}
+
+// databaseValues return values of the i-th record with times converted to the location set with SetTimeLocation
+func (group *bulkLoadGroup) databaseValues(scope *Scope, i int) ([]interface{}, error) {
+	values, err := group.values(scope, i)
+	for j, value := range values {
+		values[j] = databaseTime(scope.db.timeLocation(), value)
+	}
+	return values, err
+}
+
+// copyIn load records with `COPY FROM STDIN` of lib/pq, rows are sent by executing the prepared statement with values of each record
+func copyIn(scope *Scope, group *bulkLoadGroup) (err error) {
+	scope.SQL = fmt.Sprintf("COPY %v (%v) FROM STDIN", scope.QuotedTableName(), strings.Join(group.columns, ","))
+	defer scope.trace(NowFunc())
+
+	stmt, err := scope.SQLDB().Prepare(scope.SQL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := stmt.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for _, i := range group.rows {
+		values, err := group.databaseValues(scope, i)
+		if err != nil {
+			return err
+		}
+		if _, err = stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	_, err = stmt.Exec()
+	return err
+}
+
+// loadDataLocalInfile load records with `LOAD DATA LOCAL INFILE`, rows are streamed to the driver as tab separated values
+func loadDataLocalInfile(scope *Scope, group *bulkLoadGroup) error {
+	if RegisterMySQLReaderHandler == nil || DeregisterMySQLReaderHandler == nil {
+		return errors.New("bulk loading of mysql requires importing github.com/lun-zhang/gorm/dialects/mysql")
+	}
+
+	var (
+		reader, writer = io.Pipe()
+		valuesScope    = scope.New(scope.Value) // the scope is used by the statement concurrently
+		loc            = scope.db.parent.driverLocation
+	)
+	if loc == nil {
+		loc = time.UTC
+	}
+	defer reader.Close()
+	go func() {
+		w := bufio.NewWriter(writer)
+		for _, i := range group.rows {
+			values, err := group.databaseValues(valuesScope, i)
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			for j, value := range values {
+				if j > 0 {
+					w.WriteByte('\t')
+				}
+				if err := writeLoadDataValue(w, value, loc); err != nil {
+					writer.CloseWithError(err)
+					return
+				}
+			}
+			w.WriteByte('\n')
+		}
+		writer.CloseWithError(w.Flush())
+	}()
+
+	name := fmt.Sprintf("gorm_bulk_load_%v", atomic.AddUint64(&bulkLoadID, 1))
+	RegisterMySQLReaderHandler(name, func() io.Reader { return reader })
+	defer DeregisterMySQLReaderHandler(name)
+
+	scope.Raw(fmt.Sprintf(`LOAD DATA LOCAL INFILE 'Reader::%v' INTO TABLE %v FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n' (%v)`,
+		name, scope.QuotedTableName(), strings.Join(group.columns, ","))).Exec()
+	return scope.db.Error
+}
+
+// maxBulkInsertVars max vars of a multi-row INSERT statement, the default limit of sqlite
+const maxBulkInsertVars = 999
+
+// insertInBatches load records with multi-row `INSERT` statements, records are inserted in batches limited by maxBulkInsertVars
+func insertInBatches(scope *Scope, group *bulkLoadGroup) error {
+	size := maxBulkInsertVars / len(group.columns)
+	if size == 0 {
+		size = 1
+	}
+
+	for start := 0; start < len(group.rows); start += size {
+		end := start + size
+		if end > len(group.rows) {
+			end = len(group.rows)
+		}
+
+		batchScope := scope.New(scope.Value)
+		rows := make([]string, 0, end-start)
+		for _, i := range group.rows[start:end] {
+			values, err := group.values(batchScope, i)
+			if err != nil {
+				return err
+			}
+
+			placeholders := make([]string, len(values))
+			for j, value := range values {
+				placeholders[j] = batchScope.AddToVars(value)
+			}
+			rows = append(rows, "("+strings.Join(placeholders, ",")+")")
+		}
+
+		batchScope.Raw(fmt.Sprintf("INSERT INTO %v (%v) VALUES %v",
+			batchScope.QuotedTableName(), strings.Join(group.columns, ","), strings.Join(rows, ","))).Exec()
+		if batchScope.db.Error != nil {
+			return batchScope.db.Error
+		}
+	}
+	return nil
+}
+
+// loadDataEscaper escape special characters of values with the escape character of LOAD DATA
+var loadDataEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r", "\x00", "\\0")
+
+// writeLoadDataValue write the value as a field of LOAD DATA, NULL is written as \N,
+// times are written as the wall clock in the location of the driver like values of other statements
+func writeLoadDataValue(w *bufio.Writer, value interface{}, loc *time.Location) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		w.WriteString(`\N`)
+	case []byte:
+		loadDataEscaper.WriteString(w, string(v))
+	case string:
+		loadDataEscaper.WriteString(w, v)
+	case bool:
+		if v {
+			w.WriteByte('1')
+		} else {
+			w.WriteByte('0')
+		}
+	case int64:
+		w.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		w.WriteString(v.In(loc).Format("2006-01-02 15:04:05.999999"))
+	default:
+		return fmt.Errorf("unsupported value %T of bulk loading", value)
+	}
+	return nil
+}
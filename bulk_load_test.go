@@ -0,0 +1,210 @@
+package gorm_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type BulkRecord struct {
+	ID        uint
+	Name      string
+	Note      *string
+	Score     float64
+	CreatedAt time.Time
+}
+
+func TestBulkLoad(t *testing.T) {
+	DB.DropTableIfExists(&BulkRecord{})
+	DB.AutoMigrate(&BulkRecord{})
+
+	note := "tab\tnote"
+	records := []BulkRecord{{Name: "bulk_1", Note: &note, Score: 1.5}, {Name: "bulk_2"}}
+	if result := DB.BulkLoad(records); result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("records should be loaded, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var loaded []BulkRecord
+	DB.Order("name").Find(&loaded)
+	if len(loaded) != 2 || loaded[0].Note == nil || *loaded[0].Note != note || loaded[0].Score != 1.5 || loaded[1].Note != nil || loaded[0].CreatedAt.IsZero() {
+		t.Errorf("records should be loaded with their fields, but got %#v", loaded)
+	}
+
+	if err := DB.BulkLoad(&BulkRecord{}).Error; err == nil {
+		t.Errorf("bulk loading non-slice values should fail")
+	}
+	if result := DB.BulkLoad([]BulkRecord{}); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("bulk loading empty slices should do nothing, but got %v", result.Error)
+	}
+}
+
+func TestBulkLoadCopyIn(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	now := time.Now()
+	records := []*BulkRecord{{Name: "bulk_1", Score: 1.5, CreatedAt: now}, {Name: "bulk_2", CreatedAt: now}}
+	if result := db.BulkLoad(records); result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("records should be loaded, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var copies []gormtest.Statement
+	for _, statement := range recorder.Statements() {
+		if strings.HasPrefix(statement.SQL, "COPY") {
+			copies = append(copies, statement)
+		}
+	}
+	if len(copies) != 3 {
+		t.Fatalf("a row should be sent for each record and a flush at last, but got %#v", copies)
+	}
+	if expected := `COPY "bulk_records" ("name","note","score","created_at") FROM STDIN`; copies[0].SQL != expected {
+		t.Errorf("blank primary keys should be skipped, expected %v, but got %v", expected, copies[0].SQL)
+	}
+	if len(copies[1].Vars) != 4 || copies[1].Vars[0] != "bulk_2" || copies[1].Vars[1] != nil || len(copies[2].Vars) != 0 {
+		t.Errorf("values of records should be sent in order of columns, but got %#v", copies)
+	}
+}
+
+func TestBulkLoadLocalInfile(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	var (
+		data                 []byte
+		done                 = make(chan struct{})
+		register, deregister = gorm.RegisterMySQLReaderHandler, gorm.DeregisterMySQLReaderHandler
+	)
+	defer func() {
+		gorm.RegisterMySQLReaderHandler, gorm.DeregisterMySQLReaderHandler = register, deregister
+	}()
+	// the fake driver doesn't read files, read them when registering like the mysql driver when executing
+	gorm.RegisterMySQLReaderHandler = func(name string, handler func() io.Reader) {
+		go func() {
+			data, _ = ioutil.ReadAll(handler())
+			close(done)
+		}()
+	}
+	gorm.DeregisterMySQLReaderHandler = func(name string) { <-done }
+
+	note := "line\nwith \\ and\ttab"
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []BulkRecord{{ID: 1, Name: "bulk_1", Note: &note, Score: 1.5, CreatedAt: createdAt}, {ID: 2, Name: "bulk_2", CreatedAt: createdAt}}
+	if result := db.BulkLoad(records); result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("records should be loaded, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var statement string
+	for _, s := range recorder.Statements() {
+		if strings.HasPrefix(s.SQL, "LOAD DATA") {
+			statement = s.SQL
+		}
+	}
+	if !strings.HasSuffix(statement, "INTO TABLE `bulk_records` FIELDS TERMINATED BY '\\t' ESCAPED BY '\\\\' LINES TERMINATED BY '\\n' (`id`,`name`,`note`,`score`,`created_at`)") {
+		t.Errorf("records should be loaded from the registered reader, but got %v", statement)
+	}
+
+	expected := "1\tbulk_1\tline\\nwith \\\\ and\\ttab\t1.5\t2020-01-02 03:04:05\n" +
+		"2\tbulk_2\t\\N\t0\t2020-01-02 03:04:05\n"
+	if string(data) != expected {
+		t.Errorf("records should be written as tab separated values, expected %q, but got %q", expected, data)
+	}
+}
+
+func TestBulkLoadInsertInBatches(t *testing.T) {
+	db, recorder, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	now := time.Now()
+	records := []BulkRecord{{Name: "bulk_1", CreatedAt: now}, {ID: 5, Name: "bulk_2", CreatedAt: now}, {Name: "bulk_3", CreatedAt: now}}
+	if result := db.BulkLoad(records); result.Error != nil || result.RowsAffected != 3 {
+		t.Fatalf("records should be loaded, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var inserts []gormtest.Statement
+	for _, statement := range recorder.Statements() {
+		if strings.HasPrefix(statement.SQL, "INSERT") {
+			inserts = append(inserts, statement)
+		}
+	}
+	if len(inserts) != 2 {
+		t.Fatalf("records should be inserted in a statement for each group of columns, but got %#v", inserts)
+	}
+	if expected := `INSERT INTO "bulk_records" ("name","note","score","created_at") VALUES (?,?,?,?),(?,?,?,?)`; inserts[0].SQL != expected {
+		t.Errorf("records with blank primary keys should be inserted together, expected %v, but got %v", expected, inserts[0].SQL)
+	}
+	if !strings.HasPrefix(inserts[1].SQL, `INSERT INTO "bulk_records" ("id","name"`) || len(inserts[1].Vars) != 5 {
+		t.Errorf("records with primary keys should be inserted with them, but got %#v", inserts[1])
+	}
+}
+
+func TestBulkLoadWithPlugins(t *testing.T) {
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.Use(&gorm.WriteGuard{Tables: map[string]gorm.WriteOperation{"bulk_records": 0}})
+	err = db.BulkLoad([]BulkRecord{{Name: "bulk_1"}}).Error
+	if _, ok := err.(*gorm.WriteGuardError); !ok {
+		t.Errorf("bulk loading records of guarded tables should be refused, but got %v", err)
+	}
+}
+
+type BulkKeyRecord struct {
+	ID   string `gorm:"primary_key;default:uuid"`
+	Name string
+}
+
+func TestBulkLoadGeneratedKeys(t *testing.T) {
+	DB.DropTableIfExists(&BulkKeyRecord{})
+	DB.AutoMigrate(&BulkKeyRecord{})
+
+	records := []BulkKeyRecord{{Name: "bulk_1"}, {Name: "bulk_2"}}
+	if err := DB.BulkLoad(records).Error; err != nil {
+		t.Fatalf("records should be loaded, but got %v", err)
+	}
+
+	var loaded []BulkKeyRecord
+	DB.Order("name").Find(&loaded)
+	if len(loaded) != 2 || loaded[0].ID == "" || loaded[1].ID == "" || loaded[0].ID == loaded[1].ID || loaded[0].ID != records[0].ID {
+		t.Errorf("blank keys should be generated, but got %#v", loaded)
+	}
+}
+
+func TestBulkLoadRoutedTables(t *testing.T) {
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	db.Use(&gorm.Sharding{Tables: map[string]gorm.ShardConfig{"bulk_records": {ShardKey: "name", Shards: 2}}})
+	if err := db.BulkLoad([]BulkRecord{{Name: "bulk_1"}}).Error; err == nil || !strings.Contains(err.Error(), "shards") {
+		t.Errorf("bulk loading records of sharded tables should be refused, but got %v", err)
+	}
+
+	db, _, err = gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	other, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	db.Use((&gorm.DBResolver{}).Register(other, &BulkRecord{}))
+	if err := db.BulkLoad([]BulkRecord{{Name: "bulk_1"}}).Error; err == nil || !strings.Contains(err.Error(), "registered db") {
+		t.Errorf("bulk loading records of tables in other databases should be refused, but got %v", err)
+	}
+	if err := other.BulkLoad([]BulkRecord{{Name: "bulk_1"}}).Error; err != nil && strings.Contains(err.Error(), "registered db") {
+		t.Errorf("bulk loading records with the registered db should be allowed, but got %v", err)
+	}
+}
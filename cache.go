@@ -0,0 +1,282 @@
+package gorm
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CacheStore stores cached query results, implement it to use your own store, e.g. memcached
+type CacheStore interface {
+	// Get return cached value, the second result is false if not found or expired
+	Get(key string) ([]byte, bool)
+	// Set set value with ttl, the value never expires if ttl is 0
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// QueryCache plugin caches query results of `db.Cache(ttl)`, results are stored by hash of generated SQL and its vars,
+// and invalidated when creating, updating or deleting records of the table
+//    db.Use(gorm.NewQueryCache(nil))
+//    db.Cache(time.Minute).Where("name = ?", "jinzhu").Find(&users)
+//
+// Only the table of queried model is tracked, tables used in joins or raw SQL won't invalidate the cache.
+// Results are cached as they are returned, so values of encrypted fields are stored decrypted, use a trusted store for them
+type QueryCache struct {
+	Store CacheStore
+}
+
+// NewQueryCache create query cache plugin with store, use an in-memory LRU store with 1000 entries if store is nil
+func NewQueryCache(store CacheStore) *QueryCache {
+	if store == nil {
+		store = NewMemoryCacheStore(1000)
+	}
+	return &QueryCache{Store: store}
+}
+
+// Cache cache query results for ttl, requires QueryCache plugin, otherwise it has no effect
+//    db.Cache(time.Minute).First(&user, 1)
+func (s *DB) Cache(ttl time.Duration) *DB {
+	return s.Set("gorm:cache_ttl", ttl)
+}
+
+// Name return plugin name
+func (cache *QueryCache) Name() string {
+	return "gorm:query_cache"
+}
+
+// Initialize register callbacks to read through cache and invalidate it
+func (cache *QueryCache) Initialize(db *DB) error {
+	callback := db.Callback()
+	callback.Query().Before("gorm:query").Register("gorm:query_cache", cache.queryCallback)
+	// store results after they are preloaded and decrypted, right before after query hooks
+	callback.Query().Before("gorm:after_query").Register("gorm:query_cache_store", cache.storeCallback)
+	// invalidate cached results after the change is committed, otherwise reads before committing could cache old records again
+	callback.Create().After("gorm:commit_or_rollback_transaction").Register("gorm:query_cache_invalidate", cache.invalidateCallback)
+	callback.Update().After("gorm:commit_or_rollback_transaction").Register("gorm:query_cache_invalidate", cache.invalidateCallback)
+	callback.Delete().After("gorm:commit_or_rollback_transaction").Register("gorm:query_cache_invalidate", cache.invalidateCallback)
+	return nil
+}
+
+// queryCallback fill query destination with cached results, and skip querying database if found
+func (cache *QueryCache) queryCallback(scope *Scope) {
+	ttl, ok := scope.Get("gorm:cache_ttl")
+	if !ok || scope.HasError() {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	key := cache.key(scope)
+	if value, found := cache.Store.Get(key); found {
+		results := scope.IndirectValue()
+		if dest, ok := scope.Get("gorm:query_destination"); ok {
+			results = indirect(reflect.ValueOf(dest))
+		}
+
+		// decode into a new value, so fields omitted by gob as zero values won't keep values of the destination
+		var (
+			rowsAffected int64
+			cached       = reflect.New(results.Type())
+			decoder      = gob.NewDecoder(bytes.NewReader(value))
+		)
+		if decoder.Decode(&rowsAffected) == nil && decoder.Decode(cached.Interface()) == nil {
+			results.Set(cached.Elem())
+			scope.db.RowsAffected = rowsAffected
+			scope.InstanceSet("gorm:skip_query_callback", true)
+			return
+		}
+	}
+
+	scope.InstanceSet("gorm:query_cache_key", key)
+	scope.InstanceSet("gorm:query_cache_ttl", ttl)
+}
+
+// storeCallback save query results into cache
+func (cache *QueryCache) storeCallback(scope *Scope) {
+	key, ok := scope.InstanceGet("gorm:query_cache_key")
+	if !ok || scope.HasError() {
+		return
+	}
+
+	results := scope.IndirectValue()
+	if dest, ok := scope.Get("gorm:query_destination"); ok {
+		results = indirect(reflect.ValueOf(dest))
+	}
+
+	// results are encoded with gob, so fields are restored as they are scanned, regardless of their JSON encoding
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	err := encoder.Encode(scope.db.RowsAffected)
+	if err == nil {
+		err = encoder.Encode(results.Interface())
+	}
+	if err != nil {
+		scope.Log(fmt.Sprintf("failed to cache query results, %v", err))
+		return
+	}
+
+	ttl, _ := scope.InstanceGet("gorm:query_cache_ttl")
+	if err := cache.Store.Set(key.(string), buf.Bytes(), ttl.(time.Duration)); err != nil {
+		scope.Log(fmt.Sprintf("failed to cache query results, %v", err))
+	}
+}
+
+// invalidateCallback invalidate cached results of the table by changing its version after committing the change,
+// changes in transactions invalidate it again after committing the transaction, as reads before committing could cache old records
+func (cache *QueryCache) invalidateCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	table := scope.TableName()
+	if err := cache.invalidate(table); err != nil {
+		scope.Err(fmt.Errorf("failed to invalidate query cache of %v, %v", table, err))
+		return
+	}
+
+	scope.db.afterCommit(func() {
+		if err := cache.invalidate(table); err != nil {
+			scope.Log(fmt.Sprintf("failed to invalidate query cache of %v, %v", table, err))
+		}
+	})
+}
+
+func (cache *QueryCache) invalidate(table string) error {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	return cache.Store.Set(cache.versionKey(table), []byte(version), 0)
+}
+
+// key generate cache key with SQL, vars, destination type and version of the table
+func (cache *QueryCache) key(scope *Scope) string {
+	keyScope := scope.New(scope.Value)
+	keyScope.Search = scope.Search.clone()
+	keyScope.prepareQuerySQL()
+
+	results := scope.IndirectValue()
+	if dest, ok := scope.Get("gorm:query_destination"); ok {
+		results = indirect(reflect.ValueOf(dest))
+	}
+
+	orderBy, _ := scope.Get("gorm:order_by_primary_key")
+	version, _ := cache.Store.Get(cache.versionKey(scope.TableName()))
+
+	hash := sha1.Sum([]byte(fmt.Sprintf("%v|%v|%v|%v|%s", keyScope.SQL, keyScope.SQLVars, orderBy, results.Type(), version)))
+	return "gorm:cache:" + hex.EncodeToString(hash[:])
+}
+
+func (cache *QueryCache) versionKey(tableName string) string {
+	return "gorm:cache_version:" + tableName
+}
+
+// MemoryCacheStore in-memory cache store, evicts least recently used entries when reaching its size
+type MemoryCacheStore struct {
+	size    int
+	mutex   sync.Mutex
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore create in-memory LRU cache store
+func NewMemoryCacheStore(size int) *MemoryCacheStore {
+	return &MemoryCacheStore{size: size, entries: list.New(), items: map[string]*list.Element{}}
+}
+
+// Get get cached value
+func (store *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if elem, ok := store.items[key]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		if entry.expiresAt.IsZero() || entry.expiresAt.After(time.Now()) {
+			store.entries.MoveToFront(elem)
+			return entry.value, true
+		}
+		store.entries.Remove(elem)
+		delete(store.items, key)
+	}
+	return nil, false
+}
+
+// Set set value with ttl
+func (store *MemoryCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if elem, ok := store.items[key]; ok {
+		elem.Value = entry
+		store.entries.MoveToFront(elem)
+		return nil
+	}
+
+	store.items[key] = store.entries.PushFront(entry)
+	for store.size > 0 && store.entries.Len() > store.size {
+		oldest := store.entries.Back()
+		store.entries.Remove(oldest)
+		delete(store.items, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+// RedisClient is the minimal redis functionality the redis cache store requires, wrap your redis client to implement it
+//    type redisClient struct{ *redis.Client }
+//
+//    func (c redisClient) Get(key string) ([]byte, error) {
+//      value, err := c.Client.Get(key).Bytes()
+//      if err == redis.Nil {
+//        return nil, nil
+//      }
+//      return value, err
+//    }
+//
+//    func (c redisClient) Set(key string, value []byte, ttl time.Duration) error {
+//      return c.Client.Set(key, value, ttl).Err()
+//    }
+type RedisClient interface {
+	// Get return nil value without error if key doesn't exist
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// RedisCacheStore cache store saving results into redis, keys are prefixed to share redis with other applications
+type RedisCacheStore struct {
+	Client RedisClient
+	Prefix string
+}
+
+// NewRedisCacheStore create redis cache store
+//    db.Use(gorm.NewQueryCache(gorm.NewRedisCacheStore(redisClient{client}, "myapp:")))
+func NewRedisCacheStore(client RedisClient, prefix string) *RedisCacheStore {
+	return &RedisCacheStore{Client: client, Prefix: prefix}
+}
+
+// Get get cached value
+func (store *RedisCacheStore) Get(key string) ([]byte, bool) {
+	value, err := store.Client.Get(store.Prefix + key)
+	return value, err == nil && value != nil
+}
+
+// Set set value with ttl
+func (store *RedisCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	return store.Client.Set(store.Prefix+key, value, ttl)
+}
@@ -0,0 +1,160 @@
+package gorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestQueryCache(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Use(gorm.NewQueryCache(nil)); err != nil {
+		t.Fatalf("Failed to use query cache, got %v", err)
+	}
+
+	user1, user2 := User{Name: "query_cache", Age: 1}, User{Name: "query_cache", Age: 2}
+	db.Save(&user1).Save(&user2)
+
+	var first, last User
+	db.Cache(time.Minute).Where("name = ?", "query_cache").First(&first)
+	db.Cache(time.Minute).Where("name = ?", "query_cache").Last(&last)
+	if first.Id != user1.Id || last.Id != user2.Id {
+		t.Errorf("Should not share cache between different queries, got %v, %v", first.Id, last.Id)
+	}
+
+	// raw SQL won't invalidate cache
+	db.Exec("UPDATE users SET age = ? WHERE id = ?", 10, user1.Id)
+
+	var users []User
+	db.Cache(time.Minute).Where("name = ?", "query_cache").Order("id").Find(&users)
+	db.Exec("UPDATE users SET age = ? WHERE id = ?", 20, user1.Id)
+
+	var cachedUsers []User
+	if err := db.Cache(time.Minute).Where("name = ?", "query_cache").Order("id").Find(&cachedUsers).Error; err != nil {
+		t.Fatalf("No error should happen when querying cached results, but got %v", err)
+	}
+
+	if len(cachedUsers) != 2 || cachedUsers[0].Age != 10 {
+		t.Errorf("Should find cached results, but got %#v", cachedUsers)
+	}
+
+	var uncachedUsers []User
+	if db.Where("name = ?", "query_cache").Order("id").Find(&uncachedUsers); uncachedUsers[0].Age != 20 {
+		t.Errorf("Should not use cache without Cache, but got %v", uncachedUsers[0].Age)
+	}
+
+	db.Model(&user2).Update("age", 3)
+
+	var updatedUsers []User
+	db.Cache(time.Minute).Where("name = ?", "query_cache").Order("id").Find(&updatedUsers)
+	if len(updatedUsers) != 2 || updatedUsers[0].Age != 20 || updatedUsers[1].Age != 3 {
+		t.Errorf("Cache should be invalidated after updating, but got %#v", updatedUsers)
+	}
+}
+
+type CachedNote struct {
+	ID     uint
+	Title  string
+	Secret string `json:"-"`
+}
+
+func TestQueryCacheWithTransaction(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+	db.Use(gorm.NewQueryCache(nil))
+
+	db.DropTableIfExists(&CachedNote{})
+	db.AutoMigrate(&CachedNote{})
+
+	note := CachedNote{Title: "draft", Secret: "secret"}
+	db.Create(&note)
+
+	for i := 0; i < 2; i++ {
+		var cached CachedNote
+		if db.Cache(time.Minute).First(&cached, note.ID); cached.Title != "draft" || cached.Secret != "secret" {
+			t.Errorf("Cached results should keep all scanned fields, but got %#v", cached)
+		}
+	}
+
+	tx := db.Begin()
+	tx.Model(&note).Update("title", "published")
+
+	// read before committing caches the old record
+	var old CachedNote
+	db.Cache(time.Minute).First(&old, note.ID)
+	tx.Commit()
+
+	var committed CachedNote
+	if db.Cache(time.Minute).First(&committed, note.ID); committed.Title != "published" {
+		t.Errorf("Cache should be invalidated after committing, but got %#v", committed)
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	store := gorm.NewMemoryCacheStore(2)
+	store.Set("a", []byte("1"), 0)
+	store.Set("b", []byte("2"), 0)
+	store.Get("a")
+	store.Set("c", []byte("3"), 0)
+
+	if _, ok := store.Get("b"); ok {
+		t.Errorf("Least recently used entry should be evicted")
+	}
+
+	if value, ok := store.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Recently used entry should be kept, but got %s", value)
+	}
+
+	store.Set("d", []byte("4"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := store.Get("d"); ok {
+		t.Errorf("Expired entry should not be found")
+	}
+}
+
+func TestQueryCacheWithEncryption(t *testing.T) {
+	for _, cacheFirst := range []bool{true, false} {
+		db, recorder, err := gormtest.Open("postgres")
+		if err != nil {
+			t.Fatalf("failed to open db, got %v", err)
+		}
+
+		encryption := &gorm.Encryption{Keys: gorm.StaticKey("0123456789abcdef0123456789abcdef")}
+		plugins := []gorm.Plugin{gorm.NewQueryCache(nil), encryption}
+		if !cacheFirst {
+			plugins[0], plugins[1] = plugins[1], plugins[0]
+		}
+		for _, plugin := range plugins {
+			if err := db.Use(plugin); err != nil {
+				t.Fatalf("failed to use %v, got %v", plugin.Name(), err)
+			}
+		}
+
+		email, _ := encryption.Encrypt("encrypted_customers", "email", "jinzhu@example.org")
+		recorder.Reply(`SELECT * FROM "encrypted_customers"`, []string{"id", "email"}, []interface{}{1, email})
+
+		for i := 0; i < 2; i++ {
+			var customers []EncryptedCustomer
+			if err := db.Cache(time.Minute).Find(&customers).Error; err != nil {
+				t.Fatalf("no error should happen when querying encrypted records, but got %v", err)
+			}
+			if len(customers) != 1 || customers[0].Email != "jinzhu@example.org" {
+				t.Errorf("cached records should be decrypted, but got %#v", customers)
+			}
+		}
+
+		if statements := recorder.Statements(); len(statements) != 1 {
+			t.Errorf("records should be found from cache, but got %v", statements)
+		}
+	}
+}
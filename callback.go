@@ -1,6 +1,9 @@
 package gorm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // DefaultCallback default callbacks defined by gorm
 var DefaultCallback = &Callback{logger: nopLogger{}}
@@ -33,18 +36,28 @@ type CallbackProcessor struct {
 	kind      string              // callback type: create, update, delete, query, row_query
 	processor *func(scope *Scope) // callback handler
 	parent    *Callback
+
+	sortBefore string // callback sorted before, set when a callback registered after current callback is sorted first
 }
 
 func (c *Callback) clone(logger logger) *Callback {
-	return &Callback{
+	clone := &Callback{
 		logger:     logger,
 		creates:    c.creates,
 		updates:    c.updates,
 		deletes:    c.deletes,
 		queries:    c.queries,
 		rowQueries: c.rowQueries,
-		processors: c.processors,
 	}
+
+	// processors are changed when sorting them, so copy them instead of sharing with callbacks in use
+	clone.processors = make([]*CallbackProcessor, len(c.processors))
+	for idx, processor := range c.processors {
+		p := *processor
+		p.parent = clone
+		clone.processors[idx] = &p
+	}
+	return clone
 }
 
 // Create could be used to register callbacks for creating object
@@ -147,6 +160,145 @@ func (cp *CallbackProcessor) Get(callbackName string) (callback func(scope *Scop
 	return
 }
 
+// Wrap replace a registered callback with a new callback wrapping it, the registered callback is passed to wrapper as handler
+//     db.Callback().Query().Wrap("gorm:query", func(handler func(*Scope)) func(*Scope) {
+//       return func(scope *Scope) {
+//         start := time.Now()
+//         handler(scope)
+//         metrics.Observe(scope.TableName(), time.Since(start))
+//       }
+//     })
+func (cp *CallbackProcessor) Wrap(callbackName string, wrapper func(handler func(scope *Scope)) func(scope *Scope)) {
+	handler := cp.Get(callbackName)
+	if handler == nil {
+		cp.logger.Print("warning", fmt.Sprintf("[warning] wrapping unregistered callback `%v` from %v", callbackName, fileWithLineNum()))
+		handler = func(*Scope) {}
+	}
+	cp.Replace(callbackName, wrapper(handler))
+}
+
+// List return names of registered callbacks in execution order
+//    db.Callback().Create().List()
+//    // [gorm:begin_transaction gorm:before_create gorm:save_before_associations ...]
+func (cp *CallbackProcessor) List() []string {
+	var names []string
+	for _, p := range sortedProcessors(cp.parent.processorsOf(cp.kind)) {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// Validate check registered callbacks, return error if callbacks are registered before or after missing callbacks,
+// or their orders conflict with each other, which are reordered silently when registering, call it after registering callbacks at startup
+//    if err := db.Callback().Validate(); err != nil {
+//      panic(err)
+//    }
+func (c *Callback) Validate() error {
+	for _, kind := range []string{"create", "update", "delete", "query", "row_query"} {
+		if err := validateProcessors(kind, c.processorsOf(kind)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processorsOf return processors of the kind in registering order
+func (c *Callback) processorsOf(kind string) []*CallbackProcessor {
+	var processors []*CallbackProcessor
+	for _, processor := range c.processors {
+		if processor.name != "" && processor.kind == kind {
+			processors = append(processors, processor)
+		}
+	}
+	return processors
+}
+
+// validateProcessors check anchors of processors exist, and they are sorted without conflicts
+func validateProcessors(kind string, cps []*CallbackProcessor) error {
+	removed := map[string]bool{}
+	for _, cp := range cps {
+		removed[cp.name] = cp.remove
+	}
+
+	var (
+		names  []string
+		orders = map[string][]string{} // callbacks must run after the callback
+	)
+	for _, cp := range cps {
+		if removed[cp.name] {
+			continue
+		}
+		if getRIndex(names, cp.name) == -1 {
+			names = append(names, cp.name)
+		}
+
+		for _, anchor := range []string{cp.before, cp.after} {
+			if exists, ok := removed[anchor]; anchor != "" && (!ok || exists) {
+				return fmt.Errorf("%v callback `%v` is registered before or after missing callback `%v`", kind, cp.name, anchor)
+			}
+		}
+
+		if cp.before != "" && getRIndex(orders[cp.name], cp.before) == -1 {
+			orders[cp.name] = append(orders[cp.name], cp.before)
+		}
+		if cp.after != "" && getRIndex(orders[cp.after], cp.name) == -1 {
+			orders[cp.after] = append(orders[cp.after], cp.name)
+		}
+	}
+
+	var (
+		visiting, visited = map[string]bool{}, map[string]bool{}
+		path              []string
+		visit             func(name string) error
+	)
+	visit = func(name string) error {
+		if visiting[name] {
+			return fmt.Errorf("%v callbacks have cyclic orders: %v", kind, strings.Join(append(path[getRIndex(path, name):], name), " -> "))
+		}
+		if visited[name] {
+			return nil
+		}
+
+		visiting[name] = true
+		path = append(path, name)
+		for _, next := range orders[name] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[name], visited[name] = false, true
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	var sortedNames []string
+	for _, cp := range sortedProcessors(cps) {
+		sortedNames = append(sortedNames, cp.name)
+	}
+	for name, nexts := range orders {
+		for _, next := range nexts {
+			if getRIndex(sortedNames, name) > getRIndex(sortedNames, next) {
+				return fmt.Errorf("%v callback `%v` should run before `%v`, but it is sorted after it", kind, name, next)
+			}
+		}
+	}
+	return nil
+}
+
+// beforeName return the callback current callback should be sorted before
+func (cp *CallbackProcessor) beforeName() string {
+	if cp.before != "" {
+		return cp.before
+	}
+	return cp.sortBefore
+}
+
 // getRIndex get right index from string slice
 func getRIndex(strs []string, str string) int {
 	for i := len(strs) - 1; i >= 0; i-- {
@@ -159,26 +311,40 @@ func getRIndex(strs []string, str string) int {
 
 // sortProcessors sort callback processors based on its before, after, remove, replace
 func sortProcessors(cps []*CallbackProcessor) []*func(scope *Scope) {
+	var allNames []string
+	for _, cp := range cps {
+		// show warning message the callback name already exists
+		if index := getRIndex(allNames, cp.name); index > -1 && !cp.replace && !cp.remove {
+			cp.logger.Print("warning", fmt.Sprintf("[warning] duplicated callback `%v` from %v", cp.name, fileWithLineNum()))
+		}
+		allNames = append(allNames, cp.name)
+	}
+
+	var sortedFuncs []*func(scope *Scope)
+	for _, cp := range sortedProcessors(cps) {
+		sortedFuncs = append(sortedFuncs, cp.processor)
+	}
+	return sortedFuncs
+}
+
+// sortedProcessors return effective processors in execution order, removed callbacks are excluded
+func sortedProcessors(cps []*CallbackProcessor) []*CallbackProcessor {
 	var (
 		allNames, sortedNames []string
 		sortCallbackProcessor func(c *CallbackProcessor)
 	)
 
 	for _, cp := range cps {
-		// show warning message the callback name already exists
-		if index := getRIndex(allNames, cp.name); index > -1 && !cp.replace && !cp.remove {
-			cp.logger.Print("warning", fmt.Sprintf("[warning] duplicated callback `%v` from %v", cp.name, fileWithLineNum()))
-		}
 		allNames = append(allNames, cp.name)
 	}
 
 	sortCallbackProcessor = func(c *CallbackProcessor) {
 		if getRIndex(sortedNames, c.name) == -1 { // if not sorted
-			if c.before != "" { // if defined before callback
-				if index := getRIndex(sortedNames, c.before); index != -1 {
+			if before := c.beforeName(); before != "" { // if defined before callback
+				if index := getRIndex(sortedNames, before); index != -1 {
 					// if before callback already sorted, append current callback just after it
 					sortedNames = append(sortedNames[:index], append([]string{c.name}, sortedNames[index:]...)...)
-				} else if index := getRIndex(allNames, c.before); index != -1 {
+				} else if index := getRIndex(allNames, before); index != -1 {
 					// if before callback exists but haven't sorted, append current callback to last
 					sortedNames = append(sortedNames, c.name)
 					sortCallbackProcessor(cps[index])
@@ -193,8 +359,8 @@ func sortProcessors(cps []*CallbackProcessor) []*func(scope *Scope) {
 					// if after callback exists but haven't sorted
 					cp := cps[index]
 					// set after callback's before callback to current callback
-					if cp.before == "" {
-						cp.before = c.name
+					if cp.beforeName() == "" {
+						cp.sortBefore = c.name
 					}
 					sortCallbackProcessor(cp)
 				}
@@ -211,14 +377,14 @@ func sortProcessors(cps []*CallbackProcessor) []*func(scope *Scope) {
 		sortCallbackProcessor(cp)
 	}
 
-	var sortedFuncs []*func(scope *Scope)
+	var sorted []*CallbackProcessor
 	for _, name := range sortedNames {
 		if index := getRIndex(allNames, name); !cps[index].remove {
-			sortedFuncs = append(sortedFuncs, cps[index].processor)
+			sorted = append(sorted, cps[index])
 		}
 	}
 
-	return sortedFuncs
+	return sorted
 }
 
 // reorder all registered processors, and reset CRUD callbacks
@@ -1,16 +1,21 @@
 package gorm
 
 import (
+	"database/sql"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Define callbacks for creating
 func init() {
 	DefaultCallback.Create().Register("gorm:begin_transaction", beginTransactionCallback)
 	DefaultCallback.Create().Register("gorm:before_create", beforeCreateCallback)
+	DefaultCallback.Create().Register("gorm:generate_primary_key", generatePrimaryKeyCallback)
 	DefaultCallback.Create().Register("gorm:save_before_associations", saveBeforeAssociationsCallback)
 	DefaultCallback.Create().Register("gorm:update_time_stamp", updateTimeStampForCreateCallback)
+	DefaultCallback.Create().Register("gorm:update_actor", updateActorForCreateCallback)
 	DefaultCallback.Create().Register("gorm:create", createCallback)
 	DefaultCallback.Create().Register("gorm:force_reload_after_create", forceReloadAfterCreateCallback)
 	DefaultCallback.Create().Register("gorm:save_after_associations", saveAfterAssociationsCallback)
@@ -20,6 +25,9 @@ func init() {
 
 // beforeCreateCallback will invoke `BeforeSave`, `BeforeCreate` method before creating
 func beforeCreateCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if !scope.HasError() {
 		scope.CallMethod("BeforeSave")
 	}
@@ -33,20 +41,75 @@ func updateTimeStampForCreateCallback(scope *Scope) {
 	if !scope.HasError() {
 		now := scope.db.nowFunc()
 
-		if createdAtField, ok := scope.FieldByName("CreatedAt"); ok {
-			if createdAtField.IsBlank {
-				createdAtField.Set(now)
+		for _, field := range scope.Fields() {
+			if !field.IsBlank {
+				continue
+			}
+			if unit, ok := autoTimeUnit(field.StructField, "AUTOCREATETIME", "CreatedAt"); ok {
+				field.Set(autoTimeValue(field, unit, now))
+			} else if unit, ok := autoTimeUnit(field.StructField, "AUTOUPDATETIME", "UpdatedAt"); ok {
+				field.Set(autoTimeValue(field, unit, now))
 			}
 		}
+	}
+}
 
-		if updatedAtField, ok := scope.FieldByName("UpdatedAt"); ok {
-			if updatedAtField.IsBlank {
-				updatedAtField.Set(now)
+// autoTimeUnit return unit of the field stamped automatically, fields tagged with `autoCreateTime`, `autoUpdateTime` or named `CreatedAt`, `UpdatedAt`,
+// integer fields are stamped with unix seconds, or milliseconds, nanoseconds with tags like `autoCreateTime:milli`, `autoUpdateTime:nano`
+func autoTimeUnit(field *StructField, tag string, name string) (string, bool) {
+	if field.IsIgnored {
+		return "", false
+	}
+	if unit, ok := field.TagSettingsGet(tag); ok {
+		return strings.ToUpper(unit), strings.ToUpper(unit) != "FALSE"
+	}
+	return "", field.Name == name || field.DBName == ToDBName(name)
+}
+
+// isAutoCreateTime return true if the field is stamped when creating
+func isAutoCreateTime(field *StructField) bool {
+	_, ok := autoTimeUnit(field, "AUTOCREATETIME", "CreatedAt")
+	return ok
+}
+
+// autoTimeValue return value of time now in the unit for the field, integer fields use unix timestamps
+func autoTimeValue(field *Field, unit string, now time.Time) interface{} {
+	switch indirectType(field.Struct.Type).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch unit {
+		case "NANO":
+			return now.UnixNano()
+		case "MILLI":
+			return now.UnixNano() / int64(time.Millisecond)
+		default:
+			return now.Unix()
+		}
+	}
+	return now
+}
+
+// updateActorForCreateCallback will set `CreatedBy`, `UpdatedBy` with the actor of context set by WithActor when creating
+func updateActorForCreateCallback(scope *Scope) {
+	if !scope.HasError() {
+		for _, name := range []string{"CreatedBy", "UpdatedBy"} {
+			if field, ok := scope.FieldByName(name); ok && field.IsBlank {
+				if actor, ok := actorOf(scope, field); ok {
+					scope.Err(field.Set(actor))
+				}
 			}
 		}
 	}
 }
 
+// actorOf return actor of context set by WithActor, it will be formatted as string for string fields
+func actorOf(scope *Scope, field *Field) (interface{}, bool) {
+	actor, ok := ActorFromContext(scope.Context())
+	if _, isString := actor.(string); ok && !isString && indirectType(field.Struct.Type).Kind() == reflect.String {
+		actor = fmt.Sprint(actor)
+	}
+	return actor, ok
+}
+
 // createCallback the callback used to insert data into database
 func createCallback(scope *Scope) {
 	if !scope.HasError() {
@@ -65,7 +128,7 @@ func createCallback(scope *Scope) {
 						scope.InstanceSet("gorm:blank_columns_with_default_value", blankColumnsWithDefaultValue)
 					} else if !field.IsPrimaryKey || !field.IsBlank {
 						columns = append(columns, scope.Quote(field.DBName))
-						placeholders = append(placeholders, scope.AddToVars(field.Field.Interface()))
+						placeholders = append(placeholders, scope.AddToVars(sensitiveVar(scope, field.DBName, field.Field.Interface())))
 					}
 				} else if field.Relationship != nil && field.Relationship.Kind == "belongs_to" {
 					for _, foreignKey := range field.Relationship.ForeignDBNames {
@@ -108,9 +171,10 @@ func createCallback(scope *Scope) {
 
 		if len(columns) == 0 {
 			scope.Raw(fmt.Sprintf(
-				"INSERT%v INTO %v %v%v%v",
+				"INSERT%v INTO %v%v %v%v%v",
 				addExtraSpaceIfExist(insertModifier),
 				quotedTableName,
+				addExtraSpaceIfExist(lastInsertIDOutputInterstitial),
 				scope.Dialect().DefaultValueStr(),
 				addExtraSpaceIfExist(extraOption),
 				addExtraSpaceIfExist(lastInsertIDReturningSuffix),
@@ -136,7 +200,7 @@ func createCallback(scope *Scope) {
 
 				// set primary value to primary field
 				if primaryField != nil && primaryField.IsBlank {
-					if primaryValue, err := result.LastInsertId(); scope.Err(err) == nil {
+					if primaryValue, err := scope.lastInsertID(result); scope.Err(err) == nil {
 						scope.Err(primaryField.Set(primaryValue))
 					}
 				}
@@ -152,7 +216,7 @@ func createCallback(scope *Scope) {
 
 				// set primary value to primary field
 				if primaryField != nil && primaryField.IsBlank {
-					if primaryValue, err := result.LastInsertId(); scope.Err(err) == nil {
+					if primaryValue, err := scope.lastInsertID(result); scope.Err(err) == nil {
 						scope.Err(primaryField.Set(primaryValue))
 					}
 				}
@@ -162,7 +226,12 @@ func createCallback(scope *Scope) {
 
 		// execute create sql: dialects with additional lastInsertID requirements (currently postgres & mssql)
 		if primaryField.Field.CanAddr() {
-			if err := scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...).Scan(primaryField.Field.Addr().Interface()); scope.Err(err) == nil {
+			err := scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...).Scan(primaryField.Field.Addr().Interface())
+			if _, ok := scope.Get("gorm:ignore_conflict"); ok && errorIs(err, sql.ErrNoRows) {
+				// no rows are returned if the conflict is ignored
+				return
+			}
+			if scope.Err(err) == nil {
 				primaryField.IsBlank = false
 				scope.db.RowsAffected = 1
 			}
@@ -188,6 +257,9 @@ func forceReloadAfterCreateCallback(scope *Scope) {
 
 // afterCreateCallback will invoke `AfterCreate`, `AfterSave` method after creating
 func afterCreateCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if !scope.HasError() {
 		scope.CallMethod("AfterCreate")
 	}
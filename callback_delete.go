@@ -3,13 +3,16 @@ package gorm
 import (
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // Define callbacks for deleting
 func init() {
 	DefaultCallback.Delete().Register("gorm:begin_transaction", beginTransactionCallback)
 	DefaultCallback.Delete().Register("gorm:before_delete", beforeDeleteCallback)
+	DefaultCallback.Delete().Register("gorm:delete_associations", deleteAssociationsCallback)
 	DefaultCallback.Delete().Register("gorm:delete", deleteCallback)
+	DefaultCallback.Delete().Register("gorm:require_rows_affected", requireRowsAffectedCallback)
 	DefaultCallback.Delete().Register("gorm:after_delete", afterDeleteCallback)
 	DefaultCallback.Delete().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
 }
@@ -20,11 +23,66 @@ func beforeDeleteCallback(scope *Scope) {
 		scope.Err(errors.New("missing WHERE clause while deleting"))
 		return
 	}
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if !scope.HasError() {
 		scope.CallMethod("BeforeDelete")
 	}
 }
 
+// deleteAssociationsCallback delete selected associations of deleting records in the same transaction, e.g:
+//    db.Select("Emails", "Languages").Delete(&user)
+//    db.Select(gorm.Associations).Delete(&users)
+// has one & has many associations will be deleted (or soft deleted), many to many associations will only be removed from the join table
+func deleteAssociationsCallback(scope *Scope) {
+	selectAttrs := scope.SelectAttrs()
+	if scope.HasError() || len(selectAttrs) == 0 {
+		return
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		relationship := field.Relationship
+		if relationship == nil || !(strInSlice(Associations, selectAttrs) || strInSlice(field.Name, selectAttrs)) {
+			continue
+		}
+
+		newDB := scope.NewDB()
+		if scope.Search.Unscoped {
+			newDB = newDB.Unscoped()
+		}
+
+		switch relationship.Kind {
+		case "has_one", "has_many":
+			primaryKeys := scope.getColumnAsArray(relationship.AssociationForeignFieldNames, scope.Value)
+			if len(primaryKeys) == 0 {
+				continue
+			}
+
+			newDB = newDB.Where(
+				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(primaryKeys)),
+				toQueryValues(primaryKeys)...,
+			)
+
+			if relationship.PolymorphicType != "" {
+				newDB = newDB.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.polymorphicValue())
+			}
+			scope.Err(newDB.Delete(reflect.New(elemTypeOf(field.Struct.Type)).Interface()).Error)
+		case "many_to_many":
+			primaryKeys := scope.getColumnAsArray(fieldNamesOf(scope, relationship.ForeignFieldNames), scope.Value)
+			if len(primaryKeys) == 0 {
+				continue
+			}
+
+			newDB = newDB.Where(
+				fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relationship.ForeignDBNames), toQueryMarks(primaryKeys)),
+				toQueryValues(primaryKeys)...,
+			)
+			scope.Err(relationship.JoinTableHandler.Delete(relationship.JoinTableHandler, newDB))
+		}
+	}
+}
+
 // deleteCallback used to delete data from database or set deleted_at to current time (when using with soft delete)
 func deleteCallback(scope *Scope) {
 	if !scope.HasError() {
@@ -57,6 +115,9 @@ func deleteCallback(scope *Scope) {
 
 // afterDeleteCallback will invoke `AfterDelete` method after deleting
 func afterDeleteCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if !scope.HasError() {
 		scope.CallMethod("AfterDelete")
 	}
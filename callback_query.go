@@ -54,10 +54,18 @@ func queryCallback(scope *Scope) {
 	} else if kind != reflect.Struct {
 		scope.Err(errors.New("unsupported destination, should be slice or struct"))
 		return
+	} else {
+		resultType = results.Type()
 	}
 
 	scope.prepareQuerySQL()
 
+	// has one or belongs to associations selected with LEFT JOIN
+	var joinPreloads []*joinPreload
+	if len(scope.Search.selects) == 0 {
+		joinPreloads = scope.joinPreloads()
+	}
+
 	if !scope.HasError() {
 		scope.db.RowsAffected = 0
 
@@ -73,6 +81,15 @@ func queryCallback(scope *Scope) {
 			defer rows.Close()
 
 			columns, _ := rows.Columns()
+
+			// records are scanned with the compiled scanner of columns if fields don't need to be parsed for each record
+			var state *scanState
+			if len(joinPreloads) == 0 {
+				if scanner := scope.New(reflect.New(resultType).Interface()).GetModelStruct().scanner(columns); scanner != nil {
+					state = scanner.newState(scope.db.timeLocation())
+				}
+			}
+
 			for rows.Next() {
 				scope.db.RowsAffected++
 
@@ -81,7 +98,13 @@ func queryCallback(scope *Scope) {
 					elem = reflect.New(resultType).Elem()
 				}
 
-				scope.scan(rows, columns, scope.New(elem.Addr().Interface()).Fields())
+				if len(joinPreloads) > 0 {
+					scope.scanWithJoinPreloads(rows, columns, elem, joinPreloads)
+				} else if state != nil {
+					scope.Err(state.scan(rows, elem))
+				} else {
+					scope.scan(rows, columns, scope.New(elem.Addr().Interface()).Fields())
+				}
 
 				if isSlice {
 					if isPtr {
@@ -94,13 +117,24 @@ func queryCallback(scope *Scope) {
 
 			if err := rows.Err(); err != nil {
 				scope.Err(err)
-			} else if scope.db.RowsAffected == 0 && !isSlice {
+			} else if scope.db.RowsAffected == 0 && !isSlice && returnErrRecordNotFound(scope) {
 				scope.Err(ErrRecordNotFound)
 			}
+
+			// columns of join preloads are aliased, they are checked by scanning
+			if len(joinPreloads) == 0 {
+				scope.checkScan(resultType, columns)
+			}
 		}
 	}
 }
 
+// returnErrRecordNotFound return false if ErrRecordNotFound is disabled by ReturnErrRecordNotFound
+func returnErrRecordNotFound(scope *Scope) bool {
+	enable, ok := scope.Get("gorm:return_err_record_not_found")
+	return !ok || enable != false
+}
+
 // afterQueryCallback will invoke `AfterFind` method after querying
 func afterQueryCallback(scope *Scope) {
 	if !scope.HasError() {
@@ -1,6 +1,7 @@
 package gorm
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
@@ -8,6 +9,22 @@ import (
 	"strings"
 )
 
+// LimitPerRecord limit the number of preloaded has many or many to many associations for each record
+//    db.Preload("Comments", gorm.LimitPerRecord(3), func(db *gorm.DB) *gorm.DB {
+//      return db.Order("created_at DESC")
+//    }).Find(&posts)
+// it uses window function ROW_NUMBER() if the dialect supports it, otherwise associations are queried for each record
+type LimitPerRecord int
+
+// Associations used to preload all associations, associations of associations are preloaded until the max depth,
+// which is set with `db.Set("gorm:preload_max_depth", 2)`, default is 1
+//    db.Preload(gorm.Associations).Find(&users)
+//    db.Preload("Orders." + gorm.Associations).Find(&users)
+//
+// Conditions are applied to preloading each association of the level of the wildcard, not to nested associations
+//    db.Preload(gorm.Associations, "deleted = ?", false).Find(&users)
+const Associations = "*"
+
 // preloadCallback used to preload associations
 func preloadCallback(scope *Scope) {
 	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
@@ -28,6 +45,8 @@ func preloadCallback(scope *Scope) {
 		return
 	}
 
+	scope.Search.preload = scope.expandPreloadWildcards(scope.Search.preload)
+
 	var (
 		preloadedMap = map[string]bool{}
 		fields       = scope.Fields()
@@ -38,6 +57,7 @@ func preloadCallback(scope *Scope) {
 			preloadFields = strings.Split(preload.schema, ".")
 			currentScope  = scope
 			currentFields = fields
+			embeddedPath  string
 		)
 
 		for idx, preloadField := range preloadFields {
@@ -47,6 +67,10 @@ func preloadCallback(scope *Scope) {
 				continue
 			}
 
+			// relations declared in embedded structs are preloaded with paths like `Billing.Country`
+			preloadField = embeddedPath + preloadField
+			embeddedPath = ""
+
 			// if not preloaded
 			if preloadKey := strings.Join(preloadFields[:idx+1], "."); !preloadedMap[preloadKey] {
 
@@ -56,7 +80,7 @@ func preloadCallback(scope *Scope) {
 				}
 
 				for _, field := range currentFields {
-					if field.Name != preloadField || field.Relationship == nil {
+					if field.Relationship == nil || field.Name != preloadField && !field.hasPath(preloadField, false) {
 						continue
 					}
 
@@ -78,6 +102,10 @@ func preloadCallback(scope *Scope) {
 				}
 
 				if !preloadedMap[preloadKey] {
+					if idx < len(preloadFields)-1 && isEmbeddedPath(currentFields, preloadField) {
+						embeddedPath = preloadField + "."
+						continue
+					}
 					scope.Err(fmt.Errorf("can't preload field %s for %s", preloadField, currentScope.GetModelStruct().ModelType))
 					return
 				}
@@ -94,6 +122,111 @@ func preloadCallback(scope *Scope) {
 	}
 }
 
+// isEmbeddedPath return true if the path like `Billing` is the path of an embedded struct having fields
+func isEmbeddedPath(fields []*Field, path string) bool {
+	for _, field := range fields {
+		if field.hasPath(path, true) && !field.hasPath(path, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPreloadWildcards expand preloads ending with `Associations` to preload all associations,
+// associations are expanded recursively until the max depth, associations referring to models in the path are skipped to avoid cycles
+func (scope *Scope) expandPreloadWildcards(preloads []searchPreload) []searchPreload {
+	var (
+		results  []searchPreload
+		maxDepth = 1
+	)
+
+	if value, ok := scope.Get("gorm:preload_max_depth"); ok {
+		if depth, ok := value.(int); ok {
+			maxDepth = depth
+		}
+	}
+
+	for _, preload := range preloads {
+		if preload.schema != Associations && !strings.HasSuffix(preload.schema, "."+Associations) {
+			results = append(results, preload)
+			continue
+		}
+
+		var (
+			prefix    = strings.TrimSuffix(strings.TrimSuffix(preload.schema, Associations), ".")
+			modelType = scope.GetModelStruct().ModelType
+			path      = []reflect.Type{modelType}
+		)
+
+		if prefix != "" {
+			results = append(results, searchPreload{schema: prefix})
+			for _, name := range strings.Split(prefix, ".") {
+				var fieldType reflect.Type
+				for _, field := range scope.New(reflect.New(modelType).Interface()).GetModelStruct().StructFields {
+					if field.Name == name && field.Relationship != nil {
+						fieldType = elemTypeOf(field.Struct.Type)
+						break
+					}
+				}
+
+				// invalid preload will be reported when preloading the prefix
+				if fieldType == nil {
+					modelType = nil
+					break
+				}
+				modelType = fieldType
+				path = append(path, modelType)
+			}
+		}
+
+		if modelType != nil {
+			results = append(results, scope.associationPreloads(prefix, modelType, path, maxDepth, preload.conditions)...)
+		}
+	}
+	return results
+}
+
+// associationPreloads return preloads of all associations of the model type, preloads of the model's associations are with the conditions
+func (scope *Scope) associationPreloads(prefix string, modelType reflect.Type, path []reflect.Type, depth int, conditions []interface{}) (results []searchPreload) {
+	if depth <= 0 {
+		return
+	}
+
+	for _, field := range scope.New(reflect.New(modelType).Interface()).GetModelStruct().StructFields {
+		if field.Relationship == nil || field.IsIgnored {
+			continue
+		}
+
+		if value, ok := field.TagSettingsGet("PRELOAD"); ok {
+			if preload, err := strconv.ParseBool(value); err == nil && !preload {
+				continue
+			}
+		}
+
+		fieldType := elemTypeOf(field.Struct.Type)
+		var isCycle bool
+		for _, typ := range path {
+			if typ == fieldType {
+				isCycle = true
+				break
+			}
+		}
+		if isCycle {
+			continue
+		}
+
+		schema := field.Name
+		if prefix != "" {
+			schema = prefix + "." + field.Name
+		}
+		results = append(results, searchPreload{schema: schema, conditions: conditions})
+
+		fieldPath := append(append([]reflect.Type{}, path...), fieldType)
+		results = append(results, scope.associationPreloads(schema, fieldType, fieldPath, depth-1, nil)...)
+	}
+	return
+}
+
 func autoPreload(scope *Scope) {
 	for _, field := range scope.Fields() {
 		if field.Relationship == nil {
@@ -122,6 +255,8 @@ func (scope *Scope) generatePreloadDBWithConditions(conditions []interface{}) (*
 	for _, condition := range conditions {
 		if scopes, ok := condition.(func(*DB) *DB); ok {
 			preloadDB = scopes(preloadDB)
+		} else if _, ok := condition.(LimitPerRecord); ok {
+			continue
 		} else {
 			preloadConditions = append(preloadConditions, condition)
 		}
@@ -130,6 +265,105 @@ func (scope *Scope) generatePreloadDBWithConditions(conditions []interface{}) (*
 	return preloadDB, preloadConditions
 }
 
+// limitPerRecordOf return the limit of associations for each record from preload conditions
+func limitPerRecordOf(conditions []interface{}) int {
+	for _, condition := range conditions {
+		if limit, ok := condition.(LimitPerRecord); ok {
+			return int(limit)
+		}
+	}
+	return 0
+}
+
+// limitPerRecordDB wrap the query with window function ROW_NUMBER() to find at most `limit` records for each value of partition columns,
+// the order of the query is used to number records, default order is `defaultOrder`, columns are selected from the wrapped query,
+// so gorm_row_number isn't scanned, all columns are selected if columns is blank, e.g. the query selects custom columns
+func limitPerRecordDB(db *DB, partitionColumns []string, defaultOrder string, limit int, columns []string) *DB {
+	var (
+		orders     []string
+		selectSQL  = "*"
+		selectArgs []interface{}
+	)
+
+	for _, order := range db.search.orders {
+		if str, ok := order.(string); ok {
+			orders = append(orders, str)
+		}
+	}
+	if len(orders) == 0 {
+		orders = append(orders, defaultOrder)
+	}
+
+	if query, ok := db.search.selects["query"].(string); ok && query != "" {
+		selectSQL = query
+		selectArgs, _ = db.search.selects["args"].([]interface{})
+	}
+
+	subQuery := db.Order("", true).Select(
+		fmt.Sprintf("%v, ROW_NUMBER() OVER (PARTITION BY %v ORDER BY %v) AS gorm_row_number", selectSQL, strings.Join(partitionColumns, ","), strings.Join(orders, ",")),
+		selectArgs...,
+	).QueryExpr()
+
+	// alias the sub query as the table, so conditions or orders with table name are still valid
+	quotedTableName := db.NewScope(db.Value).QuotedTableName()
+	outerSelectSQL := "*"
+	if len(columns) > 0 {
+		outerSelectSQL = strings.Join(columns, ",")
+	}
+	return db.New().Raw(fmt.Sprintf("SELECT %v FROM (?) %v WHERE gorm_row_number <= ?", outerSelectSQL, quotedTableName), subQuery, limit).Order("gorm_row_number")
+}
+
+// limitPerRecordColumns return quoted columns of the model with extra columns to select from the query wrapped by limitPerRecordDB
+func limitPerRecordColumns(model *Scope, extraColumns ...string) []string {
+	var columns []string
+	for _, field := range model.GetModelStruct().StructFields {
+		if field.IsNormal && !field.IsIgnored {
+			columns = append(columns, model.Quote(field.DBName))
+		}
+	}
+	for _, column := range extraColumns {
+		columns = append(columns, model.Quote(column))
+	}
+	return columns
+}
+
+// findWithLimitPerRecord find at most `limit` associations for each value of foreign keys
+func (scope *Scope) findWithLimitPerRecord(preloadDB *DB, preloadConditions []interface{}, relation *Relationship, primaryKeys [][]interface{}, limit int, results interface{}) error {
+	var (
+		resultsValue = indirect(reflect.ValueOf(results))
+		resultScope  = scope.New(results)
+	)
+
+	if len(preloadConditions) > 0 {
+		preloadDB = preloadDB.Where(preloadConditions[0], preloadConditions[1:]...)
+	}
+	preloadDB = preloadDB.Model(results)
+
+	if supportWindowFunction(scope.Dialect()) {
+		var partitionColumns []string
+		for _, dbName := range relation.ForeignDBNames {
+			partitionColumns = append(partitionColumns, resultScope.QuotedTableName()+"."+scope.Quote(dbName))
+		}
+		defaultOrder := resultScope.QuotedTableName() + "." + scope.Quote(resultScope.PrimaryKey())
+		var columns []string
+		if len(preloadDB.search.selects) == 0 {
+			columns = limitPerRecordColumns(resultScope)
+		}
+		return limitPerRecordDB(preloadDB, partitionColumns, defaultOrder, limit, columns).Find(results).Error
+	}
+
+	// query associations for each record if window functions are not supported
+	for _, primaryKey := range primaryKeys {
+		partialResults := reflect.New(resultsValue.Type())
+		query := fmt.Sprintf("%v IN (%v)", toQueryCondition(scope, relation.ForeignDBNames), toQueryMarks([][]interface{}{primaryKey}))
+		if err := preloadDB.Where(query, primaryKey...).Limit(limit).Find(partialResults.Interface()).Error; err != nil {
+			return err
+		}
+		resultsValue.Set(reflect.AppendSlice(resultsValue, partialResults.Elem()))
+	}
+	return nil
+}
+
 // handleHasOnePreload used to preload has one associations
 func (scope *Scope) handleHasOnePreload(field *Field, conditions []interface{}) {
 	relation := field.Relationship
@@ -148,7 +382,7 @@ func (scope *Scope) handleHasOnePreload(field *Field, conditions []interface{})
 	values := toQueryValues(primaryKeys)
 	if relation.PolymorphicType != "" {
 		query += fmt.Sprintf(" AND %v = ?", scope.Quote(relation.PolymorphicDBName))
-		values = append(values, relation.PolymorphicValue)
+		values = append(values, relation.polymorphicValue())
 	}
 
 	results := makeSlice(field.Struct.Type)
@@ -171,7 +405,7 @@ func (scope *Scope) handleHasOnePreload(field *Field, conditions []interface{})
 			indirectValue := indirect(indirectScopeValue.Index(j))
 			valueString := toString(getValueFromFields(indirectValue, relation.AssociationForeignFieldNames))
 			if result, found := foreignValuesToResults[valueString]; found {
-				indirectValue.FieldByName(field.Name).Set(result)
+				fieldByPath(indirectValue, field.path()).Set(result)
 			}
 		}
 	} else {
@@ -200,11 +434,15 @@ func (scope *Scope) handleHasManyPreload(field *Field, conditions []interface{})
 	values := toQueryValues(primaryKeys)
 	if relation.PolymorphicType != "" {
 		query += fmt.Sprintf(" AND %v = ?", scope.Quote(relation.PolymorphicDBName))
-		values = append(values, relation.PolymorphicValue)
+		values = append(values, relation.polymorphicValue())
 	}
 
 	results := makeSlice(field.Struct.Type)
-	scope.Err(preloadDB.Where(query, values...).Find(results, preloadConditions...).Error)
+	if limit := limitPerRecordOf(conditions); limit > 0 {
+		scope.Err(scope.findWithLimitPerRecord(preloadDB.Where(query, values...), preloadConditions, relation, primaryKeys, limit, results))
+	} else {
+		scope.Err(preloadDB.Where(query, values...).Find(results, preloadConditions...).Error)
+	}
 
 	// assign find results
 	var (
@@ -223,7 +461,7 @@ func (scope *Scope) handleHasManyPreload(field *Field, conditions []interface{})
 		for j := 0; j < indirectScopeValue.Len(); j++ {
 			object := indirect(indirectScopeValue.Index(j))
 			objectRealValue := getValueFromFields(object, relation.AssociationForeignFieldNames)
-			f := object.FieldByName(field.Name)
+			f := fieldByPath(object, field.path())
 			if results, ok := preloadMap[toString(objectRealValue)]; ok {
 				f.Set(reflect.Append(f, results...))
 			} else {
@@ -243,7 +481,8 @@ func (scope *Scope) handleBelongsToPreload(field *Field, conditions []interface{
 	preloadDB, preloadConditions := scope.generatePreloadDBWithConditions(conditions)
 
 	// get relations's primary keys
-	primaryKeys := scope.getColumnAsArray(relation.ForeignFieldNames, scope.Value)
+	// polymorphic owners are preloaded only for records of its type
+	primaryKeys := scope.getColumnAsArray(relation.ForeignFieldNames, relation.polymorphicSources(scope.Value)...)
 	if len(primaryKeys) == 0 {
 		return
 	}
@@ -262,6 +501,9 @@ func (scope *Scope) handleBelongsToPreload(field *Field, conditions []interface{
 	if indirectScopeValue.Kind() == reflect.Slice {
 		for j := 0; j < indirectScopeValue.Len(); j++ {
 			object := indirect(indirectScopeValue.Index(j))
+			if !relation.isPolymorphicOwner(object) {
+				continue
+			}
 			valueString := toString(getValueFromFields(object, relation.ForeignFieldNames))
 			foreignFieldToObjects[valueString] = append(foreignFieldToObjects[valueString], &object)
 		}
@@ -273,7 +515,7 @@ func (scope *Scope) handleBelongsToPreload(field *Field, conditions []interface{
 			valueString := toString(getValueFromFields(result, relation.AssociationForeignFieldNames))
 			if objects, found := foreignFieldToObjects[valueString]; found {
 				for _, object := range objects {
-					object.FieldByName(field.Name).Set(result)
+					fieldByPath(*object, field.path()).Set(result)
 				}
 			}
 		} else {
@@ -311,61 +553,95 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 	newScope := scope.New(reflect.New(fieldType).Interface())
 	preloadDB = preloadDB.Table(newScope.TableName()).Model(newScope.Value)
 
-	if len(preloadDB.search.selects) == 0 {
-		preloadDB = preloadDB.Select("*")
+	// select columns of the join table by names of source keys only, so columns of the join table with the same names won't be selected
+	customSelect := len(preloadDB.search.selects) > 0
+	if !customSelect {
+		var (
+			quotedJoinTableName = scope.Quote(joinTableHandler.Table(scope.db))
+			selects             = []string{newScope.QuotedTableName() + ".*"}
+		)
+		for _, sourceKey := range sourceKeys {
+			selects = append(selects, quotedJoinTableName+"."+scope.Quote(sourceKey))
+		}
+		preloadDB = preloadDB.Select(strings.Join(selects, ","))
 	}
 
-	preloadDB = joinTableHandler.JoinWith(joinTableHandler, preloadDB, scope.Value)
-
 	// preload inline conditions
 	if len(preloadConditions) > 0 {
 		preloadDB = preloadDB.Where(preloadConditions[0], preloadConditions[1:]...)
 	}
 
-	rows, err := preloadDB.Rows()
+	scanRows := func(preloadDB *DB) {
+		rows, err := preloadDB.Rows()
 
-	if scope.Err(err) != nil {
-		return
-	}
-	defer rows.Close()
-
-	columns, _ := rows.Columns()
-	for rows.Next() {
-		var (
-			elem   = reflect.New(fieldType).Elem()
-			fields = scope.New(elem.Addr().Interface()).Fields()
-		)
-
-		// register foreign keys in join tables
-		var joinTableFields []*Field
-		for _, sourceKey := range sourceKeys {
-			joinTableFields = append(joinTableFields, &Field{StructField: &StructField{DBName: sourceKey, IsNormal: true}, Field: reflect.New(foreignKeyType).Elem()})
+		if scope.Err(err) != nil {
+			return
 		}
+		defer rows.Close()
+
+		columns, _ := rows.Columns()
+		for rows.Next() {
+			var (
+				elem   = reflect.New(fieldType).Elem()
+				fields = scope.New(elem.Addr().Interface()).Fields()
+			)
+
+			// register foreign keys in join tables
+			var joinTableFields []*Field
+			for _, sourceKey := range sourceKeys {
+				joinTableFields = append(joinTableFields, &Field{StructField: &StructField{DBName: sourceKey, IsNormal: true}, Field: reflect.New(foreignKeyType).Elem()})
+			}
 
-		scope.scan(rows, columns, append(fields, joinTableFields...))
+			scope.scan(rows, columns, append(fields, joinTableFields...))
 
-		scope.New(elem.Addr().Interface()).
-			InstanceSet("gorm:skip_query_callback", true).
-			callCallbacks(scope.db.parent.callbacks.queries)
+			scope.New(elem.Addr().Interface()).
+				InstanceSet("gorm:skip_query_callback", true).
+				callCallbacks(scope.db.currentCallbacks().queries)
 
-		var foreignKeys = make([]interface{}, len(sourceKeys))
-		// generate hashed forkey keys in join table
-		for idx, joinTableField := range joinTableFields {
-			if !joinTableField.Field.IsNil() {
-				foreignKeys[idx] = joinTableField.Field.Elem().Interface()
+			var foreignKeys = make([]interface{}, len(sourceKeys))
+			// generate hashed forkey keys in join table
+			for idx, joinTableField := range joinTableFields {
+				if !joinTableField.Field.IsNil() {
+					foreignKeys[idx] = joinTableField.Field.Elem().Interface()
+				}
+			}
+			hashedSourceKeys := toString(foreignKeys)
+
+			if isPtr {
+				linkHash[hashedSourceKeys] = append(linkHash[hashedSourceKeys], elem.Addr())
+			} else {
+				linkHash[hashedSourceKeys] = append(linkHash[hashedSourceKeys], elem)
 			}
 		}
-		hashedSourceKeys := toString(foreignKeys)
 
-		if isPtr {
-			linkHash[hashedSourceKeys] = append(linkHash[hashedSourceKeys], elem.Addr())
-		} else {
-			linkHash[hashedSourceKeys] = append(linkHash[hashedSourceKeys], elem)
+		if err := rows.Err(); err != nil {
+			scope.Err(err)
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		scope.Err(err)
+	if limit := limitPerRecordOf(conditions); limit > 0 && scope.IndirectValue().Kind() == reflect.Slice {
+		if supportWindowFunction(scope.Dialect()) {
+			var partitionColumns []string
+			for _, sourceKey := range sourceKeys {
+				partitionColumns = append(partitionColumns, scope.Quote(joinTableHandler.Table(scope.db))+"."+scope.Quote(sourceKey))
+			}
+			var columns []string
+			if !customSelect {
+				columns = limitPerRecordColumns(newScope, sourceKeys...)
+			}
+			defaultOrder := newScope.QuotedTableName() + "." + scope.Quote(newScope.PrimaryKey())
+			scanRows(limitPerRecordDB(joinTableHandler.JoinWith(joinTableHandler, preloadDB, scope.Value), partitionColumns, defaultOrder, limit, columns))
+		} else {
+			// query associations for each record if window functions are not supported
+			indirectScopeValue := scope.IndirectValue()
+			for j := 0; j < indirectScopeValue.Len(); j++ {
+				scanRows(joinTableHandler.JoinWith(joinTableHandler, preloadDB, indirect(indirectScopeValue.Index(j)).Interface()).Limit(limit))
+			}
+		}
+	} else if limit > 0 {
+		scanRows(joinTableHandler.JoinWith(joinTableHandler, preloadDB, scope.Value).Limit(limit))
+	} else {
+		scanRows(joinTableHandler.JoinWith(joinTableHandler, preloadDB, scope.Value))
 	}
 
 	// assign find results
@@ -377,7 +653,7 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 
 	for _, dbName := range relation.ForeignFieldNames {
 		if field, ok := scope.FieldByName(dbName); ok {
-			foreignFieldNames = append(foreignFieldNames, field.Name)
+			foreignFieldNames = append(foreignFieldNames, field.path())
 		}
 	}
 
@@ -385,11 +661,11 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 		for j := 0; j < indirectScopeValue.Len(); j++ {
 			object := indirect(indirectScopeValue.Index(j))
 			key := toString(getValueFromFields(object, foreignFieldNames))
-			fieldsSourceMap[key] = append(fieldsSourceMap[key], object.FieldByName(field.Name))
+			fieldsSourceMap[key] = append(fieldsSourceMap[key], fieldByPath(object, field.path()))
 		}
 	} else if indirectScopeValue.IsValid() {
 		key := toString(getValueFromFields(indirectScopeValue, foreignFieldNames))
-		fieldsSourceMap[key] = append(fieldsSourceMap[key], indirectScopeValue.FieldByName(field.Name))
+		fieldsSourceMap[key] = append(fieldsSourceMap[key], fieldByPath(indirectScopeValue, field.path()))
 	}
 
 	for source, fields := range fieldsSourceMap {
@@ -408,3 +684,170 @@ func (scope *Scope) handleManyToManyPreload(field *Field, conditions []interface
 		}
 	}
 }
+
+// joinPreload has one or belongs to association loaded with LEFT JOIN, its columns are selected as `<field name>__<column>`
+type joinPreload struct {
+	field        *Field
+	structFields []*StructField
+}
+
+// joinPreloadField return the association field if the join query is name of an association
+func (scope *Scope) joinPreloadField(query interface{}) *Field {
+	name, ok := query.(string)
+	if !ok || name == "" || strings.ContainsAny(name, " ()") {
+		return nil
+	}
+
+	if field, ok := scope.FieldByName(name); ok && field.Name == name && field.Relationship != nil && field.Relationship.Kind != "has_many" {
+		return field
+	}
+	return nil
+}
+
+// joinPreloads return associations should be loaded with LEFT JOIN
+func (scope *Scope) joinPreloads() (joinPreloads []*joinPreload) {
+	for _, clause := range scope.Search.joinConditions {
+		if field := scope.joinPreloadField(clause["query"]); field != nil && field.Relationship.Kind != "many_to_many" {
+			preload := &joinPreload{field: field}
+			for _, structField := range scope.New(reflect.New(indirectType(field.Struct.Type)).Interface()).GetModelStruct().StructFields {
+				aliasField := structField.clone()
+				aliasField.DBName = field.Name + "__" + structField.DBName
+				preload.structFields = append(preload.structFields, aliasField)
+			}
+			joinPreloads = append(joinPreloads, preload)
+		}
+	}
+	return
+}
+
+// joinPreloadCondition generate join condition for the has one or belongs to association, the joined table is aliased as the field name,
+// extra condition could be added with args, e.g. `db.Joins("Profile", "profiles.verified = ?", true)`
+func (scope *Scope) joinPreloadCondition(field *Field, args []interface{}) map[string]interface{} {
+	var (
+		relation   = field.Relationship
+		joinScope  = scope.New(reflect.New(elemTypeOf(field.Struct.Type)).Interface())
+		alias      = scope.Quote(field.Name)
+		tableName  = scope.QuotedTableName()
+		conditions []string
+		values     []interface{}
+	)
+
+	switch relation.Kind {
+	case "belongs_to":
+		for idx, foreignKey := range relation.ForeignDBNames {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(relation.AssociationForeignDBNames[idx]), tableName, scope.Quote(foreignKey)))
+		}
+	case "has_one":
+		for idx, foreignKey := range relation.ForeignDBNames {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(foreignKey), tableName, scope.Quote(relation.AssociationForeignDBNames[idx])))
+		}
+	}
+
+	if relation.PolymorphicType != "" {
+		if relation.Kind == "belongs_to" {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = ?", tableName, scope.Quote(relation.PolymorphicDBName)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = ?", alias, scope.Quote(relation.PolymorphicDBName)))
+		}
+		values = append(values, relation.polymorphicValue())
+	}
+
+	conditions, values = scope.joinExtraConditions(joinScope, alias, conditions, values, args)
+	return map[string]interface{}{
+		"query": fmt.Sprintf("LEFT JOIN %v %v ON %v", joinScope.QuotedTableName(), alias, strings.Join(conditions, " AND ")),
+		"args":  values,
+	}
+}
+
+// manyToManyJoinCondition generate `EXISTS` condition filtering records having the many2many association matched with conditions of args,
+// the associated table is aliased as the field name, so conditions could reference it and the join table, e.g.
+//    db.Joins("Groups", `user_groups.role = ? AND "Groups".name = ?`, "admin", "dev")
+//    // EXISTS (SELECT 1 FROM "user_groups" INNER JOIN "groups" "Groups" ON "Groups"."id" = "user_groups"."group_id" WHERE "user_groups"."user_id" = "users"."id" AND (user_groups.role = 'admin' AND "Groups".name = 'dev'))
+func (scope *Scope) manyToManyJoinCondition(field *Field, args []interface{}) string {
+	var (
+		joinTableHandler = field.Relationship.JoinTableHandler
+		joinScope        = scope.New(reflect.New(elemTypeOf(field.Struct.Type)).Interface())
+		joinTableName    = scope.Quote(joinTableHandler.Table(scope.db))
+		alias            = scope.Quote(field.Name)
+		tableName        = scope.QuotedTableName()
+		joinConditions   []string
+		conditions       []string
+		values           []interface{}
+	)
+
+	for _, foreignKey := range joinTableHandler.DestinationForeignKeys() {
+		joinConditions = append(joinConditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(foreignKey.AssociationDBName), joinTableName, scope.Quote(foreignKey.DBName)))
+	}
+	for _, foreignKey := range joinTableHandler.SourceForeignKeys() {
+		conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", joinTableName, scope.Quote(foreignKey.DBName), tableName, scope.Quote(foreignKey.AssociationDBName)))
+	}
+
+	conditions, values = scope.joinExtraConditions(joinScope, alias, conditions, values, args)
+	return scope.buildCondition(map[string]interface{}{
+		"query": fmt.Sprintf("EXISTS (SELECT 1 FROM %v INNER JOIN %v %v ON %v WHERE %v)", joinTableName, joinScope.QuotedTableName(), alias,
+			strings.Join(joinConditions, " AND "), strings.Join(conditions, " AND ")),
+		"args": values,
+	}, true)
+}
+
+// joinExtraConditions append conditions excluding soft deleted associations and the condition passed with args
+func (scope *Scope) joinExtraConditions(joinScope *Scope, alias string, conditions []string, values []interface{}, args []interface{}) ([]string, []interface{}) {
+	if deletedAtField, ok := joinScope.FieldByName("DeletedAt"); ok && !scope.Search.Unscoped {
+		conditions = append(conditions, fmt.Sprintf("%v.%v IS NULL", alias, scope.Quote(deletedAtField.DBName)))
+	}
+
+	if len(args) > 0 {
+		if condition, ok := args[0].(string); ok && condition != "" {
+			conditions = append(conditions, "("+condition+")")
+			values = append(values, args[1:]...)
+		}
+	}
+	return conditions, values
+}
+
+// joinPreloadSelectSQL select columns of associations loaded with LEFT JOIN
+func (scope *Scope) joinPreloadSelectSQL() string {
+	var columns []string
+	for _, preload := range scope.joinPreloads() {
+		alias := scope.Quote(preload.field.Name)
+		for idx, structField := range scope.New(reflect.New(indirectType(preload.field.Struct.Type)).Interface()).GetModelStruct().StructFields {
+			if structField.IsNormal && !structField.IsIgnored {
+				columns = append(columns, fmt.Sprintf("%v.%v AS %v", alias, scope.Quote(structField.DBName), scope.Quote(preload.structFields[idx].DBName)))
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		return ""
+	}
+	return "," + strings.Join(columns, ",")
+}
+
+// scanWithJoinPreloads scan a row into elem and its associations loaded with LEFT JOIN
+func (scope *Scope) scanWithJoinPreloads(rows *sql.Rows, columns []string, elem reflect.Value, joinPreloads []*joinPreload) {
+	var (
+		elemScope         = scope.New(elem.Addr().Interface())
+		fields            = elemScope.Fields()
+		associationValues = make([]reflect.Value, len(joinPreloads))
+	)
+
+	for idx, preload := range joinPreloads {
+		associationValues[idx] = reflect.New(indirectType(preload.field.Struct.Type))
+		for fieldIdx, field := range scope.New(associationValues[idx].Interface()).Fields() {
+			fields = append(fields, &Field{StructField: preload.structFields[fieldIdx], Field: field.Field})
+		}
+	}
+
+	scope.scan(rows, columns, fields)
+
+	// set associations if found
+	for idx, preload := range joinPreloads {
+		if scope.New(associationValues[idx].Interface()).PrimaryKeyZero() {
+			continue
+		}
+
+		if field, ok := elemScope.FieldByName(preload.field.Name); ok {
+			scope.Err(field.Set(associationValues[idx]))
+		}
+	}
+}
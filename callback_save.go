@@ -90,6 +90,10 @@ func saveBeforeAssociationsCallback(scope *Scope) {
 						}
 					}
 				}
+
+				if relationship.PolymorphicType != "" {
+					scope.Err(scope.SetColumn(relationship.PolymorphicType, relationship.polymorphicValue()))
+				}
 			}
 		}
 	}
@@ -120,7 +124,7 @@ func saveAfterAssociationsCallback(scope *Scope) {
 						}
 
 						if relationship.PolymorphicType != "" {
-							scope.Err(newScope.SetColumn(relationship.PolymorphicType, relationship.PolymorphicValue))
+							scope.Err(newScope.SetColumn(relationship.PolymorphicType, relationship.polymorphicValue()))
 						}
 					}
 
@@ -153,7 +157,7 @@ func saveAfterAssociationsCallback(scope *Scope) {
 					}
 
 					if relationship.PolymorphicType != "" {
-						scope.Err(newScope.SetColumn(relationship.PolymorphicType, relationship.PolymorphicValue))
+						scope.Err(newScope.SetColumn(relationship.PolymorphicType, relationship.polymorphicValue()))
 					}
 				}
 
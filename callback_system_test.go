@@ -110,3 +110,23 @@ func TestRemoveCallback(t *testing.T) {
 		t.Errorf("remove callback")
 	}
 }
+
+func TestCloneCallback(t *testing.T) {
+	var callback = &Callback{logger: defaultLogger}
+
+	callback.Create().Register("create", create)
+	callback.Create().After("create").Register("after_create1", afterCreate1)
+
+	clone := callback.clone(defaultLogger)
+	clone.Create().Before("create").Register("before_create1", beforeCreate1)
+
+	for idx, processor := range clone.processors[:len(callback.processors)] {
+		if processor == callback.processors[idx] || processor.parent != clone {
+			t.Errorf("processors should be copied when cloning callbacks")
+		}
+	}
+
+	if !equalFuncs(callback.creates, []string{"create", "afterCreate1"}) || !equalFuncs(clone.creates, []string{"beforeCreate1", "create", "afterCreate1"}) {
+		t.Errorf("registering callbacks of the clone should not change the original")
+	}
+}
@@ -1,6 +1,7 @@
 package gorm
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"sort"
@@ -14,7 +15,9 @@ func init() {
 	DefaultCallback.Update().Register("gorm:before_update", beforeUpdateCallback)
 	DefaultCallback.Update().Register("gorm:save_before_associations", saveBeforeAssociationsCallback)
 	DefaultCallback.Update().Register("gorm:update_time_stamp", updateTimeStampForUpdateCallback)
+	DefaultCallback.Update().Register("gorm:update_actor", updateActorForUpdateCallback)
 	DefaultCallback.Update().Register("gorm:update", updateCallback)
+	DefaultCallback.Update().Register("gorm:require_rows_affected", requireRowsAffectedCallback)
 	DefaultCallback.Update().Register("gorm:save_after_associations", saveAfterAssociationsCallback)
 	DefaultCallback.Update().Register("gorm:after_update", afterUpdateCallback)
 	DefaultCallback.Update().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
@@ -37,6 +40,9 @@ func beforeUpdateCallback(scope *Scope) {
 		scope.Err(errors.New("missing WHERE clause while updating"))
 		return
 	}
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if _, ok := scope.Get("gorm:update_column"); !ok {
 		if !scope.HasError() {
 			scope.CallMethod("BeforeSave")
@@ -50,12 +56,34 @@ func beforeUpdateCallback(scope *Scope) {
 // updateTimeStampForUpdateCallback will set `UpdatedAt` when updating
 func updateTimeStampForUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
-		scope.SetColumn("UpdatedAt", scope.db.nowFunc())
+		now := scope.db.nowFunc()
+		for _, field := range scope.Fields() {
+			if unit, ok := autoTimeUnit(field.StructField, "AUTOUPDATETIME", "UpdatedAt"); ok {
+				scope.SetColumn(field, autoTimeValue(field, unit, now))
+			}
+		}
+	}
+}
+
+// updateActorForUpdateCallback will set `UpdatedBy` with the actor of context set by WithActor when updating
+func updateActorForUpdateCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:update_column"); !ok {
+		if field, ok := scope.FieldByName("UpdatedBy"); ok {
+			if actor, ok := actorOf(scope, field); ok {
+				scope.Err(scope.SetColumn(field, actor))
+			}
+		}
 	}
 }
 
 // updateCallback the callback used to update data to database
 func updateCallback(scope *Scope) {
+	// upsert would ignore conditions of the update, e.g. tenant conditions, update the record then create it for them
+	if _, ok := scope.InstanceGet("gorm:save_upsert"); ok && !scope.HasError() && !scope.hasSearchConditions() {
+		saveUpsert(scope)
+		return
+	}
+
 	if !scope.HasError() {
 		var sqls []string
 
@@ -70,14 +98,14 @@ func updateCallback(scope *Scope) {
 
 			for _, column := range columns {
 				value := updateMap[column]
-				sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(column), scope.AddToVars(value)))
+				sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(column), scope.AddToVars(sensitiveVar(scope, column, value))))
 			}
 		} else {
 			for _, field := range scope.Fields() {
 				if scope.changeableField(field) {
-					if !field.IsPrimaryKey && field.IsNormal && (field.Name != "CreatedAt" || !field.IsBlank) {
+					if !field.IsPrimaryKey && field.IsNormal && (!field.IsBlank || !isAutoCreateTime(field.StructField)) {
 						if !field.IsForeignKey || !field.IsBlank || !field.HasDefaultValue {
-							sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.AddToVars(field.Field.Interface())))
+							sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.AddToVars(sensitiveVar(scope, field.DBName, field.Field.Interface()))))
 						}
 					} else if relationship := field.Relationship; relationship != nil && relationship.Kind == "belongs_to" {
 						for _, foreignKey := range relationship.ForeignDBNames {
@@ -103,13 +131,108 @@ func updateCallback(scope *Scope) {
 				strings.Join(sqls, ", "),
 				addExtraSpaceIfExist(scope.CombinedConditionSql()),
 				addExtraSpaceIfExist(extraOption),
-			)).Exec()
+			))
+
+			if field, ok := scope.InstanceGet("gorm:update_returning"); ok && supportReturning(scope.Dialect()) {
+				updateReturning(scope, field.(*Field))
+			} else {
+				scope.Exec()
+			}
+		}
+	}
+}
+
+// saveUpsert insert the record or update it on primary key conflicts in one statement when saving,
+// blank CreatedAt is inserted with current time and won't be updated
+func saveUpsert(scope *Scope) {
+	defer scope.trace(NowFunc())
+
+	var (
+		columns, placeholders, primaryKeys, updateColumns []string
+		now                                               = scope.db.nowFunc()
+	)
+
+	addColumn := func(field *Field, value interface{}) {
+		column := scope.Quote(field.DBName)
+		columns = append(columns, column)
+		placeholders = append(placeholders, scope.AddToVars(sensitiveVar(scope, field.DBName, value)))
+		if field.IsPrimaryKey {
+			primaryKeys = append(primaryKeys, column)
+		} else if !isAutoCreateTime(field.StructField) {
+			updateColumns = append(updateColumns, column)
+		}
+	}
+
+	for _, field := range scope.Fields() {
+		if !scope.changeableField(field) && !field.IsPrimaryKey {
+			continue
 		}
+
+		if field.IsNormal && !field.IsIgnored {
+			if unit, ok := autoTimeUnit(field.StructField, "AUTOCREATETIME", "CreatedAt"); ok && field.IsBlank {
+				addColumn(field, autoTimeValue(field, unit, now))
+			} else if field.IsPrimaryKey || !field.IsForeignKey || !field.IsBlank || !field.HasDefaultValue {
+				addColumn(field, field.Field.Interface())
+			}
+		} else if relationship := field.Relationship; relationship != nil && relationship.Kind == "belongs_to" {
+			for _, foreignKey := range relationship.ForeignDBNames {
+				if foreignField, ok := scope.FieldByName(foreignKey); ok && !scope.changeableField(foreignField) {
+					addColumn(foreignField, foreignField.Field.Interface())
+				}
+			}
+		}
+	}
+
+	scope.Raw(fmt.Sprintf(
+		"INSERT INTO %v (%v) VALUES (%v) %v",
+		scope.QuotedTableName(),
+		strings.Join(columns, ","),
+		strings.Join(placeholders, ","),
+		scope.Dialect().(upsertSupporter).UpsertClause(primaryKeys, updateColumns),
+	))
+
+	if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+		// mysql returns 2 if the record is updated
+		if count, err := result.RowsAffected(); scope.Err(err) == nil && count > 0 {
+			scope.db.RowsAffected = 1
+		}
+	}
+}
+
+// requireRowsAffectedCallback set ErrNoRowsAffected if the updating or deleting statement affected no rows with RequireRowsAffected
+func requireRowsAffectedCallback(scope *Scope) {
+	if required, ok := scope.Get("gorm:require_rows_affected"); !ok || required != true || scope.HasError() || scope.SQL == "" {
+		return
+	}
+
+	// Save creates the record if updating affected no rows
+	if _, ok := scope.InstanceGet("gorm:save_or_create"); ok {
+		return
+	}
+
+	if scope.db.RowsAffected == 0 {
+		scope.Err(ErrNoRowsAffected)
+	}
+}
+
+// updateReturning update the record and scan the updated value of the field with `RETURNING`
+func updateReturning(scope *Scope, field *Field) {
+	defer scope.trace(NowFunc())
+
+	scope.SQL += " RETURNING " + scope.Quote(field.DBName)
+	if err := scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...).Scan(field.Field.Addr().Interface()); err == sql.ErrNoRows {
+		scope.db.RowsAffected = 0
+	} else if scope.Err(err) == nil {
+		scope.db.RowsAffected = 1
+		scope.InstanceSet("gorm:update_returned", true)
 	}
 }
 
 // afterUpdateCallback will invoke `AfterUpdate`, `AfterSave` method after updating
 func afterUpdateCallback(scope *Scope) {
+	if _, ok := scope.Get("gorm:skip_hooks"); ok {
+		return
+	}
 	if _, ok := scope.Get("gorm:update_column"); !ok {
 		if !scope.HasError() {
 			scope.CallMethod("AfterUpdate")
@@ -1,8 +1,10 @@
 package gorm_test
 
 import (
+	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/lun-zhang/gorm"
@@ -112,6 +114,25 @@ func TestRunCallbacks(t *testing.T) {
 	}
 }
 
+func TestSkipHooks(t *testing.T) {
+	p := Product{Code: "Invalid", Price: 100}
+	if err := DB.SkipHooks().Save(&p).Error; err != nil {
+		t.Errorf("Hooks should be skipped when creating, but got %v", err)
+	}
+
+	p.Price = 200
+	DB.SkipHooks().Save(&p)
+	DB.SkipHooks().Model(&p).Update("price", 300)
+	DB.SkipHooks().Delete(&p)
+	if !reflect.DeepEqual(p.GetCallTimes(), []int64{0, 0, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("Hooks should be skipped, but got %v", p.GetCallTimes())
+	}
+
+	if !DB.Where("code = ?", "Invalid").First(&Product{}).RecordNotFound() {
+		t.Errorf("Record should be deleted without hooks")
+	}
+}
+
 func TestCallbacksWithErrors(t *testing.T) {
 	p := Product{Code: "Invalid", Price: 100}
 	if DB.Save(&p).Error == nil {
@@ -247,3 +268,239 @@ func TestUseDefaultCallback(t *testing.T) {
 		t.Errorf("`%s` should be `2, true` but `%v, %v`", scopeValueName, v, ok)
 	}
 }
+
+type TxHookUser struct {
+	Id   int64
+	Name string
+}
+
+type TxHookLog struct {
+	Id     int64
+	Action string
+}
+
+var (
+	_ gorm.BeforeCreateInterface = &TxHookUser{}
+	_ gorm.AfterCreateInterface  = &TxHookUser{}
+	_ gorm.AfterFindInterface    = &TxHookUser{}
+)
+
+func (u *TxHookUser) BeforeCreate(tx *gorm.DB) error {
+	return tx.Create(&TxHookLog{Action: "create " + u.Name}).Error
+}
+
+func (u *TxHookUser) AfterCreate(tx *gorm.DB) error {
+	if u.Name == "after_create_error" {
+		return errors.New("can't create")
+	}
+	return nil
+}
+
+func (u *TxHookUser) AfterFind(tx *gorm.DB) error {
+	if u.Name == "after_find_error" {
+		return errors.New("can't find")
+	}
+	return nil
+}
+
+func TestHooksWithTransaction(t *testing.T) {
+	DB.DropTableIfExists(&TxHookUser{}, &TxHookLog{})
+	DB.AutoMigrate(&TxHookUser{}, &TxHookLog{})
+
+	if err := DB.Create(&TxHookUser{Name: "hook"}).Error; err != nil {
+		t.Fatalf("No error should happen when create, but got %v", err)
+	}
+
+	if DB.Where("action = ?", "create hook").First(&TxHookLog{}).RecordNotFound() {
+		t.Errorf("Records created in hooks should be saved")
+	}
+
+	if err := DB.Create(&TxHookUser{Name: "after_create_error"}).Error; err == nil {
+		t.Errorf("Should get error from AfterCreate hook")
+	}
+
+	if !DB.Where("name = ?", "after_create_error").First(&TxHookUser{}).RecordNotFound() {
+		t.Errorf("Record should be rolled back because of an error in AfterCreate hook")
+	}
+
+	if !DB.Where("action = ?", "create after_create_error").First(&TxHookLog{}).RecordNotFound() {
+		t.Errorf("Records created in hooks should be rolled back in the same transaction")
+	}
+
+	DB.Create(&TxHookUser{Name: "after_find_error"})
+	var users []TxHookUser
+	if err := DB.Find(&users).Error; err == nil {
+		t.Errorf("Should get error from AfterFind hook")
+	}
+}
+
+type callbackContextKey struct{}
+
+func TestCallbackContext(t *testing.T) {
+	db := DB.New()
+	db.Callback().Query().Register("gorm:test_callback_context", func(scope *gorm.Scope) {
+		scope.Set("gorm:test_callback_context_value", scope.Context().Value(callbackContextKey{}))
+	})
+	defer db.Callback().Query().Remove("gorm:test_callback_context")
+
+	if db.Context() == nil || db.NewScope(nil).Context() == nil {
+		t.Errorf("Context should be context.Background() if it is not set")
+	}
+
+	ctxDB := db.WithContext(context.WithValue(context.Background(), callbackContextKey{}, "traced"))
+	if ctxDB.Context().Value(callbackContextKey{}) != "traced" {
+		t.Errorf("Context should return the context set with WithContext")
+	}
+
+	scope := ctxDB.Find(&[]User{}).NewScope(nil)
+	if v, ok := scope.Get("gorm:test_callback_context_value"); !ok || v != "traced" {
+		t.Errorf("Callbacks should access the context with scope.Context(), but got %v, %v", v, ok)
+	}
+}
+
+func TestCallbackListWrapAndValidate(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Callback().Validate(); err != nil {
+		t.Errorf("Default callbacks should be valid, but got %v", err)
+	}
+
+	names := db.Callback().Create().List()
+	if len(names) == 0 || names[0] != "gorm:begin_transaction" || names[len(names)-1] != "gorm:commit_or_rollback_transaction" {
+		t.Errorf("List should return callbacks in execution order, but got %v", names)
+	}
+
+	db.Callback().Create().After("gorm:create").Register("gorm:test_list_after", func(*gorm.Scope) {})
+	db.Callback().Create().Before("gorm:test_list_after").Register("gorm:test_list_before", func(*gorm.Scope) {})
+	if names := strings.Join(db.Callback().Create().List(), ","); !strings.Contains(names, "gorm:create,gorm:test_list_before,gorm:test_list_after") {
+		t.Errorf("List should return callbacks in execution order, but got %v", names)
+	}
+
+	var calls []string
+	db.Callback().Query().Wrap("gorm:query", func(handler func(*gorm.Scope)) func(*gorm.Scope) {
+		return func(scope *gorm.Scope) {
+			calls = append(calls, "before")
+			handler(scope)
+			calls = append(calls, "after")
+		}
+	})
+
+	var users []User
+	if err := db.Find(&users).Error; err != nil || strings.Join(calls, ",") != "before,after" {
+		t.Errorf("Wrapped callback should call the registered callback, but got %v, %v", calls, err)
+	}
+
+	if err := db.Callback().Validate(); err != nil {
+		t.Errorf("Callbacks should be valid, but got %v", err)
+	}
+
+	db.Callback().Update().Before("gorm:test_missing").Register("gorm:test_missing_anchor", func(*gorm.Scope) {})
+	if err := db.Callback().Validate(); err == nil || !strings.Contains(err.Error(), "gorm:test_missing") {
+		t.Errorf("Should get error for missing callback, but got %v", err)
+	}
+	db.Callback().Update().Remove("gorm:test_missing_anchor")
+
+	db.Callback().Delete().After("gorm:delete").Register("gorm:test_cycle_a", func(*gorm.Scope) {})
+	db.Callback().Delete().After("gorm:test_cycle_a").Before("gorm:delete").Register("gorm:test_cycle_b", func(*gorm.Scope) {})
+	if err := db.Callback().Validate(); err == nil || !strings.Contains(err.Error(), "cyclic") {
+		t.Errorf("Should get error for cyclic orders, but got %v", err)
+	}
+}
+
+func TestWithCallbacks(t *testing.T) {
+	var calls int
+	DB.Callback().Create().Register("gorm:test_session_callback", func(*gorm.Scope) { calls++ })
+	defer DB.Callback().Create().Remove("gorm:test_session_callback")
+
+	jobDB := DB.WithCallbacks(func(callback *gorm.Callback) {
+		callback.Create().Remove("gorm:test_session_callback")
+	})
+
+	jobDB.Create(&User{Name: "session_callbacks"})
+	jobDB.New().Create(&User{Name: "session_callbacks"})
+	if calls != 0 {
+		t.Errorf("Callbacks removed with WithCallbacks should not be called, but called %v times", calls)
+	}
+
+	DB.Create(&User{Name: "session_callbacks"})
+	if calls != 1 {
+		t.Errorf("Callbacks of other dbs should not be affected by WithCallbacks, but called %v times", calls)
+	}
+
+	if names := strings.Join(DB.Callback().Create().List(), ","); !strings.Contains(names, "gorm:test_session_callback") {
+		t.Errorf("Global callbacks should not be changed by WithCallbacks, but got %v", names)
+	}
+}
+
+type InterfaceHookOrder struct {
+	Id    int64
+	Price int
+	Calls []string `gorm:"-"`
+}
+
+var (
+	_ gorm.BeforeSaveInterface   = &InterfaceHookOrder{}
+	_ gorm.BeforeCreateInterface = &InterfaceHookOrder{}
+	_ gorm.AfterCreateInterface  = &InterfaceHookOrder{}
+	_ gorm.BeforeUpdateInterface = &InterfaceHookOrder{}
+	_ gorm.AfterUpdateInterface  = &InterfaceHookOrder{}
+	_ gorm.AfterSaveInterface    = &InterfaceHookOrder{}
+	_ gorm.BeforeDeleteInterface = &InterfaceHookOrder{}
+	_ gorm.AfterDeleteInterface  = &InterfaceHookOrder{}
+	_ gorm.AfterFindInterface    = &InterfaceHookOrder{}
+)
+
+func (o *InterfaceHookOrder) call(name string, tx *gorm.DB) error {
+	o.Calls = append(o.Calls, name)
+	if o.Price < 0 && name == "AfterUpdate" {
+		return errors.New("negative price")
+	}
+	return tx.Error
+}
+
+func (o *InterfaceHookOrder) BeforeSave(tx *gorm.DB) error   { return o.call("BeforeSave", tx) }
+func (o *InterfaceHookOrder) BeforeCreate(tx *gorm.DB) error { return o.call("BeforeCreate", tx) }
+func (o *InterfaceHookOrder) AfterCreate(tx *gorm.DB) error  { return o.call("AfterCreate", tx) }
+func (o *InterfaceHookOrder) BeforeUpdate(tx *gorm.DB) error { return o.call("BeforeUpdate", tx) }
+func (o *InterfaceHookOrder) AfterUpdate(tx *gorm.DB) error  { return o.call("AfterUpdate", tx) }
+func (o *InterfaceHookOrder) AfterSave(tx *gorm.DB) error    { return o.call("AfterSave", tx) }
+func (o *InterfaceHookOrder) BeforeDelete(tx *gorm.DB) error { return o.call("BeforeDelete", tx) }
+func (o *InterfaceHookOrder) AfterDelete(tx *gorm.DB) error  { return o.call("AfterDelete", tx) }
+func (o *InterfaceHookOrder) AfterFind(tx *gorm.DB) error    { return o.call("AfterFind", tx) }
+
+func TestHookInterfaces(t *testing.T) {
+	DB.DropTableIfExists(&InterfaceHookOrder{})
+	DB.AutoMigrate(&InterfaceHookOrder{})
+
+	order := InterfaceHookOrder{Price: 10}
+	DB.Create(&order)
+	if calls := strings.Join(order.Calls, ","); calls != "BeforeSave,BeforeCreate,AfterCreate,AfterSave" {
+		t.Errorf("Hooks of creating should be called in order, but got %v", calls)
+	}
+
+	order.Calls = nil
+	DB.Model(&order).Update("price", 20)
+	if calls := strings.Join(order.Calls, ","); calls != "BeforeSave,BeforeUpdate,AfterUpdate,AfterSave" {
+		t.Errorf("Hooks of updating should be called in order, but got %v", calls)
+	}
+
+	order.Calls = nil
+	if err := DB.Model(&order).Update("price", -1).Error; err == nil || strings.Join(order.Calls, ",") != "BeforeSave,BeforeUpdate,AfterUpdate" {
+		t.Errorf("Error of AfterUpdate should abort the update, but got %v, %v", err, order.Calls)
+	}
+
+	var found InterfaceHookOrder
+	if DB.First(&found, order.Id); found.Price != 20 || strings.Join(found.Calls, ",") != "AfterFind" {
+		t.Errorf("Update should be rolled back and AfterFind should be called, but got %v, %v", found.Price, found.Calls)
+	}
+
+	order.Calls = nil
+	DB.Delete(&order)
+	if calls := strings.Join(order.Calls, ","); calls != "BeforeDelete,AfterDelete" {
+		t.Errorf("Hooks of deleting should be called in order, but got %v", calls)
+	}
+}
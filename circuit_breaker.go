@@ -0,0 +1,221 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker fails statements fast with ErrCircuitOpen when the failure rate of a database node is too high,
+// so requests won't wait for connecting to a down database, e.g:
+//    db.UseCircuitBreaker(&gorm.CircuitBreaker{FailureRate: 0.5, OpenTimeout: 10 * time.Second}, nil)
+//
+// After OpenTimeout, the circuit is half-open to let a statement try, other statements fail fast until it finishes,
+// the circuit will be closed if it succeeds, otherwise opened again
+type CircuitBreaker struct {
+	// FailureRate open the circuit when failure rate of statements in the window reaches it, defaults to 0.5
+	FailureRate float64
+	// MinRequests min statements in the window to calculate failure rate, defaults to 10
+	MinRequests int
+	// Window time window to count statements, defaults to 10 seconds
+	Window time.Duration
+	// OpenTimeout how long the circuit stays open before trying again, defaults to 30 seconds
+	OpenTimeout time.Duration
+	// IsFailure return true if the error means the node is unavailable, defaults to connection errors and timeouts
+	IsFailure func(err error) bool
+
+	mutex       sync.Mutex
+	state       string
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	trialAt     time.Time // when the trial statement of the half-open circuit started, zero if no statement is trying
+	trial       uint64    // token of the trial statement, only its result changes the state of the half-open circuit
+}
+
+// UseCircuitBreaker use circuit breakers for master and slave, nil means no circuit breaker for the node,
+// DBs cloned before won't use them, so set them right after opening the db
+func (s *DB) UseCircuitBreaker(master, slave *CircuitBreaker) *DB {
+	s.parent.db.masterBreaker, s.parent.db.slaveBreaker = master, slave
+	s.db.masterBreaker, s.db.slaveBreaker = master, slave
+	return s
+}
+
+// State return state of the circuit, closed, open or half-open
+func (breaker *CircuitBreaker) State() string {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.state == "" {
+		return circuitClosed
+	}
+	return breaker.state
+}
+
+// allow return ErrCircuitOpen if the circuit is open, the trial token is returned if the statement is the trial of the half-open circuit,
+// pass it to record the result
+func (breaker *CircuitBreaker) allow() (uint64, error) {
+	if breaker == nil {
+		return 0, nil
+	}
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	now := time.Now()
+	switch breaker.state {
+	case circuitOpen:
+		if now.Sub(breaker.openedAt) < breaker.openTimeout() {
+			return 0, ErrCircuitOpen
+		}
+		breaker.state = circuitHalfOpen
+	case circuitHalfOpen:
+		// let one statement try at a time, try another one if its result isn't recorded after OpenTimeout
+		if !breaker.trialAt.IsZero() && now.Sub(breaker.trialAt) < breaker.openTimeout() {
+			return 0, ErrCircuitOpen
+		}
+	default:
+		return 0, nil
+	}
+	breaker.trialAt = now
+	breaker.trial++
+	return breaker.trial, nil
+}
+
+// record record result of the statement, statements other than the trial don't change the state of the half-open circuit
+func (breaker *CircuitBreaker) record(trial uint64, err error) {
+	if breaker == nil || err == ErrCircuitOpen {
+		return
+	}
+
+	failed := err != nil && breaker.isFailure(err)
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	now := time.Now()
+	switch breaker.state {
+	case circuitOpen:
+		return
+	case circuitHalfOpen:
+		if trial == 0 || trial != breaker.trial {
+			return
+		}
+		breaker.trialAt = time.Time{}
+		if failed {
+			breaker.open(now)
+		} else {
+			breaker.state = circuitClosed
+			breaker.requests, breaker.failures, breaker.windowStart = 0, 0, now
+		}
+		return
+	}
+
+	if now.Sub(breaker.windowStart) > breaker.window() {
+		breaker.requests, breaker.failures, breaker.windowStart = 0, 0, now
+	}
+
+	breaker.requests++
+	if failed {
+		breaker.failures++
+	}
+
+	if breaker.requests >= breaker.minRequests() && float64(breaker.failures)/float64(breaker.requests) >= breaker.failureRate() {
+		breaker.open(now)
+	}
+}
+
+func (breaker *CircuitBreaker) open(now time.Time) {
+	breaker.state = circuitOpen
+	breaker.openedAt = now
+	breaker.requests, breaker.failures = 0, 0
+}
+
+func (breaker *CircuitBreaker) isFailure(err error) bool {
+	if breaker.IsFailure != nil {
+		return breaker.IsFailure(err)
+	}
+	return isConnectionError(err)
+}
+
+func (breaker *CircuitBreaker) failureRate() float64 {
+	if breaker.FailureRate <= 0 {
+		return 0.5
+	}
+	return breaker.FailureRate
+}
+
+func (breaker *CircuitBreaker) minRequests() int {
+	if breaker.MinRequests <= 0 {
+		return 10
+	}
+	return breaker.MinRequests
+}
+
+func (breaker *CircuitBreaker) window() time.Duration {
+	if breaker.Window <= 0 {
+		return 10 * time.Second
+	}
+	return breaker.Window
+}
+
+func (breaker *CircuitBreaker) openTimeout() time.Duration {
+	if breaker.OpenTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return breaker.OpenTimeout
+}
+
+// rowError return error of querying the row, it is returned by Err of *sql.Row since go 1.15,
+// with earlier versions errors are only returned when scanning the row, so it is always nil
+func rowError(row *sql.Row) error {
+	if row == nil {
+		return nil
+	}
+	if errRow, ok := interface{}(row).(interface{ Err() error }); ok {
+		return errRow.Err()
+	}
+	return nil
+}
+
+// isConnectionError return true if the error means database is unavailable rather than the statement is wrong
+func isConnectionError(err error) bool {
+	for _, target := range []error{driver.ErrBadConn, sql.ErrConnDone, ErrQueryTimeout, context.DeadlineExceeded} {
+		if errorIs(err, target) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	if errorAs(err, &netErr) {
+		return true
+	}
+
+	message := err.Error()
+	for _, str := range []string{"connection refused", "broken pipe", "connection reset", "bad connection", "i/o timeout", "database is closed"} {
+		if strings.Contains(message, str) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryNode return connection and circuit breaker of the node to query
+func (db ctxDB) queryNode() (SQLCommon, *CircuitBreaker) {
+	dbSQL := db.getDBSQLInNoTxQuery()
+	if db.dbSQLSlave != nil && dbSQL == db.dbSQLSlave {
+		return dbSQL, db.slaveBreaker
+	}
+	return dbSQL, db.masterBreaker
+}
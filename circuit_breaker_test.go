@@ -0,0 +1,37 @@
+package gorm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOnlyRecordsTrial(t *testing.T) {
+	breaker := &CircuitBreaker{MinRequests: 1, OpenTimeout: 10 * time.Millisecond}
+
+	slow, _ := breaker.allow()
+	trial, _ := breaker.allow()
+	breaker.record(trial, errors.New("dial tcp: connection refused"))
+	if state := breaker.State(); state != circuitOpen {
+		t.Fatalf("circuit should be opened after failures, but got %v", state)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	trial, err := breaker.allow()
+	if err != nil {
+		t.Fatalf("half-open circuit should let a statement try, but got %v", err)
+	}
+
+	breaker.record(slow, nil)
+	if state := breaker.State(); state != circuitHalfOpen {
+		t.Errorf("statements started before opening shouldn't close the circuit, but got %v", state)
+	}
+	if _, err := breaker.allow(); err != ErrCircuitOpen {
+		t.Errorf("should fail fast while the trial is running, but got %v", err)
+	}
+
+	breaker.record(trial, nil)
+	if state := breaker.State(); state != circuitClosed {
+		t.Errorf("circuit should be closed after the trial succeeds, but got %v", state)
+	}
+}
@@ -0,0 +1,99 @@
+package gorm
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// maxClaimAttempts max attempts to claim a record optimistically before giving up
+const maxClaimAttempts = 5
+
+// Claim claim the first record matching given conditions for job queues, records locked or claimed by others are skipped,
+// attributes set with `Assign` are updated to the claimed record, e.g:
+//    db.Transaction(func(tx *gorm.DB) error {
+//      if err := tx.Assign(map[string]interface{}{"status": "running"}).Claim(&job, "status = ?", "pending").Error; err != nil {
+//        return err
+//      }
+//      return process(tx, &job)
+//    })
+//    // SELECT * FROM jobs WHERE (status = 'pending') ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED;
+//    // UPDATE jobs SET status = 'running', updated_at = '...' WHERE id = 111;
+//
+// It should be called in a transaction on dialects supporting `SKIP LOCKED` like postgres and mysql 8, the record is locked until the transaction ends.
+// Other dialects like mysql 5.7 and sqlite claim records optimistically, they select a record then update it with `Assign` attributes
+// only if it still matches the conditions, so `Assign` is required to mark the record claimed.
+// ErrRecordNotFound is returned if no record could be claimed
+func (s *DB) Claim(out interface{}, where ...interface{}) *DB {
+	s = s.ReturnErrRecordNotFound(true)
+
+	var (
+		attrs = s.search.assignAttrs
+		value = reflect.Indirect(reflect.ValueOf(out))
+	)
+
+	failed := func(err error) *DB {
+		clone := s.clone()
+		clone.AddError(err)
+		return clone
+	}
+
+	if s.supportSkipLocked() {
+		if _, ok := s.db.dbSQL.(sqlTx); !ok {
+			return failed(errors.New("claim with SKIP LOCKED should be called in a transaction"))
+		}
+
+		value.Set(reflect.Zero(value.Type()))
+
+		db := s.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").First(out, where...)
+		if db.Error == nil && len(attrs) > 0 {
+			db = s.New().Model(out).Updates(attrs)
+		}
+		return db
+	}
+
+	if len(attrs) == 0 {
+		return failed(errors.New("claim records optimistically requires Assign attributes to mark them claimed"))
+	}
+
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		// reset the record, otherwise its primary key of last attempt will be used as condition
+		value.Set(reflect.Zero(value.Type()))
+		if db := s.First(out, where...); db.Error != nil {
+			return db
+		}
+
+		// update the record only if it is not claimed by others, it should still match the conditions
+		claimDB := s.New().Model(out)
+		for _, clause := range s.search.whereConditions {
+			claimDB = claimDB.Where(clause["query"], clause["args"].([]interface{})...)
+		}
+		if len(where) > 0 {
+			claimDB = claimDB.Where(where[0], where[1:]...)
+		}
+
+		if claimDB = claimDB.Updates(attrs); claimDB.Error != nil || claimDB.RowsAffected > 0 {
+			return claimDB
+		}
+	}
+	return failed(ErrRecordNotFound)
+}
+
+// skipLockedSupport whether the database supports `SKIP LOCKED`, it is checked once as dialects like mysql query the version
+type skipLockedSupport struct {
+	once      sync.Once
+	supported bool
+}
+
+// supportSkipLocked return true if the database supports `SKIP LOCKED`, it is checked once for dbs opened together
+func (s *DB) supportSkipLocked() bool {
+	if s.parent == nil || s.parent.skipLocked == nil {
+		return supportSkipLocked(s.dialect)
+	}
+
+	support := s.parent.skipLocked
+	support.once.Do(func() {
+		support.supported = supportSkipLocked(s.dialect)
+	})
+	return support.supported
+}
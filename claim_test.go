@@ -0,0 +1,80 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type ClaimJob struct {
+	ID     uint
+	Status string
+}
+
+func TestClaim(t *testing.T) {
+	DB.DropTableIfExists(&ClaimJob{})
+	DB.AutoMigrate(&ClaimJob{})
+	DB.Create(&ClaimJob{Status: "pending"})
+	DB.Create(&ClaimJob{Status: "pending"})
+	DB.Create(&ClaimJob{Status: "done"})
+
+	running := map[string]interface{}{"status": "running"}
+	claimed := map[uint]bool{}
+	for i := 0; i < 2; i++ {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			var job ClaimJob
+			if err := tx.Assign(running).Claim(&job, "status = ?", "pending").Error; err != nil {
+				return err
+			}
+
+			if job.Status != "running" || claimed[job.ID] {
+				t.Errorf("Should claim a pending job and mark it running, but got %+v", job)
+			}
+			claimed[job.ID] = true
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Failed to claim job, got %v", err)
+		}
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Assign(running).Claim(&ClaimJob{}, "status = ?", "pending").Error
+	})
+	if err != gorm.ErrRecordNotFound {
+		t.Errorf("Should return ErrRecordNotFound if no job could be claimed, but got %v", err)
+	}
+
+	var count int
+	if DB.Model(&ClaimJob{}).Where("status = ?", "running").Count(&count); count != 2 {
+		t.Errorf("Claimed jobs should be running, but got %v", count)
+	}
+}
+
+func TestClaimChecksSkipLockedOnce(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	recorder.Reply("SELECT VERSION()", []string{"version"}, []interface{}{"8.0.30"})
+
+	for i := 0; i < 2; i++ {
+		db.Transaction(func(tx *gorm.DB) error {
+			return tx.Assign(map[string]interface{}{"status": "running"}).Claim(&ClaimJob{}, "status = ?", "pending").Error
+		})
+	}
+
+	var versions int
+	for _, statement := range recorder.Statements() {
+		if statement.SQL == "SELECT VERSION()" {
+			versions++
+		} else if strings.HasPrefix(statement.SQL, "SELECT") && !strings.HasSuffix(statement.SQL, "FOR UPDATE SKIP LOCKED") {
+			t.Errorf("Should claim with SKIP LOCKED, but got %v", statement.SQL)
+		}
+	}
+	if versions != 1 {
+		t.Errorf("Version should be queried once, but got %v times", versions)
+	}
+}
@@ -0,0 +1,68 @@
+package gorm
+
+import (
+	"fmt"
+)
+
+// Collation how CollateClause compares strings
+type Collation int
+
+const (
+	// CaseInsensitive compare strings ignoring case
+	CaseInsensitive Collation = iota
+	// CaseAndAccentInsensitive compare strings ignoring case and accents, e.g. 'José' equals to 'jose'
+	CaseAndAccentInsensitive
+)
+
+// dialectExpr expression built with the dialect of the statement, it is used as an arg of other expressions
+type dialectExpr func(dialect Dialect) *SqlExpr
+
+// GormValue build the expression with the dialect
+func (expr dialectExpr) GormValue(dialect Dialect) *SqlExpr {
+	return expr(dialect)
+}
+
+// ILike build condition matching the column with the pattern case-insensitively,
+// `ILIKE` on postgres and cockroachdb, `LOWER(column) LIKE LOWER(pattern)` on others
+//    db.Where(gorm.ILike("name", "%jinzhu%")).Find(&users)
+//    // postgres: SELECT * FROM users WHERE (name ILIKE '%jinzhu%');
+//    // mysql:    SELECT * FROM users WHERE (LOWER(name) LIKE LOWER('%jinzhu%'));
+func ILike(column string, pattern string) *SqlExpr {
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		switch dialect.GetName() {
+		case "postgres", "cockroachdb":
+			return Expr(fmt.Sprintf("%v ILIKE ?", column), pattern)
+		}
+		return Expr(fmt.Sprintf("LOWER(%v) LIKE LOWER(?)", column), pattern)
+	}))
+}
+
+// CollateClause build condition comparing the column with the value by the operator like `=`, `<>` or `LIKE` with the collation, e.g:
+//    db.Where(gorm.CollateClause("name", "=", "jose", gorm.CaseAndAccentInsensitive)).Find(&users)
+//    // mysql:    SELECT * FROM users WHERE (name COLLATE utf8mb4_unicode_ci = 'jose');
+//    // postgres: SELECT * FROM users WHERE (unaccent(LOWER(name)) = unaccent(LOWER('jose')));
+//
+// Values are lower cased to compare them case-insensitively, collations are overridden with `COLLATE` to ignore accents,
+// except postgres removing accents with `unaccent`, which requires `CREATE EXTENSION unaccent`, and sqlite which ignores case of ASCII characters only
+func CollateClause(column string, operator string, value interface{}, collation Collation) *SqlExpr {
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		if collation == CaseAndAccentInsensitive {
+			switch dialect.GetName() {
+			case "mysql", "tidb":
+				return Expr(fmt.Sprintf("%v COLLATE utf8mb4_unicode_ci %v ?", column, operator), value)
+			case "mssql":
+				return Expr(fmt.Sprintf("%v COLLATE Latin1_General_CI_AI %v ?", column, operator), value)
+			case "postgres":
+				return Expr(fmt.Sprintf("unaccent(LOWER(%v)) %v unaccent(LOWER(?))", column, operator), value)
+			case "cockroachdb":
+				// collated strings should be compared with the same collation, level 1 strength ignores case and accents
+				return Expr(fmt.Sprintf(`%v COLLATE "und-u-ks-level1" %v ? COLLATE "und-u-ks-level1"`, column, operator), value)
+			}
+		}
+
+		if dialect.GetName() == "sqlite3" {
+			return Expr(fmt.Sprintf("%v %v ? COLLATE NOCASE", column, operator), value)
+		}
+		return Expr(fmt.Sprintf("LOWER(%v) %v LOWER(?)", column, operator), value)
+	}))
+}
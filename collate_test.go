@@ -0,0 +1,54 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestILikeAndCollateClause(t *testing.T) {
+	DB.Save(&User{Name: "CollateUser", Age: 1})
+
+	var users []User
+	DB.Where(gorm.ILike("name", "%COLLATEUSER%")).Find(&users)
+	if len(users) != 1 {
+		t.Errorf("should find users case-insensitively with ILike, but got %v", len(users))
+	}
+
+	users = nil
+	DB.Where(gorm.CollateClause("name", "=", "collateuser", gorm.CaseInsensitive)).Find(&users)
+	if len(users) != 1 {
+		t.Errorf("should find users case-insensitively with CollateClause, but got %v", len(users))
+	}
+
+	users = nil
+	DB.Not(gorm.CollateClause("name", "=", "collateuser", gorm.CaseAndAccentInsensitive)).Where("name LIKE ?", "Collate%").Find(&users)
+	if len(users) != 0 {
+		t.Errorf("should exclude users case-insensitively with CollateClause, but got %v", len(users))
+	}
+
+	tests := []struct {
+		dialect string
+		query   *gorm.SqlExpr
+		sql     string
+	}{
+		{"postgres", gorm.ILike("name", "%jinzhu%"), `SELECT * FROM "users" WHERE (name ILIKE $1)`},
+		{"mysql", gorm.ILike("name", "%jinzhu%"), "SELECT * FROM `users` WHERE (LOWER(name) LIKE LOWER(?))"},
+		{"mysql", gorm.CollateClause("name", "=", "jose", gorm.CaseAndAccentInsensitive), "SELECT * FROM `users` WHERE (name COLLATE utf8mb4_unicode_ci = ?)"},
+		{"postgres", gorm.CollateClause("name", "=", "jose", gorm.CaseAndAccentInsensitive), `SELECT * FROM "users" WHERE (unaccent(LOWER(name)) = unaccent(LOWER($1)))`},
+		{"postgres", gorm.CollateClause("name", "<>", "jose", gorm.CaseInsensitive), `SELECT * FROM "users" WHERE (LOWER(name) <> LOWER($1))`},
+		{"mssql", gorm.CollateClause("name", "LIKE", "jo%", gorm.CaseAndAccentInsensitive), "SELECT * FROM [users] WHERE (name COLLATE Latin1_General_CI_AI LIKE ?)"},
+	}
+
+	for _, test := range tests {
+		db, recorder, err := gormtest.Open(test.dialect)
+		if err != nil {
+			t.Fatalf("failed to open db, got %v", err)
+		}
+		db.Where(test.query).Find(&users)
+		if sql := recorder.LastStatement().SQL; sql != test.sql {
+			t.Errorf("%v: expected %v, but got %v", test.dialect, test.sql, sql)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Connection run fc with a db pinned to a single connection of the pool, statements of it are sent to the same connection outside of a transaction,
+// e.g. to set session variables before running statements depending on them
+//    err := db.Connection(ctx, func(conn *gorm.DB) error {
+//      if err := conn.Exec("SET SESSION sql_mode = 'ANSI_QUOTES'").Error; err != nil {
+//        return err
+//      }
+//      defer conn.Exec("SET SESSION sql_mode = DEFAULT")
+//      return conn.Find(&users).Error
+//    })
+//
+// The connection is returned to the pool after fc returns, session variables set by fc stay on it, so reset them before returning.
+// Transactions begun with the db run on the connection, fc runs with the db itself if it is already in a transaction
+func (s *DB) Connection(ctx context.Context, fc func(conn *DB) error) error {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	if _, ok := s.db.dbSQL.(sqlTx); ok {
+		return fc(s)
+	}
+
+	db, ok := s.db.dbSQL.(interface {
+		Conn(ctx context.Context) (*sql.Conn, error)
+	})
+	if !ok {
+		return ErrCantCheckoutConnection
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	clone := s.clone()
+	clone.db.ctx = ctx
+	clone.db.setSource(2)
+	clone.db.dbSQL = sqlConn{Conn: conn, ctx: ctx}
+	clone.db.useMaster()
+	clone.refreshDialect()
+	return fc(clone)
+}
+
+// sqlConn a connection of the pool working as SQLCommon, statements without context run with the context of the connection
+type sqlConn struct {
+	*sql.Conn
+	ctx context.Context
+}
+
+func (conn sqlConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return conn.ExecContext(conn.ctx, query, args...)
+}
+
+func (conn sqlConn) Prepare(query string) (*sql.Stmt, error) {
+	return conn.PrepareContext(conn.ctx, query)
+}
+
+func (conn sqlConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return conn.QueryContext(conn.ctx, query, args...)
+}
+
+func (conn sqlConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return conn.QueryRowContext(conn.ctx, query, args...)
+}
+
+func (conn sqlConn) Begin() (*sql.Tx, error) {
+	return conn.BeginTx(conn.ctx, nil)
+}
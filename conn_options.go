@@ -0,0 +1,292 @@
+package gorm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConnectionOptions settings of connections, build data source names of dialects with DSN or open db with OpenWithOptions, e.g:
+//    db, err := gorm.OpenWithOptions("mysql", gorm.ConnectionOptions{
+//      Host: "db.internal", User: "app", Password: password, Database: "app",
+//      TLS:  &gorm.TLSOptions{CAFile: "/etc/ssl/rds-ca.pem"},
+//    })
+type ConnectionOptions struct {
+	Host     string
+	Port     int // defaults to the port of the dialect
+	User     string
+	Password string
+	Database string // file name for sqlite
+	// Charset charset of mysql and client encoding of postgres, defaults to utf8mb4 for mysql
+	Charset string
+	// Collation collation of mysql
+	Collation string
+	// Location time zone of times parsed by mysql, defaults to time.Local, times are always parsed as time.Time
+	Location *time.Location
+	// ConnectTimeout timeout of connecting
+	ConnectTimeout time.Duration
+	// ReadTimeout and WriteTimeout timeouts of reading and writing connections, only supported by mysql
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// TLS connect with TLS if it isn't nil
+	TLS *TLSOptions
+	// Params other params of data source names
+	Params map[string]string
+}
+
+// TLSOptions TLS settings of connections, files are PEM encoded
+type TLSOptions struct {
+	CAFile     string // CA verifying server certificates, system CAs are used if it is blank
+	CertFile   string // client certificate
+	KeyFile    string // private key of the client certificate
+	ServerName string // server name of certificates, defaults to the host
+	// SkipVerify don't verify server certificates, for testing only
+	SkipVerify bool
+}
+
+// RegisterMySQLTLSConfig register TLS configs referenced by names in data source names of mysql,
+// it is set to mysql.RegisterTLSConfig by importing github.com/lun-zhang/gorm/dialects/mysql
+var RegisterMySQLTLSConfig func(name string, config *tls.Config) error
+
+// OpenWithOptions open db with data source name built from connection options
+func OpenWithOptions(dialect string, connection ConnectionOptions, options ...Options) (*DB, error) {
+	dsn, err := connection.DSN(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{dsn}
+	for _, option := range options {
+		args = append(args, option)
+	}
+	return Open(dialect, args...)
+}
+
+// DSN build data source name of the dialect, mysql, tidb, postgres, cockroachdb, mssql and sqlite3 are supported
+func (options ConnectionOptions) DSN(dialect string) (string, error) {
+	switch dialect {
+	case "mysql", "tidb":
+		return options.mysqlDSN(dialect)
+	case "postgres", "cockroachdb":
+		return options.postgresDSN(dialect)
+	case "mssql":
+		return options.mssqlDSN()
+	case "sqlite3":
+		return options.sqliteDSN(), nil
+	}
+	return "", fmt.Errorf("data source name of %v isn't supported", dialect)
+}
+
+func (options ConnectionOptions) address(defaultPort int) string {
+	port := options.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(options.Host, strconv.Itoa(port))
+}
+
+func (options ConnectionOptions) mysqlDSN(dialect string) (string, error) {
+	defaultPort := 3306
+	if dialect == "tidb" {
+		defaultPort = 4000
+	}
+
+	charset, location := options.Charset, options.Location
+	if charset == "" {
+		charset = "utf8mb4"
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	params := url.Values{}
+	params.Set("charset", charset)
+	params.Set("parseTime", "true")
+	params.Set("loc", location.String())
+	if options.Collation != "" {
+		params.Set("collation", options.Collation)
+	}
+	for name, timeout := range map[string]time.Duration{"timeout": options.ConnectTimeout, "readTimeout": options.ReadTimeout, "writeTimeout": options.WriteTimeout} {
+		if timeout > 0 {
+			params.Set(name, timeout.String())
+		}
+	}
+
+	if options.TLS != nil {
+		config, err := options.TLS.config(options.Host)
+		if err != nil {
+			return "", err
+		}
+		if RegisterMySQLTLSConfig == nil {
+			return "", errors.New("TLS configs of mysql are registered by importing github.com/lun-zhang/gorm/dialects/mysql")
+		}
+
+		name := options.TLS.name(options.Host)
+		if err := RegisterMySQLTLSConfig(name, config); err != nil {
+			return "", err
+		}
+		params.Set("tls", name)
+	}
+	for name, value := range options.Params {
+		params.Set(name, value)
+	}
+
+	credentials := options.User
+	if options.Password != "" {
+		credentials += ":" + options.Password
+	}
+	return fmt.Sprintf("%v@tcp(%v)/%v?%v", credentials, options.address(defaultPort), options.Database, params.Encode()), nil
+}
+
+func (options ConnectionOptions) postgresDSN(dialect string) (string, error) {
+	defaultPort := 5432
+	if dialect == "cockroachdb" {
+		defaultPort = 26257
+	}
+
+	port := options.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	params := map[string]string{
+		"host":     options.Host,
+		"port":     strconv.Itoa(port),
+		"user":     options.User,
+		"password": options.Password,
+		"dbname":   options.Database,
+		"sslmode":  "disable",
+	}
+	if options.Charset != "" {
+		params["client_encoding"] = options.Charset
+	}
+	if options.ConnectTimeout > 0 {
+		params["connect_timeout"] = strconv.Itoa(int((options.ConnectTimeout + time.Second - 1) / time.Second))
+	}
+	if tlsOptions := options.TLS; tlsOptions != nil {
+		params["sslmode"] = "verify-full"
+		if tlsOptions.SkipVerify {
+			params["sslmode"] = "require"
+		} else if tlsOptions.CAFile == "" {
+			return "", fmt.Errorf("CA file is required to verify certificates of %v", dialect)
+		}
+		params["sslrootcert"] = tlsOptions.CAFile
+		params["sslcert"] = tlsOptions.CertFile
+		params["sslkey"] = tlsOptions.KeyFile
+	}
+	for name, value := range options.Params {
+		params[name] = value
+	}
+	return joinKeyValues(params), nil
+}
+
+func (options ConnectionOptions) mssqlDSN() (string, error) {
+	params := url.Values{}
+	params.Set("database", options.Database)
+	if options.ConnectTimeout > 0 {
+		params.Set("connection timeout", strconv.Itoa(int((options.ConnectTimeout+time.Second-1)/time.Second)))
+	}
+	if tlsOptions := options.TLS; tlsOptions != nil {
+		if tlsOptions.CertFile != "" {
+			return "", errors.New("client certificates aren't supported by mssql")
+		}
+		params.Set("encrypt", "true")
+		if tlsOptions.CAFile != "" {
+			params.Set("certificate", tlsOptions.CAFile)
+		}
+		if tlsOptions.ServerName != "" {
+			params.Set("hostNameInCertificate", tlsOptions.ServerName)
+		}
+		if tlsOptions.SkipVerify {
+			params.Set("TrustServerCertificate", "true")
+		}
+	}
+	for name, value := range options.Params {
+		params.Set(name, value)
+	}
+
+	dsn := url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(options.User, options.Password),
+		Host:     options.address(1433),
+		RawQuery: params.Encode(),
+	}
+	return dsn.String(), nil
+}
+
+func (options ConnectionOptions) sqliteDSN() string {
+	if len(options.Params) == 0 {
+		return options.Database
+	}
+
+	params := url.Values{}
+	for name, value := range options.Params {
+		params.Set(name, value)
+	}
+	return options.Database + "?" + params.Encode()
+}
+
+// joinKeyValues join params as `key=value` separated by spaces, values are quoted if required, blank values are omitted
+func joinKeyValues(params map[string]string) string {
+	var names []string
+	for name, value := range params {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		value := params[name]
+		if strings.ContainsAny(value, ` '\`) {
+			value = "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+		}
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// config build TLS config from files
+func (options *TLSOptions) config(host string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: options.ServerName, InsecureSkipVerify: options.SkipVerify}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+
+	if options.CAFile != "" {
+		pem, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %v", options.CAFile)
+		}
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// name return name of the TLS config registered for mysql, the same options of the host share the name
+func (options *TLSOptions) name(host string) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%v %+v", host, *options)
+	return fmt.Sprintf("gorm_%x", hash.Sum64())
+}
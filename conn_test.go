@@ -0,0 +1,54 @@
+package gorm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestConnection(t *testing.T) {
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" {
+		t.Skip("temporary tables of the connection are only tested with sqlite")
+	}
+
+	err := DB.Connection(context.Background(), func(conn *gorm.DB) error {
+		if err := conn.Exec("CREATE TEMP TABLE connection_temps (name varchar(255))").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("DROP TABLE connection_temps")
+
+		for i := 0; i < 3; i++ {
+			if err := conn.Exec("INSERT INTO connection_temps (name) VALUES (?)", "temp").Error; err != nil {
+				return err
+			}
+		}
+
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM connection_temps WHERE rowid = 1").Error
+		})
+		if err != nil {
+			return err
+		}
+
+		var count int
+		if err := conn.Table("connection_temps").Count(&count).Error; err != nil || count != 2 {
+			t.Errorf("statements should run on the same connection, but got %v, %v", count, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("No error should happen, but got %v", err)
+	}
+
+	tx := DB.Begin()
+	defer tx.Rollback()
+	if err := tx.Connection(context.Background(), func(conn *gorm.DB) error {
+		if conn != tx {
+			t.Errorf("connection of the transaction should be used")
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("No error should happen, but got %v", err)
+	}
+}
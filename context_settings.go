@@ -0,0 +1,83 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+)
+
+type settingsKey struct{}
+
+// WithValue return a context carrying the setting, settings carried by the context are applied to the db by WithContext and FromContext,
+// so they travel with the context instead of a specific db threaded through every function, e.g:
+//    ctx = gorm.WithValue(ctx, "gorm:query_option", "FOR UPDATE")
+//    ctx = gorm.WithValue(ctx, "gorm:debug", true)
+//    db.FromContext(ctx).First(&user)
+//
+// Besides settings read by callbacks with scope.Get, these settings are supported:
+//    "gorm:debug"     bool   log SQL like Debug
+//    "gorm:master"    bool   query master like Master
+//    "gorm:tenant_id" string work on the tenant's tables like ForTenant
+func WithValue(ctx context.Context, name string, value interface{}) context.Context {
+	settings := map[string]interface{}{}
+	for k, v := range contextSettings(ctx) {
+		settings[k] = v
+	}
+	settings[name] = value
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+// ValueFromContext return the setting set with WithValue
+func ValueFromContext(ctx context.Context, name string) (interface{}, bool) {
+	value, ok := contextSettings(ctx)[name]
+	return value, ok
+}
+
+// FromContext return a new db without search conditions working with the context and settings carried by it,
+// it is the same as New if ctx is nil
+//    func FindUser(ctx context.Context, id int) (user User, err error) {
+//      err = db.FromContext(ctx).First(&user, id).Error
+//      return
+//    }
+func (s *DB) FromContext(ctx context.Context) *DB {
+	clone := s.New()
+	if ctx != nil {
+		clone.db.ctx = ctx
+		clone.db.setSource(2)
+		clone.applyContextSettings()
+	}
+	return clone
+}
+
+func contextSettings(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	settings, _ := ctx.Value(settingsKey{}).(map[string]interface{})
+	return settings
+}
+
+// applyContextSettings apply settings carried by the db's context
+func (s *DB) applyContextSettings() {
+	for name, value := range contextSettings(s.db.ctx) {
+		switch name {
+		case "gorm:debug":
+			if debug, ok := value.(bool); ok && debug {
+				s.LogMode(true)
+			}
+		case "gorm:master":
+			if master, ok := value.(bool); ok && master {
+				s.db.useMaster()
+			}
+		case "gorm:tenant_id":
+			tenantID := fmt.Sprint(value)
+			if !tenantIDRegexp.MatchString(tenantID) {
+				s.AddError(fmt.Errorf("invalid tenant id %q", tenantID))
+				continue
+			}
+			s.db.ctx = WithTenant(s.db.ctx, tenantID)
+			s.InstantSet(name, tenantID)
+		default:
+			s.InstantSet(name, value)
+		}
+	}
+}
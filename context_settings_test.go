@@ -0,0 +1,48 @@
+package gorm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestContextSettings(t *testing.T) {
+	ctx := gorm.WithValue(context.Background(), "gorm:test_setting", "hello")
+	tenantCtx := gorm.WithValue(ctx, "gorm:tenant_id", "tenant1")
+
+	if _, ok := gorm.ValueFromContext(ctx, "gorm:tenant_id"); ok {
+		t.Errorf("WithValue should not change settings of the parent context")
+	}
+
+	if v, ok := DB.FromContext(ctx).Get("gorm:test_setting"); !ok || v != "hello" {
+		t.Errorf("FromContext should apply settings carried by the context, but got %v, %v", v, ok)
+	}
+
+	if v, ok := DB.WithContext(ctx).Get("gorm:test_setting"); !ok || v != "hello" {
+		t.Errorf("WithContext should apply settings carried by the context, but got %v, %v", v, ok)
+	}
+
+	if _, ok := DB.FromContext(nil).Get("gorm:test_setting"); ok {
+		t.Errorf("FromContext with nil context should not have settings")
+	}
+
+	tenantDB := DB.FromContext(tenantCtx)
+	if tableName := tenantDB.NewScope(&User{}).TableName(); tableName != "tenant1_users" {
+		t.Errorf("Tenant setting should resolve tables like ForTenant, but got %v", tableName)
+	}
+
+	if tenantID, ok := gorm.TenantFromContext(tenantDB.Context()); !ok || tenantID != "tenant1" {
+		t.Errorf("Tenant setting should be carried by the context, but got %v, %v", tenantID, ok)
+	}
+
+	if err := DB.FromContext(gorm.WithValue(ctx, "gorm:tenant_id", "tenant 1")).Error; err == nil {
+		t.Errorf("Should get error for invalid tenant id")
+	}
+
+	var user User
+	DB.Save(&User{Name: "context_settings"})
+	if err := DB.FromContext(gorm.WithValue(ctx, "gorm:debug", true)).Where("name = ?", "context_settings").First(&user).Error; err != nil {
+		t.Errorf("Should find user with debug setting, but got %v", err)
+	}
+}
@@ -1,12 +1,14 @@
 package gorm_test
 
 import (
+	"context"
 	"os"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/jinzhu/now"
+	"github.com/lun-zhang/gorm"
 )
 
 func TestCreate(t *testing.T) {
@@ -141,6 +143,40 @@ func TestCreateWithNowFuncOverride(t *testing.T) {
 	}
 }
 
+type UnixTimeProduct struct {
+	ID        uint
+	Code      string
+	CreatedAt int64 `gorm:"autoCreateTime:milli"`
+	UpdatedAt int64 `gorm:"autoUpdateTime:nano"`
+	Created   int64 `gorm:"autoCreateTime"`
+}
+
+func TestCreateWithUnixTimestamps(t *testing.T) {
+	timeA := now.MustParse("2016-01-01")
+	db := DB.New().SetNowFuncOverride(func() time.Time { return timeA })
+	db.DropTableIfExists(&UnixTimeProduct{})
+	db.AutoMigrate(&UnixTimeProduct{})
+
+	product := UnixTimeProduct{Code: "unix"}
+	if err := db.Save(&product).Error; err != nil {
+		t.Fatalf("Failed to create product, got %v", err)
+	}
+
+	if product.CreatedAt != timeA.UnixNano()/int64(time.Millisecond) || product.UpdatedAt != timeA.UnixNano() || product.Created != timeA.Unix() {
+		t.Errorf("Unix timestamps should be stamped with the unit of tags, but got %+v", product)
+	}
+
+	timeB := timeA.Add(time.Hour)
+	db.SetNowFuncOverride(func() time.Time { return timeB })
+	db.Model(&product).Update("code", "unix2")
+
+	var result UnixTimeProduct
+	db.First(&result, product.ID)
+	if result.UpdatedAt != timeB.UnixNano() || result.CreatedAt != timeA.UnixNano()/int64(time.Millisecond) {
+		t.Errorf("Only UpdatedAt should be stamped when updating, but got %+v", result)
+	}
+}
+
 type AutoIncrementUser struct {
 	User
 	Sequence uint `gorm:"AUTO_INCREMENT"`
@@ -286,3 +322,37 @@ func TestCreateIgnore(t *testing.T) {
 		t.Error("Should ignore duplicate user insert by insert modifier:IGNORE ")
 	}
 }
+
+type ActorStampedProduct struct {
+	ID        uint
+	Code      string
+	CreatedBy uint
+	UpdatedBy *string
+}
+
+func TestCreatedByAndUpdatedBy(t *testing.T) {
+	DB.DropTableIfExists(&ActorStampedProduct{})
+	DB.AutoMigrate(&ActorStampedProduct{})
+
+	product := ActorStampedProduct{Code: "L1212"}
+	DB.WithContext(gorm.WithActor(context.Background(), 1)).Create(&product)
+
+	var created ActorStampedProduct
+	DB.First(&created, product.ID)
+	if created.CreatedBy != 1 || created.UpdatedBy == nil || *created.UpdatedBy != "1" {
+		t.Errorf("CreatedBy and UpdatedBy should be set with actor when creating, but got %v, %v", created.CreatedBy, created.UpdatedBy)
+	}
+
+	DB.WithContext(gorm.WithActor(context.Background(), "admin")).Model(&product).Update("code", "L1213")
+
+	var updated ActorStampedProduct
+	DB.First(&updated, product.ID)
+	if updated.CreatedBy != 1 || updated.UpdatedBy == nil || *updated.UpdatedBy != "admin" {
+		t.Errorf("Only UpdatedBy should be set with actor when updating, but got %v, %v", updated.CreatedBy, updated.UpdatedBy)
+	}
+
+	DB.Model(&product).Update("code", "L1214")
+	if DB.First(&updated, product.ID); *updated.UpdatedBy != "admin" {
+		t.Errorf("UpdatedBy should not be changed without actor, but got %v", *updated.UpdatedBy)
+	}
+}
@@ -0,0 +1,57 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// DSNProvider return the data source name of new connections, e.g. with passwords rotated by Secrets Manager or IAM auth tokens,
+// open db with it to connect with the latest credentials without restarting processes
+//    db, err := gorm.Open("mysql", gorm.DSNProvider(func() (string, error) {
+//      token, err := rdsutils.BuildAuthToken(endpoint, region, user, credentials)
+//      return fmt.Sprintf("%v:%v@tcp(%v)/app?tls=rds&allowCleartextPasswords=true", user, token, endpoint), err
+//    }), gorm.Options{PoolOptions: gorm.PoolOptions{ConnMaxLifetime: 10 * time.Minute}})
+//
+// It is called for every new connection, cache credentials in it if getting them is expensive,
+// existing connections are kept until they are closed, limit their lifetime with ConnMaxLifetime
+type DSNProvider func() (string, error)
+
+// openDSNProvider open db of the driver connecting with data source names returned by the provider
+func openDSNProvider(driverName string, provider DSNProvider) (*sql.DB, error) {
+	// get the driver registered with the name, no connection is made by opening it
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+
+	return sql.OpenDB(dsnConnector{driver: drv, provider: provider}), nil
+}
+
+// dsnConnector connect with the data source name returned by the provider each time
+type dsnConnector struct {
+	driver   driver.Driver
+	provider DSNProvider
+}
+
+func (connector dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := connector.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	if driverContext, ok := connector.driver.(driver.DriverContext); ok {
+		dsnConnector, err := driverContext.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return dsnConnector.Connect(ctx)
+	}
+	return connector.driver.Open(dsn)
+}
+
+func (connector dsnConnector) Driver() driver.Driver {
+	return connector.driver
+}
@@ -0,0 +1,45 @@
+package gorm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestOpenWithDSNProvider(t *testing.T) {
+	if dialect := os.Getenv("GORM_DIALECT"); dialect != "" && dialect != "sqlite" {
+		t.Skip("rotated data source names are tested with sqlite files")
+	}
+
+	dir, err := ioutil.TempDir("", "gorm")
+	if err != nil {
+		t.Fatalf("failed to create dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var calls int
+	dsn := filepath.Join(dir, "old.db")
+	db, err := gorm.Open("sqlite3", gorm.DSNProvider(func() (string, error) {
+		calls++
+		return dsn, nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	db.Exec("CREATE TABLE rotations (id integer)")
+	if calls == 0 || !db.HasTable("rotations") {
+		t.Errorf("db should connect with the data source name of the provider, but got %v calls", calls)
+	}
+
+	// close idle connections, so new connections use the rotated data source name
+	dsn = filepath.Join(dir, "new.db")
+	db.DB().SetMaxIdleConns(0)
+	if db.HasTable("rotations") || calls < 2 {
+		t.Errorf("new connections should use the rotated data source name, but got %v calls", calls)
+	}
+}
@@ -0,0 +1,80 @@
+package gorm_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type Coordinate struct {
+	X, Y float64
+}
+
+func (c *Coordinate) GormDataType(dialect gorm.Dialect) string {
+	if dialect.GetName() == "mysql" {
+		return "POINT"
+	}
+	return "text"
+}
+
+func (c Coordinate) GormValue(dialect gorm.Dialect) *gorm.SqlExpr {
+	if dialect.GetName() == "mysql" {
+		return gorm.Expr("ST_GeomFromText(?)", fmt.Sprintf("POINT(%v %v)", c.X, c.Y))
+	}
+	return nil
+}
+
+func (c Coordinate) Value() (driver.Value, error) {
+	return fmt.Sprintf("%v,%v", c.X, c.Y), nil
+}
+
+func (c *Coordinate) Scan(src interface{}) error {
+	_, err := fmt.Sscanf(fmt.Sprintf("%s", src), "%v,%v", &c.X, &c.Y)
+	return err
+}
+
+type Spot struct {
+	ID       uint
+	Location Coordinate
+	Backup   *Coordinate `gorm:"type:varchar(64)"`
+}
+
+func TestCustomDataType(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.CreateTable(&Spot{})
+	if sql := recorder.LastStatement().SQL; !strings.Contains(sql, "`location` POINT,`backup` varchar(64)") {
+		t.Errorf("column types should be declared by GormDataType or type tags, got %v", sql)
+	}
+
+	db.Create(&Spot{Location: Coordinate{X: 1, Y: 2}})
+	statement := recorder.Statements()[len(recorder.Statements())-2]
+	if statement.SQL != "INSERT INTO `spots` (`location`,`backup`) VALUES (ST_GeomFromText(?),?)" || !reflect.DeepEqual(statement.Vars, []interface{}{"POINT(1 2)", nil}) {
+		t.Errorf("values should be written with GormValue, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	db.Model(&Spot{ID: 1}).Update("location", Coordinate{X: 3, Y: 4})
+	statement = recorder.Statements()[len(recorder.Statements())-2]
+	if statement.SQL != "UPDATE `spots` SET `location` = ST_GeomFromText(?) WHERE `spots`.`id` = ?" || !reflect.DeepEqual(statement.Vars, []interface{}{"POINT(3 4)", int64(1)}) {
+		t.Errorf("values should be updated with GormValue, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	DB.DropTableIfExists(&Spot{})
+	if err := DB.AutoMigrate(&Spot{}).Error; err != nil {
+		t.Fatalf("failed to migrate spots, got %v", err)
+	}
+	spot := Spot{Location: Coordinate{X: 1.5, Y: 2}}
+	DB.Create(&spot)
+	var found Spot
+	if err := DB.First(&found, spot.ID).Error; err != nil || found.Location != spot.Location {
+		t.Errorf("values without GormValue expressions should be written as normal args, got %#v, %v", found, err)
+	}
+}
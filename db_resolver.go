@@ -0,0 +1,127 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DBResolver plugin routes models and tables to different databases, each statement is resolved by its table,
+// tables not registered stay in the db using the plugin
+//    resolver := &gorm.DBResolver{}
+//    resolver.Register(ordersDB, &Order{}, "order_items")
+//    resolver.Register(paymentsDB, &Payment{})
+//    db.Use(resolver)
+//
+//    db.Find(&users)  // query users db
+//    db.Find(&orders) // query orders db
+//
+// A transaction belongs to the database beginning it, statements of other databases in it return error,
+// so do queries joining registered tables of other databases, begin transactions from the registered db
+//    ordersDB.Transaction(func(tx *gorm.DB) error {
+//      tx.Create(&order)
+//      tx.Create(&user) // error, users is not in orders db
+//    })
+//
+// Register dbs before using the plugin, statements executed with Exec and migrations are not routed, run them with the registered db
+type DBResolver struct {
+	defaultDB *DB
+	dbs       []*DB
+	tables    map[string]*DB
+}
+
+// Register route tables of models or table names to the db
+func (resolver *DBResolver) Register(db *DB, tables ...interface{}) *DBResolver {
+	if resolver.tables == nil {
+		resolver.tables = map[string]*DB{}
+	}
+
+	for _, table := range tables {
+		if name, ok := table.(string); ok {
+			resolver.tables[name] = db
+		} else {
+			resolver.tables[db.NewScope(table).TableName()] = db
+		}
+	}
+
+	for _, registered := range resolver.dbs {
+		if registered == db {
+			return resolver
+		}
+	}
+	resolver.dbs = append(resolver.dbs, db)
+	return resolver
+}
+
+// Name return plugin name
+func (resolver *DBResolver) Name() string {
+	return "gorm:db_resolver"
+}
+
+// Initialize register callbacks to route statements on current db and registered dbs
+func (resolver *DBResolver) Initialize(db *DB) error {
+	resolver.defaultDB = db.parent
+	for _, db := range append([]*DB{db}, resolver.dbs...) {
+		callback := db.Callback()
+		callback.Create().Before("gorm:begin_transaction").Register("gorm:db_resolver", resolver.resolveCallback)
+		callback.Query().Before("gorm:batch_load").Register("gorm:db_resolver", resolver.resolveCallback)
+		callback.RowQuery().Before("gorm:row_query").Register("gorm:db_resolver", resolver.resolveCallback)
+		callback.Update().Before("gorm:begin_transaction").Register("gorm:db_resolver", resolver.resolveCallback)
+		callback.Delete().Before("gorm:begin_transaction").Register("gorm:db_resolver", resolver.resolveCallback)
+	}
+	return nil
+}
+
+// resolve return the db of the table
+func (resolver *DBResolver) resolve(table string) *DB {
+	if db, ok := resolver.tables[table]; ok {
+		return db
+	}
+	return resolver.defaultDB
+}
+
+// resolveCallback switch connections of the statement to the db of its table
+func (resolver *DBResolver) resolveCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	model := scope
+	if dest, ok := scope.Get("gorm:query_destination"); ok && scope.Value == nil {
+		model = scope.New(dest)
+	}
+
+	table := model.TableName()
+	if _, ok := resolver.tables[table]; !ok && model.Value != nil && scope.Search.tableName == "" {
+		// tables may be renamed by tenants or shards
+		table = model.modelTableName()
+	}
+	target := resolver.resolve(table)
+
+	for _, join := range scope.Search.joinConditions {
+		if query, ok := join["query"].(string); ok {
+			for _, name := range strings.FieldsFunc(query, func(r rune) bool { return !isIdentifierRune(r) }) {
+				if db, ok := resolver.tables[name]; ok && db != target {
+					scope.Err(fmt.Errorf("can't join %v with %v in another database", table, name))
+					return
+				}
+			}
+		}
+	}
+
+	if _, ok := scope.db.db.dbSQL.(sqlTx); ok {
+		if scope.db.db.txSource != target.db.dbSQL {
+			scope.Err(fmt.Errorf("can't access %v in transaction of another database", table))
+		}
+		return
+	}
+
+	if scope.db.db.dbSQL == target.db.dbSQL {
+		return
+	}
+
+	scope.db.db.dbSQL = target.db.dbSQL
+	scope.db.db.dbSQLSlave = target.db.dbSQLSlave
+	scope.db.db.masterBreaker = target.db.masterBreaker
+	scope.db.db.slaveBreaker = target.db.slaveBreaker
+	scope.db.dialect, scope.db.sharedDialect = newDialect(target.dialect.GetName(), scope.db.db), false
+}
@@ -0,0 +1,90 @@
+package gorm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type ResolvedUser struct {
+	ID     uint
+	Name   string
+	Orders []ResolvedOrder
+}
+
+type ResolvedOrder struct {
+	ID             uint
+	ResolvedUserID uint
+	Amount         int
+}
+
+func TestDBResolver(t *testing.T) {
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("resolver test uses another sqlite database")
+	}
+
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	source := filepath.Join(os.TempDir(), "gorm_orders.db")
+	os.Remove(source)
+	ordersDB, err := gorm.Open("sqlite3", source)
+	if err != nil {
+		t.Fatalf("Failed to open orders db, got %v", err)
+	}
+	defer ordersDB.Close()
+
+	db.DropTableIfExists(&ResolvedUser{}, &ResolvedOrder{})
+	db.AutoMigrate(&ResolvedUser{})
+	ordersDB.AutoMigrate(&ResolvedOrder{})
+
+	if err := db.Use((&gorm.DBResolver{}).Register(ordersDB, &ResolvedOrder{})); err != nil {
+		t.Fatalf("Failed to use resolver, got %v", err)
+	}
+
+	user := ResolvedUser{Name: "resolved", Orders: []ResolvedOrder{{Amount: 10}}}
+	if err := db.Create(&user).Error; err == nil {
+		t.Errorf("Should refuse saving associations in another database within the transaction of creating")
+	}
+
+	user = ResolvedUser{Name: "resolved"}
+	db.Create(&user)
+	for _, amount := range []int{10, 20} {
+		if err := db.Create(&ResolvedOrder{ResolvedUserID: user.ID, Amount: amount}).Error; err != nil {
+			t.Fatalf("Failed to create order in orders db, got %v", err)
+		}
+	}
+
+	var count int
+	if ordersDB.Table("resolved_orders").Count(&count); count != 2 || db.HasTable(&ResolvedOrder{}) {
+		t.Errorf("Orders should be saved into orders db, but got %v", count)
+	}
+
+	var users []ResolvedUser
+	if err := db.Preload("Orders").Find(&users).Error; err != nil || len(users) != 1 || len(users[0].Orders) != 2 {
+		t.Errorf("Should preload orders from orders db, but got %v, %+v", err, users)
+	}
+
+	if err := db.Model(&ResolvedUser{}).Joins("JOIN resolved_orders ON resolved_orders.resolved_user_id = resolved_users.id").Count(&count).Error; err == nil {
+		t.Errorf("Should refuse joining tables of different databases")
+	}
+
+	err = ordersDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ResolvedOrder{ResolvedUserID: user.ID, Amount: 30}).Error; err != nil {
+			t.Errorf("Should create order in transaction of orders db, but got %v", err)
+		}
+		return tx.Create(&ResolvedUser{Name: "another"}).Error
+	})
+	if err == nil {
+		t.Errorf("Should refuse creating user in transaction of orders db")
+	}
+
+	if ordersDB.Table("resolved_orders").Count(&count); count != 2 {
+		t.Errorf("Transaction of orders db should be rolled back, but got %v orders", count)
+	}
+}
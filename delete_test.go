@@ -3,6 +3,8 @@ package gorm_test
 import (
 	"testing"
 	"time"
+
+	"github.com/lun-zhang/gorm"
 )
 
 func TestDelete(t *testing.T) {
@@ -23,6 +25,34 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteSliceByPrimaryKeys(t *testing.T) {
+	users := []User{{Name: "batch_delete1"}, {Name: "batch_delete2"}, {Name: "batch_delete3"}}
+	for i := range users {
+		DB.Save(&users[i])
+	}
+
+	if result := DB.Delete(users[:2]); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("Should delete records of the slice by primary keys, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var count int
+	if DB.Model(&User{}).Where("name LIKE ?", "batch_delete%").Count(&count); count != 1 {
+		t.Errorf("Only records of the slice should be deleted, but got %v left", count)
+	}
+
+	if err := DB.Delete(&[]User{users[2], {Name: "batch_delete4"}}).Error; err == nil {
+		t.Errorf("Should get error when deleting records with blank primary keys")
+	}
+
+	if result := DB.Delete(&[]User{}); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("Should delete nothing with empty slice, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if DB.Where("name = ?", "batch_delete3").First(&User{}).RecordNotFound() {
+		t.Errorf("Records not in the slice should not be deleted")
+	}
+}
+
 func TestInlineDelete(t *testing.T) {
 	user1, user2 := User{Name: "inline_delete1"}, User{Name: "inline_delete2"}
 	DB.Save(&user1)
@@ -89,3 +119,52 @@ func TestSoftDeleteWithCustomizedDeletedAtColumnName(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+func TestDeleteWithSelectedAssociations(t *testing.T) {
+	user := User{
+		Name:       "delete_with_associations",
+		Emails:     []Email{{Email: "delete_associations1@example.com"}, {Email: "delete_associations2@example.com"}},
+		CreditCard: CreditCard{Number: "delete_associations"},
+		Languages:  []Language{{Name: "delete_associations_lang"}},
+	}
+	DB.Save(&user)
+
+	if err := DB.Select("Emails", "Languages").Delete(&user).Error; err != nil {
+		t.Fatalf("No error should happen when delete with associations, but got %v", err)
+	}
+
+	var count int
+	if DB.Model(&Email{}).Where("user_id = ?", user.Id).Count(&count); count != 0 {
+		t.Errorf("Emails should be deleted with user, but got %v", count)
+	}
+
+	if DB.Table("user_languages").Where("user_id = ?", user.Id).Count(&count); count != 0 {
+		t.Errorf("Languages should be removed from join table, but got %v", count)
+	}
+
+	if DB.Where("name = ?", "delete_associations_lang").First(&Language{}).RecordNotFound() {
+		t.Errorf("Many to many associations should not be deleted")
+	}
+
+	if DB.Where("user_id = ?", user.Id).First(&CreditCard{}).RecordNotFound() {
+		t.Errorf("Not selected associations should not be deleted")
+	}
+
+	users := []User{
+		{Name: "delete_all_associations1", CreditCard: CreditCard{Number: "delete_all_associations1"}},
+		{Name: "delete_all_associations2", CreditCard: CreditCard{Number: "delete_all_associations2"}},
+	}
+	DB.Save(&users[0]).Save(&users[1])
+
+	if err := DB.Select(gorm.Associations).Where("id IN (?)", []int64{users[0].Id, users[1].Id}).Delete(&users).Error; err != nil {
+		t.Fatalf("No error should happen when delete with all associations, but got %v", err)
+	}
+
+	if DB.Model(&CreditCard{}).Where("user_id IN (?)", []int64{users[0].Id, users[1].Id}).Count(&count); count != 0 {
+		t.Errorf("Credit cards should be deleted with users, but got %v", count)
+	}
+
+	if !DB.Where("name LIKE ?", "delete_all_associations%").First(&User{}).RecordNotFound() {
+		t.Errorf("Users should be deleted")
+	}
+}
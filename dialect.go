@@ -1,11 +1,13 @@
 package gorm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Dialect interface contains behaviors that differ across SQL database
@@ -57,11 +59,66 @@ type Dialect interface {
 	CurrentDatabase() string
 }
 
-var dialectsMap = map[string]Dialect{}
+// foreignKeySupporter could be implemented by dialects that don't support foreign keys, e.g. TiDB
+type foreignKeySupporter interface {
+	SupportForeignKey() bool
+}
+
+// retryableErrorChecker could be implemented by dialects that could tell if a failed transaction is safe to retry
+type retryableErrorChecker interface {
+	IsRetryableError(err error) bool
+}
+
+// paginationOrderer could be implemented by dialects that can't paginate without ORDER BY, e.g. mssql's OFFSET FETCH
+type paginationOrderer interface {
+	DefaultPaginationOrder() string
+}
+
+// windowFunctionSupporter could be implemented by dialects supporting window functions like `ROW_NUMBER() OVER (...)`
+type windowFunctionSupporter interface {
+	SupportWindowFunction() bool
+}
+
+// returningSupporter could be implemented by dialects supporting `UPDATE ... RETURNING` to return updated values
+type returningSupporter interface {
+	SupportReturning() bool
+}
+
+// skipLockedSupporter could be implemented by dialects supporting `SELECT ... FOR UPDATE SKIP LOCKED`
+type skipLockedSupporter interface {
+	SupportSkipLocked() bool
+}
+
+// dataTypeChecker could be implemented by dialects refusing data types of some fields, e.g. AUTO_RANDOM of non-bigint keys of TiDB,
+// so migrations return errors instead of creating invalid columns
+type dataTypeChecker interface {
+	CheckDataType(field *StructField) error
+}
+
+// shardRowIDBitsSupporter could be implemented by dialects supporting table option `SHARD_ROW_ID_BITS`, e.g. TiDB
+type shardRowIDBitsSupporter interface {
+	SupportShardRowIDBits() bool
+}
+
+// upsertSupporter could be implemented by dialects supporting inserting a record or updating it on primary key conflicts in one statement,
+// it returns the clause appended to `INSERT` to update the columns with inserting values, columns are quoted
+type upsertSupporter interface {
+	UpsertClause(primaryKeys []string, columns []string) string
+}
+
+// replicaLagReporter could be implemented by dialects that could query replication lag of a replica,
+// ok is false if the node is not a replica
+type replicaLagReporter interface {
+	ReplicaLag(ctx context.Context, db *sql.DB) (lag time.Duration, ok bool, err error)
+}
+
+var (
+	dialectsMap     = map[string]Dialect{}
+	dialectFuncsMap = map[string]func() Dialect{}
+)
 
 func newDialect(name string, db SQLCommon) Dialect {
-	if value, ok := dialectsMap[name]; ok {
-		dialect := reflect.New(reflect.TypeOf(value).Elem()).Interface().(Dialect)
+	if dialect, ok := NewDialect(name); ok {
 		dialect.SetDB(db)
 		return dialect
 	}
@@ -72,17 +129,114 @@ func newDialect(name string, db SQLCommon) Dialect {
 	return commontDialect
 }
 
-// RegisterDialect register new dialect
+// NewDialect create a new instance of the registered dialect without db, could be used to wrap an existing dialect
+func NewDialect(name string) (Dialect, bool) {
+	if fn, ok := dialectFuncsMap[name]; ok {
+		return fn(), true
+	}
+
+	if value, ok := dialectsMap[name]; ok {
+		return reflect.New(reflect.TypeOf(value).Elem()).Interface().(Dialect), true
+	}
+	return nil, false
+}
+
+// RegisterDialect register new dialect, a new zero value of the dialect's type will be created for each connection
 func RegisterDialect(name string, dialect Dialect) {
+	delete(dialectFuncsMap, name)
 	dialectsMap[name] = dialect
 }
 
+// RegisterDialectFunc register new dialect with a constructor, which will be called for each connection,
+// use it for dialects that need to be initialized, e.g. wrapping an existing dialect to override part of its behaviors:
+//     type oracle struct {
+//       gorm.Dialect
+//     }
+//
+//     func (oracle) GetName() string { return "oracle" }
+//     func (oracle) BindVar(i int) string { return fmt.Sprintf(":%d", i) }
+//
+//     gorm.RegisterDialectFunc("oracle", func() gorm.Dialect {
+//       common, _ := gorm.NewDialect("common")
+//       return &oracle{Dialect: common}
+//     })
+// The dialect's `GetName` needs to return the registered name, as it is used to create dialects for new connections
+func RegisterDialectFunc(name string, fn func() Dialect) {
+	delete(dialectsMap, name)
+	dialectFuncsMap[name] = fn
+}
+
 // GetDialect gets the dialect for the specified dialect name
 func GetDialect(name string) (dialect Dialect, ok bool) {
+	if fn, exists := dialectFuncsMap[name]; exists {
+		return fn(), true
+	}
 	dialect, ok = dialectsMap[name]
 	return
 }
 
+func supportForeignKey(dialect Dialect) bool {
+	if supporter, ok := dialect.(foreignKeySupporter); ok {
+		return supporter.SupportForeignKey()
+	}
+	return true
+}
+
+func supportWindowFunction(dialect Dialect) bool {
+	if supporter, ok := dialect.(windowFunctionSupporter); ok {
+		return supporter.SupportWindowFunction()
+	}
+	return false
+}
+
+func supportReturning(dialect Dialect) bool {
+	if supporter, ok := dialect.(returningSupporter); ok {
+		return supporter.SupportReturning()
+	}
+	return false
+}
+
+func checkDataType(dialect Dialect, field *StructField) error {
+	if checker, ok := dialect.(dataTypeChecker); ok {
+		return checker.CheckDataType(field)
+	}
+	return nil
+}
+
+func supportShardRowIDBits(dialect Dialect) bool {
+	if supporter, ok := dialect.(shardRowIDBitsSupporter); ok {
+		return supporter.SupportShardRowIDBits()
+	}
+	return false
+}
+
+func supportSkipLocked(dialect Dialect) bool {
+	if supporter, ok := dialect.(skipLockedSupporter); ok {
+		return supporter.SupportSkipLocked()
+	}
+	return false
+}
+
+func isRetryableError(dialect Dialect, err error) bool {
+	if errorIs(err, ErrInjectedFault) {
+		return true
+	}
+
+	checker, ok := dialect.(retryableErrorChecker)
+	if !ok {
+		return false
+	}
+	if errs, ok := err.(Errors); ok {
+		for _, err := range errs {
+			if checker.IsRetryableError(err) {
+				return true
+			}
+		}
+		return false
+	}
+	return checker.IsRetryableError(err)
+}
+
 // ParseFieldStructForDialect get field's sql data type
 var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fieldValue reflect.Value, sqlType string, size int, additionalType string) {
 	// Get redirected field type
@@ -98,10 +252,14 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 	// Get redirected field value
 	fieldValue = reflect.Indirect(reflect.New(reflectType))
 
-	if gormDataType, ok := fieldValue.Interface().(interface {
-		GormDataType(Dialect) string
-	}); ok {
+	if gormDataType, ok := reflect.New(reflectType).Interface().(GormDataTypeInterface); ok && dataType == "" {
 		dataType = gormDataType.GormDataType(dialect)
+	} else if fieldValue.Type() == geometryType {
+		dataType = spatialDataTypeOf(dialect, field)
+	} else if dataType == "" && fieldValue.Type() == bitsType {
+		dataType = bitsDataTypeOf(dialect, field)
+	} else if dataType == "" && fieldValue.Type() == setType {
+		dataType = setDataTypeOf(dialect, field)
 	}
 
 	// Get scanner's real value
@@ -127,7 +285,11 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 	notNull, _ := field.TagSettingsGet("NOT NULL")
 	unique, _ := field.TagSettingsGet("UNIQUE")
 	additionalType = notNull + " " + unique
-	if value, ok := field.TagSettingsGet("DEFAULT"); ok {
+	if generator := keyGenerator(field); generator != "" {
+		if dataType == "" {
+			dataType = keyDataType(dialect, generator)
+		}
+	} else if value, ok := field.TagSettingsGet("DEFAULT"); ok {
 		additionalType = additionalType + " DEFAULT " + value
 	}
 
@@ -135,6 +297,12 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 		additionalType = additionalType + " COMMENT " + value
 	}
 
+	if enumType, check := enumDataType(dialect, field, fieldValue.Kind()); dataType == "" && enumType != "" {
+		dataType = enumType
+	} else if check != "" {
+		additionalType = additionalType + " " + check
+	}
+
 	return fieldValue, dataType, size, strings.TrimSpace(additionalType)
 }
 
@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// asOfSystemTimeRegexp matches timestamps of `AS OF SYSTEM TIME` that are safe to be put into SQL: string literals without quotes, numbers,
+// and functions of bounded staleness reads, e.g. '-10s', 1609459200000000000.0, follower_read_timestamp(), with_max_staleness('10s')
+var asOfSystemTimeRegexp = regexp.MustCompile(`^(?:'[^'\\]*'|-?[0-9]+(?:\.[0-9]+)?|follower_read_timestamp\(\)|` +
+	`with_(?:min_timestamp|max_staleness)\(\s*(?:'[^'\\]*'|-?[0-9]+(?:\.[0-9]+)?|now\(\))(?:\s*,\s*(?:true|false))?\s*\))$`)
+
+// detectedDialects caches detected dialects by source, so `SELECT version()` is only sent once for each database
+var detectedDialects sync.Map
+
+// cockroachdb CockroachDB speaks the postgres wire protocol, it is detected when opening a `postgres` connection
+type cockroachdb struct {
+	postgres
+}
+
+func init() {
+	RegisterDialect("cockroachdb", &cockroachdb{})
+}
+
+func (cockroachdb) GetName() string {
+	return "cockroachdb"
+}
+
+// DataTypeOf get data type for CockroachDB dialect, auto increment keys use unique_rowid() as SERIAL isn't sequential,
+// and uuid primary keys are generated by gen_random_uuid() if no default value given
+func (s *cockroachdb) DataTypeOf(field *StructField) string {
+	sqlType := s.postgres.DataTypeOf(field)
+
+	for _, serial := range []string{"bigserial", "serial"} {
+		if strings.HasPrefix(sqlType, serial) {
+			sqlType = "INT8 DEFAULT unique_rowid()" + strings.TrimPrefix(sqlType, serial)
+			break
+		}
+	}
+
+	if _, ok := field.TagSettingsGet("DEFAULT"); !ok && field.IsPrimaryKey && strings.HasPrefix(sqlType, "uuid") {
+		sqlType = strings.Replace(sqlType, "uuid", "uuid DEFAULT gen_random_uuid()", 1)
+	}
+	return sqlType
+}
+
+// SupportAsOfSystemTime CockroachDB supports historical reads with `AS OF SYSTEM TIME`
+func (cockroachdb) SupportAsOfSystemTime() bool {
+	return true
+}
+
+// IsRetryableError returns true if the transaction got a serialization failure (SQLSTATE 40001), which should be retried by the client
+func (cockroachdb) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if state, ok := sqlState(err); ok {
+		return state == "40001"
+	}
+	return strings.Contains(err.Error(), "restart transaction")
+}
+
+// detectDialect detects databases speaking other database's protocol, currently CockroachDB for `postgres`,
+// the result is cached by the source, or by the connection if it isn't opened from a source
+func detectDialect(name, source string, db SQLCommon) string {
+	if name != "postgres" || db == nil {
+		return name
+	}
+
+	var key interface{} = source
+	if source == "" {
+		if !reflect.TypeOf(db).Comparable() {
+			detected, _ := queryDialect(name, db)
+			return detected
+		}
+		key = db
+	}
+	if detected, ok := detectedDialects.Load(key); ok {
+		return detected.(string)
+	}
+
+	detected, err := queryDialect(name, db)
+	if err == nil {
+		detectedDialects.Store(key, detected)
+	}
+	return detected
+}
+
+func queryDialect(name string, db SQLCommon) (string, error) {
+	var version string
+	if err := db.QueryRow("SELECT version()").Scan(&version); err != nil {
+		return name, err
+	}
+	if strings.Contains(version, "CockroachDB") {
+		return "cockroachdb", nil
+	}
+	return name, nil
+}
@@ -0,0 +1,159 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type CockroachAccount struct {
+	ID      int64
+	Balance int64
+}
+
+func TestCockroachDBDataTypeOf(t *testing.T) {
+	dialect, ok := gorm.GetDialect("cockroachdb")
+	if !ok {
+		t.Fatalf("cockroachdb dialect should be registered")
+	}
+
+	for _, field := range DB.NewScope(&CockroachAccount{}).GetModelStruct().StructFields {
+		switch field.Name {
+		case "ID":
+			if typ := dialect.DataTypeOf(field); typ != "INT8 DEFAULT unique_rowid()" {
+				t.Errorf("auto increment primary key should use unique_rowid(), but got %v", typ)
+			}
+		case "Balance":
+			if typ := dialect.DataTypeOf(field); typ != "bigint" {
+				t.Errorf("normal field should use postgres's data type, but got %v", typ)
+			}
+		}
+	}
+}
+
+func TestAsOfSystemTime(t *testing.T) {
+	var users []User
+	err := DB.AsOfSystemTime("follower_read_timestamp()").Find(&users).Error
+
+	if dialect := DB.Dialect().GetName(); dialect == "cockroachdb" {
+		if err != nil {
+			t.Errorf("should be able to read historical data, but got %v", err)
+		}
+	} else if err == nil {
+		t.Errorf("AS OF SYSTEM TIME should be rejected by %v", dialect)
+	}
+}
+
+func TestAsOfSystemTimeOnlySelects(t *testing.T) {
+	db, recorder, err := gormtest.Open("cockroachdb")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	var accounts []CockroachAccount
+	db.AsOfSystemTime("follower_read_timestamp()").Where("balance > ?", 10).Find(&accounts)
+	if sql := recorder.LastStatement().SQL; sql != `SELECT * FROM "cockroach_accounts" AS OF SYSTEM TIME follower_read_timestamp() WHERE (balance > $1)` {
+		t.Errorf("AS OF SYSTEM TIME should be put into the select, but got %v", sql)
+	}
+
+	recorder.Reset()
+	db.Model(&CockroachAccount{}).AsOfSystemTime("'-10s'").Where("balance > ?", 10).Update("balance", 0)
+	db.AsOfSystemTime("'-10s'").Where("balance > ?", 10).Delete(&CockroachAccount{})
+	for _, statement := range recorder.Statements() {
+		if strings.Contains(statement.SQL, "AS OF SYSTEM TIME") {
+			t.Errorf("AS OF SYSTEM TIME should only be put into selects, but got %v", statement.SQL)
+		}
+	}
+	if len(recorder.Statements()) != 6 {
+		t.Errorf("update and delete should be executed, but got %v", recorder.Statements())
+	}
+}
+
+func TestAsOfSystemTimeRejectsInvalidTimestamp(t *testing.T) {
+	db, recorder, err := gormtest.Open("cockroachdb")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	for _, timestamp := range []string{"'-10s'", "-10", "1609459200000000000.0", "follower_read_timestamp()", "with_max_staleness('10s')", "with_min_timestamp(now(), true)"} {
+		var accounts []CockroachAccount
+		if err := db.AsOfSystemTime(timestamp).Find(&accounts).Error; err != nil {
+			t.Errorf("timestamp %v should be valid, but got %v", timestamp, err)
+		}
+	}
+
+	recorder.Reset()
+	for _, timestamp := range []string{"'-10s' WHERE 1 = 1; DROP TABLE cockroach_accounts; --", "'-10s'' OR ''1'' = ''1'", "now() - interval '10s'", "pg_sleep(10)"} {
+		var accounts []CockroachAccount
+		if err := db.AsOfSystemTime(timestamp).Find(&accounts).Error; err == nil {
+			t.Errorf("timestamp %v should be rejected", timestamp)
+		}
+	}
+	if statements := recorder.Statements(); len(statements) > 0 {
+		t.Errorf("invalid timestamps shouldn't be sent, but got %v", statements)
+	}
+}
+
+func TestDetectCockroachDBOnce(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	recorder.Reply("SELECT version()", []string{"version"}, []interface{}{"CockroachDB CCL v22.2.0"})
+
+	for i := 0; i < 2; i++ {
+		cockroach, err := gorm.Open("postgres", db.DB())
+		if err != nil {
+			t.Fatalf("failed to open db, got %v", err)
+		}
+		if name := cockroach.Dialect().GetName(); name != "cockroachdb" {
+			t.Errorf("CockroachDB should be detected, but got %v", name)
+		}
+	}
+
+	var count int
+	for _, statement := range recorder.Statements() {
+		if statement.SQL == "SELECT version()" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("version should be selected once for the same connection, but got %v times", count)
+	}
+}
+
+// sqlStateError carries SQLSTATE like *pq.Error
+type sqlStateError string
+
+func (err sqlStateError) Error() string { return "pq: error with SQLSTATE " + string(err) }
+
+func (err sqlStateError) Get(k byte) string {
+	if k == 'C' {
+		return string(err)
+	}
+	return ""
+}
+
+func TestCockroachDBRetryableError(t *testing.T) {
+	dialect, _ := gorm.GetDialect("cockroachdb")
+	checker, ok := dialect.(interface {
+		IsRetryableError(error) bool
+	})
+	if !ok {
+		t.Fatalf("cockroachdb dialect should be able to check retryable errors")
+	}
+
+	if !checker.IsRetryableError(&gorm.QueryError{Err: sqlStateError("40001"), SQL: "UPDATE accounts SET balance = 1"}) {
+		t.Errorf("serialization failure wrapped by QueryError should be retryable")
+	}
+
+	if checker.IsRetryableError(gorm.Errors{&gorm.QueryError{Err: sqlStateError("23505")}}) {
+		t.Errorf("unique violation shouldn't be retryable")
+	}
+
+	if err := (&gorm.QueryError{Err: sqlStateError("23505")}); !err.Is(gorm.ErrDuplicatedKey) {
+		t.Errorf("unique violation should be duplicated key error")
+	}
+}
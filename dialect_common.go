@@ -47,6 +47,19 @@ func (s *commonDialect) fieldCanAutoIncrement(field *StructField) bool {
 	return field.IsPrimaryKey
 }
 
+// onConflictClause return `ON CONFLICT ... DO UPDATE` clause of postgres and sqlite to update columns with inserting values
+func onConflictClause(primaryKeys []string, columns []string) string {
+	if len(columns) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%v) DO NOTHING", strings.Join(primaryKeys, ", "))
+	}
+
+	var sets []string
+	for _, column := range columns {
+		sets = append(sets, fmt.Sprintf("%v = excluded.%v", column, column))
+	}
+	return fmt.Sprintf("ON CONFLICT (%v) DO UPDATE SET %v", strings.Join(primaryKeys, ", "), strings.Join(sets, ", "))
+}
+
 func (s *commonDialect) DataTypeOf(field *StructField) string {
 	var dataValue, sqlType, size, additionalType = ParseFieldStructForDialect(field, s)
 
@@ -0,0 +1,44 @@
+package gorm_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/erikstmartin/go-testdb"
+	"github.com/lun-zhang/gorm"
+)
+
+func TestMssqlPagination(t *testing.T) {
+	db, _ := gorm.Open("mssql", "testdb", "")
+	defer testdb.Reset()
+
+	var queries []string
+	testdb.SetQueryFunc(func(query string) (driver.Rows, error) {
+		queries = append(queries, query)
+		return testdb.RowsFromCSVString([]string{"id", "name"}, "1,mssql"), nil
+	})
+
+	var users []User
+	db.Limit(10).Offset(20).Find(&users)
+	db.Order("name").Limit(10).Find(&users)
+
+	if len(queries) != 2 {
+		t.Fatalf("should execute two queries, but got %v", queries)
+	}
+
+	if !strings.HasSuffix(queries[0], "ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY") {
+		t.Errorf("pagination without order should use default order, but got %v", queries[0])
+	}
+
+	if !strings.HasSuffix(queries[1], "ORDER BY [name] OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY") {
+		t.Errorf("pagination with order should use the given order, but got %v", queries[1])
+	}
+}
+
+func TestMssqlQuote(t *testing.T) {
+	dialect, _ := gorm.GetDialect("mssql")
+	if quoted := dialect.Quote("weird]name"); quoted != "[weird]]name]" {
+		t.Errorf("closing bracket should be escaped, but got %v", quoted)
+	}
+}
@@ -1,11 +1,14 @@
 package gorm
 
 import (
+	"context"
 	"crypto/sha1"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -208,6 +211,39 @@ func (s mysql) CurrentDatabase() (name string) {
 	return
 }
 
+// UpsertClause mysql updates the record with `ON DUPLICATE KEY UPDATE`
+func (mysql) UpsertClause(primaryKeys []string, columns []string) string {
+	var sets []string
+	for _, column := range columns {
+		sets = append(sets, fmt.Sprintf("%v = VALUES(%v)", column, column))
+	}
+	if len(sets) == 0 {
+		sets = append(sets, fmt.Sprintf("%v = %v", primaryKeys[0], primaryKeys[0]))
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// SupportSkipLocked mysql supports `SKIP LOCKED` since 8.0, mariadb supports it since 10.6
+func (s mysql) SupportSkipLocked() bool {
+	var version string
+	if err := s.db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return false
+	}
+
+	minMajor, minMinor := 8, 0
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		minMajor, minMinor = 10, 6
+	}
+
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, _ := strconv.Atoi(parts[0])
+	minor, _ := strconv.Atoi(parts[1])
+	return major > minMajor || major == minMajor && minor >= minMinor
+}
+
 func (mysql) SelectFromDummyTable() string {
 	return "FROM DUAL"
 }
@@ -244,3 +280,41 @@ func (mysql) NormalizeIndexAndColumn(indexName, columnName string) (string, stri
 func (mysql) DefaultValueStr() string {
 	return "VALUES()"
 }
+
+// ReplicaLag query replication lag with `SHOW SLAVE STATUS`
+func (mysql) ReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, bool, error) {
+	rows, err := db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, false, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, false, err
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for idx := range values {
+		dest[idx] = &values[idx]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, false, err
+	}
+
+	for idx, column := range columns {
+		if column == "Seconds_Behind_Master" {
+			if values[idx] == nil {
+				return 0, false, errors.New("replication is not running")
+			}
+			seconds, err := strconv.ParseInt(string(values[idx]), 10, 64)
+			return time.Duration(seconds) * time.Second, err == nil, err
+		}
+	}
+	return 0, false, nil
+}
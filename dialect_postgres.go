@@ -1,6 +1,8 @@
 package gorm
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -67,6 +69,10 @@ func (s *postgres) DataTypeOf(field *StructField) string {
 				sqlType = "hstore"
 			}
 		default:
+			if sqlType = arrayDataType(dataValue.Type()); sqlType != "" {
+				break
+			}
+
 			if IsByteArrayOrSlice(dataValue) {
 				sqlType = "bytea"
 
@@ -128,10 +134,44 @@ func (s postgres) LastInsertIDReturningSuffix(tableName, key string) string {
 	return fmt.Sprintf("RETURNING %v.%v", tableName, key)
 }
 
+// SupportWindowFunction postgres supports window functions since 8.4
+func (postgres) SupportWindowFunction() bool {
+	return true
+}
+
+// SupportReturning postgres supports `UPDATE ... RETURNING`
+func (postgres) SupportReturning() bool {
+	return true
+}
+
+// UpsertClause postgres supports `ON CONFLICT ... DO UPDATE` since 9.5
+func (postgres) UpsertClause(primaryKeys []string, columns []string) string {
+	return onConflictClause(primaryKeys, columns)
+}
+
+// SupportSkipLocked postgres supports `SKIP LOCKED` since 9.5
+func (postgres) SupportSkipLocked() bool {
+	return true
+}
+
 func (postgres) SupportLastInsertID() bool {
 	return false
 }
 
+// ReplicaLag query replication lag with the timestamp of last replayed transaction
+func (postgres) ReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, bool, error) {
+	var (
+		inRecovery bool
+		lag        sql.NullFloat64
+	)
+
+	err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery(), EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())").Scan(&inRecovery, &lag)
+	if err != nil || !inRecovery || !lag.Valid {
+		return 0, false, err
+	}
+	return time.Duration(lag.Float64 * float64(time.Second)), true, nil
+}
+
 func isUUID(value reflect.Value) bool {
 	if value.Kind() != reflect.Array || value.Type().Len() != 16 {
 		return false
@@ -19,6 +19,11 @@ func (sqlite3) GetName() string {
 	return "sqlite3"
 }
 
+// UpsertClause sqlite supports `ON CONFLICT ... DO UPDATE` since 3.24
+func (sqlite3) UpsertClause(primaryKeys []string, columns []string) string {
+	return onConflictClause(primaryKeys, columns)
+}
+
 // Get Data Type for Sqlite Dialect
 func (s *sqlite3) DataTypeOf(field *StructField) string {
 	var dataValue, sqlType, size, additionalType = ParseFieldStructForDialect(field, s)
@@ -76,6 +81,12 @@ func (s sqlite3) HasIndex(tableName string, indexName string) bool {
 	return count > 0
 }
 
+func (s sqlite3) HasForeignKey(tableName string, foreignKeyName string) bool {
+	var count int
+	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM sqlite_master WHERE tbl_name = ? AND (sql LIKE '%%CONSTRAINT \"%v\" FOREIGN KEY%%' OR sql LIKE '%%CONSTRAINT %v FOREIGN KEY%%')", foreignKeyName, foreignKeyName), tableName).Scan(&count)
+	return count > 0
+}
+
 func (s sqlite3) HasTable(tableName string) bool {
 	var count int
 	s.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name=?", tableName).Scan(&count)
@@ -105,3 +116,8 @@ func (s sqlite3) CurrentDatabase() (name string) {
 	}
 	return
 }
+
+// SupportWindowFunction sqlite supports window functions since 3.25
+func (sqlite3) SupportWindowFunction() bool {
+	return true
+}
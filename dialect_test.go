@@ -0,0 +1,69 @@
+package gorm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type quotingDialect struct {
+	gorm.Dialect
+	quoted *int
+}
+
+func (quotingDialect) GetName() string {
+	return "quoting_sqlite3"
+}
+
+func (d quotingDialect) Quote(key string) string {
+	*d.quoted++
+	return d.Dialect.Quote(key)
+}
+
+func TestRegisterDialectFunc(t *testing.T) {
+	var quoted int
+	gorm.RegisterDialectFunc("quoting_sqlite3", func() gorm.Dialect {
+		sqlite, _ := gorm.NewDialect("sqlite3")
+		return &quotingDialect{Dialect: sqlite, quoted: &quoted}
+	})
+
+	db, err := gorm.Open("quoting_sqlite3", "sqlite3", filepath.Join(os.TempDir(), "gorm_quoting.db"))
+	if err != nil {
+		t.Fatalf("should be able to open connection with registered dialect, but got %v", err)
+	}
+	defer db.Close()
+
+	if name := db.Dialect().GetName(); name != "quoting_sqlite3" {
+		t.Errorf("should use registered dialect, but got %v", name)
+	}
+
+	db.DropTableIfExists(&User{})
+	if err := db.AutoMigrate(&User{}).Error; err != nil {
+		t.Errorf("should be able to migrate with wrapped dialect, but got %v", err)
+	}
+
+	if err := db.Where(&User{Name: "wrapped"}).First(&User{}).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("should be able to query with wrapped dialect, but got %v", err)
+	}
+
+	if quoted == 0 {
+		t.Errorf("wrapped dialect's overridden method should be used")
+	}
+}
+
+func TestNewDialect(t *testing.T) {
+	if _, ok := gorm.NewDialect("not_registered"); ok {
+		t.Errorf("should not create dialect which isn't registered")
+	}
+
+	dialect, ok := gorm.NewDialect("mysql")
+	if !ok || dialect.GetName() != "mysql" {
+		t.Errorf("should create registered dialect")
+	}
+
+	if registered, _ := gorm.GetDialect("mysql"); registered == dialect {
+		t.Errorf("should create a new instance of the dialect")
+	}
+}
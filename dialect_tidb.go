@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tidbRetryableErrors error numbers TiDB returns when an optimistic transaction should be retried
+var tidbRetryableErrors = map[int]bool{
+	1213: true, // deadlock found
+	8002: true, // SELECT FOR UPDATE write conflict
+	8022: true, // transaction commit failed and retrying is allowed
+	8028: true, // information schema changed
+	9007: true, // write conflict
+}
+
+var mysqlErrorNumberRegexp = regexp.MustCompile(`^Error (\d+)`)
+
+// tidb TiDB speaks the mysql protocol, but it doesn't support foreign keys and has its own DDL extensions
+type tidb struct {
+	mysql
+}
+
+func init() {
+	RegisterDialect("tidb", &tidb{})
+}
+
+func (tidb) GetName() string {
+	return "tidb"
+}
+
+// DataTypeOf get data type for TiDB dialect, a bigint primary key with tag `AUTO_RANDOM` (or `AUTO_RANDOM:5` to set the shard bits) uses AUTO_RANDOM instead of AUTO_INCREMENT
+func (s *tidb) DataTypeOf(field *StructField) string {
+	autoRandom, ok := field.TagSettingsGet("AUTO_RANDOM")
+	if !ok || !field.IsPrimaryKey {
+		return s.mysql.DataTypeOf(field)
+	}
+
+	// tag settings are shared by the cached model struct, so set AUTO_INCREMENT of a copy
+	field = field.clone()
+	field.TagSettingsSet("AUTO_INCREMENT", "false")
+	sqlType := s.mysql.DataTypeOf(field)
+	if !strings.HasPrefix(sqlType, "bigint") {
+		return sqlType
+	}
+
+	if bits, err := strconv.Atoi(autoRandom); err == nil {
+		return strings.Replace(sqlType, "bigint", fmt.Sprintf("bigint AUTO_RANDOM(%d)", bits), 1)
+	}
+	return strings.Replace(sqlType, "bigint", "bigint AUTO_RANDOM", 1)
+}
+
+// CheckDataType AUTO_RANDOM requires a bigint primary key
+func (s *tidb) CheckDataType(field *StructField) error {
+	if _, ok := field.TagSettingsGet("AUTO_RANDOM"); !ok || !field.IsPrimaryKey {
+		return nil
+	}
+
+	field = field.clone()
+	field.TagSettingsSet("AUTO_INCREMENT", "false")
+	if sqlType := s.mysql.DataTypeOf(field); !strings.HasPrefix(sqlType, "bigint") {
+		return fmt.Errorf("AUTO_RANDOM requires a bigint primary key, but field %s is %s", field.Name, sqlType)
+	}
+	return nil
+}
+
+// SupportShardRowIDBits TiDB scatters implicit row ids of tables without integer primary keys with `SHARD_ROW_ID_BITS`,
+// set the bits with setting `gorm:shard_row_id_bits` when creating tables
+//    db.Set("gorm:shard_row_id_bits", 4).CreateTable(&Event{})
+//    // CREATE TABLE `events` (...) SHARD_ROW_ID_BITS = 4
+func (tidb) SupportShardRowIDBits() bool {
+	return true
+}
+
+// HasForeignKey TiDB parses but never enforces foreign keys, so they're treated as non-existent
+func (tidb) HasForeignKey(tableName string, foreignKeyName string) bool {
+	return false
+}
+
+// SupportForeignKey TiDB doesn't support foreign keys, adding or removing them will be ignored
+func (tidb) SupportForeignKey() bool {
+	return false
+}
+
+// IsRetryableError returns true if the transaction failed because of an optimistic lock conflict and could be retried
+func (tidb) IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if submatch := mysqlErrorNumberRegexp.FindStringSubmatch(err.Error()); len(submatch) == 2 {
+		number, _ := strconv.Atoi(submatch[1])
+		return tidbRetryableErrors[number]
+	}
+	return false
+}
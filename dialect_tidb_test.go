@@ -0,0 +1,142 @@
+package gorm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type TiDBOrder struct {
+	ID     int64 `gorm:"primary_key;auto_random:5"`
+	Amount int64
+}
+
+func TestTiDBDataTypeOf(t *testing.T) {
+	dialect, ok := gorm.GetDialect("tidb")
+	if !ok {
+		t.Fatalf("tidb dialect should be registered")
+	}
+
+	for _, field := range DB.NewScope(&TiDBOrder{}).GetModelStruct().StructFields {
+		switch field.Name {
+		case "ID":
+			if typ := dialect.DataTypeOf(field); typ != "bigint AUTO_RANDOM(5)" {
+				t.Errorf("primary key with auto_random should use AUTO_RANDOM, but got %v", typ)
+			}
+		case "Amount":
+			if typ := dialect.DataTypeOf(field); typ != "bigint" {
+				t.Errorf("normal field should use mysql's data type, but got %v", typ)
+			}
+		}
+	}
+}
+
+func TestTiDBRetryableError(t *testing.T) {
+	dialect, _ := gorm.GetDialect("tidb")
+	checker, ok := dialect.(interface {
+		IsRetryableError(error) bool
+	})
+	if !ok {
+		t.Fatalf("tidb dialect should be able to check retryable errors")
+	}
+
+	if !checker.IsRetryableError(errors.New("Error 9007: Write conflict, txnStartTS=1")) {
+		t.Errorf("write conflict should be retryable")
+	}
+
+	if checker.IsRetryableError(errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'")) {
+		t.Errorf("duplicate entry shouldn't be retryable")
+	}
+}
+
+func TestTransactionWithRetry(t *testing.T) {
+	var count int
+	err := DB.TransactionWithRetry(3, func(tx *gorm.DB) error {
+		count++
+		return errors.New("Error 9007: Write conflict")
+	})
+
+	if err == nil {
+		t.Errorf("should return the error of the last try")
+	}
+
+	// sqlite doesn't report any error as retryable
+	if count != 1 {
+		t.Errorf("should only run once when the error isn't retryable, but ran %v times", count)
+	}
+}
+
+type TiDBInvalidOrder struct {
+	ID     int32 `gorm:"primary_key;auto_random"`
+	Amount int64
+}
+
+type TiDBEvent struct {
+	Code string `gorm:"primary_key"`
+	Name string
+}
+
+func TestTiDBCreateTable(t *testing.T) {
+	db, recorder, err := gormtest.Open("tidb")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	if err := db.CreateTable(&TiDBOrder{}).Error; err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	if sql := recorder.LastStatement().SQL; !strings.Contains(sql, "`id` bigint AUTO_RANDOM(5)") {
+		t.Errorf("primary key with auto_random should use AUTO_RANDOM, but got %v", sql)
+	}
+	for _, field := range db.NewScope(&TiDBOrder{}).GetModelStruct().StructFields {
+		if value, ok := field.TagSettingsGet("AUTO_INCREMENT"); ok && value == "false" {
+			t.Errorf("tag settings of the cached model struct shouldn't be changed")
+		}
+	}
+
+	recorder.Reset()
+	if err := db.CreateTable(&TiDBInvalidOrder{}).Error; err == nil || !strings.Contains(err.Error(), "AUTO_RANDOM requires a bigint primary key") {
+		t.Errorf("auto_random of non-bigint primary keys should return error, but got %v", err)
+	}
+	if len(recorder.Statements()) != 0 {
+		t.Errorf("invalid table shouldn't be created, but got %v", recorder.Statements())
+	}
+
+	if err := db.Set("gorm:shard_row_id_bits", 4).CreateTable(&TiDBEvent{}).Error; err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	if sql := recorder.LastStatement().SQL; !strings.HasSuffix(sql, "SHARD_ROW_ID_BITS = 4") {
+		t.Errorf("table should be created with SHARD_ROW_ID_BITS, but got %v", sql)
+	}
+}
+
+func TestTiDBTransactionWithRetry(t *testing.T) {
+	db, recorder, err := gormtest.Open("tidb")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	var count int
+	recorder.ReplyError("UPDATE orders SET amount = 1", errors.New("Error 9007: Write conflict, txnStartTS=1"))
+	recorder.ReplyError("UPDATE orders SET amount = 1", errors.New("Error 8002: SELECT FOR UPDATE has write conflict"))
+	err = db.TransactionWithRetry(3, func(tx *gorm.DB) error {
+		count++
+		return tx.Exec("UPDATE orders SET amount = 1").Error
+	})
+	if err != nil || count != 3 {
+		t.Errorf("write conflicts of TiDB should be retried, but got %v after %v runs", err, count)
+	}
+
+	count = 0
+	recorder.ReplyError("UPDATE orders SET amount = 1", errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'"))
+	err = db.TransactionWithRetry(3, func(tx *gorm.DB) error {
+		count++
+		return tx.Exec("UPDATE orders SET amount = 1").Error
+	})
+	if err == nil || count != 1 {
+		t.Errorf("errors not retryable should be returned, but got %v after %v runs", err, count)
+	}
+}
@@ -19,7 +19,7 @@ func setIdentityInsert(scope *gorm.Scope) {
 	if scope.Dialect().GetName() == "mssql" {
 		for _, field := range scope.PrimaryFields() {
 			if _, ok := field.TagSettingsGet("AUTO_INCREMENT"); ok && !field.IsBlank {
-				scope.NewDB().Exec(fmt.Sprintf("SET IDENTITY_INSERT %v ON", scope.TableName()))
+				scope.NewDB().Exec(fmt.Sprintf("SET IDENTITY_INSERT %v ON", scope.QuotedTableName()))
 				scope.InstanceSet("mssql:identity_insert_on", true)
 			}
 		}
@@ -29,7 +29,7 @@ func setIdentityInsert(scope *gorm.Scope) {
 func turnOffIdentityInsert(scope *gorm.Scope) {
 	if scope.Dialect().GetName() == "mssql" {
 		if _, ok := scope.InstanceGet("mssql:identity_insert_on"); ok {
-			scope.NewDB().Exec(fmt.Sprintf("SET IDENTITY_INSERT %v OFF", scope.TableName()))
+			scope.NewDB().Exec(fmt.Sprintf("SET IDENTITY_INSERT %v OFF", scope.QuotedTableName()))
 		}
 	}
 }
@@ -57,8 +57,9 @@ func (mssql) BindVar(i int) string {
 	return "$$$" // ?
 }
 
+// Quote quotes identifier with brackets, closing brackets in the identifier are escaped by doubling them
 func (mssql) Quote(key string) string {
-	return fmt.Sprintf(`[%s]`, key)
+	return fmt.Sprintf(`[%s]`, strings.Replace(key, "]", "]]", -1))
 }
 
 func (s *mssql) DataTypeOf(field *gorm.StructField) string {
@@ -172,6 +173,8 @@ func parseInt(value interface{}) (int64, error) {
 	return strconv.ParseInt(fmt.Sprint(value), 0, 0)
 }
 
+// LimitAndOffsetSQL use OFFSET FETCH for pagination, which is supported since SQL Server 2012,
+// it requires an ORDER BY clause, refer DefaultPaginationOrder
 func (mssql) LimitAndOffsetSQL(limit, offset interface{}) (sql string, err error) {
 	if offset != nil {
 		if parsedOffset, err := parseInt(offset); err != nil {
@@ -194,21 +197,28 @@ func (mssql) LimitAndOffsetSQL(limit, offset interface{}) (sql string, err error
 	return
 }
 
+// DefaultPaginationOrder OFFSET FETCH is only valid after ORDER BY, used when paginating without any order
+func (mssql) DefaultPaginationOrder() string {
+	return "(SELECT NULL)"
+}
+
+// SupportWindowFunction SQL Server supports window functions since 2005
+func (mssql) SupportWindowFunction() bool {
+	return true
+}
+
 func (mssql) SelectFromDummyTable() string {
 	return ""
 }
 
+// LastInsertIDOutputInterstitial return the inserted primary key with OUTPUT, which also works for `DEFAULT VALUES` inserts and inside triggers unlike SCOPE_IDENTITY()
 func (mssql) LastInsertIDOutputInterstitial(tableName, columnName string, columns []string) string {
-	if len(columns) == 0 {
-		// No OUTPUT to query
-		return ""
-	}
-	return fmt.Sprintf("OUTPUT Inserted.%v", columnName)
+	return fmt.Sprintf("OUTPUT INSERTED.%v", columnName)
 }
 
+// LastInsertIDReturningSuffix never used as the primary key is returned by OUTPUT
 func (mssql) LastInsertIDReturningSuffix(tableName, columnName string) string {
-	// https://stackoverflow.com/questions/5228780/how-to-get-last-inserted-id
-	return "; SELECT SCOPE_IDENTITY()"
+	return ""
 }
 
 func (mssql) DefaultValueStr() string {
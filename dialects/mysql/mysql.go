@@ -1,3 +1,12 @@
 package mysql
 
-import _ "github.com/go-sql-driver/mysql"
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/lun-zhang/gorm"
+)
+
+func init() {
+	gorm.RegisterMySQLTLSConfig = mysql.RegisterTLSConfig
+	gorm.RegisterMySQLReaderHandler = mysql.RegisterReaderHandler
+	gorm.DeregisterMySQLReaderHandler = mysql.DeregisterReaderHandler
+}
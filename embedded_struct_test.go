@@ -89,3 +89,74 @@ func TestEmbeddedPointerTypeStruct(t *testing.T) {
 		t.Errorf("Should find correct value for embedded pointer type")
 	}
 }
+
+type EmbeddedCountry struct {
+	ID   uint
+	Name string
+}
+
+type EmbeddedAddress struct {
+	Street    string
+	CountryID uint
+	Country   EmbeddedCountry
+}
+
+type EmbeddedShop struct {
+	ID       uint
+	Name     string
+	Billing  EmbeddedAddress `gorm:"embedded;embedded_prefix:billing_"`
+	Shipping EmbeddedAddress `gorm:"embedded;embedded_prefix:shipping_"`
+}
+
+func TestEmbeddedStructPaths(t *testing.T) {
+	DB.DropTableIfExists(&EmbeddedShop{}, &EmbeddedCountry{})
+	if err := DB.AutoMigrate(&EmbeddedShop{}, &EmbeddedCountry{}).Error; err != nil {
+		t.Fatalf("failed to migrate shops, got %v", err)
+	}
+
+	china, japan := EmbeddedCountry{Name: "China"}, EmbeddedCountry{Name: "Japan"}
+	DB.Create(&china)
+	DB.Create(&japan)
+
+	shop := EmbeddedShop{Name: "shop", Billing: EmbeddedAddress{Street: "billing street", CountryID: china.ID}, Shipping: EmbeddedAddress{Street: "shipping street", CountryID: japan.ID}}
+	if err := DB.Omit("Shipping").Create(&shop).Error; err != nil {
+		t.Fatalf("failed to create shop, got %v", err)
+	}
+
+	var found EmbeddedShop
+	if err := DB.Where(&EmbeddedShop{Billing: EmbeddedAddress{Street: "billing street"}}).First(&found).Error; err != nil {
+		t.Fatalf("failed to find shop with embedded conditions, got %v", err)
+	}
+	if found.Billing.Street != "billing street" || found.Shipping.Street != "" {
+		t.Errorf("embedded struct omitted by its path shouldn't be created, got %#v", found)
+	}
+	if DB.Where(&EmbeddedShop{Shipping: EmbeddedAddress{Street: "billing street"}}).First(&EmbeddedShop{}).Error == nil {
+		t.Errorf("conditions of embedded struct should use prefixed columns")
+	}
+
+	if err := DB.Model(&found).Select("Shipping.Street", "Shipping.CountryID").Updates(shop).Error; err != nil {
+		t.Errorf("failed to update shop, got %v", err)
+	}
+	found = EmbeddedShop{}
+	DB.First(&found, shop.ID)
+	if found.Shipping.Street != "shipping street" || found.Shipping.CountryID != japan.ID || found.Name != "shop" {
+		t.Errorf("fields selected by embedded paths should be updated, got %#v", found)
+	}
+
+	if field, ok := DB.NewScope(&shop).FieldByName("Shipping.Street"); !ok || field.DBName != "shipping_street" {
+		t.Errorf("fields should be found by embedded paths, got %#v", field)
+	}
+
+	var shops []EmbeddedShop
+	if err := DB.Preload("Billing.Country").Preload("Shipping.Country").Find(&shops).Error; err != nil {
+		t.Fatalf("failed to preload relations of embedded structs, got %v", err)
+	}
+	if len(shops) != 1 || shops[0].Billing.Country.Name != "China" || shops[0].Shipping.Country.Name != "Japan" {
+		t.Errorf("relations of embedded structs should be preloaded, got %#v", shops)
+	}
+
+	found = EmbeddedShop{}
+	if err := DB.Preload("Shipping.Country").First(&found, shop.ID).Error; err != nil || found.Shipping.Country.Name != "Japan" || found.Billing.Country.Name != "" {
+		t.Errorf("relations of embedded structs should be preloaded, got %#v, %v", found, err)
+	}
+}
@@ -0,0 +1,329 @@
+package gorm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// KeyProvider provides keys to encrypt columns, implement it to load keys from KMS, vault etc
+type KeyProvider interface {
+	// Key return the key of the table's column, its length should be 16, 24 or 32 bytes to use AES-128, AES-192 or AES-256
+	Key(table, column string) ([]byte, error)
+}
+
+// StaticKey key provider using the same key for all columns
+type StaticKey []byte
+
+// Key return the static key
+func (key StaticKey) Key(table, column string) ([]byte, error) {
+	return key, nil
+}
+
+// Encryption plugin encrypts fields tagged with `encrypted` using AES-GCM when saving them, and decrypts them after querying,
+// encrypted values are saved as base64 strings, so only string, *string and []byte fields with enough size are supported
+//    type User struct {
+//      ID    uint
+//      Email string `gorm:"encrypted:deterministic"`
+//      Notes string `gorm:"encrypted;type:text"`
+//    }
+//
+//    db.Use(&gorm.Encryption{Keys: gorm.StaticKey(key)})
+//
+// `encrypted` encrypts the same value into different ciphertexts, so the column can't be queried,
+// `encrypted:deterministic` always encrypts the same value into the same ciphertext, so the column could be queried by equality
+// with struct or map conditions, use Encrypt for conditions written in SQL
+//    db.Where(&User{Email: "jinzhu@example.org"}).First(&user)
+//
+// Values scanned with Row, Rows, Scan or Pluck are not decrypted
+type Encryption struct {
+	Keys KeyProvider
+}
+
+// Name return plugin name
+func (encryption *Encryption) Name() string {
+	return "gorm:encryption"
+}
+
+// Initialize register callbacks to encrypt and decrypt fields
+func (encryption *Encryption) Initialize(db *DB) error {
+	if encryption.Keys == nil {
+		return fmt.Errorf("key provider of %v is required", encryption.Name())
+	}
+
+	callback := db.Callback()
+	callback.Create().Before("gorm:create").Register("gorm:encrypt", encryption.encryptCallback)
+	callback.Create().After("gorm:create").Register("gorm:restore_encrypted", restoreEncryptedCallback)
+	callback.Update().Before("gorm:update").Register("gorm:encrypt", encryption.encryptCallback)
+	callback.Update().After("gorm:update").Register("gorm:restore_encrypted", restoreEncryptedCallback)
+	// decrypt results right after preloading, so query cache stores decrypted results
+	callback.Query().After("gorm:preload").Register("gorm:decrypt", encryption.decryptCallback)
+	return nil
+}
+
+// Encrypt encrypt value of deterministic encrypted column, to use it in SQL conditions
+//    email, err := encryption.Encrypt("users", "email", "jinzhu@example.org")
+//    db.Where("email = ? OR backup_email = ?", email, backupEmail).Find(&users)
+func (encryption *Encryption) Encrypt(table, column, value string) (string, error) {
+	return encryption.encrypt(table, column, []byte(value), true)
+}
+
+// encryptCallback encrypt fields before saving them, original values will be restored after saving
+func (encryption *Encryption) encryptCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		updateAttrs := attrs.(map[string]interface{})
+		for _, field := range scope.GetModelStruct().StructFields {
+			if value, ok := updateAttrs[field.DBName]; ok {
+				if encrypted, deterministic := isEncryptedField(field); encrypted {
+					value, err := encryption.encryptValue(scope.TableName(), field.DBName, value, deterministic)
+					if scope.Err(err) != nil {
+						return
+					}
+					updateAttrs[field.DBName] = value
+				}
+			}
+		}
+		return
+	}
+
+	var originals = map[*Field]reflect.Value{}
+	for _, field := range scope.Fields() {
+		if encrypted, deterministic := isEncryptedField(field.StructField); encrypted && field.Field.IsValid() {
+			value, err := encryption.encryptValue(scope.TableName(), field.DBName, field.Field.Interface(), deterministic)
+			if scope.Err(err) != nil {
+				break
+			}
+
+			originals[field] = reflect.New(field.Field.Type()).Elem()
+			originals[field].Set(field.Field)
+			field.Set(value)
+		}
+	}
+	scope.InstanceSet("gorm:encrypted_fields", originals)
+}
+
+// restoreEncryptedCallback restore fields with original values after saving
+func restoreEncryptedCallback(scope *Scope) {
+	if originals, ok := scope.InstanceGet("gorm:encrypted_fields"); ok {
+		for field, original := range originals.(map[*Field]reflect.Value) {
+			field.Set(original)
+		}
+	}
+}
+
+// decryptCallback decrypt fields of query results
+func (encryption *Encryption) decryptCallback(scope *Scope) {
+	// results are decrypted already if they are loaded by other scopes or cache, e.g. batch loaded results,
+	// rows scanned by Iterate aren't decrypted, so they need to be decrypted
+	_, skip := scope.InstanceGet("gorm:skip_query_callback")
+	_, iterated := scope.InstanceGet("gorm:iterated_row")
+	if (skip && !iterated) || scope.HasError() {
+		return
+	}
+
+	results := scope.IndirectValue()
+	if dest, ok := scope.Get("gorm:query_destination"); ok {
+		results = indirect(reflect.ValueOf(dest))
+	}
+
+	var records []reflect.Value
+	switch results.Kind() {
+	case reflect.Slice:
+		for i := 0; i < results.Len(); i++ {
+			records = append(records, indirect(results.Index(i)))
+		}
+	case reflect.Struct:
+		records = append(records, results)
+	}
+
+	for _, record := range records {
+		if record.Kind() != reflect.Struct || !record.CanAddr() {
+			continue
+		}
+
+		for _, field := range scope.New(record.Addr().Interface()).Fields() {
+			if encrypted, _ := isEncryptedField(field.StructField); encrypted && field.Field.IsValid() {
+				value, err := encryption.decryptValue(scope.TableName(), field.DBName, field.Field.Interface())
+				if scope.Err(err) != nil {
+					return
+				}
+				field.Set(value)
+			}
+		}
+	}
+}
+
+// encryptValue encrypt string, *string or []byte value, the encrypted value will be in the same type
+func (encryption *Encryption) encryptValue(table, column string, value interface{}, deterministic bool) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return encryption.encrypt(table, column, []byte(v), deterministic)
+	case *string:
+		if v == nil {
+			return v, nil
+		}
+		ciphertext, err := encryption.encrypt(table, column, []byte(*v), deterministic)
+		return &ciphertext, err
+	case []byte:
+		if v == nil {
+			return v, nil
+		}
+		ciphertext, err := encryption.encrypt(table, column, v, deterministic)
+		return []byte(ciphertext), err
+	}
+	return nil, fmt.Errorf("failed to encrypt %v.%v, unsupported type %T", table, column, value)
+}
+
+// decryptValue decrypt string, *string or []byte value, blank values won't be decrypted
+func (encryption *Encryption) decryptValue(table, column string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		plaintext, err := encryption.decrypt(table, column, v)
+		return string(plaintext), err
+	case *string:
+		if v == nil {
+			return v, nil
+		}
+		plaintext, err := encryption.decrypt(table, column, *v)
+		result := string(plaintext)
+		return &result, err
+	case []byte:
+		if v == nil {
+			return v, nil
+		}
+		return encryption.decrypt(table, column, string(v))
+	}
+	return nil, fmt.Errorf("failed to decrypt %v.%v, unsupported type %T", table, column, value)
+}
+
+// encrypt encrypt plaintext with AES-GCM, the nonce is generated from HMAC of the table, column and plaintext if it is deterministic,
+// otherwise it is random. The table and column are authenticated as additional data, so ciphertexts can't be moved to other columns
+func (encryption *Encryption) encrypt(table, column string, plaintext []byte, deterministic bool) (string, error) {
+	gcm, macKey, err := encryption.cipher(table, column)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, macKey)
+		mac.Write(encryptionContext(table, column))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, encryptionContext(table, column))), nil
+}
+
+func (encryption *Encryption) decrypt(table, column, ciphertext string) ([]byte, error) {
+	if ciphertext == "" {
+		return []byte{}, nil
+	}
+
+	gcm, _, err := encryption.cipher(table, column)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("failed to decrypt %v.%v, invalid encrypted value", table, column)
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], encryptionContext(table, column))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %v.%v, %v", table, column, err)
+	}
+	return plaintext, nil
+}
+
+// cipher return AES-GCM cipher of the column and the key to generate deterministic nonces,
+// both keys are derived from the column's key with HKDF, so the column's key is never used directly
+func (encryption *Encryption) cipher(table, column string) (cipher.AEAD, []byte, error) {
+	key, err := encryption.Keys.Key(table, column)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get key of %v.%v, %v", table, column, err)
+	}
+
+	block, err := aes.NewCipher(hkdfSHA256(key, "gorm:encryption:aes-gcm", len(key)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key of %v.%v, %v", table, column, err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	return gcm, hkdfSHA256(key, "gorm:encryption:nonce", sha256.Size), err
+}
+
+// encryptionContext identify the column of encrypted values, table names and columns can't contain NUL
+func encryptionContext(table, column string) []byte {
+	return []byte(table + "\x00" + column + "\x00")
+}
+
+// hkdfSHA256 derive key of length from secret with HKDF (RFC 5869) using SHA-256 and empty salt
+func hkdfSHA256(secret []byte, info string, length int) []byte {
+	extractor := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extractor.Write(secret)
+	prk := extractor.Sum(nil)
+
+	var okm, block []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		expander := hmac.New(sha256.New, prk)
+		expander.Write(block)
+		expander.Write([]byte(info))
+		expander.Write([]byte{counter})
+		block = expander.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length]
+}
+
+// isEncryptedField return true if the field is tagged with `encrypted`, and whether it is encrypted deterministically
+func isEncryptedField(field *StructField) (encrypted bool, deterministic bool) {
+	value, ok := field.TagSettingsGet("ENCRYPTED")
+	return ok, ok && strings.ToUpper(value) == "DETERMINISTIC"
+}
+
+// encryptedConditionValue encrypt value of condition on deterministic encrypted column of the model
+func (scope *Scope) encryptedConditionValue(model *Scope, column string, value interface{}) interface{} {
+	if scope.db == nil || scope.db.parent == nil {
+		return value
+	}
+
+	scope.db.parent.RLock()
+	encryption, ok := scope.db.parent.plugins["gorm:encryption"].(*Encryption)
+	scope.db.parent.RUnlock()
+	if !ok {
+		return value
+	}
+
+	for _, field := range model.GetModelStruct().StructFields {
+		if field.DBName != column {
+			continue
+		}
+
+		if encrypted, deterministic := isEncryptedField(field); !encrypted {
+			return value
+		} else if !deterministic {
+			scope.Err(fmt.Errorf("column %v is encrypted randomly and can't be queried, use `encrypted:deterministic` instead", column))
+			return value
+		}
+
+		value, err := encryption.encryptValue(model.TableName(), column, value, true)
+		scope.Err(err)
+		return value
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package gorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type EncryptedCustomer struct {
+	ID    uint
+	Email string  `gorm:"encrypted:deterministic"`
+	Notes *string `gorm:"encrypted;type:text"`
+}
+
+func TestEncryption(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Use(&gorm.Encryption{}); err == nil {
+		t.Errorf("Should get error when using encryption without key provider")
+	}
+
+	encryption := &gorm.Encryption{Keys: gorm.StaticKey("0123456789abcdef0123456789abcdef")}
+	if err := db.Use(encryption); err != nil {
+		t.Fatalf("Failed to use encryption, got %v", err)
+	}
+	db.Use(gorm.NewQueryCache(nil))
+
+	db.DropTableIfExists(&EncryptedCustomer{})
+	db.AutoMigrate(&EncryptedCustomer{})
+
+	notes := "vip"
+	customer := EncryptedCustomer{Email: "jinzhu@example.org", Notes: &notes}
+	db.Create(&customer)
+	if customer.Email != "jinzhu@example.org" || *customer.Notes != "vip" {
+		t.Errorf("Values should be restored after saving, but got %v, %v", customer.Email, *customer.Notes)
+	}
+
+	var email, savedNotes string
+	db.Table("encrypted_customers").Select("email, notes").Where("id = ?", customer.ID).Row().Scan(&email, &savedNotes)
+	if email == "" || email == customer.Email || savedNotes == notes {
+		t.Errorf("Values should be encrypted in database, but got %v, %v", email, savedNotes)
+	}
+
+	ciphertext, _ := encryption.Encrypt("encrypted_customers", "email", "jinzhu@example.org")
+	if ciphertext != email {
+		t.Errorf("Deterministic encrypted values should be same")
+	}
+
+	if other, _ := encryption.Encrypt("encrypted_customers", "backup_email", "jinzhu@example.org"); other == ciphertext {
+		t.Errorf("Deterministic encrypted values of different columns should be different")
+	}
+
+	var found EncryptedCustomer
+	if err := db.Where(&EncryptedCustomer{Email: "jinzhu@example.org"}).First(&found).Error; err != nil || found.ID != customer.ID {
+		t.Fatalf("Should find record by deterministic encrypted column, but got %v", err)
+	}
+
+	if found.Email != "jinzhu@example.org" || found.Notes == nil || *found.Notes != "vip" {
+		t.Errorf("Values should be decrypted after querying, but got %v, %v", found.Email, found.Notes)
+	}
+
+	if err := db.Where(map[string]interface{}{"notes": "vip"}).First(&EncryptedCustomer{}).Error; err == nil {
+		t.Errorf("Should get error when querying randomly encrypted column")
+	}
+
+	db.Model(&customer).Update("email", "hello@example.org")
+	for i := 0; i < 2; i++ {
+		var customers []EncryptedCustomer
+		db.Cache(time.Minute).Where(map[string]interface{}{"email": "hello@example.org"}).Find(&customers)
+		if len(customers) != 1 || customers[0].Email != "hello@example.org" || *customers[0].Notes != "vip" {
+			t.Errorf("Should find decrypted records after updating, but got %#v", customers)
+		}
+	}
+
+	var iterated EncryptedCustomer
+	db.Where("id = ?", customer.ID).Iterate(&iterated, func() error {
+		if iterated.Email != "hello@example.org" || iterated.Notes == nil || *iterated.Notes != "vip" {
+			t.Errorf("Values should be decrypted when iterating, but got %v, %v", iterated.Email, iterated.Notes)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnumError occurs when creating or updating a field with a value which isn't listed in its `enum` tag
+//    type User struct {
+//      Status string `gorm:"enum:active,disabled"`
+//    }
+type EnumError struct {
+	Field   string
+	Value   interface{}
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("invalid value %v for field %v, should be one of %v", e.Value, e.Field, strings.Join(e.Allowed, ","))
+}
+
+// Define callbacks for validating enum values
+func init() {
+	DefaultCallback.Create().Before("gorm:create").Register("gorm:validate_enum", validateEnumCallback)
+	DefaultCallback.Update().Before("gorm:update").Register("gorm:validate_enum", validateEnumCallback)
+}
+
+// validateEnumCallback check values of fields having `enum` tag before creating or updating
+func validateEnumCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if updateAttrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		for column, value := range updateAttrs.(map[string]interface{}) {
+			if field, ok := scope.FieldByName(column); ok {
+				if scope.Err(checkEnumValue(field.StructField, value)) != nil {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	for _, field := range scope.Fields() {
+		if !scope.changeableField(field) || (field.IsBlank && (field.HasDefaultValue || field.IsPrimaryKey)) {
+			continue
+		}
+		if field.Field.IsValid() && scope.Err(checkEnumValue(field.StructField, field.Field.Interface())) != nil {
+			return
+		}
+	}
+}
+
+// enumValues return allowed values defined with tag `enum`
+func enumValues(field *StructField) []string {
+	var values []string
+	if enum, ok := field.TagSettingsGet("ENUM"); ok {
+		for _, value := range strings.Split(enum, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func checkEnumValue(field *StructField, value interface{}) error {
+	allowed := enumValues(field)
+	if len(allowed) == 0 || isNullValue(value) {
+		return nil
+	}
+
+	if _, ok := value.(*SqlExpr); ok {
+		return nil
+	}
+
+	if valuer, ok := value.(driver.Valuer); ok {
+		value, _ = valuer.Value()
+	}
+
+	str := fmt.Sprint(reflect.Indirect(reflect.ValueOf(value)).Interface())
+	for _, v := range allowed {
+		if v == str {
+			return nil
+		}
+	}
+	return &EnumError{Field: field.Name, Value: value, Allowed: allowed}
+}
+
+// enumDataType return ENUM type for mysql's string fields, otherwise a CHECK constraint as additional type
+func enumDataType(dialect Dialect, field *StructField, kind reflect.Kind) (dataType string, check string) {
+	allowed := enumValues(field)
+	if len(allowed) == 0 {
+		return "", ""
+	}
+
+	values := make([]string, len(allowed))
+	for idx, value := range allowed {
+		values[idx] = enumLiteral(kind, value)
+	}
+
+	if kind == reflect.String && (dialect.GetName() == "mysql" || dialect.GetName() == "tidb") {
+		return fmt.Sprintf("ENUM(%v)", strings.Join(values, ",")), ""
+	}
+	return "", fmt.Sprintf("CHECK (%v IN (%v))", dialect.Quote(field.DBName), strings.Join(values, ","))
+}
+
+// checkEnumDataType return error if values of `enum` tag aren't values of the field's type, e.g. `abc` of integer fields
+func checkEnumDataType(field *StructField) error {
+	kind := indirectType(field.Struct.Type).Kind()
+	for _, value := range enumValues(field) {
+		if err := parseEnumValue(kind, value); err != nil {
+			return fmt.Errorf("invalid enum value %v of field %v with type %v, %v", value, field.Name, field.Struct.Type, err)
+		}
+	}
+	return nil
+}
+
+// parseEnumValue return error if the value isn't a number or boolean of the kind, values of other kinds aren't checked
+func parseEnumValue(kind reflect.Kind, value string) (err error) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		_, err = strconv.ParseBool(value)
+	}
+	return
+}
+
+// enumLiteral return the value as a SQL literal, numbers and booleans are kept as they are, other values are quoted as strings
+func enumLiteral(kind reflect.Kind, value string) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		if parseEnumValue(kind, value) == nil {
+			return value
+		}
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
@@ -0,0 +1,93 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type EnumAccount struct {
+	ID     uint
+	Status string `gorm:"enum:active,disabled"`
+	Level  int    `gorm:"enum:1,2,3"`
+}
+
+func TestEnumDataType(t *testing.T) {
+	mysql, _ := gorm.GetDialect("mysql")
+	postgres, _ := gorm.GetDialect("postgres")
+
+	for _, field := range DB.NewScope(&EnumAccount{}).GetModelStruct().StructFields {
+		switch field.Name {
+		case "Status":
+			if typ := mysql.DataTypeOf(field); typ != "ENUM('active','disabled')" {
+				t.Errorf("mysql should use ENUM for string enums, but got %v", typ)
+			}
+			if typ := postgres.DataTypeOf(field); typ != `text CHECK ("status" IN ('active','disabled'))` {
+				t.Errorf("postgres should use CHECK constraint for enums, but got %v", typ)
+			}
+		case "Level":
+			if typ := mysql.DataTypeOf(field); typ != "int CHECK (`level` IN (1,2,3))" {
+				t.Errorf("mysql should use CHECK constraint for integer enums, but got %v", typ)
+			}
+		}
+	}
+}
+
+func TestEnumValidation(t *testing.T) {
+	DB.DropTableIfExists(&EnumAccount{})
+	if err := DB.AutoMigrate(&EnumAccount{}).Error; err != nil {
+		t.Fatalf("failed to migrate table with enums, got %v", err)
+	}
+
+	account := EnumAccount{Status: "active", Level: 2}
+	if err := DB.Create(&account).Error; err != nil {
+		t.Errorf("should be able to create record with valid enum values, but got %v", err)
+	}
+
+	err := DB.Create(&EnumAccount{Status: "deleted", Level: 1}).Error
+	if enumErr, ok := err.(*gorm.EnumError); !ok || enumErr.Field != "Status" {
+		t.Errorf("should return EnumError when creating with invalid value, but got %v", err)
+	}
+
+	if err := DB.Model(&account).Update("level", 5).Error; err == nil {
+		t.Errorf("should return error when updating with invalid value")
+	}
+
+	if err := DB.Model(&account).Update("status", "disabled").Error; err != nil {
+		t.Errorf("should be able to update with valid value, but got %v", err)
+	}
+
+	account.Level = 4
+	if _, ok := DB.Save(&account).Error.(*gorm.EnumError); !ok {
+		t.Errorf("should return EnumError when saving with invalid value")
+	}
+
+	var result EnumAccount
+	DB.First(&result, account.ID)
+	if result.Status != "disabled" || result.Level != 2 {
+		t.Errorf("invalid values shouldn't be saved, but got %+v", result)
+	}
+}
+
+type InvalidEnumAccount struct {
+	ID    uint
+	Level int `gorm:"enum:low,high"`
+}
+
+func TestEnumValuesOfFieldType(t *testing.T) {
+	if err := DB.AutoMigrate(&InvalidEnumAccount{}).Error; err == nil {
+		t.Errorf("should return error when enum values aren't values of the field type")
+	}
+	if DB.HasTable(&InvalidEnumAccount{}) {
+		t.Errorf("table with invalid enum values shouldn't be created")
+	}
+
+	postgres, _ := gorm.GetDialect("postgres")
+	for _, field := range DB.NewScope(&InvalidEnumAccount{}).GetModelStruct().StructFields {
+		if field.Name == "Level" {
+			if typ := postgres.DataTypeOf(field); typ != `integer CHECK ("level" IN ('low','high'))` {
+				t.Errorf("non-numeric values should be quoted, but got %v", typ)
+			}
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package gorm
 
 import (
+	"errors"
 	jg "github.com/jinzhu/gorm"
+	"reflect"
 	"strings"
+	"time"
 )
 
 var (
@@ -16,21 +19,102 @@ var (
 	ErrCantStartTransaction = jg.ErrCantStartTransaction
 	// ErrUnaddressable unaddressable value
 	ErrUnaddressable = jg.ErrUnaddressable
+	// ErrQueryTimeout occurs when statement runs longer than the timeout set by `Timeout` or `SetDefaultTimeout`
+	ErrQueryTimeout = errors.New("query timeout")
+	// ErrCircuitOpen occurs when the circuit breaker of the database node is open because of too many failures
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrNoRowsAffected occurs when updating or deleting records affects no rows with RequireRowsAffected
+	ErrNoRowsAffected = errors.New("no rows affected")
+	// ErrReadOnlyModel occurs when creating, updating or deleting read-only models, like models mapped to views
+	ErrReadOnlyModel = errors.New("read-only model can't be created, updated or deleted")
+	// ErrReadOnlyDB occurs when creating, updating, deleting or executing statements with dbs returned by `ReadOnly`
+	ErrReadOnlyDB = errors.New("read-only db can't write")
+	// ErrMissingTenant occurs when querying or changing tenant scoped models guarded by TenantGuard without tenant in the context
+	ErrMissingTenant = errors.New("missing tenant")
+	// ErrCantCheckoutConnection occurs when the db isn't a pool of connections with `Connection`
+	ErrCantCheckoutConnection = errors.New("can't check out connection")
+	// ErrProxyUnsafeStatement occurs when running multiple statements or USE statements in the mode set with `SetProxyMode`
+	ErrProxyUnsafeStatement = errors.New("multiple statements and USE statements aren't supported by proxies")
+	// ErrLastInsertIDOutsideTransaction occurs when creating records outside transactions with `DisableLastInsertID` of `ProxyMode`,
+	// as `SELECT LAST_INSERT_ID()` may be sent to another connection
+	ErrLastInsertIDOutsideTransaction = errors.New("last insert id can't be queried outside transactions")
+	// ErrInjectedFault occurs when statements fail with faults injected by WithFaults
+	ErrInjectedFault = errors.New("injected fault")
+	// ErrDuplicatedKey occurs when inserting or updating records violates primary keys or unique indexes,
+	// errors of the database are not replaced, check them with errors.Is(db.Error, gorm.ErrDuplicatedKey)
+	ErrDuplicatedKey = errors.New("duplicated key")
 )
 
+// QueryError wraps errors returned by the database when executing statements, with the statement for diagnostics, e.g:
+//    if queryErr, ok := db.Error.(*gorm.QueryError); ok {
+//      log.Printf("%v: %v %v (%v at %v)", queryErr.Err, queryErr.SQL, queryErr.SanitizedArgs, queryErr.Duration, queryErr.Source)
+//    }
+//
+// The driver error is returned by Unwrap, so it works with errors.Is and errors.As,
+// errors of gorm like ErrQueryTimeout and ErrCircuitOpen are not wrapped
+type QueryError struct {
+	Err           error
+	SQL           string
+	SanitizedArgs []interface{} // args with sensitive values masked as `***`
+	Source        string        // caller who ran the statement
+	Duration      time.Duration
+}
+
+// Error returns the message of the wrapped error
+func (e *QueryError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is ErrDuplicatedKey and the wrapped error is a unique violation
+func (e *QueryError) Is(target error) bool {
+	return target == ErrDuplicatedKey && isDuplicatedKeyError(e.Err)
+}
+
+// isDuplicatedKeyError return true if the error of the database is a unique violation,
+// SQLSTATE 23505 of postgres and cockroachdb, error 1062 of mysql and tidb, 2601 and 2627 of mssql, and unique constraint errors of sqlite
+func isDuplicatedKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if state, ok := sqlState(err); ok {
+		return state == "23505"
+	}
+
+	msg := err.Error()
+	if submatch := mysqlErrorNumberRegexp.FindStringSubmatch(msg); len(submatch) == 2 {
+		return submatch[1] == "1062"
+	}
+	for _, s := range []string{"UNIQUE constraint failed", "PRIMARY KEY must be unique", "Cannot insert duplicate key"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapQueryError wrap the error of the statement as *QueryError
+func wrapQueryError(err error, sql string, args []interface{}, source string, duration time.Duration) error {
+	switch err.(type) {
+	case nil, *QueryError:
+		return err
+	}
+	if err == ErrQueryTimeout || err == ErrCircuitOpen || err == ErrInjectedFault {
+		return err
+	}
+	return &QueryError{Err: err, SQL: sql, SanitizedArgs: sanitizedVars(args), Source: source, Duration: duration}
+}
+
 // Errors contains all happened errors
 type Errors []error
 
 // IsRecordNotFoundError returns true if error contains a RecordNotFound error
 func IsRecordNotFoundError(err error) bool {
-	if errs, ok := err.(Errors); ok {
-		for _, err := range errs {
-			if err == ErrRecordNotFound {
-				return true
-			}
-		}
-	}
-	return err == ErrRecordNotFound
+	return errorIs(err, ErrRecordNotFound)
 }
 
 // GetErrors gets all errors that have occurred and returns a slice of errors (Error type)
@@ -62,6 +146,96 @@ func (errs Errors) Add(newErrors ...error) Errors {
 	return errs
 }
 
+// Is returns true if any of the errors is target or wraps it, so errors.Is(db.Error, gorm.ErrRecordNotFound) works with several errors
+func (errs Errors) Is(target error) bool {
+	for _, err := range errs {
+		if errorIs(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors matching target like errors.As, e.g:
+//    var queryErr *gorm.QueryError
+//    if errors.As(db.Error, &queryErr) {
+//      log.Println(queryErr.SQL)
+//    }
+func (errs Errors) As(target interface{}) bool {
+	for _, err := range errs {
+		if errorAs(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the errors
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// sqlStateError errors of postgres drivers carrying SQLSTATE, e.g. *pq.Error
+type sqlStateError interface {
+	Get(k byte) string
+}
+
+// sqlState return SQLSTATE of the database error in err's chain, errors are wrapped by QueryError and Errors
+func sqlState(err error) (string, bool) {
+	var stateErr sqlStateError
+	if err != nil && errorAs(err, &stateErr) {
+		return stateErr.Get('C'), true
+	}
+	return "", false
+}
+
+// errorIs report whether err or any error it wraps is target, the same as errors.Is which is unavailable before go 1.13
+func errorIs(err, target error) bool {
+	for err != nil {
+		if err == target {
+			return true
+		}
+
+		if e, ok := err.(interface{ Is(error) bool }); ok && e.Is(target) {
+			return true
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = wrapper.Unwrap()
+	}
+	return false
+}
+
+// errorAs find the first error matching target in err's chain and set target to it, the same as errors.As which is unavailable before go 1.13
+func errorAs(err error, target interface{}) bool {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		panic("gorm: target must be a non-nil pointer")
+	}
+	targetType := value.Type().Elem()
+
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			value.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+
+		if e, ok := err.(interface{ As(interface{}) bool }); ok && e.As(target) {
+			return true
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = wrapper.Unwrap()
+	}
+	return false
+}
+
 // Error takes a slice of all errors that have occurred and returns it as a formatted string
 func (errs Errors) Error() string {
 	var errors = []string{}
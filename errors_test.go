@@ -1,7 +1,9 @@
 package gorm_test
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/lun-zhang/gorm"
@@ -18,3 +20,57 @@ func TestErrorsCanBeUsedOutsideGorm(t *testing.T) {
 		t.Fatalf("Gave wrong error, got %s", gErrs.Error())
 	}
 }
+
+func TestQueryError(t *testing.T) {
+	err := DB.WithContext(context.Background()).Exec("UPDATE not_exist_table SET name = ? WHERE secret = ?", "query_error", gorm.Sensitive("secret")).Error
+
+	queryErr, ok := err.(*gorm.QueryError)
+	if !ok {
+		t.Fatalf("statement error should be a *QueryError, but got %T: %v", err, err)
+	}
+
+	if queryErr.SQL != "UPDATE not_exist_table SET name = ? WHERE secret = ?" {
+		t.Errorf("SQL of the error should be the statement, but got %v", queryErr.SQL)
+	}
+
+	if len(queryErr.SanitizedArgs) != 2 || queryErr.SanitizedArgs[0] != "query_error" || queryErr.SanitizedArgs[1] != "***" {
+		t.Errorf("sensitive args of the error should be masked, but got %v", queryErr.SanitizedArgs)
+	}
+
+	if !strings.Contains(queryErr.Source, "TestQueryError") {
+		t.Errorf("source of the error should be the caller, but got %v", queryErr.Source)
+	}
+
+	if queryErr.Unwrap() == nil || queryErr.Error() != queryErr.Unwrap().Error() {
+		t.Errorf("error should wrap the driver error, but got %v", queryErr.Unwrap())
+	}
+
+	if err := DB.First(&User{}, "name = ?", "not_exist_query_error").Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("errors of gorm should not be wrapped, but got %v", err)
+	}
+}
+
+func TestErrorsIsAndAs(t *testing.T) {
+	db := DB.New()
+	db.AddError(errors.New("first"))
+	db.AddError(gorm.ErrRecordNotFound)
+
+	errs, ok := db.Error.(gorm.Errors)
+	if !ok || !errs.Is(gorm.ErrRecordNotFound) || !gorm.IsRecordNotFoundError(db.Error) || !db.RecordNotFound() {
+		t.Fatalf("ErrRecordNotFound should be kept with other errors, but got %v", db.Error)
+	}
+
+	if errs.Is(gorm.ErrNoRowsAffected) {
+		t.Errorf("errors should not be ErrNoRowsAffected")
+	}
+
+	db.AddError(DB.Exec("UPDATE not_exist_table SET name = ?", "errors_as").Error)
+	var queryErr *gorm.QueryError
+	if !db.Error.(gorm.Errors).As(&queryErr) || queryErr.SQL != "UPDATE not_exist_table SET name = ?" {
+		t.Errorf("QueryError should be found in errors, but got %v", db.Error)
+	}
+
+	if len(db.Error.(gorm.Errors).Unwrap()) != 3 {
+		t.Errorf("errors should unwrap to all errors, but got %v", db.Error)
+	}
+}
@@ -0,0 +1,88 @@
+package gorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/rand"
+	"time"
+)
+
+const (
+	faultQuery = "query"
+	faultExec  = "exec"
+	faultBegin = "begin"
+)
+
+// FaultConfig faults injected into statements by WithFaults, to validate retries and circuit breakers of services in staging, e.g:
+//    db.WithFaults(gorm.FaultConfig{
+//      Query: gorm.FaultRule{DelayRate: 0.1, Delay: time.Second},
+//      Exec:  gorm.FaultRule{ErrorRate: 0.05, KillRate: 0.01},
+//    }).Create(&user)
+type FaultConfig struct {
+	Query FaultRule // faults of queries, including QueryRow
+	Exec  FaultRule // faults of Exec, including creating, updating and deleting
+	Begin FaultRule // faults of beginning transactions
+	// Rand return a random number in [0, 1) to decide faults, defaults to math/rand.Float64
+	Rand func() float64
+}
+
+// FaultRule rates of faults from 0 to 1, faults are decided independently for each statement
+type FaultRule struct {
+	// DelayRate rate of statements delayed by Delay before sending them to database
+	DelayRate float64
+	Delay     time.Duration
+	// ErrorRate rate of statements failing with ErrInjectedFault without sending them to database, it is retried by TransactionWithRetry
+	ErrorRate float64
+	// KillRate rate of statements failing with driver.ErrBadConn like their connections are killed, it is a failure of circuit breakers
+	KillRate float64
+}
+
+// WithFaults return a db injecting faults into its statements, faults are opt-in, they are never injected into DBs not created with it
+func (s *DB) WithFaults(config FaultConfig) *DB {
+	clone := s.clone()
+	clone.db.faults = &config
+	return clone
+}
+
+// injectFault delay the operation or return the injected error by rates of the operation
+func (db ctxDB) injectFault(ctx context.Context, operation string) error {
+	if db.faults == nil {
+		return nil
+	}
+
+	var rule FaultRule
+	switch operation {
+	case faultQuery:
+		rule = db.faults.Query
+	case faultExec:
+		rule = db.faults.Exec
+	case faultBegin:
+		rule = db.faults.Begin
+	}
+
+	random := db.faults.Rand
+	if random == nil {
+		random = rand.Float64
+	}
+
+	if rule.DelayRate > 0 && random() < rule.DelayRate {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		timer := time.NewTimer(rule.Delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	if rule.KillRate > 0 && random() < rule.KillRate {
+		return driver.ErrBadConn
+	}
+	if rule.ErrorRate > 0 && random() < rule.ErrorRate {
+		return ErrInjectedFault
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package gorm_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/erikstmartin/go-testdb"
+	"github.com/lun-zhang/gorm"
+)
+
+func TestWithFaults(t *testing.T) {
+	db, _ := gorm.Open("testdb", "")
+	defer testdb.Reset()
+
+	var calls int
+	testdb.SetExecFunc(func(query string) (driver.Result, error) {
+		calls++
+		return driver.RowsAffected(1), nil
+	})
+
+	always := func() float64 { return 0 }
+	if err := db.WithFaults(gorm.FaultConfig{Exec: gorm.FaultRule{ErrorRate: 1}, Rand: always}).Exec("UPDATE users SET age = 1").Error; err != gorm.ErrInjectedFault || calls != 0 {
+		t.Errorf("Should fail with injected fault without executing, but got %v after %v calls", err, calls)
+	}
+
+	if err := db.WithFaults(gorm.FaultConfig{Query: gorm.FaultRule{ErrorRate: 1}, Rand: always}).Exec("UPDATE users SET age = 1").Error; err != nil || calls != 1 {
+		t.Errorf("Should only inject faults of the operation, but got %v after %v calls", err, calls)
+	}
+
+	if err := db.Exec("UPDATE users SET age = 1").Error; err != nil || calls != 2 {
+		t.Errorf("Should not inject faults without WithFaults, but got %v after %v calls", err, calls)
+	}
+
+	breaker := &gorm.CircuitBreaker{MinRequests: 2, OpenTimeout: time.Minute}
+	db.UseCircuitBreaker(breaker, nil)
+	killer := db.WithFaults(gorm.FaultConfig{Exec: gorm.FaultRule{KillRate: 1}, Rand: always})
+	for i := 0; i < 2; i++ {
+		killer.Exec("UPDATE users SET age = 1")
+	}
+	if breaker.State() != "open" || calls != 2 {
+		t.Errorf("Killed connections should open the circuit, but got %v after %v calls", breaker.State(), calls)
+	}
+}
+
+func TestWithFaultsDelay(t *testing.T) {
+	db := DB.WithFaults(gorm.FaultConfig{Query: gorm.FaultRule{DelayRate: 1, Delay: 20 * time.Millisecond}, Rand: func() float64 { return 0 }})
+
+	start := time.Now()
+	if err := db.Find(&[]User{}).Error; err != nil || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Query should be delayed, but got %v after %v", err, time.Since(start))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := db.WithContext(ctx).Find(&[]User{}).Error; err == nil {
+		t.Errorf("Delay should be cancelled with the context")
+	}
+}
+
+func TestWithFaultsRetry(t *testing.T) {
+	var rolls int
+	db := DB.WithFaults(gorm.FaultConfig{Begin: gorm.FaultRule{ErrorRate: 0.5}, Rand: func() float64 {
+		rolls++
+		if rolls == 1 {
+			return 0
+		}
+		return 1
+	}})
+
+	var count int
+	err := db.TransactionWithRetry(3, func(tx *gorm.DB) error {
+		count++
+		return nil
+	})
+	if err != nil || rolls != 2 || count != 2 {
+		t.Errorf("Transaction should be retried after injected faults, but got %v after %v rolls and %v runs", err, rolls, count)
+	}
+}
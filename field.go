@@ -31,6 +31,16 @@ func (field *Field) Set(value interface{}) (err error) {
 	}
 
 	fieldValue := field.Field
+
+	// dereference pointer values that can't be set directly, e.g. set `*string` to `sql.NullString`, nil pointers will set field to zero value
+	if reflectValue.Kind() == reflect.Ptr && !reflectValue.Type().ConvertibleTo(fieldValue.Type()) {
+		if reflectValue.IsNil() {
+			reflectValue = reflect.Value{}
+		} else {
+			reflectValue = reflectValue.Elem()
+		}
+	}
+
 	if reflectValue.IsValid() {
 		if reflectValue.Type().ConvertibleTo(fieldValue.Type()) {
 			fieldValue.Set(reflectValue.Convert(fieldValue.Type()))
@@ -0,0 +1,77 @@
+package gorm
+
+import (
+	"reflect"
+)
+
+// FirstOrCreateMode how FirstOrCreate creates the record not found, set it with `db.Set("gorm:first_or_create_mode", mode)`,
+// other modes than FirstOrCreateSelect find the record again if it is created concurrently, instead of failing with duplicated keys, e.g:
+//    db.Set("gorm:first_or_create_mode", gorm.FirstOrCreateUpsert).FirstOrCreate(&user, User{Email: "jinzhu@example.org"})
+//    // SELECT * FROM users WHERE (email = 'jinzhu@example.org') ORDER BY id LIMIT 1;
+//    // INSERT INTO users (email) VALUES ('jinzhu@example.org') ON CONFLICT DO NOTHING RETURNING id;
+//    // SELECT * FROM users WHERE (email = 'jinzhu@example.org') ORDER BY id LIMIT 1; -- if it is created concurrently
+//
+// Conditions should be covered by an unique index, otherwise duplicated records are still created concurrently
+type FirstOrCreateMode int
+
+const (
+	// FirstOrCreateSelect insert the record not found, it fails with duplicated keys if the record is created concurrently
+	FirstOrCreateSelect FirstOrCreateMode = iota
+	// FirstOrCreateUpsert insert the record ignoring conflicts, then select the record if it is not inserted,
+	// mysql, postgres, cockroachdb and sqlite are supported, it works like FirstOrCreateRetry with other dialects.
+	// `BeforeCreate` and `AfterCreate` hooks are called even if the record is not inserted
+	FirstOrCreateUpsert
+	// FirstOrCreateRetry insert the record, then select the record if inserting fails with ErrDuplicatedKey,
+	// the record is inserted with a savepoint in transactions, so the transaction is still usable after the conflict
+	FirstOrCreateRetry
+)
+
+// createNotFound create the record not found by FirstOrCreate, conflicted is true if the record is created concurrently
+func (s *DB) createNotFound(out interface{}, where ...interface{}) (db *DB, conflicted bool) {
+	create := func(db *DB) *DB {
+		return db.NewScope(out).inlineCondition(where...).initialize().callCallbacks(db.currentCallbacks().creates).db
+	}
+
+	mode, _ := s.Get("gorm:first_or_create_mode")
+	switch mode {
+	case FirstOrCreateUpsert:
+		if clause, ok := ignoreConflictClause(s.NewScope(out)); ok {
+			db = create(s.Set("gorm:insert_option", clause).InstantSet("gorm:ignore_conflict", true))
+			return db, db.Error == nil && db.RowsAffected == 0
+		}
+		fallthrough
+	case FirstOrCreateRetry:
+		if _, ok := s.db.dbSQL.(sqlTx); ok {
+			// insert with a savepoint, postgres aborts the transaction after errors
+			s.Transaction(func(tx *DB) error {
+				db = create(tx)
+				return db.Error
+			})
+		} else {
+			db = create(s)
+		}
+		return db, errorIs(db.Error, ErrDuplicatedKey)
+	}
+	return create(s), false
+}
+
+// ignoreConflictClause return the clause appended to `INSERT` to ignore conflicts of primary keys and unique indexes
+func ignoreConflictClause(scope *Scope) (string, bool) {
+	switch scope.Dialect().GetName() {
+	case "postgres", "cockroachdb", "sqlite3":
+		return "ON CONFLICT DO NOTHING", true
+	case "mysql", "tidb":
+		// `INSERT IGNORE` ignores other errors too, update the primary key to itself instead
+		if field := scope.PrimaryField(); field != nil {
+			column := scope.Quote(field.DBName)
+			return "ON DUPLICATE KEY UPDATE " + column + " = " + column, true
+		}
+	}
+	return "", false
+}
+
+// resetValue reset the record to its zero value, so its primary key won't be used as condition when finding it again
+func resetValue(out interface{}) {
+	value := reflect.Indirect(reflect.ValueOf(out))
+	value.Set(reflect.Zero(value.Type()))
+}
@@ -0,0 +1,80 @@
+package gorm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type UniqueAccount struct {
+	ID    uint
+	Email string `gorm:"unique_index"`
+	Name  string
+}
+
+func TestFirstOrCreateMode(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&UniqueAccount{})
+	db.AutoMigrate(&UniqueAccount{})
+
+	// create the record concurrently after FirstOrCreate didn't find it
+	var concurrent string
+	db.Callback().Query().After("gorm:query").Register("test:create_concurrently", func(scope *gorm.Scope) {
+		if email := concurrent; email != "" {
+			concurrent = ""
+			// insert it in the same transaction if any, the db of the scope has the error of record not found
+			tx := scope.NewDB()
+			tx.Error = nil
+			if err := tx.Create(&UniqueAccount{Email: email, Name: "concurrent"}).Error; err != nil {
+				t.Errorf("Failed to create the record concurrently, got %v", err)
+			}
+		}
+	})
+
+	concurrent = "select@example.org"
+	var account UniqueAccount
+	if err := db.FirstOrCreate(&account, UniqueAccount{Email: "select@example.org"}).Error; !gorm.Errors([]error{err}).Is(gorm.ErrDuplicatedKey) {
+		t.Errorf("should fail with duplicated key by default, but got %v", err)
+	}
+
+	for _, mode := range []gorm.FirstOrCreateMode{gorm.FirstOrCreateUpsert, gorm.FirstOrCreateRetry} {
+		var account UniqueAccount
+		email := fmt.Sprintf("mode%v@example.org", mode)
+		concurrent = email
+		if err := db.Set("gorm:first_or_create_mode", mode).Attrs(UniqueAccount{Name: "attrs"}).FirstOrCreate(&account, UniqueAccount{Email: email}).Error; err != nil {
+			t.Errorf("mode %v should find the record created concurrently, but got %v", mode, err)
+		}
+		if account.ID == 0 || account.Name != "concurrent" {
+			t.Errorf("mode %v should find the record created concurrently, but got %#v", mode, account)
+		}
+
+		account = UniqueAccount{}
+		if err := db.Set("gorm:first_or_create_mode", mode).FirstOrCreate(&account, UniqueAccount{Email: "new-" + email, Name: "new"}).Error; err != nil || account.ID == 0 {
+			t.Errorf("mode %v should create the record, but got %v, %#v", mode, err, account)
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		concurrent = "tx@example.org"
+		var account UniqueAccount
+		if err := tx.Set("gorm:first_or_create_mode", gorm.FirstOrCreateRetry).FirstOrCreate(&account, UniqueAccount{Email: "tx@example.org"}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&account).Update("name", "updated").Error
+	})
+	if err != nil {
+		t.Errorf("transaction should be usable after conflicts, but got %v", err)
+	}
+
+	var count int
+	db.Model(&UniqueAccount{}).Where("email = ? AND name = ?", "tx@example.org", "updated").Count(&count)
+	if count != 1 {
+		t.Errorf("record created concurrently should be updated, but got %v", count)
+	}
+}
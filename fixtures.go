@@ -0,0 +1,365 @@
+package gorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FixtureOptions options of loading fixtures
+type FixtureOptions struct {
+	// Models models of fixture tables, keys of records are mapped to their columns by field names or column names,
+	// and tables are loaded after tables they reference
+	Models []interface{}
+	// Truncate how to clear tables before loading fixtures, defaults to TruncateDelete
+	Truncate TruncateStrategy
+}
+
+// TruncateStrategy how to clear tables before loading fixtures
+type TruncateStrategy int
+
+const (
+	// TruncateDelete delete rows of the tables and reset their identities
+	TruncateDelete TruncateStrategy = iota
+	// TruncateTable truncate the tables, it cascades to tables referencing them in postgres, sqlite deletes rows instead
+	TruncateTable
+	// TruncateNone keep rows of the tables, fixtures are inserted besides them
+	TruncateNone
+)
+
+// FixtureDecoders decoders of fixture files by extensions, JSON files are supported, register decoders for other formats like YAML
+//    gorm.FixtureDecoders[".yml"] = yaml.Unmarshal
+var FixtureDecoders = map[string]func(data []byte, v interface{}) error{
+	".json": json.Unmarshal,
+}
+
+var fixtureTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// LoadFixtures load fixture files of the directory into tables in a transaction, files are named after their tables, e.g. users.json
+//    [
+//      {"id": 1, "name": "jinzhu", "company_id": 1, "birthday": "2000-01-01T00:00:00Z"}
+//    ]
+//
+// Tables are cleared before loading fixtures, identities are reset to continue from loaded records, pass models of tables with FixtureOptions
+// to load tables after tables they reference and convert values to field types
+//    db.LoadFixtures("testdata/fixtures", gorm.FixtureOptions{Models: []interface{}{&User{}, &Company{}}})
+//
+// TRUNCATE and ALTER TABLE commit transactions implicitly in mysql and tidb, so tables are cleared before the transaction there,
+// and clearing them isn't rolled back if loading fails; loading in transactions of mysql and tidb deletes rows without resetting identities
+func (s *DB) LoadFixtures(dir string, options ...FixtureOptions) *DB {
+	var option FixtureOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	db := s.clone()
+	fixtures, err := readFixtures(dir)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	var (
+		_, inTransaction = s.db.dbSQL.(sqlTx)
+		dialect          = s.Dialect().GetName()
+		implicitCommit   = dialect == "mysql" || dialect == "tidb"
+	)
+	tables, models := fixtureTables(s, fixtures, option.Models)
+	if option.Truncate != TruncateNone && implicitCommit && !inTransaction {
+		if err := truncateFixtureTables(s, tables, option.Truncate, false); err != nil {
+			db.AddError(err)
+			return db
+		}
+	}
+
+	load := func(tx *DB) error {
+		if option.Truncate != TruncateNone && (!implicitCommit || inTransaction) {
+			if err := truncateFixtureTables(tx, tables, option.Truncate, implicitCommit); err != nil {
+				return err
+			}
+		}
+
+		for _, table := range tables {
+			if err := insertFixtures(tx, table, models[table], fixtures[table]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if inTransaction {
+		db.AddError(load(s))
+	} else {
+		db.AddError(s.Transaction(load))
+	}
+	return db
+}
+
+// readFixtures read records of fixture files by table names
+func readFixtures(dir string) (map[string][]map[string]interface{}, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := map[string][]map[string]interface{}{}
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		decode, ok := FixtureDecoders[strings.ToLower(ext)]
+		if file.IsDir() || !ok {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var records []map[string]interface{}
+		if err := decode(data, &records); err != nil {
+			return nil, fmt.Errorf("invalid fixture file %v: %v", file.Name(), err)
+		}
+		fixtures[strings.TrimSuffix(file.Name(), ext)] = records
+	}
+	return fixtures, nil
+}
+
+// fixtureTables return tables of fixtures in loading order, and scopes of their models
+func fixtureTables(tx *DB, fixtures map[string][]map[string]interface{}, modelValues []interface{}) ([]string, map[string]*Scope) {
+	var (
+		models = map[string]*Scope{}
+		deps   = map[string][]string{}
+	)
+	for _, model := range modelValues {
+		scope := tx.NewScope(model)
+		table := scope.TableName()
+		models[table] = scope
+
+		for _, field := range scope.GetModelStruct().StructFields {
+			relationship := field.Relationship
+			if relationship == nil {
+				continue
+			}
+
+			elemType := field.Struct.Type
+			for elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			associationTable := tx.NewScope(reflect.New(elemType).Interface()).TableName()
+
+			switch relationship.Kind {
+			case "belongs_to":
+				deps[table] = append(deps[table], associationTable)
+			case "has_one", "has_many":
+				deps[associationTable] = append(deps[associationTable], table)
+			case "many_to_many":
+				if relationship.JoinTableHandler != nil {
+					joinTable := relationship.JoinTableHandler.Table(tx)
+					deps[joinTable] = append(deps[joinTable], table, associationTable)
+				}
+			}
+		}
+	}
+
+	return sortFixtureTables(fixtures, deps), models
+}
+
+// truncateFixtureTables clear tables in reverse loading order, only delete rows if TRUNCATE and ALTER TABLE commit the transaction implicitly
+func truncateFixtureTables(tx *DB, tables []string, strategy TruncateStrategy, deleteOnly bool) error {
+	for i := len(tables) - 1; i >= 0; i-- {
+		if err := truncateFixtureTable(tx, tables[i], strategy, deleteOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortFixtureTables sort tables after tables they depend on, tables are sorted by names otherwise
+func sortFixtureTables(fixtures map[string][]map[string]interface{}, deps map[string][]string) []string {
+	var names []string
+	for table := range fixtures {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+
+	var (
+		tables  []string
+		visited = map[string]bool{}
+		visit   func(table string)
+	)
+	visit = func(table string) {
+		if _, ok := fixtures[table]; !ok || visited[table] {
+			return
+		}
+		// mark it before visiting dependencies to break cyclic references
+		visited[table] = true
+		for _, dep := range deps[table] {
+			visit(dep)
+		}
+		tables = append(tables, table)
+	}
+	for _, table := range names {
+		visit(table)
+	}
+	return tables
+}
+
+func truncateFixtureTable(tx *DB, table string, strategy TruncateStrategy, deleteOnly bool) error {
+	var (
+		scope       = tx.NewScope(nil)
+		quotedTable = scope.Quote(table)
+		dialect     = tx.Dialect().GetName()
+	)
+
+	if deleteOnly {
+		return tx.Exec(fmt.Sprintf("DELETE FROM %v", quotedTable)).Error
+	}
+
+	if strategy == TruncateTable {
+		switch dialect {
+		case "postgres", "cockroachdb":
+			return tx.Exec(fmt.Sprintf("TRUNCATE TABLE %v RESTART IDENTITY CASCADE", quotedTable)).Error
+		case "mysql", "tidb", "mssql":
+			return tx.Exec(fmt.Sprintf("TRUNCATE TABLE %v", quotedTable)).Error
+		}
+	}
+
+	if err := tx.Exec(fmt.Sprintf("DELETE FROM %v", quotedTable)).Error; err != nil {
+		return err
+	}
+
+	switch dialect {
+	case "sqlite3":
+		if tx.Dialect().HasTable("sqlite_sequence") {
+			return tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", table).Error
+		}
+	case "mysql", "tidb":
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %v AUTO_INCREMENT = 1", quotedTable)).Error
+	case "mssql":
+		return tx.Exec(fmt.Sprintf("DBCC CHECKIDENT ('%v', RESEED, 0)", table)).Error
+	}
+	return nil
+}
+
+func insertFixtures(tx *DB, table string, model *Scope, records []map[string]interface{}) error {
+	var (
+		quotedTable = tx.NewScope(nil).Quote(table)
+		dialect     = tx.Dialect().GetName()
+		identity    = fixtureIdentity(model)
+	)
+
+	for _, record := range records {
+		var (
+			columns, placeholders []string
+			vars                  []interface{}
+			hasIdentity           bool
+		)
+		for key, value := range record {
+			column := key
+			if model != nil {
+				field, ok := model.FieldByName(key)
+				if !ok || !field.IsNormal {
+					return fmt.Errorf("unknown column %v of fixture table %v", key, table)
+				}
+				column = field.DBName
+
+				converted, err := fixtureValue(field.StructField, value)
+				if err != nil {
+					return fmt.Errorf("invalid value of column %v of fixture table %v: %v", key, table, err)
+				}
+				value = converted
+			} else if f, ok := value.(float64); ok && f == float64(int64(f)) {
+				value = int64(f)
+			}
+
+			hasIdentity = hasIdentity || column == identity
+			columns = append(columns, column)
+			vars = append(vars, value)
+		}
+
+		// sort columns for stable statements
+		sort.Sort(fixtureColumns{columns: columns, vars: vars})
+		for i, column := range columns {
+			columns[i] = tx.NewScope(nil).Quote(column)
+			placeholders = append(placeholders, "?")
+		}
+
+		sql := fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)", quotedTable, strings.Join(columns, ","), strings.Join(placeholders, ","))
+		if dialect == "mssql" && hasIdentity {
+			sql = fmt.Sprintf("SET IDENTITY_INSERT %v ON; %v; SET IDENTITY_INSERT %v OFF", quotedTable, sql, quotedTable)
+		}
+		if err := tx.Exec(sql, vars...).Error; err != nil {
+			return err
+		}
+	}
+
+	if identity != "" && dialect == "postgres" {
+		// sequences are not advanced by inserting explicit ids
+		return tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(%v), 0) + 1, false) FROM %v",
+			tx.NewScope(nil).Quote(identity), quotedTable), table, identity).Error
+	}
+	return nil
+}
+
+// fixtureIdentity return the auto increment primary key of the model
+func fixtureIdentity(model *Scope) string {
+	if model == nil || len(model.PrimaryFields()) != 1 {
+		return ""
+	}
+
+	field := model.PrimaryField()
+	if value, ok := field.TagSettingsGet("AUTO_INCREMENT"); ok && strings.ToLower(value) == "false" {
+		return ""
+	}
+
+	switch field.Struct.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.DBName
+	}
+	return ""
+}
+
+// fixtureValue convert decoded value to the field's type
+func fixtureValue(structField *StructField, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	fieldType := structField.Struct.Type
+	if str, ok := value.(string); ok && indirectType(fieldType) == reflect.TypeOf(time.Time{}) {
+		var err error
+		for _, layout := range fixtureTimeLayouts {
+			var t time.Time
+			if t, err = time.Parse(layout, str); err == nil {
+				value = t
+				break
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	field := &Field{StructField: structField, Field: reflect.New(fieldType).Elem()}
+	if err := field.Set(value); err != nil {
+		return nil, err
+	}
+	return field.Field.Interface(), nil
+}
+
+// fixtureColumns sort columns with their vars
+type fixtureColumns struct {
+	columns []string
+	vars    []interface{}
+}
+
+func (c fixtureColumns) Len() int           { return len(c.columns) }
+func (c fixtureColumns) Less(i, j int) bool { return c.columns[i] < c.columns[j] }
+func (c fixtureColumns) Swap(i, j int) {
+	c.columns[i], c.columns[j] = c.columns[j], c.columns[i]
+	c.vars[i], c.vars[j] = c.vars[j], c.vars[i]
+}
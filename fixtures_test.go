@@ -0,0 +1,140 @@
+package gorm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type FixtureTeam struct {
+	Id   int64
+	Name string
+}
+
+type FixtureMember struct {
+	Id       int64
+	Name     string
+	JoinedAt time.Time
+	TeamId   int64
+	Team     FixtureTeam
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatalf("failed to create fixtures dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"fixture_members.json": `[
+			{"id": 1, "name": "jinzhu", "team_id": 1, "joined_at": "2020-01-02T03:04:05Z"},
+			{"Id": 2, "Name": "zhang", "TeamId": 2, "JoinedAt": "2020-02-03"}
+		]`,
+		"fixture_teams.json": `[{"id": 1, "name": "gorm"}, {"id": 2, "name": "lun"}]`,
+		"README.md":          "fixtures of teams",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write fixture, got %v", err)
+		}
+	}
+
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&FixtureMember{}, &FixtureTeam{})
+	db.AutoMigrate(&FixtureMember{}, &FixtureTeam{})
+	db.Save(&FixtureTeam{Name: "existing"})
+
+	collector := &sqlCollector{}
+	db.SetLogger(collector)
+	db.LogMode(true)
+
+	options := gorm.FixtureOptions{Models: []interface{}{&FixtureMember{}, &FixtureTeam{}}}
+	for i := 0; i < 2; i++ {
+		if err := db.LoadFixtures(dir, options).Error; err != nil {
+			t.Fatalf("failed to load fixtures, got %v", err)
+		}
+	}
+
+	var teamInserted, memberInserted int
+	for i, sql := range collector.sqls {
+		if strings.HasPrefix(sql, `INSERT INTO "fixture_teams"`) && teamInserted == 0 {
+			teamInserted = i + 1
+		} else if strings.HasPrefix(sql, `INSERT INTO "fixture_members"`) && memberInserted == 0 {
+			memberInserted = i + 1
+		}
+	}
+	if teamInserted == 0 || memberInserted == 0 || teamInserted > memberInserted {
+		t.Errorf("teams should be loaded before members referencing them, but got %v", collector.sqls)
+	}
+
+	var teams []FixtureTeam
+	if db.Order("id").Find(&teams); len(teams) != 2 || teams[0].Name != "gorm" {
+		t.Errorf("existing teams should be deleted before loading fixtures, but got %v", teams)
+	}
+
+	var member FixtureMember
+	if err := db.Preload("Team").First(&member, 2).Error; err != nil || member.Team.Name != "lun" || !member.JoinedAt.Equal(time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("member should be loaded with fields, but got %+v, %v", member, err)
+	}
+
+	newMember := FixtureMember{Name: "new", TeamId: 1}
+	if db.Save(&newMember); newMember.Id != 3 {
+		t.Errorf("identities should continue from fixtures, but got %v", newMember.Id)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "fixture_teams.json"), []byte(`[{"id": 3, "title": "unknown"}]`), 0644)
+	if err := db.LoadFixtures(dir, options).Error; err == nil || !strings.Contains(err.Error(), "title") {
+		t.Errorf("unknown columns should return error, but got %v", err)
+	}
+
+	var count int
+	if db.Model(&FixtureMember{}).Count(&count); count != 3 {
+		t.Errorf("fixtures should be loaded in a transaction, but got %v members", count)
+	}
+}
+
+func TestLoadFixturesWithImplicitCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatalf("failed to create fixtures dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "fixture_teams.json"), []byte(`[{"id": 1, "name": "gorm"}]`), 0644)
+
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	options := gorm.FixtureOptions{Models: []interface{}{&FixtureTeam{}}, Truncate: gorm.TruncateTable}
+	if err := db.LoadFixtures(dir, options).Error; err != nil {
+		t.Fatalf("failed to load fixtures, got %v", err)
+	}
+	if statements := recorder.Statements(); len(statements) < 3 || !strings.HasPrefix(statements[0].SQL, "TRUNCATE TABLE") || statements[1].SQL != "BEGIN" {
+		t.Errorf("tables should be truncated before the transaction, but got %v", statements)
+	}
+
+	recorder.Reset()
+	tx := db.Begin()
+	if err := tx.LoadFixtures(dir, options).Error; err != nil {
+		t.Fatalf("failed to load fixtures in transaction, got %v", err)
+	}
+	tx.Rollback()
+	for _, statement := range recorder.Statements() {
+		if strings.HasPrefix(statement.SQL, "TRUNCATE") || strings.HasPrefix(statement.SQL, "ALTER") {
+			t.Errorf("statements committing implicitly should not run in transactions, but got %v", statement.SQL)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextSearchConfig text search configuration of postgres used by full-text indexes and Match, e.g. "english" to match words by stems,
+// set it before migrating, indexes are used only if queries are built with the same configuration
+var TextSearchConfig = "simple"
+
+// Match build full-text search condition matching columns with the query, e.g:
+//    db.Where(gorm.Match("+gorm -beta", "title", "body")).Find(&posts)
+//    // mysql:    SELECT * FROM posts WHERE (MATCH (title,body) AGAINST ('+gorm -beta' IN BOOLEAN MODE));
+//    // postgres: SELECT * FROM posts WHERE (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')) @@ websearch_to_tsquery('simple', '+gorm -beta'));
+//
+// Columns should be indexed together with the `fulltext` tag, refer MatchRank to order records by relevance.
+// Dialects without full-text search fall back to `LIKE` matching the whole query
+func Match(query string, columns ...string) *SqlExpr {
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		switch dialect.GetName() {
+		case "mysql":
+			return Expr(fmt.Sprintf("MATCH (%v) AGAINST (? IN BOOLEAN MODE)", strings.Join(columns, ",")), query)
+		case "postgres", "cockroachdb":
+			return Expr(fmt.Sprintf("%v @@ websearch_to_tsquery(?, ?)", tsvectorSQL(columns)), TextSearchConfig, query)
+		}
+
+		var (
+			conditions []string
+			args       []interface{}
+		)
+		for _, column := range columns {
+			conditions = append(conditions, fmt.Sprintf("%v LIKE ?", column))
+			args = append(args, "%"+query+"%")
+		}
+		return Expr("("+strings.Join(conditions, " OR ")+")", args...)
+	}))
+}
+
+// MatchRank build expression of the relevance of columns to the query, to select or order records found by Match, e.g:
+//    db.Where(gorm.Match("gorm", "title", "body")).Order(gorm.Expr("? DESC", gorm.MatchRank("gorm", "title", "body"))).Find(&posts)
+//    // postgres: SELECT * FROM posts WHERE (...) ORDER BY ts_rank(to_tsvector('simple', ...), websearch_to_tsquery('simple', 'gorm')) DESC;
+//
+// It is 0 with dialects without full-text search
+func MatchRank(query string, columns ...string) *SqlExpr {
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		switch dialect.GetName() {
+		case "mysql":
+			return Expr(fmt.Sprintf("MATCH (%v) AGAINST (? IN BOOLEAN MODE)", strings.Join(columns, ",")), query)
+		case "postgres", "cockroachdb":
+			return Expr(fmt.Sprintf("ts_rank(%v, websearch_to_tsquery(?, ?))", tsvectorSQL(columns)), TextSearchConfig, query)
+		}
+		// a bind var, as constant integers are positions of columns in `ORDER BY`
+		return Expr("?", 0)
+	}))
+}
+
+// tsvectorSQL return the document of columns for postgres, e.g. `to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, ''))`,
+// the configuration is inlined, so queries match the expression of indexes
+func tsvectorSQL(columns []string) string {
+	var documents []string
+	for _, column := range columns {
+		documents = append(documents, fmt.Sprintf("coalesce(%v, '')", column))
+	}
+	return fmt.Sprintf("to_tsvector('%v', %v)", strings.Replace(TextSearchConfig, "'", "''", -1), strings.Join(documents, " || ' ' || "))
+}
+
+// addFullTextIndex create FULLTEXT index of mysql, or GIN index of the document of postgres, other dialects don't support full-text indexes
+func (scope *Scope) addFullTextIndex(indexName string, column ...string) {
+	if scope.Dialect().HasIndex(scope.TableName(), indexName) {
+		return
+	}
+
+	var columns []string
+	for _, name := range column {
+		columns = append(columns, scope.quoteIfPossible(name))
+	}
+
+	switch dialect := scope.Dialect().GetName(); dialect {
+	case "mysql":
+		scope.Raw(fmt.Sprintf("CREATE FULLTEXT INDEX %v ON %v(%v)", indexName, scope.QuotedTableName(), strings.Join(columns, ", "))).Exec()
+	case "postgres", "cockroachdb":
+		scope.Raw(fmt.Sprintf("CREATE INDEX %v ON %v USING GIN (%v)", indexName, scope.QuotedTableName(), tsvectorSQL(columns))).Exec()
+	default:
+		scope.db.print("warning", fileWithLineNum(), fmt.Sprintf("%v doesn't support full-text indexes, ignored adding full-text index %v", dialect, indexName))
+	}
+}
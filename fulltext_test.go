@@ -0,0 +1,68 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type Article struct {
+	ID    uint
+	Title string `gorm:"fulltext:idx_articles_search"`
+	Body  string `gorm:"fulltext:idx_articles_search"`
+}
+
+func TestMatch(t *testing.T) {
+	DB.DropTableIfExists(&Article{})
+	if err := DB.AutoMigrate(&Article{}).Error; err != nil {
+		t.Fatalf("full-text indexes should be ignored with sqlite, but got %v", err)
+	}
+	DB.Create(&Article{Title: "Getting started", Body: "install gorm"})
+	DB.Create(&Article{Title: "Associations", Body: "has many"})
+
+	var articles []Article
+	DB.Where(gorm.Match("gorm", "title", "body")).Order(gorm.Expr("? DESC", gorm.MatchRank("gorm", "title", "body"))).Find(&articles)
+	if len(articles) != 1 || articles[0].Title != "Getting started" {
+		t.Errorf("should match articles with LIKE on sqlite, but got %v", len(articles))
+	}
+
+	tests := []struct {
+		dialect string
+		sql     string
+		index   string
+	}{
+		{
+			"mysql",
+			"SELECT * FROM `articles` WHERE (MATCH (title,body) AGAINST (? IN BOOLEAN MODE)) ORDER BY MATCH (title,body) AGAINST (? IN BOOLEAN MODE) DESC",
+			"CREATE FULLTEXT INDEX idx_articles_search ON `articles`(`title`, `body`)",
+		},
+		{
+			"postgres",
+			`SELECT * FROM "articles" WHERE (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')) @@ websearch_to_tsquery($1, $2)) ORDER BY ts_rank(to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')), websearch_to_tsquery($3, $4)) DESC`,
+			`CREATE INDEX idx_articles_search ON "articles" USING GIN (to_tsvector('simple', coalesce("title", '') || ' ' || coalesce("body", '')))`,
+		},
+	}
+
+	for _, test := range tests {
+		db, recorder, err := gormtest.Open(test.dialect)
+		if err != nil {
+			t.Fatalf("failed to open db, got %v", err)
+		}
+
+		db.AutoMigrate(&Article{})
+		var indexed bool
+		for _, statement := range recorder.Statements() {
+			indexed = indexed || strings.TrimSpace(statement.SQL) == test.index
+		}
+		if !indexed {
+			t.Errorf("%v: full-text index should be created with %v", test.dialect, test.index)
+		}
+
+		db.Where(gorm.Match("gorm", "title", "body")).Order(gorm.Expr("? DESC", gorm.MatchRank("gorm", "title", "body"))).Find(&articles)
+		if sql := recorder.LastStatement().SQL; sql != test.sql {
+			t.Errorf("%v: expected %v, but got %v", test.dialect, test.sql, sql)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package gorm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Geometry spatial value stored as WKT, e.g. `POINT(1 2)`, it is inserted with ST_GeomFromText for MySQL and PostGIS,
+// and scanned from WKT, WKB, PostGIS's EWKB or MySQL's internal geometry format
+//    type Place struct {
+//      ID       uint
+//      Location gorm.Geometry `gorm:"type:point;srid:4326"`
+//    }
+// Tag `type` sets the geometry subtype of the column, default is `geometry`, tag `srid` sets the column's SRID
+type Geometry struct {
+	WKT  string
+	SRID int
+}
+
+// Value get value of Geometry
+func (g Geometry) Value() (driver.Value, error) {
+	if g.WKT == "" {
+		return nil, nil
+	}
+	return g.WKT, nil
+}
+
+// Scan scan value into Geometry
+func (g *Geometry) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*g = Geometry{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("failed to scan geometry value: %v", value)
+	}
+
+	if isHexString(data) {
+		decoded := make([]byte, hex.DecodedLen(len(data)))
+		if _, err := hex.Decode(decoded, data); err != nil {
+			return err
+		}
+		data = decoded
+	} else if isPrintable(string(data)) {
+		return g.parseEWKT(string(data))
+	}
+
+	if wkt, srid, n, err := readWKB(data); err == nil && n == len(data) {
+		g.WKT, g.SRID = wkt, srid
+		return nil
+	}
+
+	// MySQL's internal format is a 4 bytes SRID followed by WKB
+	if len(data) > 4 {
+		if wkt, _, n, err := readWKB(data[4:]); err == nil && n == len(data)-4 {
+			g.WKT, g.SRID = wkt, int(binary.LittleEndian.Uint32(data[:4]))
+			return nil
+		}
+	}
+	return errors.New("failed to scan geometry value: invalid WKB")
+}
+
+// GormValue wrap value with ST_GeomFromText for databases having spatial extensions
+func (g Geometry) GormValue(dialect Dialect) *SqlExpr {
+	if g.WKT == "" || !isSpatialDialect(dialect) {
+		return nil
+	}
+	return Expr("ST_GeomFromText(?, ?)", g.WKT, g.SRID)
+}
+
+func (g *Geometry) parseEWKT(ewkt string) error {
+	g.WKT, g.SRID = strings.TrimSpace(ewkt), 0
+	if strings.HasPrefix(strings.ToUpper(g.WKT), "SRID=") {
+		parts := strings.SplitN(g.WKT, ";", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("failed to scan geometry value: invalid EWKT %v", ewkt)
+		}
+		srid, err := strconv.Atoi(parts[0][len("SRID="):])
+		if err != nil {
+			return err
+		}
+		g.WKT, g.SRID = parts[1], srid
+	}
+	return nil
+}
+
+// STContains build condition `ST_Contains(column, geometry)`
+func STContains(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("ST_Contains(%v, ST_GeomFromText(?, ?))", column), geometry.WKT, geometry.SRID)
+}
+
+// STWithin build condition `ST_Within(column, geometry)`
+func STWithin(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("ST_Within(%v, ST_GeomFromText(?, ?))", column), geometry.WKT, geometry.SRID)
+}
+
+// STIntersects build condition `ST_Intersects(column, geometry)`
+func STIntersects(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("ST_Intersects(%v, ST_GeomFromText(?, ?))", column), geometry.WKT, geometry.SRID)
+}
+
+// STDistance build expression `ST_Distance(column, geometry)`, could be used in select or order
+//    db.Order(gorm.STDistance("location", point)).Find(&places)
+func STDistance(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("ST_Distance(%v, ST_GeomFromText(?, ?))", column), geometry.WKT, geometry.SRID)
+}
+
+// STBBoxIntersects build PostGIS condition `column && geometry`, which uses the spatial index to compare bounding boxes
+func STBBoxIntersects(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("%v && ST_GeomFromText(?, ?)", column), geometry.WKT, geometry.SRID)
+}
+
+// STKNNDistance build PostGIS expression `column <-> geometry` for index assisted nearest neighbour ordering
+//    db.Order(gorm.STKNNDistance("location", point)).Limit(10).Find(&places)
+func STKNNDistance(column string, geometry Geometry) *SqlExpr {
+	return Expr(fmt.Sprintf("%v <-> ST_GeomFromText(?, ?)", column), geometry.WKT, geometry.SRID)
+}
+
+var geometryType = reflect.TypeOf(Geometry{})
+
+func isSpatialDialect(dialect Dialect) bool {
+	switch dialect.GetName() {
+	case "mysql", "tidb", "postgres", "cockroachdb":
+		return true
+	}
+	return false
+}
+
+// spatialDataTypeOf return column type for Geometry fields
+func spatialDataTypeOf(dialect Dialect, field *StructField) string {
+	subtype := "geometry"
+	if value, ok := field.TagSettingsGet("TYPE"); ok && value != "" {
+		subtype = strings.ToLower(value)
+	}
+	srid, hasSRID := field.TagSettingsGet("SRID")
+
+	switch dialect.GetName() {
+	case "postgres", "cockroachdb":
+		if hasSRID {
+			return fmt.Sprintf("geometry(%v,%v)", strings.Title(subtype), srid)
+		} else if subtype != "geometry" {
+			return fmt.Sprintf("geometry(%v)", strings.Title(subtype))
+		}
+		return "geometry"
+	case "mysql", "tidb":
+		if hasSRID {
+			return fmt.Sprintf("%v SRID %v", strings.ToUpper(subtype), srid)
+		}
+		return strings.ToUpper(subtype)
+	}
+	return "text"
+}
+
+func isHexString(data []byte) bool {
+	if len(data) == 0 || len(data)%2 != 0 {
+		return false
+	}
+	for _, b := range data {
+		if !(b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	ewkbZFlag    = 0x80000000
+	ewkbMFlag    = 0x40000000
+	ewkbSRIDFlag = 0x20000000
+)
+
+var wkbTypeNames = map[uint32]string{
+	1: "POINT",
+	2: "LINESTRING",
+	3: "POLYGON",
+	4: "MULTIPOINT",
+	5: "MULTILINESTRING",
+	6: "MULTIPOLYGON",
+	7: "GEOMETRYCOLLECTION",
+}
+
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// readWKB convert 2D WKB or PostGIS EWKB to WKT, returns the number of bytes read
+func readWKB(data []byte) (wkt string, srid int, n int, err error) {
+	reader := &wkbReader{data: data}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid WKB: %v", r)
+		}
+	}()
+	wkt, srid, err = reader.readGeometry()
+	return wkt, srid, reader.pos, err
+}
+
+func (r *wkbReader) readUint32() uint32 {
+	v := r.order.Uint32(r.data[r.pos : r.pos+4])
+	r.pos += 4
+	return v
+}
+
+func (r *wkbReader) readPoints(count uint32) string {
+	if uint64(count)*16 > uint64(len(r.data)-r.pos) {
+		panic("unexpected end of data")
+	}
+	points := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		x := math.Float64frombits(r.order.Uint64(r.data[r.pos : r.pos+8]))
+		y := math.Float64frombits(r.order.Uint64(r.data[r.pos+8 : r.pos+16]))
+		r.pos += 16
+		points = append(points, strconv.FormatFloat(x, 'f', -1, 64)+" "+strconv.FormatFloat(y, 'f', -1, 64))
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+func (r *wkbReader) readGeometry() (wkt string, srid int, err error) {
+	switch r.data[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return "", 0, errors.New("invalid byte order")
+	}
+	r.pos++
+
+	typ := r.readUint32()
+	if typ&(ewkbZFlag|ewkbMFlag) != 0 || typ&0x0fffffff == 0 || typ&0x0fffffff > 7 {
+		return "", 0, errors.New("only 2D geometries are supported")
+	}
+	if typ&ewkbSRIDFlag != 0 {
+		srid = int(r.readUint32())
+	}
+	typ &= 0x0fffffff
+
+	var body bytes.Buffer
+	switch typ {
+	case 1:
+		body.WriteString(r.readPoints(1))
+		if body.String() == "(NaN NaN)" {
+			return "POINT EMPTY", srid, nil
+		}
+	case 2:
+		body.WriteString(r.readPoints(r.readUint32()))
+	case 3:
+		rings := r.readUint32()
+		var parts []string
+		for i := uint32(0); i < rings; i++ {
+			parts = append(parts, r.readPoints(r.readUint32()))
+		}
+		body.WriteString("(" + strings.Join(parts, ",") + ")")
+	case 4, 5, 6, 7:
+		count := r.readUint32()
+		if count == 0 {
+			return wkbTypeNames[typ] + " EMPTY", srid, nil
+		}
+		var parts []string
+		for i := uint32(0); i < count; i++ {
+			sub, _, err := r.readGeometry()
+			if err != nil {
+				return "", 0, err
+			}
+			if typ != 7 {
+				sub = sub[strings.Index(sub, "("):]
+			}
+			parts = append(parts, sub)
+		}
+		body.WriteString("(" + strings.Join(parts, ",") + ")")
+	}
+	return wkbTypeNames[typ] + body.String(), srid, nil
+}
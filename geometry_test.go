@@ -0,0 +1,89 @@
+package gorm_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type SpatialPlace struct {
+	ID       uint
+	Name     string
+	Location gorm.Geometry `gorm:"type:point;srid:4326"`
+}
+
+func pointWKB(order binary.ByteOrder, x, y float64) []byte {
+	data := make([]byte, 21)
+	if order == binary.LittleEndian {
+		data[0] = 1
+	}
+	order.PutUint32(data[1:], 1)
+	order.PutUint64(data[5:], math.Float64bits(x))
+	order.PutUint64(data[13:], math.Float64bits(y))
+	return data
+}
+
+func TestGeometryScan(t *testing.T) {
+	var geometry gorm.Geometry
+
+	if err := geometry.Scan(pointWKB(binary.LittleEndian, 1.5, -2)); err != nil || geometry.WKT != "POINT(1.5 -2)" {
+		t.Errorf("should scan WKB, but got %v, %v", geometry, err)
+	}
+
+	if err := geometry.Scan(pointWKB(binary.BigEndian, 3, 4)); err != nil || geometry.WKT != "POINT(3 4)" {
+		t.Errorf("should scan big endian WKB, but got %v, %v", geometry, err)
+	}
+
+	mysqlInternal := append([]byte{0xE6, 0x10, 0, 0}, pointWKB(binary.LittleEndian, 5, 6)...)
+	if err := geometry.Scan(mysqlInternal); err != nil || geometry.WKT != "POINT(5 6)" || geometry.SRID != 4326 {
+		t.Errorf("should scan mysql's internal format, but got %v, %v", geometry, err)
+	}
+
+	ewkb := pointWKB(binary.LittleEndian, 7, 8)
+	binary.LittleEndian.PutUint32(ewkb[1:], 1|0x20000000)
+	ewkb = append(ewkb[:5], append([]byte{0xE6, 0x10, 0, 0}, ewkb[5:]...)...)
+	if err := geometry.Scan(hex.EncodeToString(ewkb)); err != nil || geometry.WKT != "POINT(7 8)" || geometry.SRID != 4326 {
+		t.Errorf("should scan postgis's hex encoded EWKB, but got %v, %v", geometry, err)
+	}
+
+	if err := geometry.Scan("SRID=3857;LINESTRING(0 0,1 1)"); err != nil || geometry.WKT != "LINESTRING(0 0,1 1)" || geometry.SRID != 3857 {
+		t.Errorf("should scan EWKT, but got %v, %v", geometry, err)
+	}
+
+	if err := geometry.Scan([]byte{1, 1, 0, 0, 0, 1}); err == nil {
+		t.Errorf("should return error for truncated WKB")
+	}
+}
+
+func TestGeometryCreateAndQuery(t *testing.T) {
+	DB.DropTableIfExists(&SpatialPlace{})
+	if err := DB.AutoMigrate(&SpatialPlace{}).Error; err != nil {
+		t.Fatalf("should be able to migrate geometry column, but got %v", err)
+	}
+
+	place := SpatialPlace{Name: "office", Location: gorm.Geometry{WKT: "POINT(116.4 39.9)", SRID: 4326}}
+	if err := DB.Create(&place).Error; err != nil {
+		t.Fatalf("should be able to create geometry value, but got %v", err)
+	}
+
+	var result SpatialPlace
+	if err := DB.First(&result, place.ID).Error; err != nil {
+		t.Fatalf("should be able to find geometry value, but got %v", err)
+	}
+
+	if result.Location.WKT != "POINT(116.4 39.9)" {
+		t.Errorf("geometry value should be scanned, but got %v", result.Location)
+	}
+
+	if dialect := DB.Dialect().GetName(); dialect == "mysql" || dialect == "postgres" {
+		var count int
+		polygon := gorm.Geometry{WKT: "POLYGON((116 39,117 39,117 40,116 40,116 39))", SRID: 4326}
+		DB.Model(&SpatialPlace{}).Where(gorm.STWithin("location", polygon)).Count(&count)
+		if count != 1 {
+			t.Errorf("should find place within polygon")
+		}
+	}
+}
@@ -0,0 +1,344 @@
+// Package gormgen generates column constants and typed query helpers of models from their metadata parsed by gorm,
+// so queries don't refer to columns with strings, and tags are parsed exactly like they are at runtime.
+//
+// Run it with a generator program of the models' package, e.g. `models/gen/main.go` invoked by `go generate`:
+//    func main() {
+//      code, err := gormgen.Generate(gormgen.Config{Package: "models"}, &models.User{}, &models.Order{})
+//      if err != nil {
+//        log.Fatal(err)
+//      }
+//      ioutil.WriteFile("models_gen.go", code, 0644)
+//    }
+//
+// Generated helpers are used like:
+//    users, err := models.QueryUser(db).WhereNameEq("jinzhu").WhereAgeGt(18).OrderByAge(true).Find()
+//    db.Where(models.UserColumnName+" = ?", "jinzhu").Find(&users)
+package gormgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+const gormPath = "github.com/lun-zhang/gorm"
+
+// Config options of generating code
+type Config struct {
+	// Package name of the package of generated code, which should be the package of models
+	Package string
+	// DB parse models with its naming strategy, a recording db of sqlite3 is used by default
+	DB *gorm.DB
+}
+
+// Generate return the formatted code of column constants and typed query helpers of models, models should be in the package of generated code
+func Generate(config Config, models ...interface{}) ([]byte, error) {
+	if config.Package == "" {
+		return nil, fmt.Errorf("gormgen: package name is required")
+	}
+
+	db := config.DB
+	if db == nil {
+		var err error
+		if db, _, err = gormtest.Open("sqlite3"); err != nil {
+			return nil, err
+		}
+		defer db.Close()
+	}
+
+	g := generator{imports: map[string]string{gormPath: "gorm"}}
+	var data = struct {
+		Package string
+		Imports []string
+		Models  []model
+	}{Package: config.Package}
+
+	for _, value := range models {
+		metadata := db.ModelMetadata(value)
+		if metadata == nil {
+			return nil, fmt.Errorf("gormgen: %T isn't a model", value)
+		}
+		if g.pkgPath == "" {
+			g.pkgPath = metadata.Type.PkgPath()
+		} else if g.pkgPath != metadata.Type.PkgPath() {
+			return nil, fmt.Errorf("gormgen: models should be in the same package, %v isn't in %v", metadata.Type, g.pkgPath)
+		}
+		data.Models = append(data.Models, g.model(metadata))
+	}
+
+	for pkgPath, name := range g.imports {
+		if name == path.Base(pkgPath) {
+			data.Imports = append(data.Imports, fmt.Sprintf("%q", pkgPath))
+		} else {
+			data.Imports = append(data.Imports, fmt.Sprintf("%v %q", name, pkgPath))
+		}
+	}
+	sort.Strings(data.Imports)
+
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type model struct {
+	Name    string
+	Table   string
+	Columns []column
+}
+
+type column struct {
+	Name    string
+	Column  string
+	Type    string
+	Ordered bool
+	String  bool
+	Null    bool
+}
+
+type generator struct {
+	pkgPath string
+	imports map[string]string
+}
+
+func (g *generator) model(metadata *gorm.ModelMetadata) model {
+	m := model{Name: metadata.Name, Table: metadata.Table}
+	names := map[string]int{}
+	for _, field := range metadata.Fields {
+		names[field.Name]++
+	}
+
+	for _, field := range metadata.Fields {
+		if field.Column == "" || field.IsIgnored || !field.IsNormal {
+			continue
+		}
+
+		typ, ok := g.typeString(field.Type)
+		if !ok {
+			continue
+		}
+
+		indirectType := field.Type
+		for indirectType.Kind() == reflect.Ptr {
+			indirectType = indirectType.Elem()
+		}
+		// fields of embedded structs are named by their paths if their names are duplicated, like `BillingStreet`
+		name := field.Name
+		if names[field.Name] > 1 {
+			name = strings.Replace(field.Path, ".", "", -1)
+		}
+		c := column{
+			Name:   name,
+			Column: field.Column,
+			Type:   typ,
+			Null:   field.Type.Kind() == reflect.Ptr,
+			String: indirectType.Kind() == reflect.String,
+		}
+		switch indirectType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.String:
+			c.Ordered = true
+		case reflect.Struct:
+			c.Ordered = indirectType == reflect.TypeOf(time.Time{})
+		}
+		m.Columns = append(m.Columns, c)
+	}
+	return m
+}
+
+// typeString return the type expression in generated code, types which can't be referred from the package are skipped
+func (g *generator) typeString(typ reflect.Type) (string, bool) {
+	if typ.Name() != "" {
+		if typ.PkgPath() == "" || typ.PkgPath() == g.pkgPath {
+			return typ.Name(), true
+		}
+		if !ast.IsExported(typ.Name()) {
+			return "", false
+		}
+		return g.importName(typ.PkgPath()) + "." + typ.Name(), true
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, ok := g.typeString(typ.Elem())
+		return "*" + elem, ok
+	case reflect.Slice:
+		elem, ok := g.typeString(typ.Elem())
+		return "[]" + elem, ok
+	case reflect.Array:
+		elem, ok := g.typeString(typ.Elem())
+		return fmt.Sprintf("[%d]%v", typ.Len(), elem), ok
+	case reflect.Map:
+		key, ok := g.typeString(typ.Key())
+		elem, elemOK := g.typeString(typ.Elem())
+		return fmt.Sprintf("map[%v]%v", key, elem), ok && elemOK
+	}
+	return "", false
+}
+
+// importName return the name of the imported package, packages whose base names aren't identifiers or conflict with others are aliased
+func (g *generator) importName(pkgPath string) string {
+	if name, ok := g.imports[pkgPath]; ok {
+		return name
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, path.Base(pkgPath))
+	if name == "" || name[0] >= '0' && name[0] <= '9' || token.Lookup(name).IsKeyword() {
+		name = "pkg_" + name
+	}
+	for base, i := name, 2; g.hasImportName(name); i++ {
+		name = fmt.Sprintf("%v%d", base, i)
+	}
+	g.imports[pkgPath] = name
+	return name
+}
+
+func (g *generator) hasImportName(name string) bool {
+	for _, n := range g.imports {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+var codeTemplate = template.Must(template.New("gormgen").Parse(`// Code generated by gormgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{range .Imports}}	{{.}}
+{{end}})
+{{range $model := .Models}}
+// {{.Name}}Table table name of {{.Name}}
+const {{.Name}}Table = "{{.Table}}"
+
+// Column names of {{.Name}}
+const (
+{{range .Columns}}	{{$model.Name}}Column{{.Name}} = "{{.Column}}"
+{{end}})
+
+// {{.Name}}Query typed query of {{.Name}}
+type {{.Name}}Query struct {
+	DB *gorm.DB
+}
+
+// Query{{.Name}} return the typed query of {{.Name}} based on the db
+func Query{{.Name}}(db *gorm.DB) {{.Name}}Query {
+	return {{.Name}}Query{DB: db.Model(&{{.Name}}{})}
+}
+
+// Where add conditions like *gorm.DB's Where
+func (q {{.Name}}Query) Where(query interface{}, args ...interface{}) {{.Name}}Query {
+	return {{.Name}}Query{DB: q.DB.Where(query, args...)}
+}
+
+// Limit specify the number of records to be retrieved
+func (q {{.Name}}Query) Limit(limit int) {{.Name}}Query {
+	return {{.Name}}Query{DB: q.DB.Limit(limit)}
+}
+
+// Offset specify the number of records to skip before retrieving records
+func (q {{.Name}}Query) Offset(offset int) {{.Name}}Query {
+	return {{.Name}}Query{DB: q.DB.Offset(offset)}
+}
+
+// Find find records matching conditions
+func (q {{.Name}}Query) Find() ([]{{.Name}}, error) {
+	var records []{{.Name}}
+	err := q.DB.Find(&records).Error
+	return records, err
+}
+
+// First find the first record ordered by primary key matching conditions
+func (q {{.Name}}Query) First() (*{{.Name}}, error) {
+	var record {{.Name}}
+	if err := q.DB.First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Count count records matching conditions
+func (q {{.Name}}Query) Count() (int64, error) {
+	var count int64
+	err := q.DB.Count(&count).Error
+	return count, err
+}
+{{range .Columns}}
+// Where{{.Name}}Eq add condition {{.Column}} = value
+func (q {{$model.Name}}Query) Where{{.Name}}Eq(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} = ?", value)}
+}
+
+// Where{{.Name}}Ne add condition {{.Column}} <> value
+func (q {{$model.Name}}Query) Where{{.Name}}Ne(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} <> ?", value)}
+}
+
+// Where{{.Name}}In add condition {{.Column}} IN values
+func (q {{$model.Name}}Query) Where{{.Name}}In(values ...{{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} IN (?)", values)}
+}
+{{if .Ordered}}
+// Where{{.Name}}Gt add condition {{.Column}} > value
+func (q {{$model.Name}}Query) Where{{.Name}}Gt(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} > ?", value)}
+}
+
+// Where{{.Name}}Gte add condition {{.Column}} >= value
+func (q {{$model.Name}}Query) Where{{.Name}}Gte(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} >= ?", value)}
+}
+
+// Where{{.Name}}Lt add condition {{.Column}} < value
+func (q {{$model.Name}}Query) Where{{.Name}}Lt(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} < ?", value)}
+}
+
+// Where{{.Name}}Lte add condition {{.Column}} <= value
+func (q {{$model.Name}}Query) Where{{.Name}}Lte(value {{.Type}}) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} <= ?", value)}
+}
+{{end}}{{if .String}}
+// Where{{.Name}}Like add condition {{.Column}} LIKE pattern
+func (q {{$model.Name}}Query) Where{{.Name}}Like(pattern string) {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} LIKE ?", pattern)}
+}
+{{end}}{{if .Null}}
+// Where{{.Name}}IsNull add condition {{.Column}} IS NULL
+func (q {{$model.Name}}Query) Where{{.Name}}IsNull() {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} IS NULL")}
+}
+
+// Where{{.Name}}IsNotNull add condition {{.Column}} IS NOT NULL
+func (q {{$model.Name}}Query) Where{{.Name}}IsNotNull() {{$model.Name}}Query {
+	return {{$model.Name}}Query{DB: q.DB.Where("{{.Column}} IS NOT NULL")}
+}
+{{end}}
+// OrderBy{{.Name}} order records by {{.Column}}
+func (q {{$model.Name}}Query) OrderBy{{.Name}}(desc bool) {{$model.Name}}Query {
+	if desc {
+		return {{$model.Name}}Query{DB: q.DB.Order("{{.Column}} DESC")}
+	}
+	return {{$model.Name}}Query{DB: q.DB.Order("{{.Column}}")}
+}
+{{end}}{{end}}`))
@@ -0,0 +1,88 @@
+package gormgen_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormgen"
+)
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type User struct {
+	ID        uint
+	Name      string `gorm:"column:user_name"`
+	Age       int
+	Birthday  *time.Time
+	Flags     gorm.Bits
+	Home      Address `gorm:"embedded;embedded_prefix:home_"`
+	Work      Address `gorm:"embedded;embedded_prefix:work_"`
+	Orders    []Order
+	Ignored   string `gorm:"-"`
+	CreatedAt time.Time
+}
+
+type Order struct {
+	ID     uint
+	UserID uint
+	Amount float64
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := gormgen.Generate(gormgen.Config{Package: "models"}, &User{}, &Order{})
+	if err != nil {
+		t.Fatalf("failed to generate code, got %v", err)
+	}
+
+	// constants are aligned by gofmt, compare code with spaces collapsed
+	source := strings.Join(strings.Fields(string(code)), " ")
+	for _, expected := range []string{
+		"package models",
+		`"github.com/lun-zhang/gorm"`,
+		`"time"`,
+		`UserTable = "users"`,
+		`UserColumnName = "user_name"`,
+		`UserColumnHomeStreet = "home_street"`,
+		`UserColumnWorkCity = "work_city"`,
+		`OrderColumnAmount = "amount"`,
+		"func QueryUser(db *gorm.DB) UserQuery {",
+		"func (q UserQuery) WhereNameEq(value string) UserQuery {",
+		`q.DB.Where("user_name = ?", value)`,
+		"func (q UserQuery) WhereNameLike(pattern string) UserQuery {",
+		"func (q UserQuery) WhereAgeIn(values ...int) UserQuery {",
+		"func (q UserQuery) WhereBirthdayIsNull() UserQuery {",
+		"func (q UserQuery) WhereCreatedAtGt(value time.Time) UserQuery {",
+		"func (q UserQuery) WhereFlagsEq(value gorm.Bits) UserQuery {",
+		"func (q OrderQuery) WhereAmountLte(value float64) OrderQuery {",
+		"func (q OrderQuery) OrderByUserID(desc bool) OrderQuery {",
+	} {
+		if !strings.Contains(source, strings.Join(strings.Fields(expected), " ")) {
+			t.Errorf("generated code should contain %q, got\n%s", expected, code)
+		}
+	}
+
+	for _, unexpected := range []string{"Orders", "Ignored", "WhereAgeLike", "WhereNameIsNull"} {
+		if strings.Contains(source, unexpected) {
+			t.Errorf("generated code shouldn't contain %q", unexpected)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := gormgen.Generate(gormgen.Config{}, &User{}); err == nil {
+		t.Errorf("package name should be required")
+	}
+
+	if _, err := gormgen.Generate(gormgen.Config{Package: "models"}, "users"); err == nil {
+		t.Errorf("non-struct values should be rejected")
+	}
+
+	if _, err := gormgen.Generate(gormgen.Config{Package: "models"}, &User{}, &gorm.Model{}); err == nil {
+		t.Errorf("models of different packages should be rejected")
+	}
+}
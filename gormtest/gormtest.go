@@ -0,0 +1,271 @@
+// Package gormtest provides a db recording statements instead of executing them, so unit tests could assert statements
+// generated by gorm and return canned rows without a real database, e.g:
+//    db, recorder, err := gormtest.Open("postgres")
+//    recorder.Reply(`SELECT * FROM "users" WHERE ("users"."id" = $1) ORDER BY "users"."id" ASC LIMIT 1`, []string{"id", "name"}, []interface{}{1, "jinzhu"})
+//
+//    db.First(&user, 1)
+//    // user.Name == "jinzhu"
+//    recorder.LastStatement()
+//    // gormtest.Statement{SQL: `SELECT * FROM "users" WHERE ("users"."id" = $1) ORDER BY "users"."id" ASC LIMIT 1`, Vars: []interface{}{int64(1)}}
+package gormtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lun-zhang/gorm"
+)
+
+const driverName = "gormtest"
+
+var (
+	recorders sync.Map
+	sequence  int64
+)
+
+func init() {
+	sql.Register(driverName, fakeDriver{})
+}
+
+// Statement statement received by the db, whitespaces of SQL are collapsed, vars are converted to driver values
+type Statement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// Recorder records statements of the db and replies canned results, it is safe for concurrent use
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	replies    map[string][]reply
+}
+
+type reply struct {
+	columns      []string
+	rows         [][]driver.Value
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+}
+
+// Open return a db of the dialect recording statements, like `postgres`, `mysql` and `sqlite3`, and its recorder
+func Open(dialect string) (*gorm.DB, *Recorder, error) {
+	recorder := &Recorder{replies: map[string][]reply{}}
+	name := fmt.Sprintf("recorder-%d", atomic.AddInt64(&sequence, 1))
+	recorders.Store(name, recorder)
+
+	sqlDB, err := sql.Open(driverName, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := gorm.Open(dialect, sqlDB)
+	if err != nil {
+		return nil, nil, err
+	}
+	// forget statements detecting the database when opening
+	recorder.Reset()
+	return db, recorder, nil
+}
+
+// Reply return the rows when querying the SQL next time, replies of the same SQL are returned in order, queries without replies return no rows
+func (recorder *Recorder) Reply(sql string, columns []string, rows ...[]interface{}) {
+	values := make([][]driver.Value, len(rows))
+	for i, row := range rows {
+		values[i] = make([]driver.Value, len(row))
+		for j, value := range row {
+			v, err := driver.DefaultParameterConverter.ConvertValue(value)
+			if err != nil {
+				panic(fmt.Sprintf("gormtest: invalid value %v of column %v: %v", value, columns[j], err))
+			}
+			values[i][j] = v
+		}
+	}
+	recorder.addReply(sql, reply{columns: columns, rows: values})
+}
+
+// ReplyExec return the result when executing the SQL next time, executions without replies affect 1 row
+func (recorder *Recorder) ReplyExec(sql string, lastInsertID, rowsAffected int64) {
+	recorder.addReply(sql, reply{lastInsertID: lastInsertID, rowsAffected: rowsAffected})
+}
+
+// ReplyError return the error when querying or executing the SQL next time
+func (recorder *Recorder) ReplyError(sql string, err error) {
+	recorder.addReply(sql, reply{err: err})
+}
+
+// Statements return recorded statements
+func (recorder *Recorder) Statements() []Statement {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return append([]Statement(nil), recorder.statements...)
+}
+
+// LastStatement return the last recorded statement, it is blank if there are no statements
+func (recorder *Recorder) LastStatement() Statement {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.statements) == 0 {
+		return Statement{}
+	}
+	return recorder.statements[len(recorder.statements)-1]
+}
+
+// Reset forget recorded statements and replies
+func (recorder *Recorder) Reset() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.statements = nil
+	recorder.replies = map[string][]reply{}
+}
+
+func (recorder *Recorder) addReply(sql string, r reply) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	sql = normalize(sql)
+	recorder.replies[sql] = append(recorder.replies[sql], r)
+}
+
+// record record the statement and return its reply
+func (recorder *Recorder) record(query string, args []driver.Value) (reply, bool) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	statement := Statement{SQL: normalize(query)}
+	for _, arg := range args {
+		statement.Vars = append(statement.Vars, arg)
+	}
+	recorder.statements = append(recorder.statements, statement)
+
+	replies := recorder.replies[statement.SQL]
+	if len(replies) == 0 {
+		return reply{}, false
+	}
+	recorder.replies[statement.SQL] = replies[1:]
+	return replies[0], true
+}
+
+// normalize collapse whitespaces of the SQL
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	recorder, ok := recorders.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("gormtest: unknown recorder %v", name)
+	}
+	return &fakeConn{recorder: recorder.(*Recorder)}, nil
+}
+
+type fakeConn struct {
+	recorder *Recorder
+}
+
+func (conn *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: conn, query: query}, nil
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	conn.recorder.record("BEGIN", nil)
+	return fakeTx{conn: conn}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx fakeTx) Commit() error {
+	tx.conn.recorder.record("COMMIT", nil)
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.conn.recorder.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (stmt *fakeStmt) Close() error {
+	return nil
+}
+
+// NumInput return -1, so args are not checked
+func (stmt *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (stmt *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	r, ok := stmt.conn.recorder.record(stmt.query, args)
+	if !ok {
+		r.rowsAffected = 1
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return fakeResult{lastInsertID: r.lastInsertID, rowsAffected: r.rowsAffected}, nil
+}
+
+func (stmt *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, _ := stmt.conn.recorder.record(stmt.query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &fakeRows{columns: r.columns, rows: r.rows}, nil
+}
+
+type fakeResult struct {
+	lastInsertID, rowsAffected int64
+}
+
+func (result fakeResult) LastInsertId() (int64, error) {
+	return result.lastInsertID, nil
+}
+
+func (result fakeResult) RowsAffected() (int64, error) {
+	return result.rowsAffected, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	idx     int
+}
+
+func (rows *fakeRows) Columns() []string {
+	return rows.columns
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.idx >= len(rows.rows) {
+		return io.EOF
+	}
+
+	row := rows.rows[rows.idx]
+	if len(row) != len(dest) {
+		return errors.New("gormtest: values of the replied row don't match its columns")
+	}
+	copy(dest, row)
+	rows.idx++
+	return nil
+}
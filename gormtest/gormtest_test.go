@@ -0,0 +1,65 @@
+package gormtest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type User struct {
+	Id   int64
+	Name string
+	Age  int
+}
+
+func TestRecorder(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	query := `SELECT * FROM "users" WHERE (name = $1) ORDER BY "users"."id" ASC LIMIT 1`
+	recorder.Reply(query, []string{"id", "name", "age"}, []interface{}{1, "jinzhu", 18})
+
+	var user User
+	if err := db.First(&user, "name = ?", "jinzhu").Error; err != nil || user.Name != "jinzhu" || user.Age != 18 {
+		t.Errorf("user should be found with the replied row, but got %+v, %v", user, err)
+	}
+
+	if statement := recorder.LastStatement(); !reflect.DeepEqual(statement, gormtest.Statement{SQL: query, Vars: []interface{}{"jinzhu"}}) {
+		t.Errorf("query should be recorded, but got %#v", statement)
+	}
+
+	if err := db.First(&User{}, "name = ?", "jinzhu").Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("replies should be returned once, but got %v", err)
+	}
+
+	update := `UPDATE "users" SET "name" = $1 WHERE "users"."id" = $2`
+	recorder.ReplyExec(update, 0, 0)
+	if err := db.RequireRowsAffected().Model(&user).UpdateColumn("name", "zhang").Error; err != gorm.ErrNoRowsAffected {
+		t.Errorf("replied result should be returned, but got %v", err)
+	}
+
+	if statement := recorder.Statements()[3]; !reflect.DeepEqual(statement, gormtest.Statement{SQL: update, Vars: []interface{}{"zhang", int64(1)}}) {
+		t.Errorf("update should be recorded, but got %#v", statement)
+	}
+
+	boom := errors.New("boom")
+	recorder.ReplyError(`DELETE FROM "users" WHERE "users"."id" = $1`, boom)
+	if err := db.Delete(&user).Error; err == nil || err.Error() != "boom" {
+		t.Errorf("replied error should be returned, but got %v", err)
+	}
+
+	statements := recorder.Statements()
+	if len(statements) != 8 || statements[2].SQL != "BEGIN" || statements[4].SQL != "ROLLBACK" || statements[6].SQL != `DELETE FROM "users" WHERE "users"."id" = $1` {
+		t.Errorf("statements should be recorded in order, but got %v", statements)
+	}
+
+	recorder.Reset()
+	if len(recorder.Statements()) != 0 {
+		t.Errorf("statements should be reset")
+	}
+}
@@ -0,0 +1,45 @@
+package gormtest
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+// Runner runs tests in transactions which are always rolled back, so tests are isolated without truncating tables
+type Runner struct {
+	db *gorm.DB
+}
+
+// TxRunner return a runner of tests in transactions of the db, runners of transactions run tests in savepoints, e.g. for sub-tests
+//    var runner = gormtest.TxRunner(db)
+//
+//    func TestCreateUser(t *testing.T) {
+//      runner.Run(t, func(tx *gorm.DB) {
+//        tx.Create(&User{Name: "jinzhu"})
+//
+//        t.Run("orders", func(t *testing.T) {
+//          gormtest.TxRunner(tx).Run(t, func(tx *gorm.DB) {
+//            tx.Create(&Order{UserName: "jinzhu"}) // rolled back after the sub-test
+//          })
+//        })
+//      })
+//    }
+func TxRunner(db *gorm.DB) *Runner {
+	return &Runner{db: db}
+}
+
+// Run begin a transaction, or a savepoint if the db is in a transaction, run fc with it, then roll it back
+func (runner *Runner) Run(t testing.TB, fc func(tx *gorm.DB)) {
+	tx := runner.db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin transaction, got %v", tx.Error)
+	}
+	defer func() {
+		if err := tx.Rollback().Error; err != nil {
+			t.Errorf("failed to roll back transaction, got %v", err)
+		}
+	}()
+
+	fc(tx)
+}
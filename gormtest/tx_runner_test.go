@@ -0,0 +1,53 @@
+package gormtest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	_ "github.com/lun-zhang/gorm/dialects/sqlite"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestTxRunner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gormtest")
+	if err != nil {
+		t.Fatalf("failed to create dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "gormtest.db"))
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+	db.AutoMigrate(&User{})
+
+	count := func(db *gorm.DB) (count int) {
+		db.Model(&User{}).Count(&count)
+		return
+	}
+
+	gormtest.TxRunner(db).Run(t, func(tx *gorm.DB) {
+		tx.Create(&User{Name: "outer"})
+
+		t.Run("nested", func(t *testing.T) {
+			gormtest.TxRunner(tx).Run(t, func(tx *gorm.DB) {
+				tx.Create(&User{Name: "nested"})
+				if count(tx) != 2 {
+					t.Errorf("users of outer and nested transactions should be found, but got %v", count(tx))
+				}
+			})
+		})
+
+		if count(tx) != 1 {
+			t.Errorf("users of the nested transaction should be rolled back, but got %v", count(tx))
+		}
+	})
+
+	if count(db) != 0 {
+		t.Errorf("users should be rolled back after the test, but got %v", count(db))
+	}
+}
@@ -0,0 +1,78 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// NodeStatus health status of a database node
+type NodeStatus struct {
+	// Role master or slave
+	Role      string
+	Reachable bool
+	// Error error of pinging the node
+	Error           error
+	Latency         time.Duration
+	OpenConnections int
+	InUse           int
+	// ReplicaLag replication lag of slave, nil if the node is not a replica or the dialect can't query it
+	ReplicaLag *time.Duration
+}
+
+// HealthStatus health status of master and slaves
+type HealthStatus []NodeStatus
+
+// Healthy return true if all nodes are reachable
+func (status HealthStatus) Healthy() bool {
+	for _, node := range status {
+		if !node.Reachable {
+			return false
+		}
+	}
+	return len(status) > 0
+}
+
+// HealthCheck ping master and slaves, and report their connections and replication lag, e.g:
+//    if status := db.HealthCheck(ctx); !status.Healthy() {
+//      log.Printf("database is unhealthy: %+v", status)
+//    }
+func (s *DB) HealthCheck(ctx context.Context) HealthStatus {
+	status := HealthStatus{s.checkNode(ctx, "master", s.parent.db.dbSQL)}
+	if s.parent.db.dbSQLSlave != nil {
+		status = append(status, s.checkNode(ctx, "slave", s.parent.db.dbSQLSlave))
+	}
+	return status
+}
+
+// checkNode ping the node and collect its status
+func (s *DB) checkNode(ctx context.Context, role string, dbSQL SQLCommon) NodeStatus {
+	status := NodeStatus{Role: role}
+
+	pinger, ok := dbSQL.(interface {
+		PingContext(ctx context.Context) error
+	})
+	if !ok {
+		status.Error = errors.New("database connection doesn't support ping")
+		return status
+	}
+
+	start := time.Now()
+	status.Error = pinger.PingContext(ctx)
+	status.Latency = time.Since(start)
+	status.Reachable = status.Error == nil
+
+	if db, ok := dbSQL.(*sql.DB); ok {
+		stats := db.Stats()
+		status.OpenConnections = stats.OpenConnections
+		status.InUse = stats.InUse
+
+		if reporter, ok := s.parent.dialect.(replicaLagReporter); ok && status.Reachable {
+			if lag, ok, err := reporter.ReplicaLag(ctx, db); ok && err == nil {
+				status.ReplicaLag = &lag
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,135 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	hintOptimizer   = "optimizer"
+	hintUseIndex    = "USE"
+	hintForceIndex  = "FORCE"
+	hintIgnoreIndex = "IGNORE"
+)
+
+// Hint optimizer or index hint of queries, create it with UseIndex, ForceIndex, IgnoreIndex, MaxExecutionTime or OptimizerHint
+type Hint struct {
+	kind   string
+	values []string
+}
+
+// UseIndex hint the query to use one of the indexes of the table
+func UseIndex(names ...string) Hint {
+	return Hint{kind: hintUseIndex, values: names}
+}
+
+// ForceIndex force the query to use one of the indexes of the table
+func ForceIndex(names ...string) Hint {
+	return Hint{kind: hintForceIndex, values: names}
+}
+
+// IgnoreIndex hint the query not to use the indexes of the table
+func IgnoreIndex(names ...string) Hint {
+	return Hint{kind: hintIgnoreIndex, values: names}
+}
+
+// MaxExecutionTime hint the query to be cancelled after running for milliseconds
+func MaxExecutionTime(ms int) Hint {
+	return OptimizerHint(fmt.Sprintf("MAX_EXECUTION_TIME(%d)", ms))
+}
+
+// OptimizerHint optimizer hint written in the `/*+ ... */` comment after SELECT, e.g. `SET_VAR(sort_buffer_size = 16M)`
+func OptimizerHint(hint string) Hint {
+	return Hint{kind: hintOptimizer, values: []string{hint}}
+}
+
+// Hints add optimizer and index hints to queries, hints are written in the form of the dialect, e.g:
+//    db.Hints(gorm.UseIndex("idx_users_email"), gorm.MaxExecutionTime(500)).Where("email = ?", email).First(&user)
+//    // mysql:    SELECT /*+ MAX_EXECUTION_TIME(500) */ * FROM `users` USE INDEX (`idx_users_email`) WHERE ...
+//    // postgres: SELECT /*+ IndexScan(users idx_users_email) MAX_EXECUTION_TIME(500) */ * FROM "users" WHERE ...
+//
+// Index hints are USE/FORCE/IGNORE INDEX for mysql, WITH (INDEX(...)) for mssql, INDEXED BY for sqlite, table@index for cockroachdb
+// and IndexScan of pg_hint_plan for postgres, hints not supported by the dialect return error.
+// Optimizer hints are written in the comment after SELECT for all dialects, databases not supporting them ignore it as a comment
+func (s *DB) Hints(hints ...Hint) *DB {
+	clone := s.clone()
+	clone.search.hints = append(append([]Hint{}, clone.search.hints...), hints...)
+	return clone
+}
+
+// optimizerHintsSQL return the comment of optimizer hints written after SELECT
+func (scope *Scope) optimizerHintsSQL() string {
+	var hints []string
+	for _, hint := range scope.Search.hints {
+		switch {
+		case hint.kind == hintOptimizer:
+			hints = append(hints, hint.values...)
+		case scope.Dialect().GetName() == "postgres":
+			// hints of pg_hint_plan
+			if hint.kind == hintIgnoreIndex {
+				hints = append(hints, fmt.Sprintf("NoIndexScan(%v)", scope.TableName()))
+			} else {
+				hints = append(hints, fmt.Sprintf("IndexScan(%v)", strings.Join(append([]string{scope.TableName()}, hint.values...), " ")))
+			}
+		}
+	}
+
+	if len(hints) == 0 {
+		return ""
+	}
+	return "/*+ " + strings.Join(hints, " ") + " */ "
+}
+
+// tableHintsSQL return index hints written after the table
+func (scope *Scope) tableHintsSQL() string {
+	var sqls, tableHints []string
+	for _, hint := range scope.Search.hints {
+		if hint.kind == hintOptimizer {
+			continue
+		}
+
+		var quotedNames []string
+		for _, name := range hint.values {
+			quotedNames = append(quotedNames, scope.Quote(name))
+		}
+
+		switch dialect := scope.Dialect().GetName(); dialect {
+		case "mysql", "tidb":
+			sqls = append(sqls, fmt.Sprintf(" %v INDEX (%v)", hint.kind, strings.Join(quotedNames, ", ")))
+		case "mssql":
+			if hint.kind == hintIgnoreIndex {
+				scope.Err(fmt.Errorf("IGNORE INDEX isn't supported by %v", dialect))
+				continue
+			}
+			tableHints = append(tableHints, fmt.Sprintf("INDEX(%v)", strings.Join(quotedNames, ", ")))
+		case "sqlite3", "cockroachdb":
+			if hint.kind == hintIgnoreIndex {
+				if dialect == "sqlite3" && len(hint.values) == 0 {
+					sqls = append(sqls, " NOT INDEXED")
+					continue
+				}
+				scope.Err(fmt.Errorf("IGNORE INDEX of specific indexes isn't supported by %v", dialect))
+				continue
+			}
+			if len(quotedNames) != 1 {
+				scope.Err(fmt.Errorf("only one index could be hinted for %v", dialect))
+				continue
+			}
+			if dialect == "sqlite3" {
+				sqls = append(sqls, " INDEXED BY "+quotedNames[0])
+			} else {
+				sqls = append(sqls, "@"+quotedNames[0])
+			}
+		case "postgres":
+			// written as optimizer hints
+		default:
+			scope.Err(fmt.Errorf("index hints aren't supported by %v", dialect))
+		}
+	}
+
+	if len(tableHints) > 0 {
+		// table hints of mssql
+		sqls = append(sqls, fmt.Sprintf(" WITH (%v)", strings.Join(tableHints, ", ")))
+	}
+	return strings.Join(sqls, "")
+}
@@ -0,0 +1,55 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestHints(t *testing.T) {
+	cases := map[string]string{
+		"mysql":       "SELECT /*+ MAX_EXECUTION_TIME(500) */ * FROM `users` USE INDEX (`idx_users_email`) FORCE INDEX (`idx_users_name`, `idx_users_age`) WHERE (name = ?)",
+		"postgres":    `SELECT /*+ IndexScan(users idx_users_email) IndexScan(users idx_users_name idx_users_age) MAX_EXECUTION_TIME(500) */ * FROM "users" WHERE (name = $1)`,
+		"mssql":       "SELECT /*+ MAX_EXECUTION_TIME(500) */ * FROM [users] WITH (INDEX([idx_users_email]), INDEX([idx_users_name], [idx_users_age])) WHERE (name = ?)",
+		"cockroachdb": "",
+	}
+
+	for dialect, sql := range cases {
+		db, recorder, err := gormtest.Open(dialect)
+		if err != nil {
+			t.Fatalf("failed to open %v, got %v", dialect, err)
+		}
+
+		err = db.Hints(gorm.UseIndex("idx_users_email"), gorm.ForceIndex("idx_users_name", "idx_users_age"), gorm.MaxExecutionTime(500)).
+			Where("name = ?", "hints").Find(&[]User{}).Error
+		if sql == "" {
+			if err == nil {
+				t.Errorf("hints not supported by %v should return error", dialect)
+			}
+			continue
+		}
+		if err != nil || recorder.LastStatement().SQL != sql {
+			t.Errorf("hints should be written in the form of %v, but got %v, %v", dialect, recorder.LastStatement().SQL, err)
+		}
+	}
+}
+
+func TestHintsWithSqlite(t *testing.T) {
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" {
+		t.Skip("INDEXED BY is only supported by sqlite")
+	}
+
+	DB.Model(&User{}).AddIndex("idx_users_hints", "name")
+	defer DB.Model(&User{}).RemoveIndex("idx_users_hints")
+
+	DB.Save(&User{Name: "hints"})
+	var users []User
+	if err := DB.Hints(gorm.UseIndex("idx_users_hints")).Where("name = ?", "hints").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("users should be found with the index, but got %v, %v", users, err)
+	}
+
+	if err := DB.Hints(gorm.UseIndex("idx_users_missing")).Where("name = ?", "hints").Find(&users).Error; err == nil {
+		t.Errorf("sqlite should return error for missing index")
+	}
+}
@@ -0,0 +1,124 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueryInvoker send the statement to database, result is sql.Result for Exec, *sql.Rows for Query and *sql.Row for QueryRow
+type QueryInvoker func(ctx context.Context, query string, args []interface{}) (interface{}, error)
+
+// QueryInterceptor wrap statements sent to database, including raw SQL of Exec and Raw, call next to continue executing it, e.g:
+//    db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+//      if chaos.ShouldFail(ctx) {
+//        return nil, errors.New("injected failure")
+//      }
+//      return next(ctx, "/* service:api */ "+query, args)
+//    })
+type QueryInterceptor func(ctx context.Context, query string, args []interface{}, next QueryInvoker) (interface{}, error)
+
+// AddQueryInterceptor add interceptor wrapping statements, interceptors added first are called first,
+// interceptors are shared by DBs opened together, they could be added while the db is in use
+func (s *DB) AddQueryInterceptor(interceptor QueryInterceptor) *DB {
+	s.db.interceptors.add(interceptor)
+	return s
+}
+
+// queryInterceptors interceptors shared by DBs opened together, they are copied when adding interceptors,
+// so statements read them without locking
+type queryInterceptors struct {
+	mutex sync.Mutex
+	value atomic.Value
+}
+
+func (interceptors *queryInterceptors) add(interceptor QueryInterceptor) {
+	interceptors.mutex.Lock()
+	defer interceptors.mutex.Unlock()
+
+	current := interceptors.load()
+	value := make([]QueryInterceptor, len(current), len(current)+1)
+	copy(value, current)
+	interceptors.value.Store(append(value, interceptor))
+}
+
+func (interceptors *queryInterceptors) load() []QueryInterceptor {
+	if interceptors == nil {
+		return nil
+	}
+	value, _ := interceptors.value.Load().([]QueryInterceptor)
+	return value
+}
+
+// intercept call interceptors in order, and the invoker at last
+func (db ctxDB) intercept(query string, args []interface{}, invoker QueryInvoker) (interface{}, error) {
+	interceptors := db.interceptors.load()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, query string, args []interface{}) (interface{}, error) {
+			return interceptor(ctx, query, args, next)
+		}
+	}
+
+	ctx := db.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return invoker(ctx, query, args)
+}
+
+// errorRow return a row whose Scan returns err, as *sql.Row can't be created with an error,
+// query it with a done context returning err, so no connection will be used and no statement will be sent to database,
+// errorRowDB is used as connections like wrappers of SQLCommon may not support QueryRowContext
+func (db ctxDB) errorRow(err error) *sql.Row {
+	return errorRowDB.QueryRowContext(errorContext{Context: context.Background(), err: err}, "")
+}
+
+// errorRowDB a database never connected, it is only queried with done contexts by errorRow
+var errorRowDB = sql.OpenDB(errorConnector{})
+
+type errorConnector struct{}
+
+func (errorConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("gorm: error rows can't be connected")
+}
+
+func (connector errorConnector) Driver() driver.Driver {
+	return errorDriver{connector}
+}
+
+type errorDriver struct {
+	connector errorConnector
+}
+
+func (d errorDriver) Open(string) (driver.Conn, error) {
+	return d.connector.Connect(context.Background())
+}
+
+var closedChan = make(chan struct{})
+
+func init() {
+	close(closedChan)
+}
+
+// errorContext a context done with err
+type errorContext struct {
+	context.Context
+	err error
+}
+
+func (ctx errorContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (ctx errorContext) Done() <-chan struct{} {
+	return closedChan
+}
+
+func (ctx errorContext) Err() error {
+	return ctx.err
+}
@@ -0,0 +1,110 @@
+package gorm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestQueryInterceptors(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var calls []string
+	failure := errors.New("injected failure")
+	db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+		calls = append(calls, "first")
+		if strings.Contains(query, "intercepted_failure") {
+			return nil, failure
+		}
+		return next(ctx, query, args)
+	})
+	db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+		calls = append(calls, "second")
+		return next(ctx, strings.Replace(query, "intercepted_name", "name", -1), args)
+	})
+
+	user := User{Name: "query_interceptor", Age: 20}
+	db.Save(&user)
+	if len(calls) == 0 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Interceptors should be called in order, but got %v", calls)
+	}
+
+	var count int
+	if db.Model(&User{}).Where("intercepted_name = ?", "query_interceptor").Count(&count); count != 1 {
+		t.Errorf("Should query with rewritten statement, but got %v", count)
+	}
+
+	var users []User
+	if err := db.Raw("SELECT * FROM users WHERE intercepted_name = ?", "query_interceptor").Scan(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Raw SQL should be intercepted too, but got %v, %v", err, len(users))
+	}
+
+	if err := db.Exec("UPDATE users SET age = 1 WHERE intercepted_failure = 1").Error; err != failure {
+		t.Errorf("Should get error from interceptor when executing, but got %v", err)
+	}
+
+	if err := db.Table("users").Where("intercepted_failure = 1").Count(&count).Error; err != failure {
+		t.Errorf("Should get error from interceptor when querying row, but got %v", err)
+	}
+}
+
+func TestQueryInterceptorsOfWrappedConnection(t *testing.T) {
+	db, err := gorm.Open(DB.Dialect().GetName(), struct{ gorm.SQLCommon }{DB.CommonDB()})
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+
+	failure := errors.New("injected failure")
+	db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+		return nil, failure
+	})
+
+	var count int
+	if err := db.Model(&User{}).Count(&count).Error; err != failure {
+		t.Errorf("Should get error from interceptor when querying row of wrapped connection, but got %v", err)
+	}
+}
+
+func TestAddQueryInterceptorsConcurrently(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+				return next(ctx, query, args)
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			var count int
+			db.Model(&User{}).Count(&count)
+		}()
+	}
+	wg.Wait()
+
+	var calls int32
+	db.AddQueryInterceptor(func(ctx context.Context, query string, args []interface{}, next gorm.QueryInvoker) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return next(ctx, query, args)
+	})
+	var count int
+	if db.Model(&User{}).Count(&count); atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Interceptor added after others should be called once, but got %v calls", calls)
+	}
+}
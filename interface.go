@@ -13,6 +13,15 @@ type SQLCommon interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
+type sqlExecContext interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type sqlQueryContext interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type sqlDb interface {
 	Begin() (*sql.Tx, error)
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
@@ -22,3 +31,129 @@ type sqlTx interface {
 	Commit() error
 	Rollback() error
 }
+
+// GormDataTypeInterface implement it to declare the column type of custom types for each dialect, it is used by migrations,
+// the `type` tag of fields overrides it, e.g:
+//    func (Point) GormDataType(dialect gorm.Dialect) string {
+//      if dialect.GetName() == "mysql" {
+//        return "POINT"
+//      }
+//      return "text"
+//    }
+type GormDataTypeInterface interface {
+	GormDataType(Dialect) string
+}
+
+// GormValuerInterface implement it to declare the SQL expression of custom values when they are written or used in conditions,
+// return nil to write the value as a normal arg, implement it with a value receiver as values of fields are passed by value, e.g:
+//    func (p Point) GormValue(dialect gorm.Dialect) *gorm.SqlExpr {
+//      return gorm.Expr("ST_GeomFromText(?)", fmt.Sprintf("POINT(%v %v)", p.X, p.Y))
+//    }
+type GormValuerInterface interface {
+	GormValue(Dialect) *SqlExpr
+}
+
+// Model hooks, implement them to run code before or after creating, updating, deleting or querying records, e.g:
+//    func (user *User) BeforeCreate(tx *gorm.DB) error {
+//      return tx.Create(&AuditLog{Action: "create user"}).Error
+//    }
+//
+// tx is a new *DB running inside the transaction of current statement, returning an error from any hook aborts the statement
+// and rolls back its transaction, hooks without arguments or receiving *Scope, and hooks without returning error are also supported.
+//
+// Hooks are invoked by default callbacks, so callbacks registered with Before/After could run around them:
+//    Create: gorm:begin_transaction, BeforeSave, BeforeCreate (gorm:before_create), gorm:save_before_associations, gorm:create,
+//            gorm:save_after_associations, AfterCreate, AfterSave (gorm:after_create), gorm:commit_or_rollback_transaction
+//    Update: gorm:begin_transaction, BeforeSave, BeforeUpdate (gorm:before_update), gorm:save_before_associations, gorm:update,
+//            gorm:save_after_associations, AfterUpdate, AfterSave (gorm:after_update), gorm:commit_or_rollback_transaction
+//    Delete: gorm:begin_transaction, BeforeDelete (gorm:before_delete), gorm:delete, AfterDelete (gorm:after_delete), gorm:commit_or_rollback_transaction
+//    Query:  gorm:query, gorm:preload, AfterFind (gorm:after_query)
+// Hooks are not invoked by UpdateColumn(s), which skips gorm:before_update and gorm:after_update
+
+// BeforeSaveInterface called before creating or updating a record
+type BeforeSaveInterface interface {
+	BeforeSave(tx *DB) error
+}
+
+// BeforeCreateInterface called before creating a record, after BeforeSave
+type BeforeCreateInterface interface {
+	BeforeCreate(tx *DB) error
+}
+
+// AfterCreateInterface called after creating a record, before AfterSave
+type AfterCreateInterface interface {
+	AfterCreate(tx *DB) error
+}
+
+// BeforeUpdateInterface called before updating a record, after BeforeSave
+type BeforeUpdateInterface interface {
+	BeforeUpdate(tx *DB) error
+}
+
+// AfterUpdateInterface called after updating a record, before AfterSave
+type AfterUpdateInterface interface {
+	AfterUpdate(tx *DB) error
+}
+
+// AfterSaveInterface called after creating or updating a record
+type AfterSaveInterface interface {
+	AfterSave(tx *DB) error
+}
+
+// BeforeDeleteInterface called before deleting a record
+type BeforeDeleteInterface interface {
+	BeforeDelete(tx *DB) error
+}
+
+// AfterDeleteInterface called after deleting a record
+type AfterDeleteInterface interface {
+	AfterDelete(tx *DB) error
+}
+
+// AfterFindInterface called after querying a record, tx runs with the connection of the query
+type AfterFindInterface interface {
+	AfterFind(tx *DB) error
+}
+
+// hookOf return the hook of the value if it implements the hook interface of the method, e.g. BeforeCreateInterface for `BeforeCreate`
+func hookOf(methodName string, value interface{}) (func(tx *DB) error, bool) {
+	switch methodName {
+	case "BeforeSave":
+		if hook, ok := value.(BeforeSaveInterface); ok {
+			return hook.BeforeSave, true
+		}
+	case "BeforeCreate":
+		if hook, ok := value.(BeforeCreateInterface); ok {
+			return hook.BeforeCreate, true
+		}
+	case "AfterCreate":
+		if hook, ok := value.(AfterCreateInterface); ok {
+			return hook.AfterCreate, true
+		}
+	case "BeforeUpdate":
+		if hook, ok := value.(BeforeUpdateInterface); ok {
+			return hook.BeforeUpdate, true
+		}
+	case "AfterUpdate":
+		if hook, ok := value.(AfterUpdateInterface); ok {
+			return hook.AfterUpdate, true
+		}
+	case "AfterSave":
+		if hook, ok := value.(AfterSaveInterface); ok {
+			return hook.AfterSave, true
+		}
+	case "BeforeDelete":
+		if hook, ok := value.(BeforeDeleteInterface); ok {
+			return hook.BeforeDelete, true
+		}
+	case "AfterDelete":
+		if hook, ok := value.(AfterDeleteInterface); ok {
+			return hook.AfterDelete, true
+		}
+	case "AfterFind":
+		if hook, ok := value.(AfterFindInterface); ok {
+			return hook.AfterFind, true
+		}
+	}
+	return nil, false
+}
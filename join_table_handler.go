@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -79,7 +80,7 @@ func (s *JoinTableHandler) Setup(relationship *Relationship, tableName string, s
 
 // Table return join table's table name
 func (s JoinTableHandler) Table(db *DB) string {
-	return DefaultTableNameHandler(db, s.TableName)
+	return tenantTableName(db, db.naming().qualifiedTableName(DefaultTableNameHandler(db, db.naming().JoinTableName(s.TableName))))
 }
 
 func (s JoinTableHandler) updateConditionMap(conditionMap map[string]interface{}, db *DB, joinTableSources []JoinTableSource, sources ...interface{}) {
@@ -100,7 +101,27 @@ func (s JoinTableHandler) updateConditionMap(conditionMap map[string]interface{}
 	}
 }
 
-// Add create relationship in join table for source and destination
+// joinTableAttributes values of join table's extra columns, set with `db.Set("gorm:join_table_attrs", map[string]interface{}{"role": "admin"})`
+type joinTableAttributes struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func joinTableAttrs(db *DB) (attrs joinTableAttributes) {
+	if value, ok := db.Get("gorm:join_table_attrs"); ok {
+		if values, ok := value.(map[string]interface{}); ok {
+			for key := range values {
+				attrs.keys = append(attrs.keys, key)
+			}
+			sort.Strings(attrs.keys)
+			attrs.values = values
+		}
+	}
+	return
+}
+
+// Add create relationship in join table for source and destination, values of extra columns could be set with `gorm:join_table_attrs`
+//    db.Set("gorm:join_table_attrs", map[string]interface{}{"role": "admin"}).Save(&user)
 func (s JoinTableHandler) Add(handler JoinTableHandlerInterface, db *DB, source interface{}, destination interface{}) error {
 	var (
 		scope        = db.NewScope("")
@@ -114,18 +135,25 @@ func (s JoinTableHandler) Add(handler JoinTableHandlerInterface, db *DB, source
 	s.updateConditionMap(conditionMap, db, []JoinTableSource{s.Destination}, destination)
 
 	var assignColumns, binVars, conditions []string
-	var values []interface{}
+	var values, conditionValues []interface{}
 	for key, value := range conditionMap {
 		assignColumns = append(assignColumns, scope.Quote(key))
 		binVars = append(binVars, `?`)
 		conditions = append(conditions, fmt.Sprintf("%v = ?", scope.Quote(key)))
 		values = append(values, value)
+		conditionValues = append(conditionValues, value)
 	}
 
-	for _, value := range values {
-		values = append(values, value)
+	// extra columns of join table
+	attrs := joinTableAttrs(db)
+	for _, key := range attrs.keys {
+		assignColumns = append(assignColumns, scope.Quote(key))
+		binVars = append(binVars, `?`)
+		values = append(values, attrs.values[key])
 	}
 
+	values = append(values, conditionValues...)
+
 	quotedTable := scope.Quote(handler.Table(db))
 	sql := fmt.Sprintf(
 		"INSERT INTO %v (%v) SELECT %v %v WHERE NOT EXISTS (SELECT * FROM %v WHERE %v)",
@@ -140,6 +168,113 @@ func (s JoinTableHandler) Add(handler JoinTableHandlerInterface, db *DB, source
 	return db.Exec(sql, values...).Error
 }
 
+// AddBatch create relationships in join table for each pair of sources and destinations, existing relationships are skipped,
+// they are found with one query, then missing relationships are inserted with one multi-row `INSERT ... VALUES` statement,
+// so types of values are inferred from columns of the join table by all dialects
+func (s JoinTableHandler) AddBatch(handler JoinTableHandlerInterface, db *DB, sources []interface{}, destinations []interface{}) error {
+	var (
+		scope       = db.NewScope("")
+		quotedTable = scope.Quote(handler.Table(db))
+		columns     []string
+		keys        []string
+		rows        [][]interface{}
+		conditions  []string
+		values      []interface{}
+		added       = map[string]bool{}
+		attrs       = joinTableAttrs(db)
+	)
+
+	for idx := range sources {
+		conditionMap := map[string]interface{}{}
+		s.updateConditionMap(conditionMap, db, []JoinTableSource{s.Source}, sources[idx])
+		s.updateConditionMap(conditionMap, db, []JoinTableSource{s.Destination}, destinations[idx])
+
+		if columns == nil {
+			for key := range conditionMap {
+				columns = append(columns, key)
+			}
+			sort.Strings(columns)
+		}
+
+		var rowConditions []string
+		var rowValues []interface{}
+		for _, column := range columns {
+			rowConditions = append(rowConditions, fmt.Sprintf("%v = ?", scope.Quote(column)))
+			rowValues = append(rowValues, conditionMap[column])
+		}
+
+		if key := toString(rowValues); !added[key] {
+			added[key] = true
+			keys = append(keys, key)
+			rows = append(rows, rowValues)
+			conditions = append(conditions, "("+strings.Join(rowConditions, " AND ")+")")
+			values = append(values, rowValues...)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var quotedColumns []string
+	for _, column := range columns {
+		quotedColumns = append(quotedColumns, scope.Quote(column))
+	}
+
+	existing, err := db.Raw(fmt.Sprintf("SELECT %v FROM %v WHERE %v", strings.Join(quotedColumns, ","), quotedTable, strings.Join(conditions, " OR ")), values...).Rows()
+	if err != nil {
+		return err
+	}
+	defer existing.Close()
+
+	found := map[string]bool{}
+	for existing.Next() {
+		var (
+			rowValues = make([]interface{}, len(columns))
+			dests     = make([]interface{}, len(columns))
+		)
+		for i := range rowValues {
+			dests[i] = &rowValues[i]
+		}
+		if err := existing.Scan(dests...); err != nil {
+			return err
+		}
+		found[toString(rowValues)] = true
+	}
+	if err := existing.Err(); err != nil {
+		return err
+	}
+
+	var binVars []string
+	values = nil
+	for idx, rowValues := range rows {
+		if found[keys[idx]] {
+			continue
+		}
+
+		var rowBinVars []string
+		for range columns {
+			rowBinVars = append(rowBinVars, "?")
+		}
+		values = append(values, rowValues...)
+		for _, key := range attrs.keys {
+			rowBinVars = append(rowBinVars, "?")
+			values = append(values, attrs.values[key])
+		}
+		binVars = append(binVars, "("+strings.Join(rowBinVars, ",")+")")
+	}
+
+	if len(binVars) == 0 {
+		return nil
+	}
+
+	for _, column := range attrs.keys {
+		quotedColumns = append(quotedColumns, scope.Quote(column))
+	}
+	sql := fmt.Sprintf("INSERT INTO %v (%v) VALUES %v", quotedTable, strings.Join(quotedColumns, ","), strings.Join(binVars, ","))
+	return db.Exec(sql, values...).Error
+}
+
 // Delete delete relationship in join table for sources
 func (s JoinTableHandler) Delete(handler JoinTableHandlerInterface, db *DB, sources ...interface{}) error {
 	var (
@@ -3,10 +3,12 @@ package gorm_test
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
 )
 
 type Person struct {
@@ -115,3 +117,139 @@ func TestEmbeddedMany2ManyRelationship(t *testing.T) {
 		t.Errorf("Should deleted all addresses")
 	}
 }
+
+type MembershipUser struct {
+	ID     uint
+	Name   string
+	Groups []MembershipGroup `gorm:"many2many:membership_user_groups"`
+}
+
+type MembershipGroup struct {
+	ID   uint
+	Name string
+}
+
+type MembershipUserGroup struct {
+	MembershipUserID  uint `gorm:"primary_key;auto_increment:false"`
+	MembershipGroupID uint `gorm:"primary_key;auto_increment:false"`
+	Role              string
+	CreatedAt         time.Time
+}
+
+func TestJoinTableWithExtraColumns(t *testing.T) {
+	DB.DropTableIfExists(&MembershipUser{}, &MembershipGroup{}, &MembershipUserGroup{})
+	if err := DB.AutoMigrate(&MembershipUserGroup{}, &MembershipUser{}, &MembershipGroup{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	user := MembershipUser{Name: "membership"}
+	DB.Save(&user)
+
+	now := time.Now().Round(time.Second)
+	DB.Model(&user).Association("Groups").JoinTableAttrs(map[string]interface{}{"role": "admin", "created_at": now}).Append(&MembershipGroup{Name: "admins"})
+	DB.Model(&user).Association("Groups").JoinTableAttrs(map[string]interface{}{"role": "member"}).Append(&MembershipGroup{Name: "members"})
+
+	var memberships []MembershipUserGroup
+	DB.Model(&user).Association("Groups").FindJoinTable(&memberships)
+	if len(memberships) != 2 {
+		t.Fatalf("should find 2 join table records, but got %v", len(memberships))
+	}
+
+	for _, membership := range memberships {
+		if membership.Role == "" {
+			t.Errorf("join table's extra columns should be saved, but got %+v", membership)
+		}
+		if membership.Role == "admin" && !membership.CreatedAt.Equal(now) {
+			t.Errorf("join table's created_at should be saved, but got %v", membership.CreatedAt)
+		}
+	}
+
+	var groups []MembershipGroup
+	DB.Model(&user).Association("Groups").Where("membership_user_groups.role = ?", "admin").Find(&groups)
+	if len(groups) != 1 || groups[0].Name != "admins" {
+		t.Errorf("should find associations with join table conditions, but got %+v", groups)
+	}
+
+	var preloaded MembershipUser
+	DB.Preload("Groups", "membership_user_groups.role = ?", "member").First(&preloaded, user.ID)
+	if len(preloaded.Groups) != 1 || preloaded.Groups[0].Name != "members" {
+		t.Errorf("should preload associations with join table conditions, but got %+v", preloaded.Groups)
+	}
+
+	var users []MembershipUser
+	DB.Joins("Groups", `membership_user_groups.role = ? AND "Groups".name = ?`, "admin", "admins").Find(&users)
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("should find users by joining many2many associations, but got %+v", users)
+	}
+
+	DB.Joins("Groups", `membership_user_groups.role = ? AND "Groups".name = ?`, "admin", "members").Find(&users)
+	if len(users) != 0 {
+		t.Errorf("should filter users with join table conditions, but got %+v", users)
+	}
+}
+
+func TestJoinManyToManyWithMultipleAssociations(t *testing.T) {
+	DB.DropTableIfExists(&MembershipUser{}, &MembershipGroup{}, &MembershipUserGroup{})
+	if err := DB.AutoMigrate(&MembershipUserGroup{}, &MembershipUser{}, &MembershipGroup{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	user := MembershipUser{Name: "multiple", Groups: []MembershipGroup{{Name: "first"}, {Name: "second"}}}
+	DB.Save(&user)
+	DB.Save(&MembershipUser{Name: "none"})
+
+	var users []MembershipUser
+	if err := DB.Joins("Groups").Preload("Groups").Find(&users).Error; err != nil {
+		t.Fatalf("failed to find users, got %v", err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID || len(users[0].Groups) != 2 {
+		t.Errorf("users having groups should be found once with their groups, but got %+v", users)
+	}
+
+	var count int
+	if DB.Model(&MembershipUser{}).Joins("Groups", `"Groups".name IN (?)`, []string{"first", "second"}).Count(&count); count != 1 {
+		t.Errorf("users should be counted once, but got %v", count)
+	}
+}
+
+func TestPreloadManyToManyWithLimitPerRecord(t *testing.T) {
+	DB.DropTableIfExists(&MembershipUser{}, &MembershipGroup{}, &MembershipUserGroup{})
+	if err := DB.AutoMigrate(&MembershipUserGroup{}, &MembershipUser{}, &MembershipGroup{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	users := []MembershipUser{
+		{Name: "limit1", Groups: []MembershipGroup{{Name: "first"}, {Name: "second"}}},
+		{Name: "limit2", Groups: []MembershipGroup{{Name: "third"}}},
+	}
+	for i := range users {
+		DB.Save(&users[i])
+	}
+
+	var results []MembershipUser
+	if err := DB.CheckScan(gorm.ScanCheckError).Order("id").Preload("Groups", gorm.LimitPerRecord(1), func(db *gorm.DB) *gorm.DB {
+		return db.Order("membership_groups.id DESC")
+	}).Find(&results).Error; err != nil {
+		t.Fatalf("failed to preload groups, got %v", err)
+	}
+
+	if len(results) != 2 || len(results[0].Groups) != 1 || results[0].Groups[0].Name != "second" || len(results[1].Groups) != 1 {
+		t.Errorf("should preload the latest group of each user, but got %+v", results)
+	}
+}
+
+func TestPreloadManyToManyWithLimitPerRecordColumns(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	recorder.Reply(`SELECT * FROM "membership_users"`, []string{"id", "name"}, []interface{}{int64(1), "limit1"}, []interface{}{int64(2), "limit2"})
+
+	var results []MembershipUser
+	db.Preload("Groups", gorm.LimitPerRecord(1)).Find(&results)
+
+	statement := recorder.LastStatement().SQL
+	if !strings.HasPrefix(statement, `SELECT "id","name","membership_user_id" FROM (SELECT "membership_groups".*,"membership_user_groups"."membership_user_id", ROW_NUMBER()`) {
+		t.Errorf("only columns of the association and join keys should be selected, but got %v", statement)
+	}
+}
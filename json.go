@@ -0,0 +1,212 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSONB map stored as JSON, it is migrated as jsonb on postgres and cockroachdb, json on mysql, text on sqlite and nvarchar(max) on mssql,
+// query its attributes with JSONQuery, and index it with `gin_index` on postgres to query it by containment
+//    type Product struct {
+//      ID         uint
+//      Attributes gorm.JSONB `gorm:"gin_index"`
+//    }
+//
+//    db.Where(gorm.JSONQuery("attributes").Contains(map[string]interface{}{"color": "red"})).Find(&products)
+//    // postgres: SELECT * FROM products WHERE (attributes @> '{"color":"red"}');
+type JSONB map[string]interface{}
+
+// Value return the JSON of the map, nil maps are NULL
+func (m JSONB) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// Scan parse the JSON into the map, NULL is scanned as a nil map
+func (m *JSONB) Scan(src interface{}) error {
+	var bytes []byte
+	switch src := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		bytes = src
+	case string:
+		bytes = []byte(src)
+	default:
+		return fmt.Errorf("failed to scan JSONB from %T", src)
+	}
+
+	result := JSONB{}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
+
+// GormDataType return jsonb on postgres and cockroachdb, json on mysql, and text on others
+func (JSONB) GormDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres", "cockroachdb":
+		return "jsonb"
+	case "mysql", "tidb":
+		return "json"
+	case "mssql":
+		return "nvarchar(max)"
+	}
+	return "text"
+}
+
+// JSONQueryExpression build conditions of JSON columns, they are compiled with the dialect of the statement
+type JSONQueryExpression struct {
+	column string
+}
+
+// JSONQuery build conditions of the JSON column, paths of keys are separated by `.`, sqlite requires the json1 extension, e.g:
+//    db.Where(gorm.JSONQuery("attributes").HasKey("size.width")).Find(&products)
+//    // postgres: SELECT * FROM products WHERE (jsonb_exists(attributes #> '{size}', 'width'));
+//    // mysql:    SELECT * FROM products WHERE (JSON_CONTAINS_PATH(attributes, 'one', '$.size.width'));
+//    // sqlite:   SELECT * FROM products WHERE (json_type(attributes, '$.size.width') IS NOT NULL);
+//
+//    db.Where(gorm.JSONQuery("attributes").Equals("size.width", 10)).Find(&products)
+//    // postgres: SELECT * FROM products WHERE (attributes #>> '{size,width}' = '10');
+//    // mysql:    SELECT * FROM products WHERE (JSON_UNQUOTE(JSON_EXTRACT(attributes, '$.size.width')) = '10');
+//    // sqlite:   SELECT * FROM products WHERE (json_extract(attributes, '$.size.width') = 10);
+func JSONQuery(column string) JSONQueryExpression {
+	return JSONQueryExpression{column: column}
+}
+
+// HasKey build condition matching records having the key path, even if its value is null
+func (query JSONQueryExpression) HasKey(path string) *SqlExpr {
+	keys := strings.Split(path, ".")
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		switch dialect.GetName() {
+		case "postgres", "cockroachdb":
+			// the `?` operator conflicts with bind vars, use the function behind it instead
+			if len(keys) == 1 {
+				return Expr(fmt.Sprintf("jsonb_exists(%v, ?)", query.column), keys[0])
+			}
+			return Expr(fmt.Sprintf("jsonb_exists(%v #> ?, ?)", query.column), Array(keys[:len(keys)-1]), keys[len(keys)-1])
+		case "mysql", "tidb":
+			return Expr(fmt.Sprintf("JSON_CONTAINS_PATH(%v, 'one', ?)", query.column), jsonPath(keys))
+		case "mssql":
+			return Expr(fmt.Sprintf("JSON_PATH_EXISTS(%v, ?) = 1", query.column), jsonPath(keys))
+		}
+		// json_type returns 'null' for null values, and NULL for missing keys
+		return Expr(fmt.Sprintf("json_type(%v, ?) IS NOT NULL", query.column), jsonPath(keys))
+	}))
+}
+
+// Equals build condition matching records whose value of the key path equals to the value,
+// values are compared as text on postgres, cockroachdb and mysql, compare numbers in other forms like `1.5` with the same form
+func (query JSONQueryExpression) Equals(path string, value interface{}) *SqlExpr {
+	keys := strings.Split(path, ".")
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		extract, arg := jsonExtractSQL(dialect, query.column, keys)
+		switch dialect.GetName() {
+		case "sqlite3":
+			return Expr(fmt.Sprintf("%v = ?", extract), arg, value)
+		}
+		return Expr(fmt.Sprintf("%v = ?", extract), arg, fmt.Sprint(value))
+	}))
+}
+
+// Contains build condition matching records whose JSON contains the value, e.g. objects containing all pairs of the map,
+// it is compiled to `@>` on postgres and cockroachdb and `JSON_CONTAINS` on mysql,
+// other dialects compare top-level keys of maps only
+func (query JSONQueryExpression) Contains(value interface{}) *SqlExpr {
+	return Expr("?", dialectExpr(func(dialect Dialect) *SqlExpr {
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			return Expr("?", jsonError{err: err})
+		}
+
+		switch dialect.GetName() {
+		case "postgres", "cockroachdb":
+			return Expr(fmt.Sprintf("%v @> ?", query.column), string(bytes))
+		case "mysql", "tidb":
+			return Expr(fmt.Sprintf("JSON_CONTAINS(%v, ?)", query.column), string(bytes))
+		}
+
+		var pairs map[string]interface{}
+		if err := json.Unmarshal(bytes, &pairs); err != nil {
+			return Expr("?", jsonError{err: fmt.Errorf("%v doesn't support JSON containment of %T, only maps are supported", dialect.GetName(), value)})
+		}
+
+		var (
+			keys       []string
+			conditions []string
+			args       []interface{}
+		)
+		for key := range pairs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			extract, arg := jsonExtractSQL(dialect, query.column, []string{key})
+			value := pairs[key]
+			switch v := value.(type) {
+			case map[string]interface{}, []interface{}:
+				// objects and arrays are extracted as JSON text
+				bytes, _ := json.Marshal(v)
+				value = string(bytes)
+			case bool:
+				// sqlite extracts booleans as 1 and 0, others as text
+				if dialect.GetName() != "sqlite3" {
+					value = fmt.Sprint(v)
+				}
+			}
+			conditions = append(conditions, fmt.Sprintf("%v = ?", extract))
+			args = append(args, arg, value)
+		}
+		if len(conditions) == 0 {
+			return Expr("1 = 1")
+		}
+		return Expr(strings.Join(conditions, " AND "), args...)
+	}))
+}
+
+// jsonExtractSQL return the SQL extracting the value of keys as text or scalars, and its arg of the key path
+func jsonExtractSQL(dialect Dialect, column string, keys []string) (string, interface{}) {
+	switch dialect.GetName() {
+	case "postgres", "cockroachdb":
+		return fmt.Sprintf("%v #>> ?", column), Array(keys)
+	case "mysql", "tidb":
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%v, ?))", column), jsonPath(keys)
+	case "mssql":
+		return fmt.Sprintf("JSON_VALUE(%v, ?)", column), jsonPath(keys)
+	}
+	return fmt.Sprintf("json_extract(%v, ?)", column), jsonPath(keys)
+}
+
+// jsonPath return the JSON path of keys like `$.size."the width"`
+func jsonPath(keys []string) string {
+	path := "$"
+	for _, key := range keys {
+		if strings.ContainsAny(key, ` ."$[]*`) {
+			key = `"` + strings.Replace(key, `"`, `\"`, -1) + `"`
+		}
+		path += "." + key
+	}
+	return path
+}
+
+// jsonError fail the statement with the error when the value is converted
+type jsonError struct {
+	err error
+}
+
+// Value return the error
+func (e jsonError) Value() (driver.Value, error) {
+	return nil, e.err
+}
@@ -0,0 +1,88 @@
+package gorm_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type Gadget struct {
+	ID         uint
+	Name       string
+	Attributes gorm.JSONB `gorm:"gin_index"`
+}
+
+func TestJSONBConditions(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.AutoMigrate(&Gadget{})
+	var created, indexed bool
+	for _, statement := range recorder.Statements() {
+		created = created || strings.Contains(statement.SQL, `"attributes" jsonb`)
+		indexed = indexed || strings.TrimSpace(statement.SQL) == `CREATE INDEX gin_gadgets_attributes ON "gadgets" USING GIN ("attributes")`
+	}
+	if !created || !indexed {
+		t.Errorf("JSONB columns should be migrated as jsonb with GIN index, got %v", recorder.Statements())
+	}
+
+	query := gorm.JSONQuery("attributes")
+	db.Where(query.Contains(map[string]interface{}{"color": "red"})).Where(query.HasKey("size.width")).Where(query.Equals("size.height", 10)).Find(&[]Gadget{})
+	statement := recorder.LastStatement()
+	if statement.SQL != `SELECT * FROM "gadgets" WHERE (attributes @> $1) AND (jsonb_exists(attributes #> $2, $3)) AND (attributes #>> $4 = $5)` ||
+		!reflect.DeepEqual(statement.Vars, []interface{}{`{"color":"red"}`, "{\"size\"}", "width", `{"size","height"}`, "10"}) {
+		t.Errorf("postgres JSON conditions are wrong, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	mysql, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	mysql.Where(query.Contains(map[string]interface{}{"color": "red"})).Where(query.HasKey("size.width")).Where(query.Equals("size.height", 10)).Find(&[]Gadget{})
+	statement = recorder.LastStatement()
+	if statement.SQL != "SELECT * FROM `gadgets` WHERE (JSON_CONTAINS(attributes, ?)) AND (JSON_CONTAINS_PATH(attributes, 'one', ?)) AND (JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) = ?)" ||
+		!reflect.DeepEqual(statement.Vars, []interface{}{`{"color":"red"}`, "$.size.width", "$.size.height", "10"}) {
+		t.Errorf("mysql JSON conditions are wrong, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	sqlite, recorder, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	sqlite.Where(query.Contains(map[string]interface{}{"color": "red", "on sale": true})).Where(query.HasKey("size.width")).Find(&[]Gadget{})
+	statement = recorder.LastStatement()
+	if statement.SQL != "SELECT * FROM \"gadgets\" WHERE (json_extract(attributes, ?) = ? AND json_extract(attributes, ?) = ?) AND (json_type(attributes, ?) IS NOT NULL)" ||
+		!reflect.DeepEqual(statement.Vars, []interface{}{"$.color", "red", `$."on sale"`, true, "$.size.width"}) {
+		t.Errorf("sqlite JSON conditions are wrong, got %v %#v", statement.SQL, statement.Vars)
+	}
+}
+
+func TestJSONB(t *testing.T) {
+	DB.DropTableIfExists(&Gadget{})
+	if err := DB.AutoMigrate(&Gadget{}).Error; err != nil {
+		t.Fatalf("failed to migrate gadgets, got %v", err)
+	}
+
+	shirt := Gadget{Name: "shirt", Attributes: gorm.JSONB{"color": "red", "on sale": true, "size": map[string]interface{}{"width": 10, "height": nil}}}
+	hat := Gadget{Name: "hat", Attributes: gorm.JSONB{"color": "blue"}}
+	DB.Create(&shirt)
+	DB.Create(&hat)
+	DB.Create(&Gadget{Name: "box"})
+
+	var found Gadget
+	if err := DB.First(&found, shirt.ID).Error; err != nil {
+		t.Fatalf("failed to find gadget, got %v", err)
+	}
+	if found.Attributes["color"] != "red" || found.Attributes["size"].(map[string]interface{})["width"] != float64(10) {
+		t.Errorf("JSONB should be scanned into the map, but got %#v", found.Attributes)
+	}
+	var box Gadget
+	if err := DB.First(&box, "name = ?", "box").Error; err != nil || box.Attributes != nil {
+		t.Errorf("NULL should be scanned as nil JSONB, but got %#v, %v", box.Attributes, err)
+	}
+}
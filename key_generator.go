@@ -0,0 +1,313 @@
+package gorm
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Primary keys tagged with `default:uuid`, `default:ulid` or `default:snowflake` are generated when creating records with blank primary keys
+//    type User struct {
+//      ID   string `gorm:"primary_key;default:uuid"` // uuid on postgres, char(36) on mysql
+//      Name string
+//    }
+//
+//    type Order struct {
+//      ID     int64 `gorm:"primary_key;default:snowflake"`
+//      UserID string
+//    }
+//
+// uuid and ulid keys should be strings, snowflake keys should be integers, the node id of snowflake keys is set with SetSnowflakeNode
+const (
+	keyGeneratorUUID      = "UUID"
+	keyGeneratorULID      = "ULID"
+	keyGeneratorSnowflake = "SNOWFLAKE"
+	keyGeneratorIDGen     = "ID_GEN"
+)
+
+// IDGenerator allocate ids of fields tagged with `id_gen` when creating records, e.g. for sharded tables can't use auto increment keys,
+// set it with SetIDGenerator, ids are allocated by the snowflake of SetSnowflakeNode if it is not set
+//    type Order struct {
+//      ID     int64 `gorm:"primary_key;id_gen"`
+//      UserID string
+//    }
+//
+//    db.SetIDGenerator(sonyflakeGenerator)
+//    db.Create(&order)
+type IDGenerator interface {
+	NextID() (int64, error)
+}
+
+// BatchIDGenerator allocate ids in batches, ids of records are allocated with one call in AllocateIDs
+type BatchIDGenerator interface {
+	IDGenerator
+	NextIDs(n int) ([]int64, error)
+}
+
+// SetIDGenerator set the generator allocating ids of fields tagged with `id_gen`
+func (s *DB) SetIDGenerator(generator IDGenerator) *DB {
+	s.parent.idGenerator = generator
+	return s
+}
+
+// AllocateIDs allocate ids of blank fields tagged with `id_gen` for a slice of records at once, e.g. before inserting them in batches,
+// ids are allocated with NextIDs if the generator is a BatchIDGenerator
+//    db.AllocateIDs(&orders)
+func (s *DB) AllocateIDs(values interface{}) error {
+	var fields []*Field
+	records := indirect(reflect.ValueOf(values))
+	if records.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported value %T to allocate ids, should be a slice", values)
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		record := records.Index(i)
+		if record.Kind() != reflect.Ptr {
+			record = record.Addr()
+		}
+		for _, field := range s.NewScope(record.Interface()).Fields() {
+			if keyGenerator(field.StructField) == keyGeneratorIDGen && field.IsBlank {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	ids, err := nextIDs(s.idAllocator(), len(fields))
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		if err := field.Set(ids[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idAllocator return the generator set with SetIDGenerator, or the snowflake of SetSnowflakeNode
+func (s *DB) idAllocator() IDGenerator {
+	if s.parent.idGenerator != nil {
+		return s.parent.idGenerator
+	}
+	if s.parent.snowflake != nil {
+		return s.parent.snowflake
+	}
+	return defaultSnowflake
+}
+
+func nextIDs(generator IDGenerator, n int) ([]int64, error) {
+	if batch, ok := generator.(BatchIDGenerator); ok {
+		ids, err := batch.NextIDs(n)
+		if err == nil && len(ids) != n {
+			err = fmt.Errorf("id generator allocated %v ids, but %v ids are required", len(ids), n)
+		}
+		return ids, err
+	}
+
+	ids := make([]int64, n)
+	for i := range ids {
+		id, err := generator.NextID()
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// defaultSnowflake snowflake used if the node is not set with SetSnowflakeNode
+var defaultSnowflake = &Snowflake{}
+
+// SetSnowflakeNode set the node id of snowflake primary keys, it should be unique for each process creating records, from 0 to 1023
+//    db.SetSnowflakeNode(podIndex)
+func (s *DB) SetSnowflakeNode(node int64) *DB {
+	s.parent.snowflake = &Snowflake{Node: node}
+	return s
+}
+
+// Snowflake generate 64 bits ids increasing by time, composed of 41 bits milliseconds since epoch, 10 bits node id and 12 bits sequence
+type Snowflake struct {
+	Node  int64     // node id from 0 to 1023
+	Epoch time.Time // time of the id 0, 2020-01-01 UTC if it is zero
+
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+}
+
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NextID return the next id
+func (snowflake *Snowflake) NextID() (int64, error) {
+	if snowflake.Node < 0 || snowflake.Node > 1023 {
+		return 0, fmt.Errorf("invalid snowflake node %v, it should be from 0 to 1023", snowflake.Node)
+	}
+
+	epoch := snowflake.Epoch
+	if epoch.IsZero() {
+		epoch = snowflakeEpoch
+	}
+
+	snowflake.mu.Lock()
+	defer snowflake.mu.Unlock()
+
+	now := int64(time.Since(epoch) / time.Millisecond)
+	if now < snowflake.last {
+		// clock moved backwards, keep using the last time to avoid duplicated ids
+		now = snowflake.last
+	}
+
+	if now == snowflake.last {
+		snowflake.sequence = (snowflake.sequence + 1) & 4095
+		if snowflake.sequence == 0 {
+			// sequence of the millisecond is exhausted, wait for the next millisecond
+			for now <= snowflake.last {
+				time.Sleep(100 * time.Microsecond)
+				now = int64(time.Since(epoch) / time.Millisecond)
+			}
+		}
+	} else {
+		snowflake.sequence = 0
+	}
+
+	if now >= 1<<41 {
+		return 0, errors.New("snowflake ids are exhausted since the epoch")
+	}
+
+	snowflake.last = now
+	return now<<22 | snowflake.Node<<12 | snowflake.sequence, nil
+}
+
+// NextIDs return the next n ids
+func (snowflake *Snowflake) NextIDs(n int) ([]int64, error) {
+	ids := make([]int64, n)
+	for i := range ids {
+		id, err := snowflake.NextID()
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// keyGenerator return the generator of the field tagged with `id_gen`, or the primary key tagged with `default:uuid`, `default:ulid` or `default:snowflake`
+func keyGenerator(field *StructField) string {
+	if _, ok := field.TagSettingsGet("ID_GEN"); ok {
+		return keyGeneratorIDGen
+	}
+
+	if !field.IsPrimaryKey {
+		return ""
+	}
+
+	value, _ := field.TagSettingsGet("DEFAULT")
+	switch generator := strings.ToUpper(strings.Trim(value, "'")); generator {
+	case keyGeneratorUUID, keyGeneratorULID, keyGeneratorSnowflake:
+		return generator
+	}
+	return ""
+}
+
+// keyDataType return column type of generated primary keys
+func keyDataType(dialect Dialect, generator string) string {
+	switch generator {
+	case keyGeneratorUUID:
+		switch dialect.GetName() {
+		case "postgres", "cockroachdb":
+			return "uuid"
+		case "mysql", "tidb":
+			return "char(36)"
+		}
+		return "varchar(36)"
+	case keyGeneratorULID:
+		return "char(26)"
+	case keyGeneratorSnowflake, keyGeneratorIDGen:
+		return "bigint"
+	}
+	return ""
+}
+
+// generatePrimaryKeyCallback generate blank primary keys tagged with `default:uuid`, `default:ulid` or `default:snowflake`,
+// and blank fields tagged with `id_gen` when creating
+func generatePrimaryKeyCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	for _, field := range scope.Fields() {
+		if generator := keyGenerator(field.StructField); generator != "" && field.IsBlank {
+			value, err := generateKey(scope, generator)
+			if scope.Err(err) != nil {
+				return
+			}
+			scope.Err(field.Set(value))
+		}
+	}
+}
+
+func generateKey(scope *Scope, generator string) (interface{}, error) {
+	switch generator {
+	case keyGeneratorUUID:
+		return newUUID()
+	case keyGeneratorULID:
+		return newULID(scope.db.nowFunc())
+	case keyGeneratorSnowflake:
+		snowflake := scope.db.parent.snowflake
+		if snowflake == nil {
+			snowflake = defaultSnowflake
+		}
+		return snowflake.NextID()
+	case keyGeneratorIDGen:
+		return scope.db.idAllocator().NextID()
+	}
+	return nil, fmt.Errorf("unsupported key generator %v", generator)
+}
+
+// newUUID return a random uuid (version 4)
+func newUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID return an ulid composed of 48 bits milliseconds and 80 bits randomness, encoded as 26 characters in Crockford's base32
+func newULID(now time.Time) (string, error) {
+	var ulid [16]byte
+	if _, err := rand.Read(ulid[6:]); err != nil {
+		return "", err
+	}
+
+	ms := uint64(now.UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		ulid[i] = byte(ms)
+		ms >>= 8
+	}
+
+	var (
+		value     = new(big.Int).SetBytes(ulid[:])
+		base      = big.NewInt(32)
+		remainder = new(big.Int)
+		encoded   = make([]byte, 26)
+	)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		value.DivMod(value, base, remainder)
+		encoded[i] = crockfordBase32[remainder.Int64()]
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,147 @@
+package gorm_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type UUIDUser struct {
+	ID   string `gorm:"primary_key;default:uuid"`
+	Name string
+}
+
+type ULIDEvent struct {
+	ID   string `gorm:"primary_key;default:ulid"`
+	Name string
+}
+
+type SnowflakeOrder struct {
+	ID   int64 `gorm:"primary_key;default:snowflake"`
+	Name string
+}
+
+func TestGeneratePrimaryKeys(t *testing.T) {
+	DB.DropTableIfExists(&UUIDUser{}, &ULIDEvent{}, &SnowflakeOrder{})
+	if err := DB.AutoMigrate(&UUIDUser{}, &ULIDEvent{}, &SnowflakeOrder{}).Error; err != nil {
+		t.Fatalf("Failed to migrate tables with generated keys, got %v", err)
+	}
+
+	user1, user2 := UUIDUser{Name: "uuid1"}, UUIDUser{Name: "uuid2"}
+	DB.Create(&user1)
+	DB.Create(&user2)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(user1.ID) || user1.ID == user2.ID {
+		t.Errorf("Should generate uuid keys, but got %v, %v", user1.ID, user2.ID)
+	}
+
+	var user UUIDUser
+	if err := DB.First(&user, "id = ?", user1.ID).Error; err != nil || user.Name != "uuid1" {
+		t.Errorf("Should find user with the generated uuid, but got %+v, %v", user, err)
+	}
+
+	user3 := UUIDUser{ID: "3f0c1f8e-0000-4000-8000-000000000000", Name: "uuid3"}
+	DB.Create(&user3)
+	if user3.ID != "3f0c1f8e-0000-4000-8000-000000000000" {
+		t.Errorf("Should not generate key if it is not blank, but got %v", user3.ID)
+	}
+
+	event1, event2 := ULIDEvent{Name: "ulid1"}, ULIDEvent{Name: "ulid2"}
+	DB.Create(&event1)
+	DB.Create(&event2)
+	if !regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`).MatchString(event1.ID) || event1.ID == event2.ID || event1.ID[:10] > event2.ID[:10] {
+		t.Errorf("Should generate ulid keys, but got %v, %v", event1.ID, event2.ID)
+	}
+
+	DB.SetSnowflakeNode(7)
+	order1, order2 := SnowflakeOrder{Name: "snowflake1"}, SnowflakeOrder{Name: "snowflake2"}
+	DB.Create(&order1)
+	DB.Create(&order2)
+	if order1.ID <= 0 || order2.ID <= order1.ID || (order1.ID>>12)&1023 != 7 {
+		t.Errorf("Should generate increasing snowflake keys with the node, but got %v, %v", order1.ID, order2.ID)
+	}
+
+	var order SnowflakeOrder
+	if err := DB.First(&order, order2.ID).Error; err != nil || order.Name != "snowflake2" {
+		t.Errorf("Should find order with the generated snowflake key, but got %+v, %v", order, err)
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	snowflake := &gorm.Snowflake{Node: 1}
+	ids := map[int64]bool{}
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := snowflake.NextID()
+		if err != nil || ids[id] || id <= last {
+			t.Fatalf("Snowflake ids should be unique and increasing, but got %v after %v, %v", id, last, err)
+		}
+		ids[id], last = true, id
+	}
+
+	if _, err := (&gorm.Snowflake{Node: 1024}).NextID(); err == nil {
+		t.Errorf("Should get error for invalid node")
+	}
+}
+
+type AllocatedOrder struct {
+	ID   int64 `gorm:"primary_key;id_gen"`
+	Name string
+}
+
+type sequenceGenerator struct {
+	next       int64
+	batchCalls int
+}
+
+func (generator *sequenceGenerator) NextID() (int64, error) {
+	generator.next++
+	return generator.next, nil
+}
+
+func (generator *sequenceGenerator) NextIDs(n int) ([]int64, error) {
+	generator.batchCalls++
+	var ids []int64
+	for i := 0; i < n; i++ {
+		id, _ := generator.NextID()
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func TestIDGenerator(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&AllocatedOrder{})
+	db.AutoMigrate(&AllocatedOrder{})
+
+	generator := &sequenceGenerator{next: 1000}
+	db.SetIDGenerator(generator)
+
+	order := AllocatedOrder{Name: "order1"}
+	if err := db.Create(&order).Error; err != nil || order.ID != 1001 {
+		t.Errorf("Should allocate id with the generator, but got %v, %v", order.ID, err)
+	}
+
+	orders := []AllocatedOrder{{Name: "order2"}, {ID: 1, Name: "order3"}, {Name: "order4"}}
+	if err := db.AllocateIDs(&orders); err != nil {
+		t.Errorf("Failed to allocate ids, got %v", err)
+	}
+
+	if orders[0].ID != 1002 || orders[1].ID != 1 || orders[2].ID != 1003 || generator.batchCalls != 1 {
+		t.Errorf("Should allocate ids of blank fields in one batch, but got %+v, %v batches", orders, generator.batchCalls)
+	}
+
+	for _, order := range orders {
+		db.Create(&order)
+	}
+
+	var count int
+	if db.Model(&AllocatedOrder{}).Count(&count); count != 4 || generator.next != 1003 {
+		t.Errorf("Records with allocated ids should be created, but got %v records, last id %v", count, generator.next)
+	}
+}
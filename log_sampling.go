@@ -0,0 +1,78 @@
+package gorm
+
+import (
+	"sync"
+	"time"
+)
+
+// slowSQLThreshold statements running longer than it are logged as slow sql
+const slowSQLThreshold = 200 * time.Millisecond
+
+// LogSampling controls how many successful statements are logged, errors and slow statements are always logged
+type LogSampling struct {
+	// Every log 1 of every N successful statements, log all of them if it is 0 or 1
+	Every uint64
+	// Rate max successful statements logged per second with a token bucket, no limit if it is 0
+	Rate float64
+	// Burst size of the token bucket, defaults to Rate, and at least 1 so rates less than 1 still log statements
+	Burst int
+}
+
+// SetLogSampling sample logs of successful statements for both the trace log and the logger of LogMode, e.g:
+//    db.SetLogSampling(gorm.LogSampling{Every: 100, Rate: 10})
+//
+// DBs cloned before won't sample the trace log, so set it right after opening the db
+func (s *DB) SetLogSampling(sampling LogSampling) *DB {
+	s.parent.logSampler = &logSampler{LogSampling: sampling}
+	s.parent.db.logSampler = &logSampler{LogSampling: sampling}
+	s.db.logSampler = s.parent.db.logSampler
+	return s
+}
+
+// logSampler samples successful statements, nil sampler samples all of them
+type logSampler struct {
+	LogSampling
+	mutex  sync.Mutex
+	count  uint64
+	tokens float64
+	last   time.Time
+}
+
+// sample return true if the statement should be logged
+func (sampler *logSampler) sample() bool {
+	if sampler == nil {
+		return true
+	}
+
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+
+	sampler.count++
+	if sampler.Every > 1 && sampler.count%sampler.Every != 1 {
+		return false
+	}
+
+	if sampler.Rate > 0 {
+		burst := float64(sampler.Burst)
+		if burst <= 0 {
+			burst = sampler.Rate
+		}
+		if burst < 1 {
+			burst = 1
+		}
+
+		now := time.Now()
+		if sampler.last.IsZero() {
+			sampler.tokens = burst
+		} else if sampler.tokens += now.Sub(sampler.last).Seconds() * sampler.Rate; sampler.tokens > burst {
+			sampler.tokens = burst
+		}
+		sampler.last = now
+
+		if sampler.tokens < 1 {
+			return false
+		}
+		sampler.tokens--
+	}
+	return true
+}
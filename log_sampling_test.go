@@ -0,0 +1,54 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestLogSampling(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	collector := &sqlCollector{}
+	db.SetLogger(collector)
+	db.SetLogSampling(gorm.LogSampling{Every: 3})
+	db = db.LogMode(true)
+
+	for i := 0; i < 6; i++ {
+		db.First(&User{})
+	}
+
+	if len(collector.sqls) != 2 {
+		t.Errorf("Should log 1 of every 3 statements, but got %v", len(collector.sqls))
+	}
+
+	db.Table("not_existing_table").First(&User{})
+	db.Table("not_existing_table").First(&User{})
+	if len(collector.sqls) != 4 {
+		t.Errorf("Failed statements should always be logged, but got %v", len(collector.sqls))
+	}
+
+	collector.sqls = nil
+	db.SetLogSampling(gorm.LogSampling{Rate: 0.001, Burst: 2})
+	for i := 0; i < 5; i++ {
+		db.First(&User{})
+	}
+
+	if len(collector.sqls) != 2 {
+		t.Errorf("Should limit rate of logs, but got %v", len(collector.sqls))
+	}
+
+	collector.sqls = nil
+	db.SetLogSampling(gorm.LogSampling{Rate: 0.5})
+	for i := 0; i < 3; i++ {
+		db.First(&User{})
+	}
+
+	if len(collector.sqls) != 1 {
+		t.Errorf("Should log statements with rate less than 1, but got %v", len(collector.sqls))
+	}
+}
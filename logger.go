@@ -68,6 +68,11 @@ var LogFormatter = func(values ...interface{}) (messages []interface{}) {
 func PrintSQL(query string, args ...interface{}) (sql string) {
 	var formattedValues []string
 	for _, value := range args {
+		if _, ok := value.(sensitiveValue); ok {
+			formattedValues = append(formattedValues, "'***'")
+			continue
+		}
+
 		indirectValue := reflect.Indirect(reflect.ValueOf(value))
 		if indirectValue.IsValid() {
 			value = indirectValue.Interface()
@@ -3,6 +3,7 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"github.com/aws/aws-xray-sdk-go/xray"
@@ -10,14 +11,43 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	targetMaster = "master"
+	targetSlave  = "slave"
+)
+
 type ctxDB struct {
-	dbSQL      SQLCommon //主库，写或事务操作
-	dbSQLSlave SQLCommon //从库，非事务读操作
-	ctx        context.Context
-	source     string
+	dbSQL       SQLCommon //主库，写或事务操作
+	dbSQLSlave  SQLCommon //从库，非事务读操作
+	ctx         context.Context
+	source      string
+	sourceFixed bool //source由WithSource指定，不再取调用者
+	timeout     time.Duration
+	logSampler  *logSampler
+
+	masterBreaker *CircuitBreaker
+	slaveBreaker  *CircuitBreaker
+
+	interceptors *queryInterceptors
+	rewriters    []QueryRewriter
+	proxyMode    *ProxyMode
+	target       string //Master或Slave明确指定的节点
+	readOnly     bool   //只读，拒绝写操作
+	segmentHooks []SegmentHook
+	segmentNamer SegmentNamer
+	annotations  map[string]interface{}
+	scope        *Scope //执行语句的scope，用于追踪
+
+	txSource    SQLCommon //开启事务的库
+	namedQuery  *namedQuery
+	savepoint   *savepoint //事务中开启的嵌套事务
+	commitHooks *[]func()  //事务提交后执行的函数
+	faults      *FaultConfig
+	queryStats  *queryStats
 }
 
 //用在query中，如果是事务或是写操作用主库，否则用从库
@@ -37,22 +67,31 @@ func (db ctxDB) getDBSQLInNoTxQuery() (dbSQL SQLCommon) {
 // 如果没有主库，那么后面执行sql时候会报空指针的错误，符合逻辑
 func (db *ctxDB) useMaster() {
 	db.dbSQLSlave = nil
+	db.target = targetMaster
 }
 
 //为了记录trace_id而直接打日志
-func beginSeg(db ctxDB, query string, args ...interface{}) func(errPtr *error, r func() *int64) {
+func beginSeg(db ctxDB, master bool, query string, args ...interface{}) func(errPtr *error, r func() *int64) {
 	sql := PrintSQL(query, args...)
 	entry := logrus.WithContext(db.ctx).WithFields(logrus.Fields{
 		"sql":    sql,
 		"stack":  nil,
 		"source": db.source,
 	})
+	if db.namedQuery != nil {
+		entry = entry.WithField("query", db.namedQuery.name)
+	}
 	start := time.Now()
-	var seg *xray.Segment
+	var (
+		seg  *xray.Segment
+		info StatementInfo
+	)
 	if db.ctx != nil && xray.GetSegment(db.ctx) != nil {
-		_, seg = xray.BeginSubsegment(db.ctx, db.source)
+		info = db.statementInfo(sql, master)
+		_, seg = xray.BeginSubsegment(db.ctx, db.segmentName(info))
 		seg.Namespace = "remote"
 		seg.GetSQL().SanitizedQuery = sql
+		db.annotate(seg)
 	}
 	return func(errPtr *error, getRows func() *int64) {
 		var err error
@@ -60,23 +99,41 @@ func beginSeg(db ctxDB, query string, args ...interface{}) func(errPtr *error, r
 			err = *errPtr
 		}
 		end := time.Now()
+		duration := end.Sub(start)
+		rows := getRows()
 		if seg != nil {
+			info.Duration, info.Error = duration, err
+			if rows != nil {
+				info.RowsAffected = *rows
+			}
+			db.onSegment(seg, info)
 			seg.Close(err)
 		}
-		duration := end.Sub(start)
+		db.namedQuery.observe(duration, err)
+		db.queryStats.observe(query, duration, rows, err)
+		if err != nil {
+			source := db.source
+			if source == "" {
+				source = fileWithLineNum()
+			}
+			*errPtr = wrapQueryError(err, query, args, source, duration)
+		}
 
 		entry = entry.WithField("duration", duration.String())
-		if r := getRows(); r != nil {
-			entry = entry.WithField("exec_rows", *r) //只打印执行语句的行数，不打印查询语句行数
+		if rows != nil {
+			entry = entry.WithField("exec_rows", *rows) //只打印执行语句的行数，不打印查询语句行数
 		}
 		if err != nil {
 			entry.WithError(err).Error()
 			return
 		}
-		if duration >= 200*time.Millisecond {
+		if duration >= slowSQLThreshold {
 			entry.Warn("slow sql") //慢查询警告
 			return
 		}
+		if !db.logSampler.sample() {
+			return
+		}
 		entry.Debug()
 		if db.ctx == nil {
 			entry.Trace("nil context, forget call WithContext?") //不然比较吵人
@@ -87,31 +144,150 @@ func beginSeg(db ctxDB, query string, args ...interface{}) func(errPtr *error, r
 
 var rowsNil = func() *int64 { return nil }
 
-func (db ctxDB) Exec(query string, args ...interface{}) (result sql.Result, err error) {
-	defer beginSeg(db, query, args...)(&err, func() *int64 {
+func (db ctxDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if err := db.readOnlyStatement(query, false); err != nil {
+		return nil, err
+	}
+	query, args = db.rewrite(query, args)
+	query, err := db.proxyStatement(query, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(db.interceptors.load()) == 0 {
+		return db.exec(db.ctx, query, args...)
+	}
+
+	result, err := db.intercept(query, args, func(ctx context.Context, query string, args []interface{}) (interface{}, error) {
+		return db.exec(ctx, query, args...)
+	})
+	r, _ := result.(sql.Result)
+	return r, err
+}
+func (db ctxDB) exec(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	defer beginSeg(db, true, query, args...)(&err, func() *int64 {
 		if err != nil {
 			return nil
 		}
 		rows, _ := result.RowsAffected()
 		return &rows
 	})
+	trial, err := db.masterBreaker.allow()
+	if err != nil {
+		return
+	}
+	defer func() { db.masterBreaker.record(trial, err) }()
+	if err = db.injectFault(ctx, faultExec); err != nil {
+		return
+	}
+
+	if execer, ok := db.dbSQL.(sqlExecContext); ok && db.timeout > 0 {
+		ctx, cancel := db.statementContext(ctx)
+		defer cancel()
+		result, err = execer.ExecContext(ctx, query, args...)
+		err = db.timeoutError(ctx, err)
+		return
+	}
 	result, err = db.dbSQL.Exec(query, args...) //FIXME: 是否需要替换成ExecContent
 	return
 }
 func (db ctxDB) Prepare(query string) (stmt *sql.Stmt, err error) {
-	defer beginSeg(db, query)(&err, rowsNil)
+	if err = db.readOnlyStatement(query, true); err != nil {
+		return
+	}
+	query, _ = db.rewrite(query, nil)
+	defer beginSeg(db, true, query)(&err, rowsNil)
 	stmt, err = db.dbSQL.Prepare(query)
 	return
 }
-func (db ctxDB) Query(query string, args ...interface{}) (rows *sql.Rows, err error) {
+func (db ctxDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if err := db.readOnlyStatement(query, true); err != nil {
+		return nil, err
+	}
+	query, args = db.rewrite(query, args)
+	query, err := db.proxyStatement(query, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(db.interceptors.load()) == 0 {
+		return db.query(db.ctx, query, args...)
+	}
+
+	result, err := db.intercept(query, args, func(ctx context.Context, query string, args []interface{}) (interface{}, error) {
+		return db.query(ctx, query, args...)
+	})
+	rows, _ := result.(*sql.Rows)
+	return rows, err
+}
+func (db ctxDB) query(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
 	//NOTE: 不能用rows.Next()来获取长度，因为外面会用rows.Next()把数据拷贝出来，因此不打印行数了
-	defer beginSeg(db, query, args...)(&err, rowsNil)
-	rows, err = db.getDBSQLInNoTxQuery().Query(query, args...)
+	dbSQL, breaker := db.queryNode()
+	defer beginSeg(db, dbSQL == db.dbSQL, query, args...)(&err, rowsNil)
+	trial, err := breaker.allow()
+	if err != nil {
+		return
+	}
+	defer func() { breaker.record(trial, err) }()
+	if err = db.injectFault(ctx, faultQuery); err != nil {
+		return
+	}
+
+	if queryer, ok := dbSQL.(sqlQueryContext); ok && db.timeout > 0 {
+		// rows are read after returning, the context is canceled when the scope has read them,
+		// or released after the timeout if they are returned to the caller
+		ctx, cancel := db.statementContext(ctx)
+		db.deferCancel(cancel)
+		rows, err = queryer.QueryContext(ctx, query, args...)
+		err = db.timeoutError(ctx, err)
+		return
+	}
+	rows, err = dbSQL.Query(query, args...)
 	return
 }
-func (db ctxDB) QueryRow(query string, args ...interface{}) (row *sql.Row) {
-	defer beginSeg(db, query, args...)(nil, rowsNil)
-	row = db.getDBSQLInNoTxQuery().QueryRow(query, args...)
+func (db ctxDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	if err := db.readOnlyStatement(query, true); err != nil {
+		return db.errorRow(err)
+	}
+	query, args = db.rewrite(query, args)
+	query, err := db.proxyStatement(query, true)
+	if err != nil {
+		return db.errorRow(err)
+	}
+	if len(db.interceptors.load()) == 0 {
+		return db.queryRow(db.ctx, query, args...)
+	}
+
+	result, err := db.intercept(query, args, func(ctx context.Context, query string, args []interface{}) (interface{}, error) {
+		return db.queryRow(ctx, query, args...), nil
+	})
+	if row, ok := result.(*sql.Row); ok && err == nil {
+		return row
+	} else if err == nil {
+		err = fmt.Errorf("query interceptor returned %T instead of *sql.Row", result)
+	}
+	return db.errorRow(err)
+}
+func (db ctxDB) queryRow(ctx context.Context, query string, args ...interface{}) (row *sql.Row) {
+	dbSQL, breaker := db.queryNode()
+	defer beginSeg(db, dbSQL == db.dbSQL, query, args...)(nil, rowsNil)
+	trial, err := breaker.allow()
+	if err != nil {
+		return db.errorRow(err)
+	}
+	if err := db.injectFault(ctx, faultQuery); err != nil {
+		breaker.record(trial, err)
+		return db.errorRow(err)
+	}
+	defer func() { breaker.record(trial, rowError(row)) }()
+
+	if queryer, ok := dbSQL.(sqlQueryContext); ok && db.timeout > 0 {
+		// the row is scanned after returning, the context is canceled when the scope has scanned it,
+		// or released after the timeout if it is returned to the caller
+		ctx, cancel := db.statementContext(ctx)
+		db.deferCancel(cancel)
+		row = queryer.QueryRowContext(ctx, query, args...)
+		return
+	}
+	row = dbSQL.QueryRow(query, args...)
 	return
 }
 
@@ -128,13 +304,35 @@ type DB struct {
 	logMode           logModeValue
 	logger            logger
 	search            *search
-	values            sync.Map
+	values            atomic.Value // settings shared with clones, replaced when setting
+	valuesLock        sync.Mutex
+	sessionCallbacks  *Callback
+	statementSQL      string
+	statementVars     []interface{}
 
 	// global db
-	parent        *DB
-	callbacks     *Callback
-	dialect       Dialect
-	singularTable bool
+	parent         *DB
+	callbacks      *Callback
+	dialect        Dialect
+	sharedDialect  bool         // the dialect is shared with the db cloned from, it isn't bound to the connection of the db
+	boundDialect   atomic.Value // dialect bound to the connection of the db, created by Dialect when the dialect is shared
+	singularTable  bool
+	namingStrategy *NamingStrategy
+	plugins        map[string]Plugin
+	pluginNames    []string
+	maskedColumns  atomic.Value // []string, replaced when registering patterns
+	maskedLock     sync.Mutex
+	logSampler     *logSampler
+	tenantResolver TenantResolver
+	location       *time.Location
+	driverLocation *time.Location // location the driver formats times in, e.g. `loc` of mysql data source names
+	snowflake      *Snowflake
+	idGenerator    IDGenerator
+	namedQueries   map[string]*namedQuery
+	skipLocked     *skipLockedSupport
+
+	// function computing suffixes of model tables for each statement
+	tableSuffixFunc func(model interface{}, ctx context.Context) string
 
 	// function to be used to override the creating of a new timestamp
 	nowFuncOverride func() time.Time
@@ -155,10 +353,20 @@ func (s *DB) WithContext(ctx context.Context) *DB {
 	}
 	clone := s.clone() //NOTE: 复制避免多个线程使用同一个ctx
 	clone.db.ctx = ctx
-	clone.db.source = GetSource(2)
+	clone.db.setSource(2)
+	clone.applyContextSettings()
 	return clone
 }
 
+// Context return the context set with WithContext, or context.Background() if it is not set,
+// callbacks could access it with scope.Context() to do tracing, deadline checks etc
+func (s *DB) Context() context.Context {
+	if s.db.ctx == nil {
+		return context.Background()
+	}
+	return s.db.ctx
+}
+
 // Open initialize a new db connection, need to import driver first, e.g:
 //
 //     import _ "github.com/go-sql-driver/mysql"
@@ -170,7 +378,23 @@ func (s *DB) WithContext(ctx context.Context) *DB {
 //    // import _ "github.com/lun-zhang/gorm/dialects/postgres"
 //    // import _ "github.com/lun-zhang/gorm/dialects/sqlite"
 //    // import _ "github.com/lun-zhang/gorm/dialects/mssql"
+//
+// Pool settings and the naming strategy could be passed with Options
+//    db, err := gorm.Open("mysql", dsn, gorm.Options{PoolOptions: gorm.PoolOptions{MaxOpenConns: 100}})
+//
+// Besides data source names, db could be opened with a DSNProvider to connect with rotated credentials, or a driver.Connector
 func Open(dialect string, args ...interface{}) (db *DB, err error) {
+	var options Options
+	var sources []interface{}
+	for _, arg := range args {
+		if value, ok := arg.(Options); ok {
+			options = value
+		} else {
+			sources = append(sources, arg)
+		}
+	}
+	args = sources
+
 	if len(args) == 0 {
 		err = errors.New("invalid database source")
 		return nil, err
@@ -190,6 +414,15 @@ func Open(dialect string, args ...interface{}) (db *DB, err error) {
 		}
 		dbSQL, err = sql.Open(driver, source)
 		ownDbSQL = true
+	case DSNProvider:
+		dbSQL, err = openDSNProvider(dialect, value)
+		ownDbSQL = true
+	case func() (string, error):
+		dbSQL, err = openDSNProvider(dialect, value)
+		ownDbSQL = true
+	case driver.Connector:
+		dbSQL = sql.OpenDB(value)
+		ownDbSQL = true
 	case SQLCommon:
 		dbSQL = value
 		ownDbSQL = false
@@ -198,56 +431,85 @@ func Open(dialect string, args ...interface{}) (db *DB, err error) {
 	}
 
 	db = &DB{
-		db:        ctxDB{dbSQL: dbSQL},
-		logger:    defaultLogger,
-		callbacks: DefaultCallback,
-		dialect:   newDialect(dialect, dbSQL),
+		db:             ctxDB{dbSQL: dbSQL, interceptors: &queryInterceptors{}},
+		logger:         defaultLogger,
+		callbacks:      DefaultCallback,
+		dialect:        newDialect(dialect, dbSQL),
+		driverLocation: driverLocation(dialect, source),
+		skipLocked:     &skipLockedSupport{},
 	}
 	db.parent = db
 	if err != nil {
 		return
 	}
+	if options.NamingStrategy != nil {
+		db.SetNamingStrategy(options.NamingStrategy)
+	}
+	if d, ok := dbSQL.(*sql.DB); ok {
+		options.PoolOptions.apply(d)
+	}
 	// Send a ping to make sure the database connection is alive.
 	if d, ok := dbSQL.(*sql.DB); ok {
 		if err = d.Ping(); err != nil && ownDbSQL {
 			d.Close()
+			return
 		}
 	}
+	if name := detectDialect(dialect, source, dbSQL); name != dialect {
+		db.dialect = newDialect(name, dbSQL)
+	}
 	return
 }
 
-func openAndPing(driver, source string) (db *sql.DB, err error) {
+func openAndPing(driver, source string, pool PoolOptions, retry RetryConfig) (db *sql.DB, err error) {
 	db, err = sql.Open(driver, source)
 	if err != nil {
 		return
 	}
+	pool.apply(db)
 	// Send a ping to make sure the database connection is alive.
-	if err = db.Ping(); err != nil {
+	if err = retry.do(db.Ping); err != nil {
 		db.Close()
 	}
 	return
 }
 
-func OpenMasterAndSlave(driver, master, slave string) (db *DB, err error) {
-	var ctxDB ctxDB
+// OpenMasterAndSlave open db with master and slave, pool settings of them could be passed with Options
+func OpenMasterAndSlave(driver, master, slave string, options ...Options) (db *DB, err error) {
+	return openMasterAndSlave(driver, master, slave, RetryConfig{}, options...)
+}
+
+func openMasterAndSlave(driver, master, slave string, retry RetryConfig, options ...Options) (db *DB, err error) {
+	var ctxDB = ctxDB{interceptors: &queryInterceptors{}}
+	var option Options
+	if len(options) > 0 {
+		option = options[0]
+	}
 
-	ctxDB.dbSQL, err = openAndPing(driver, master)
+	masterDB, err := openAndPing(driver, master, option.PoolOptions, retry)
 	if err != nil {
 		return
 	}
+	ctxDB.dbSQL = masterDB
 
-	ctxDB.dbSQLSlave, err = openAndPing(driver, slave)
+	ctxDB.dbSQLSlave, err = openAndPing(driver, slave, option.slavePoolOptions(), retry)
 	if err != nil {
+		masterDB.Close()
 		return
 	}
 
 	db = &DB{
-		db:        ctxDB,
-		logger:    defaultLogger,
-		callbacks: DefaultCallback,
-		dialect:   newDialect(driver, ctxDB), //NOTE: dialect也同时使用主库和从库
+		db:             ctxDB,
+		logger:         defaultLogger,
+		callbacks:      DefaultCallback,
+		dialect:        newDialect(detectDialect(driver, master, ctxDB.dbSQL), ctxDB), //NOTE: dialect也同时使用主库和从库
+		driverLocation: driverLocation(driver, master),
+		skipLocked:     &skipLockedSupport{},
 	}
 	db.parent = db
+	if option.NamingStrategy != nil {
+		db.SetNamingStrategy(option.NamingStrategy)
+	}
 	return
 }
 
@@ -263,10 +525,14 @@ type closer interface {
 	Close() error
 }
 
-// Close close current db connection.  If database connection is not an io.Closer, returns an error.
+// Close close current db connection and registered plugins.  If database connection is not an io.Closer, returns an error.
 func (s *DB) Close() error {
+	err := s.closePlugins()
 	if db, ok := s.parent.db.dbSQL.(closer); ok {
-		return db.Close()
+		if e := db.Close(); e != nil {
+			return e
+		}
+		return err
 	}
 	return errors.New("can't close current db")
 }
@@ -298,6 +564,15 @@ func (s *DB) Master() *DB {
 	return clone
 }
 
+//明确表示使用从库:
+// 恢复被Master去掉的从库，代理模式下查询会加上从库的hint
+func (s *DB) Slave() *DB {
+	clone := s.clone()
+	clone.db.dbSQLSlave = s.parent.db.dbSQLSlave
+	clone.db.target = targetSlave
+	return clone
+}
+
 // CommonDB return the underlying `*sql.DB` or `*sql.Tx` instance, mainly intended to allow coexistence with legacy non-GORM code.
 func (s *DB) CommonDB() SQLCommon {
 	return s.db.dbSQL
@@ -305,9 +580,37 @@ func (s *DB) CommonDB() SQLCommon {
 
 // Dialect get dialect
 func (s *DB) Dialect() Dialect {
+	if s.sharedDialect {
+		// dbs may be used by goroutines concurrently, so cache the bound dialect aside rather than replacing the shared one
+		if dialect, ok := s.boundDialect.Load().(Dialect); ok {
+			return dialect
+		}
+		dialect := newDialect(s.dialect.GetName(), s.db)
+		s.boundDialect.Store(dialect)
+		return dialect
+	}
 	return s.dialect
 }
 
+// ownDialect return the dialect bound to the connection of the db, dialects are shared by clones until they are used,
+// as most clones only build conditions, it should be called with dbs owned by scopes
+func (s *DB) ownDialect() Dialect {
+	if s.sharedDialect {
+		if dialect, ok := s.boundDialect.Load().(Dialect); ok {
+			s.dialect, s.sharedDialect = dialect, false
+		} else {
+			s.refreshDialect()
+		}
+	}
+	return s.dialect
+}
+
+// refreshDialect bind a new dialect to the connection of the db after changing the connection
+func (s *DB) refreshDialect() {
+	s.dialect = newDialect(s.dialect.GetName(), s.db)
+	s.sharedDialect = false
+}
+
 // Callback return `Callbacks` container, you could add/change/delete callbacks with it
 //     db.Callback().Create().Register("update_created_at", updateCreated)
 // Refer https://jinzhu.github.io/gorm/development.html#callbacks
@@ -316,6 +619,28 @@ func (s *DB) Callback() *Callback {
 	return s.parent.callbacks
 }
 
+// WithCallbacks return a db using its own copy of callbacks changed by fc, callbacks of other dbs are not affected,
+// e.g. a background job could skip callbacks without affecting web requests
+//    jobDB := db.WithCallbacks(func(callback *gorm.Callback) {
+//      callback.Create().Remove("gorm:audit")
+//    })
+//
+// Callbacks registered with Callback later are not applied to the returned db
+func (s *DB) WithCallbacks(fc func(callback *Callback)) *DB {
+	clone := s.clone()
+	clone.sessionCallbacks = s.currentCallbacks().clone(s.logger)
+	fc(clone.sessionCallbacks)
+	return clone
+}
+
+// currentCallbacks return callbacks set with WithCallbacks or the global callbacks
+func (s *DB) currentCallbacks() *Callback {
+	if s.sessionCallbacks != nil {
+		return s.sessionCallbacks
+	}
+	return s.parent.callbacks
+}
+
 // SetLogger replace default logger
 func (s *DB) SetLogger(log logger) {
 	s.logger = log
@@ -340,11 +665,15 @@ func (s *DB) SetNowFuncOverride(nowFuncOverride func() time.Time) *DB {
 // Get a new timestamp, using the provided nowFuncOverride on the DB instance if set,
 // otherwise defaults to the global NowFunc()
 func (s *DB) nowFunc() time.Time {
+	now := NowFunc()
 	if s.nowFuncOverride != nil {
-		return s.nowFuncOverride()
+		now = s.nowFuncOverride()
 	}
 
-	return NowFunc()
+	if loc := s.timeLocation(); loc != nil {
+		return now.In(loc)
+	}
+	return now
 }
 
 // BlockGlobalUpdate if true, generates an error on update/delete without where clause.
@@ -398,10 +727,30 @@ func (s *DB) SubQuery() *SqlExpr {
 }
 
 // Where return a new relation, filter records with given conditions, accepts `map`, `struct` or `string` as conditions, refer http://jinzhu.github.io/gorm/crud.html#query
+//
+// Conditions of a db are grouped in parentheses, so they could be nested with `Where`, `Or` and `Not`
+//    db.Where(db.Where("pizza = ?", "pepperoni").Where("size = ?", "small")).Or(db.Where("pizza = ?", "hawaiian").Where("size = ?", "xlarge")).Find(&pizzas)
+//    // SELECT * FROM pizzas WHERE ((pizza = 'pepperoni') AND (size = 'small')) OR ((pizza = 'hawaiian') AND (size = 'xlarge'));
 func (s *DB) Where(query interface{}, args ...interface{}) *DB {
 	return s.clone().search.Where(query, args...).db
 }
 
+// WhereStruct filter records with struct conditions like `Where`, but could include fields with zero values, e.g:
+//    db.WhereStruct(&User{Name: "jinzhu", Status: 0}, gorm.IncludeZeroFields("Status")).Find(&users)
+//    // SELECT * FROM users WHERE (name = 'jinzhu') AND (status = 0);
+//
+// Fields tagged with `include_zero` are always included in struct conditions, even with `Where`
+//    type User struct {
+//      Status int `gorm:"include_zero"`
+//    }
+func (s *DB) WhereStruct(value interface{}, options ...StructConditionOption) *DB {
+	condition := structCondition{value: value}
+	for _, option := range options {
+		option(&condition)
+	}
+	return s.clone().search.Where(condition).db
+}
+
 // Or filter records that match before conditions or this one, similar to `Where`
 func (s *DB) Or(query interface{}, args ...interface{}) *DB {
 	return s.clone().search.Or(query, args...).db
@@ -431,12 +780,13 @@ func (s *DB) Order(value interface{}, reorder ...bool) *DB {
 }
 
 // Select specify fields that you want to retrieve from database when querying, by default, will select all fields;
-// When creating/updating, specify fields that you want to save to database
+// When creating/updating, specify fields that you want to save to database, fields of embedded structs could be specified by paths like `Billing.Street`,
+// or all of them by paths of embedded structs like `Billing`
 func (s *DB) Select(query interface{}, args ...interface{}) *DB {
 	return s.clone().search.Select(query, args...).db
 }
 
-// Omit specify fields that you want to ignore when saving to database for creating, updating
+// Omit specify fields that you want to ignore when saving to database for creating, updating, paths of embedded structs are supported like `Select`
 func (s *DB) Omit(columns ...string) *DB {
 	return s.clone().search.Omit(columns...).db
 }
@@ -453,10 +803,23 @@ func (s *DB) Having(query interface{}, values ...interface{}) *DB {
 
 // Joins specify Joins conditions
 //     db.Joins("JOIN emails ON emails.user_id = users.id AND emails.email = ?", "jinzhu@example.org").Find(&user)
+// If the query is name of a has one or belongs to association, it will be loaded with LEFT JOIN in the same query
+//     db.Joins("Profile").Find(&users)
+// Many2many associations filter records having associations matched with conditions passed as args, with `EXISTS` through the join table,
+// associations aren't loaded and records aren't repeated, preload them with join table conditions instead
+//     db.Joins("Groups", `user_groups.role = ? AND "Groups".name = ?`, "admin", "dev").Find(&users)
 func (s *DB) Joins(query string, args ...interface{}) *DB {
 	return s.clone().search.Joins(query, args...).db
 }
 
+// AsOfSystemTime read historical data with `AS OF SYSTEM TIME`, only supported by CockroachDB and only applied to selects,
+// the timestamp should be a string literal, a number, or a staleness function like follower_read_timestamp(), e.g. follower reads:
+//     db.AsOfSystemTime("follower_read_timestamp()").Find(&users)
+//     db.AsOfSystemTime("'-10s'").Find(&users)
+func (s *DB) AsOfSystemTime(timestamp string) *DB {
+	return s.clone().search.AsOfSystemTime(timestamp).db
+}
+
 // Scopes pass current database connection to arguments `func(*DB) *DB`, which could be used to add conditions dynamically
 //     func AmountGreaterThan1000(db *gorm.DB) *gorm.DB {
 //         return db.Where("amount > ?", 1000)
@@ -477,6 +840,37 @@ func (s *DB) Scopes(funcs ...func(*DB) *DB) *DB {
 	return s
 }
 
+// SkipHooks return a db creating, updating and deleting records without calling model hooks like `BeforeSave`, `AfterCreate`, e.g.
+// for bulk loads and data repairs, other callbacks could be removed for the returned db with WithCallbacks
+//    db.SkipHooks().Create(&user)
+//    db.SkipHooks().WithCallbacks(func(callback *gorm.Callback) {
+//      callback.Update().Remove("gorm:audit")
+//    }).Model(&user).Update("name", "hello")
+func (s *DB) SkipHooks() *DB {
+	return s.Set("gorm:skip_hooks", true)
+}
+
+// RequireRowsAffected return a db returning ErrNoRowsAffected when updating or deleting records affects no rows,
+// mysql counts changed rows only unless connecting with `clientFoundRows=true`
+//    if err := db.RequireRowsAffected().Model(&order).Where("status = ?", "pending").Update("status", "paid").Error; err == gorm.ErrNoRowsAffected {
+//      // the order has been paid or canceled by others
+//    }
+func (s *DB) RequireRowsAffected() *DB {
+	return s.Set("gorm:require_rows_affected", true)
+}
+
+// ReturnErrRecordNotFound return a db controlling whether First, Take and Last set ErrRecordNotFound when no record is found,
+// it is enabled by default, RowsAffected is 0 when it is disabled and nothing is found
+//    db = db.ReturnErrRecordNotFound(false)
+//    if db.First(&user, id).RowsAffected == 0 {
+//      // not found
+//    }
+//
+// FirstOrError, TakeOrError and LastOrError always return ErrRecordNotFound
+func (s *DB) ReturnErrRecordNotFound(enable bool) *DB {
+	return s.Set("gorm:return_err_record_not_found", enable)
+}
+
 // Unscoped return all record including deleted record, refer Soft Delete https://jinzhu.github.io/gorm/crud.html#soft-delete
 func (s *DB) Unscoped() *DB {
 	return s.clone().search.unscoped().db
@@ -498,14 +892,14 @@ func (s *DB) First(out interface{}, where ...interface{}) *DB {
 	newScope.Search.Limit(1)
 
 	return newScope.Set("gorm:order_by_primary_key", "ASC").
-		inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
+		inlineCondition(where...).callCallbacks(s.currentCallbacks().queries).db
 }
 
 // Take return a record that match given conditions, the order will depend on the database implementation
 func (s *DB) Take(out interface{}, where ...interface{}) *DB {
 	newScope := s.NewScope(out)
 	newScope.Search.Limit(1)
-	return newScope.inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
+	return newScope.inlineCondition(where...).callCallbacks(s.currentCallbacks().queries).db
 }
 
 // Last find last record that match given conditions, order by primary key
@@ -513,22 +907,37 @@ func (s *DB) Last(out interface{}, where ...interface{}) *DB {
 	newScope := s.NewScope(out)
 	newScope.Search.Limit(1)
 	return newScope.Set("gorm:order_by_primary_key", "DESC").
-		inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
+		inlineCondition(where...).callCallbacks(s.currentCallbacks().queries).db
+}
+
+// FirstOrError find first record that match given conditions like First, return ErrRecordNotFound if not found even if it is disabled by ReturnErrRecordNotFound
+func (s *DB) FirstOrError(out interface{}, where ...interface{}) error {
+	return s.ReturnErrRecordNotFound(true).First(out, where...).Error
+}
+
+// TakeOrError return a record that match given conditions like Take, return ErrRecordNotFound if not found even if it is disabled by ReturnErrRecordNotFound
+func (s *DB) TakeOrError(out interface{}, where ...interface{}) error {
+	return s.ReturnErrRecordNotFound(true).Take(out, where...).Error
+}
+
+// LastOrError find last record that match given conditions like Last, return ErrRecordNotFound if not found even if it is disabled by ReturnErrRecordNotFound
+func (s *DB) LastOrError(out interface{}, where ...interface{}) error {
+	return s.ReturnErrRecordNotFound(true).Last(out, where...).Error
 }
 
 // Find find records that match given conditions
 func (s *DB) Find(out interface{}, where ...interface{}) *DB {
-	return s.NewScope(out).inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
+	return s.NewScope(out).inlineCondition(where...).callCallbacks(s.currentCallbacks().queries).db
 }
 
 //Preloads preloads relations, don`t touch out
 func (s *DB) Preloads(out interface{}) *DB {
-	return s.NewScope(out).InstanceSet("gorm:only_preload", 1).callCallbacks(s.parent.callbacks.queries).db
+	return s.NewScope(out).InstanceSet("gorm:only_preload", 1).callCallbacks(s.currentCallbacks().queries).db
 }
 
 // Scan scan value to a struct
 func (s *DB) Scan(dest interface{}) *DB {
-	return s.NewScope(s.Value).Set("gorm:query_destination", dest).callCallbacks(s.parent.callbacks.queries).db
+	return s.NewScope(s.Value).Set("gorm:query_destination", dest).callCallbacks(s.currentCallbacks().queries).db
 }
 
 // Row return `*sql.Row` with given conditions
@@ -551,11 +960,59 @@ func (s *DB) ScanRows(rows *sql.Rows, result interface{}) error {
 
 	if clone.AddError(err) == nil {
 		scope.scan(rows, columns, scope.Fields())
+		scope.checkScan(scope.IndirectValue().Type(), columns)
 	}
 
 	return clone.Error
 }
 
+// Iterate stream records matching conditions into dest one by one, fn is called after scanning each record and running callbacks
+// after querying it like AfterFind, associations aren't preloaded, rows are closed when finished, return error from fn to stop iterating, e.g:
+//    var user User
+//    err := db.Where("age > ?", 18).Iterate(&user, func() error {
+//      return writer.Write(user)
+//    })
+func (s *DB) Iterate(dest interface{}, fn func() error) (err error) {
+	// reset dest, otherwise its primary key will be used as condition
+	value := reflect.Indirect(reflect.ValueOf(dest))
+	value.Set(reflect.Zero(value.Type()))
+
+	db := s
+	if s.Value == nil {
+		db = s.Model(dest)
+	}
+
+	scope := db.NewScope(db.Value)
+	defer scope.releaseStatements()
+	rows, err := scope.rows()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := rows.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		value.Set(reflect.Zero(value.Type()))
+		if err = db.ScanRows(rows, dest); err != nil {
+			return err
+		}
+
+		// run callbacks after querying for the row, e.g. decrypting and AfterFind
+		rowScope := db.NewScope(dest).InstanceSet("gorm:skip_query_callback", true).InstanceSet("gorm:iterated_row", true)
+		if err = rowScope.callCallbacks(db.currentCallbacks().queries).db.Error; err != nil {
+			return err
+		}
+
+		if err = fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // Pluck used to query single column from a model as a map
 //     var ages []int64
 //     db.Find(&users).Pluck("age", &ages)
@@ -563,6 +1020,40 @@ func (s *DB) Pluck(column string, value interface{}) *DB {
 	return s.NewScope(s.Value).pluck(column, value).db
 }
 
+// PluckMap query columns from a model into maps with a single query, keys of maps are column names returned by the database, e.g:
+//    var results []map[string]interface{}
+//    db.Model(&User{}).Where("age > ?", 18).PluckMap([]string{"id", "name"}, &results)
+//
+// Values are returned as they are scanned by the driver, except `[]byte` is converted to string
+func (s *DB) PluckMap(columns []string, value *[]map[string]interface{}) *DB {
+	return s.NewScope(s.Value).pluckMap(columns, value).db
+}
+
+// Exists return true if any record matches conditions, it stops at the first matched record rather than counting all of them,
+// and queries slave out of transactions like other queries, e.g:
+//    exists, err := db.Model(&User{}).Where("email = ?", email).Exists()
+//    // SELECT 1 FROM users WHERE (email = 'jinzhu@example.org') LIMIT 1;
+func (s *DB) Exists() (bool, error) {
+	scope := s.NewScope(s.Value)
+	scope.Search.Select("1").Limit(1)
+	scope.Search.orders = nil
+
+	var (
+		exists int
+		row    = scope.row()
+	)
+	if scope.HasError() {
+		return false, scope.db.Error
+	}
+
+	if err := row.Scan(&exists); err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Count get how many records for a model
 func (s *DB) Count(value interface{}) *DB {
 	return s.NewScope(s.Value).count(value).db
@@ -577,7 +1068,7 @@ func (s *DB) Related(value interface{}, foreignKeys ...string) *DB {
 // https://jinzhu.github.io/gorm/crud.html#firstorinit
 func (s *DB) FirstOrInit(out interface{}, where ...interface{}) *DB {
 	c := s.clone()
-	if result := c.First(out, where...); result.Error != nil {
+	if result := c.ReturnErrRecordNotFound(true).First(out, where...); result.Error != nil {
 		if !result.RecordNotFound() {
 			return result
 		}
@@ -590,31 +1081,59 @@ func (s *DB) FirstOrInit(out interface{}, where ...interface{}) *DB {
 
 // FirstOrCreate find first matched record or create a new one with given conditions (only works with struct, map conditions)
 // https://jinzhu.github.io/gorm/crud.html#firstorcreate
+//
+// Set `gorm:first_or_create_mode` to find the record again if it is created concurrently, refer FirstOrCreateMode
+//    db.Set("gorm:first_or_create_mode", gorm.FirstOrCreateUpsert).FirstOrCreate(&user, User{Email: "jinzhu@example.org"})
 func (s *DB) FirstOrCreate(out interface{}, where ...interface{}) *DB {
 	c := s.clone()
-	if result := s.First(out, where...); result.Error != nil {
+	if result := s.ReturnErrRecordNotFound(true).First(out, where...); result.Error != nil {
 		if !result.RecordNotFound() {
 			return result
 		}
-		return c.NewScope(out).inlineCondition(where...).initialize().callCallbacks(c.parent.callbacks.creates).db
-	} else if len(c.search.assignAttrs) > 0 {
-		return c.NewScope(out).InstanceSet("gorm:update_interface", c.search.assignAttrs).callCallbacks(c.parent.callbacks.updates).db
+
+		created, conflicted := c.createNotFound(out, where...)
+		if !conflicted {
+			return created
+		}
+
+		resetValue(out)
+		if result := s.ReturnErrRecordNotFound(true).First(out, where...); result.Error != nil {
+			return result
+		}
+	}
+
+	if len(c.search.assignAttrs) > 0 {
+		return c.NewScope(out).InstanceSet("gorm:update_interface", c.search.assignAttrs).callCallbacks(c.currentCallbacks().updates).db
 	}
 	return c
 }
 
 // Update update attributes with callbacks, refer: https://jinzhu.github.io/gorm/crud.html#update
-// WARNING when update with struct, GORM will not update fields that with zero value
+// WARNING when update with struct, GORM will not update fields that with zero value, except fields tagged with `update_zero`
 func (s *DB) Update(attrs ...interface{}) *DB {
 	return s.Updates(toSearchableMap(attrs...), true)
 }
 
 // Updates update attributes with callbacks, refer: https://jinzhu.github.io/gorm/crud.html#update
+//
+// Records of a slice are updated by their primary keys if no conditions given, refer `Delete`
+//    db.Model(&users).Updates(map[string]interface{}{"role": "admin"})
+//    // UPDATE users SET role='admin' WHERE id IN (1,2,3);
 func (s *DB) Updates(values interface{}, ignoreProtectedAttrs ...bool) *DB {
+	if s.byPrimaryKeys(s.Value) {
+		return s.batchByPrimaryKeys(s.Value, func(chunk interface{}) *DB {
+			return s.NewScope(chunk).
+				Set("gorm:ignore_protected_attrs", len(ignoreProtectedAttrs) > 0).
+				InstanceSet("gorm:update_interface", values).
+				InstanceSet("gorm:batch_primary_keys", true).
+				callCallbacks(s.currentCallbacks().updates).db
+		})
+	}
+
 	return s.NewScope(s.Value).
 		Set("gorm:ignore_protected_attrs", len(ignoreProtectedAttrs) > 0).
 		InstanceSet("gorm:update_interface", values).
-		callCallbacks(s.parent.callbacks.updates).db
+		callCallbacks(s.currentCallbacks().updates).db
 }
 
 // UpdateColumn update attributes without callbacks, refer: https://jinzhu.github.io/gorm/crud.html#update
@@ -624,36 +1143,114 @@ func (s *DB) UpdateColumn(attrs ...interface{}) *DB {
 
 // UpdateColumns update attributes without callbacks, refer: https://jinzhu.github.io/gorm/crud.html#update
 func (s *DB) UpdateColumns(values interface{}) *DB {
+	if s.byPrimaryKeys(s.Value) {
+		return s.batchByPrimaryKeys(s.Value, func(chunk interface{}) *DB {
+			return s.NewScope(chunk).
+				Set("gorm:update_column", true).
+				Set("gorm:save_associations", false).
+				InstanceSet("gorm:update_interface", values).
+				InstanceSet("gorm:batch_primary_keys", true).
+				callCallbacks(s.currentCallbacks().updates).db
+		})
+	}
+
 	return s.NewScope(s.Value).
 		Set("gorm:update_column", true).
 		Set("gorm:save_associations", false).
 		InstanceSet("gorm:update_interface", values).
-		callCallbacks(s.parent.callbacks.updates).db
+		callCallbacks(s.currentCallbacks().updates).db
+}
+
+// Increment increase the column atomically without callbacks, e.g:
+//    db.Model(&account).Increment("balance", 10)
+//    // UPDATE accounts SET balance = balance + 10 WHERE id = 111;
+//
+// If the model is a record with primary key, its field will be set to the new value, which is returned by `RETURNING` in postgres,
+// or selected from master after updating, the selected value may include changes committed by others unless in a transaction
+func (s *DB) Increment(column string, value interface{}) *DB {
+	return s.increment(column, "+", value)
+}
+
+// Decrement decrease the column atomically without callbacks, refer `Increment`
+//    db.Model(&product).Decrement("stock", 1)
+func (s *DB) Decrement(column string, value interface{}) *DB {
+	return s.increment(column, "-", value)
 }
 
-// Save update value in database, if the value doesn't have primary key, will insert it
+func (s *DB) increment(column string, operator string, value interface{}) *DB {
+	scope := s.NewScope(s.Value)
+	scope.Set("gorm:update_column", true).
+		Set("gorm:save_associations", false).
+		InstanceSet("gorm:update_interface", map[string]interface{}{
+			column: Expr(fmt.Sprintf("%v %v ?", scope.Quote(column), operator), value),
+		})
+
+	field, reload := scope.FieldByName(column)
+	reload = reload && scope.IndirectValue().Kind() == reflect.Struct && !scope.PrimaryKeyZero() && field.Field.CanAddr()
+	if reload {
+		scope.InstanceSet("gorm:update_returning", field)
+	}
+
+	scope.callCallbacks(s.currentCallbacks().updates)
+
+	if _, returned := scope.InstanceGet("gorm:update_returned"); reload && !returned && !scope.HasError() && scope.db.RowsAffected > 0 {
+		db := scope.NewDB().Master().Table(scope.TableName()).Select(scope.Quote(field.DBName))
+		for _, primaryField := range scope.PrimaryFields() {
+			db = db.Where(fmt.Sprintf("%v = ?", scope.Quote(primaryField.DBName)), primaryField.Field.Interface())
+		}
+		scope.Err(db.Row().Scan(field.Field.Addr().Interface()))
+	}
+	return scope.db
+}
+
+// Save update value in database, if the value doesn't have primary key, will insert it.
+// With dialects supporting upsert (mysql, postgres, sqlite), set `gorm:save_upsert` to true to insert or update a value with primary key
+// in one statement, only `BeforeSave`, `BeforeUpdate`, `AfterUpdate`, `AfterSave` hooks are called for it, and mysql updates the record
+// on conflicts of any unique key, not only the primary key. Saving with conditions, e.g. `Where` or conditions added by plugins like
+// TenantGuard, always updates the record then creates it if it doesn't exist
+//    db.Set("gorm:save_upsert", true).Save(&user)
 func (s *DB) Save(value interface{}) *DB {
 	scope := s.NewScope(value)
 	if !scope.PrimaryKeyZero() {
-		newDB := scope.callCallbacks(s.parent.callbacks.updates).db
+		if _, ok := scope.Dialect().(upsertSupporter); ok {
+			if upsert, ok := s.Get("gorm:save_upsert"); ok && upsert == true {
+				scope.InstanceSet("gorm:save_upsert", true)
+			}
+		}
+
+		newDB := scope.InstanceSet("gorm:save_or_create", true).callCallbacks(s.currentCallbacks().updates).db
 		if newDB.Error == nil && newDB.RowsAffected == 0 {
 			return s.New().Table(scope.TableName()).FirstOrCreate(value)
 		}
 		return newDB
 	}
-	return scope.callCallbacks(s.parent.callbacks.creates).db
+	return scope.callCallbacks(s.currentCallbacks().creates).db
 }
 
 // Create insert the value into database
 func (s *DB) Create(value interface{}) *DB {
 	scope := s.NewScope(value)
-	return scope.callCallbacks(s.parent.callbacks.creates).db
+	return scope.callCallbacks(s.currentCallbacks().creates).db
 }
 
 // Delete delete value match given conditions, if the value has primary key, then will including the primary key as condition
 // WARNING If model has DeletedAt field, GORM will only set field DeletedAt's value to current time
+// Selected associations will be deleted in the same transaction, e.g:
+//    db.Select("CreditCard", "Languages").Delete(&user)
+//    db.Select(gorm.Associations).Delete(&user)
+//
+// Records of a slice are deleted by their primary keys if no conditions given, at most 1000 keys in one statement,
+// RowsAffected is the sum of all statements, it returns error if any record's primary key is blank
+//    db.Delete(&users)
+//    // DELETE FROM users WHERE id IN (1,2,3);
 func (s *DB) Delete(value interface{}, where ...interface{}) *DB {
-	return s.NewScope(value).inlineCondition(where...).callCallbacks(s.parent.callbacks.deletes).db
+	if s.byPrimaryKeys(value, where...) {
+		return s.batchByPrimaryKeys(value, func(chunk interface{}) *DB {
+			return s.NewScope(chunk).InstanceSet("gorm:batch_primary_keys", true).inlineCondition(where...).callCallbacks(s.currentCallbacks().deletes).db
+		})
+	}
+
+	return s.NewScope(value).inlineCondition(where...).callCallbacks(s.currentCallbacks().deletes).db
 }
 
 // Raw use raw sql as conditions, won't run it unless invoked by other methods
@@ -717,19 +1314,50 @@ func (s *DB) Transaction(fc func(tx *DB) error) (err error) {
 	return
 }
 
+// TransactionWithRetry works like `Transaction`, but reruns the whole block up to `retries` times
+// if the dialect reports the error as retryable, e.g. write conflicts of TiDB's optimistic transactions
+func (s *DB) TransactionWithRetry(retries int, fc func(tx *DB) error) (err error) {
+	for i := 0; ; i++ {
+		if err = s.Transaction(fc); err == nil || i >= retries || !isRetryableError(s.dialect, err) {
+			return
+		}
+	}
+}
+
 // Begin begins a transaction
 func (s *DB) Begin() *DB {
 	return s.BeginTx(context.Background(), &sql.TxOptions{})
 }
 
-// BeginTx begins a transaction with options
+// BeginTx begins a transaction with options, it begins a nested transaction with a savepoint if the db is in a transaction,
+// committing it releases the savepoint, rolling back it rolls back to the savepoint
 func (s *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) *DB {
 	c := s.clone()
+	if _, ok := c.db.dbSQL.(sqlTx); ok {
+		return c.beginSavePoint()
+	}
+
 	if db, ok := c.db.dbSQL.(sqlDb); ok && db != nil {
+		if err := c.db.injectFault(ctx, faultBegin); err != nil {
+			c.AddError(err)
+			return c
+		}
+
+		// mssql doesn't support read-only transactions, statements of them are still checked by the read-only db
+		if c.db.readOnly && c.Dialect().GetName() != "mssql" {
+			readOnlyOpts := sql.TxOptions{ReadOnly: true}
+			if opts != nil {
+				readOnlyOpts.Isolation = opts.Isolation
+			}
+			opts = &readOnlyOpts
+		}
+
 		tx, err := db.BeginTx(ctx, opts)
+		c.db.txSource = c.db.dbSQL
 		c.db.dbSQL = interface{}(tx).(SQLCommon)
+		c.db.commitHooks = &[]func(){}
 
-		c.dialect.SetDB(c.db)
+		c.refreshDialect()
 		c.AddError(err)
 	} else {
 		c.AddError(ErrCantStartTransaction)
@@ -740,18 +1368,38 @@ func (s *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) *DB {
 //NOTE: commit用主库
 // Commit commit a transaction
 func (s *DB) Commit() *DB {
+	if s.db.savepoint != nil {
+		return s.endSavePoint(true)
+	}
+
 	var emptySQLTx *sql.Tx
 	if db, ok := s.db.dbSQL.(sqlTx); ok && db != nil && db != emptySQLTx {
-		s.AddError(db.Commit())
+		if s.AddError(db.Commit()) == nil && s.db.commitHooks != nil {
+			for _, hook := range *s.db.commitHooks {
+				hook()
+			}
+		}
 	} else {
 		s.AddError(ErrInvalidTransaction)
 	}
 	return s
 }
 
+// afterCommit run fc after committing the transaction begun with Begin, nested transactions run it after committing the outermost one,
+// it does nothing if the db isn't in a transaction begun with Begin
+func (s *DB) afterCommit(fc func()) {
+	if s.db.commitHooks != nil {
+		*s.db.commitHooks = append(*s.db.commitHooks, fc)
+	}
+}
+
 //NOTE: rollback用主库
 // Rollback rollback a transaction
 func (s *DB) Rollback() *DB {
+	if s.db.savepoint != nil {
+		return s.endSavePoint(false)
+	}
+
 	var emptySQLTx *sql.Tx
 	if db, ok := s.db.dbSQL.(sqlTx); ok && db != nil && db != emptySQLTx {
 		if err := db.Rollback(); err != nil && err != sql.ErrTxDone {
@@ -766,6 +1414,13 @@ func (s *DB) Rollback() *DB {
 // RollbackUnlessCommitted rollback a transaction if it has not yet been
 // committed.
 func (s *DB) RollbackUnlessCommitted() *DB {
+	if s.db.savepoint != nil {
+		if !s.db.savepoint.done {
+			s.endSavePoint(false)
+		}
+		return s
+	}
+
 	var emptySQLTx *sql.Tx
 	if db, ok := s.db.dbSQL.(sqlTx); ok && db != nil && db != emptySQLTx {
 		err := db.Rollback()
@@ -822,7 +1477,11 @@ func (s *DB) CloseTx(ctx context.Context, errp *error) {
 // skip用于打印调用者所在函数位置
 func (s *DB) closeTx(ctx context.Context, errp *error) {
 	if xray.GetSegment(ctx) != nil {
-		_, seg := xray.BeginSubsegment(ctx, GetSource(3))
+		name := s.db.source
+		if !s.db.sourceFixed {
+			name = GetSource(3)
+		}
+		_, seg := xray.BeginSubsegment(ctx, name)
 		defer func() { seg.Close(*errp) }()
 	}
 
@@ -855,21 +1514,16 @@ func (s *DB) NewRecord(value interface{}) bool {
 
 // RecordNotFound check if returning ErrRecordNotFound error
 func (s *DB) RecordNotFound() bool {
-	for _, err := range s.GetErrors() {
-		if err == ErrRecordNotFound {
-			return true
-		}
-	}
-	return false
+	return IsRecordNotFoundError(s.Error)
 }
 
 // CreateTable create table for models
 func (s *DB) CreateTable(models ...interface{}) *DB {
-	db := s.Unscoped()
+	db := s.Unscoped().withForeignKeyConstraints(models...)
 	for _, model := range models {
 		db = db.NewScope(model).createTable().db
 	}
-	return db
+	return db.NewScope(db.Value).addForeignKeyConstraints().db
 }
 
 // DropTable drop table for models
@@ -916,11 +1570,11 @@ func (s *DB) HasTable(value interface{}) bool {
 
 // AutoMigrate run auto migration for given models, will only add missing fields, won't delete/change current data
 func (s *DB) AutoMigrate(values ...interface{}) *DB {
-	db := s.Unscoped()
+	db := s.Unscoped().withForeignKeyConstraints(values...)
 	for _, value := range values {
 		db = db.NewScope(value).autoMigrate().db
 	}
-	return db
+	return db.NewScope(db.Value).addForeignKeyConstraints().db
 }
 
 // ModifyColumn modify column to type
@@ -979,7 +1633,7 @@ func (s *DB) Association(column string) *Association {
 	var err error
 	var scope = s.Set("gorm:association:source", s.Value).NewScope(s.Value)
 
-	if primaryField := scope.PrimaryField(); primaryField.IsBlank {
+	if isPrimaryKeyBlank(scope) {
 		err = errors.New("primary key can't be nil")
 	} else {
 		if field, ok := scope.FieldByName(column); ok {
@@ -998,27 +1652,51 @@ func (s *DB) Association(column string) *Association {
 
 // Preload preload associations with given conditions
 //    db.Preload("Orders", "state NOT IN (?)", "cancelled").Find(&users)
+//
+// Associations declared in embedded structs are preloaded by their paths
+//    db.Preload("Billing.Country").Find(&shops)
 func (s *DB) Preload(column string, conditions ...interface{}) *DB {
 	return s.clone().search.Preload(column, conditions...).db
 }
 
-// Set set setting by name, which could be used in callbacks, will clone a new db, and update its setting
+// Set set setting by name, which could be used in callbacks, will clone a new db, and update its setting,
+// the db and other clones of it aren't affected
 func (s *DB) Set(name string, value interface{}) *DB {
 	return s.clone().InstantSet(name, value)
 }
 
-// InstantSet instant set setting, will affect current db
+// InstantSet instant set setting, will affect current db and dbs cloned from it later, dbs cloned before aren't affected
 func (s *DB) InstantSet(name string, value interface{}) *DB {
-	s.values.Store(name, value)
+	s.valuesLock.Lock()
+	s.values.Store(s.settings().with(name, value))
+	s.valuesLock.Unlock()
 	return s
 }
 
 // Get get setting by name
 func (s *DB) Get(name string) (value interface{}, ok bool) {
-	value, ok = s.values.Load(name)
+	value, ok = s.settings()[name]
 	return
 }
 
+func (s *DB) settings() settings {
+	values, _ := s.values.Load().(settings)
+	return values
+}
+
+// settings copy-on-write map of settings, it is never modified once stored in dbs, so clones share it without copying
+type settings map[string]interface{}
+
+// with return a copy of settings with the setting
+func (values settings) with(name string, value interface{}) settings {
+	result := make(settings, len(values)+1)
+	for k, v := range values {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
 // SetJoinTableHandler set a model's join table handler for a relation
 func (s *DB) SetJoinTableHandler(source interface{}, column string, handler JoinTableHandlerInterface) {
 	scope := s.NewScope(source)
@@ -1046,12 +1724,13 @@ func (s *DB) AddError(err error) error {
 			} else {
 				s.log(err)
 			}
+		}
 
-			errors := Errors(s.GetErrors())
-			errors = errors.Add(err)
-			if len(errors) > 1 {
-				err = errors
-			}
+		// ErrRecordNotFound is kept in the chain too, so errors.Is(db.Error, ErrRecordNotFound) works with other errors
+		errors := Errors(s.GetErrors())
+		errors = errors.Add(err)
+		if len(errors) > 1 {
+			err = errors
 		}
 
 		s.Error = err
@@ -1082,14 +1761,15 @@ func (s *DB) clone() *DB {
 		Value:             s.Value,
 		Error:             s.Error,
 		blockGlobalUpdate: s.blockGlobalUpdate,
-		dialect:           newDialect(s.dialect.GetName(), s.db),
+		dialect:           s.dialect,
+		sharedDialect:     true,
 		nowFuncOverride:   s.nowFuncOverride,
+		sessionCallbacks:  s.sessionCallbacks,
 	}
 
-	s.values.Range(func(k, v interface{}) bool {
-		db.values.Store(k, v)
-		return true
-	})
+	if values := s.settings(); values != nil {
+		db.values.Store(values)
+	}
 
 	if s.search == nil {
 		db.search = &search{limit: -1, offset: -1}
@@ -1111,8 +1791,36 @@ func (s *DB) log(v ...interface{}) {
 	}
 }
 
+// SQL return SQL of the last statement executed by the db, statements are recorded with setting `gorm:record_sql` or in detailed log mode, e.g:
+//    result := db.Set("gorm:record_sql", true).Where("name = ?", "jinzhu").First(&user)
+//    result.SQL()  // SELECT * FROM "users" WHERE (name = $1) ORDER BY "users"."id" ASC LIMIT 1
+//    result.Vars() // [jinzhu]
+func (s *DB) SQL() string {
+	return s.statementSQL
+}
+
+// Vars return vars of the last statement executed by the db, sensitive values are masked as `***`, refer `SQL`
+func (s *DB) Vars() []interface{} {
+	return s.statementVars
+}
+
+// recordStatement record the executed statement if setting `gorm:record_sql` is true or in detailed log mode
+func (s *DB) recordStatement(sql string, vars []interface{}) {
+	if record, ok := s.Get("gorm:record_sql"); (!ok || record != true) && s.logMode != detailedLogMode {
+		return
+	}
+
+	s.statementSQL = sql
+	s.statementVars = sanitizedVars(vars)
+}
+
 func (s *DB) slog(sql string, t time.Time, vars ...interface{}) {
 	if s.logMode == detailedLogMode {
-		s.print("sql", fileWithLineNum(), NowFunc().Sub(t), sql, vars, s.RowsAffected)
+		duration := NowFunc().Sub(t)
+		failed := s.Error != nil && s.Error != ErrRecordNotFound
+		if !failed && duration < slowSQLThreshold && !s.parent.logSampler.sample() {
+			return
+		}
+		s.print("sql", fileWithLineNum(), duration, sql, vars, s.RowsAffected)
 	}
 }
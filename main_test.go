@@ -27,6 +27,7 @@ import (
 	_ "github.com/lun-zhang/gorm/dialects/mysql"
 	"github.com/lun-zhang/gorm/dialects/postgres"
 	_ "github.com/lun-zhang/gorm/dialects/sqlite"
+	"github.com/lun-zhang/gorm/gormtest"
 )
 
 var (
@@ -557,6 +558,54 @@ func TestTransaction_NoErrorOnRollbackAfterCommit(t *testing.T) {
 	}
 }
 
+func TestNestedTransaction(t *testing.T) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&User{Name: "nested-transaction-1"}).Error; err != nil {
+			t.Errorf("No error should raise")
+		}
+
+		if err := tx.Transaction(func(tx *gorm.DB) error {
+			tx.Save(&User{Name: "nested-transaction-2"})
+			return errors.New("rollback")
+		}); err == nil {
+			t.Errorf("Nested transaction should return error")
+		}
+
+		return tx.Transaction(func(tx *gorm.DB) error {
+			return tx.Save(&User{Name: "nested-transaction-3"}).Error
+		})
+	})
+	if err != nil {
+		t.Errorf("No error should raise, but got %v", err)
+	}
+
+	for name, found := range map[string]bool{"nested-transaction-1": true, "nested-transaction-2": false, "nested-transaction-3": true} {
+		if err := DB.First(&User{}, "name = ?", name).Error; (err == nil) != found {
+			t.Errorf("user %v should be found: %v, but got error %v", name, found, err)
+		}
+	}
+
+	tx := DB.Begin()
+	defer tx.Rollback()
+	tx.Save(&User{Name: "savepoint-1"})
+	tx.SavePoint("before_user")
+	tx.Save(&User{Name: "savepoint-2"})
+	if err := tx.RollbackTo("before_user").Error; err != nil {
+		t.Errorf("No error should raise, but got %v", err)
+	}
+
+	if err := tx.First(&User{}, "name = ?", "savepoint-1").Error; err != nil {
+		t.Errorf("user before the savepoint should be found, but got %v", err)
+	}
+	if err := tx.First(&User{}, "name = ?", "savepoint-2").Error; err == nil {
+		t.Errorf("user after the savepoint should be rolled back")
+	}
+
+	if err := DB.New().SavePoint("before_user").Error; err != gorm.ErrInvalidTransaction {
+		t.Errorf("savepoints should be created in transactions, but got %v", err)
+	}
+}
+
 func TestTransactionReadonly(t *testing.T) {
 	dialect := os.Getenv("GORM_DIALECT")
 	if dialect == "" {
@@ -659,6 +708,72 @@ func TestScanRows(t *testing.T) {
 	}
 }
 
+func TestRecordSQL(t *testing.T) {
+	user := User{Name: "record_sql"}
+	DB.Save(&user)
+
+	var result User
+	if db := DB.Where("name = ?", "record_sql").First(&result); db.SQL() != "" || db.Vars() != nil {
+		t.Errorf("Statements should not be recorded by default, but got %v", db.SQL())
+	}
+
+	db := DB.Set("gorm:record_sql", true).Where("name = ?", gorm.Sensitive("record_sql")).Where("age = ?", 0).First(&User{})
+	if !strings.HasPrefix(db.SQL(), "SELECT * FROM") || !strings.Contains(db.SQL(), "name = ") {
+		t.Errorf("Should record SQL of the statement, but got %v", db.SQL())
+	}
+
+	if vars := db.Vars(); len(vars) != 2 || vars[0] != "***" || fmt.Sprint(vars[1]) != "0" {
+		t.Errorf("Should record vars of the statement with sensitive values masked, but got %v", vars)
+	}
+
+	if db := DB.Set("gorm:record_sql", true).Model(&user).Update("age", 20); !strings.HasPrefix(db.SQL(), "UPDATE") {
+		t.Errorf("Should record SQL of updating, but got %v", db.SQL())
+	}
+}
+
+func TestIterate(t *testing.T) {
+	DB.Save(&User{Name: "IterateUser1", Age: 1})
+	DB.Save(&User{Name: "IterateUser2", Age: 2})
+	DB.Save(&User{Name: "IterateUser3", Age: 3})
+
+	var (
+		user  User
+		names []string
+	)
+	err := DB.Where("name LIKE ?", "IterateUser%").Order("age").Iterate(&user, func() error {
+		names = append(names, user.Name)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(names, []string{"IterateUser1", "IterateUser2", "IterateUser3"}) {
+		t.Errorf("Should iterate all records, but got %v, %v", err, names)
+	}
+
+	stop := errors.New("stop")
+	names = nil
+	err = DB.Where("name LIKE ?", "IterateUser%").Order("age").Iterate(&user, func() error {
+		if names = append(names, user.Name); len(names) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || len(names) != 2 {
+		t.Errorf("Should stop iterating with error, but got %v, %v", err, names)
+	}
+
+	DB.Save(&Product{Code: "iterate_product", Price: 10})
+	var product Product
+	DB.Where("code = ?", "iterate_product").Iterate(&product, func() error {
+		if product.AfterFindCallTimes != 1 {
+			t.Errorf("AfterFind should be called when iterating, but got %v", product.AfterFindCallTimes)
+		}
+		return nil
+	})
+
+	if err := DB.Table("not_exists_table").Iterate(&user, func() error { return nil }); err == nil {
+		t.Errorf("Should return error of the query")
+	}
+}
+
 func TestScan(t *testing.T) {
 	user1 := User{Name: "ScanUser1", Age: 1, Birthday: parseTime("2000-1-1")}
 	user2 := User{Name: "ScanUser2", Age: 10, Birthday: parseTime("2010-1-1")}
@@ -1071,6 +1186,60 @@ func TestSetAndGet(t *testing.T) {
 	}
 }
 
+func TestSetIsolation(t *testing.T) {
+	base := DB.Set("hello", "world")
+	clone := base.Where("name = ?", "jinzhu")
+	changed := base.Set("hello", "gorm")
+	base.InstantSet("instant", true)
+
+	if value, _ := base.Get("hello"); value != "world" {
+		t.Errorf("Set shouldn't change the db cloned from, got %v", value)
+	}
+	if value, _ := changed.Get("hello"); value != "gorm" {
+		t.Errorf("Set should change the clone, got %v", value)
+	}
+	if _, ok := clone.Get("instant"); ok {
+		t.Errorf("InstantSet shouldn't change dbs cloned before")
+	}
+	if _, ok := changed.Get("instant"); ok {
+		t.Errorf("InstantSet shouldn't change dbs set before")
+	}
+	if value, ok := base.Where("age = ?", 18).Get("instant"); !ok || value != true {
+		t.Errorf("InstantSet should change dbs cloned later, got %v", value)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			base.InstantSet(fmt.Sprint("concurrent", i), i)
+			base.Get("hello")
+			base.Set("hello", i).Get("hello")
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 10; i++ {
+		if value, ok := base.Get(fmt.Sprint("concurrent", i)); !ok || value != i {
+			t.Errorf("concurrent InstantSet shouldn't be lost, got %v", value)
+		}
+	}
+}
+
+func TestInstanceSet(t *testing.T) {
+	scope := DB.NewScope(&User{})
+	scope.InstanceSet("gorm:instance", true)
+	if value, ok := scope.InstanceGet("gorm:instance"); !ok || value != true {
+		t.Errorf("InstanceSet should set setting of the scope, got %v", value)
+	}
+	if _, ok := scope.New(&User{}).InstanceGet("gorm:instance"); ok {
+		t.Errorf("InstanceSet shouldn't change other scopes")
+	}
+	if _, ok := scope.NewDB().NewScope(&User{}).InstanceGet("gorm:instance"); ok {
+		t.Errorf("InstanceSet shouldn't change scopes of dbs cloned later")
+	}
+}
+
 func TestCompatibilityMode(t *testing.T) {
 	DB, _ := gorm.Open("testdb", "")
 	testdb.SetQueryFunc(func(query string) (driver.Rows, error) {
@@ -1105,6 +1274,162 @@ func TestOpenExistingDB(t *testing.T) {
 	}
 }
 
+func TestDialectOfClonedDB(t *testing.T) {
+	db := DB.Where("name = ?", "jinzhu")
+	if db.Dialect() != db.Dialect() {
+		t.Errorf("Dialect of the cloned db should be created once")
+	}
+}
+
+func TestOpenWithPoolOptions(t *testing.T) {
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("pool options are tested with sqlite")
+	}
+
+	source := filepath.Join(os.TempDir(), "gorm.db")
+	db, err := gorm.Open("sqlite3", source, gorm.Options{PoolOptions: gorm.PoolOptions{MaxOpenConns: 3}})
+	if err != nil {
+		t.Fatalf("Failed to open db with options, got %v", err)
+	}
+	defer db.Close()
+
+	if stats := db.DB().Stats(); stats.MaxOpenConnections != 3 {
+		t.Errorf("Should apply pool options, but got %v", stats.MaxOpenConnections)
+	}
+
+	masterAndSlave, err := gorm.OpenMasterAndSlave("sqlite3", source, source, gorm.Options{
+		PoolOptions: gorm.PoolOptions{MaxOpenConns: 5},
+		Slave:       gorm.PoolOptions{MaxOpenConns: 7},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open master and slave with options, got %v", err)
+	}
+	defer masterAndSlave.DBSlave().Close()
+	defer masterAndSlave.Close()
+
+	if masterAndSlave.DB().Stats().MaxOpenConnections != 5 || masterAndSlave.DBSlave().Stats().MaxOpenConnections != 7 {
+		t.Errorf("Should apply pool options of master and slave separately")
+	}
+}
+
+func TestOpenWithRetry(t *testing.T) {
+	defer testdb.Reset()
+
+	var attempts int
+	testdb.SetOpenFunc(func(dsn string) (driver.Conn, error) {
+		if attempts++; attempts < 3 {
+			return nil, errors.New("database is not ready")
+		}
+		return testdb.Conn(), nil
+	})
+
+	db, err := gorm.OpenWithRetry("testdb", "", gorm.RetryConfig{Attempts: 5, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	if err != nil || attempts < 3 {
+		t.Fatalf("Should connect database after retrying, but got %v after %v attempts", err, attempts)
+	}
+	db.Close()
+
+	db, err = gorm.OpenWithRetry("testdb", "", gorm.RetryConfig{Attempts: 1}, gorm.Options{
+		PoolOptions:    gorm.PoolOptions{MaxOpenConns: 3},
+		NamingStrategy: &gorm.NamingStrategy{TablePrefix: "t_"},
+	})
+	if err != nil {
+		t.Fatalf("Should connect database, but got %v", err)
+	}
+	if name := db.NewScope(&User{}).TableName(); name != "t_users" || db.DB().Stats().MaxOpenConnections != 3 {
+		t.Errorf("Should apply options, but got table name %v and %v max open connections", name, db.DB().Stats().MaxOpenConnections)
+	}
+	db.Close()
+
+	attempts = -10
+	if _, err := gorm.OpenWithRetry("testdb", "", gorm.RetryConfig{Attempts: 2, Backoff: time.Millisecond}); err == nil || attempts != -8 {
+		t.Errorf("Should give up after attempts run out, but got %v after %v attempts", err, attempts+10)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	status := DB.HealthCheck(context.Background())
+	if !status.Healthy() || len(status) != 1 || status[0].Role != "master" || status[0].OpenConnections == 0 {
+		t.Errorf("Should report healthy master, but got %+v", status)
+	}
+
+	if DB.Dialect().GetName() == "sqlite3" {
+		source := filepath.Join(os.TempDir(), "gorm.db")
+		db, err := gorm.OpenMasterAndSlave("sqlite3", source, source)
+		if err != nil {
+			t.Fatalf("Failed to open master and slave, got %v", err)
+		}
+
+		if status := db.HealthCheck(context.Background()); !status.Healthy() || len(status) != 2 || status[1].Role != "slave" || status[1].ReplicaLag != nil {
+			t.Errorf("Should report status of master and slave, but got %+v", status)
+		}
+
+		db.Close()
+		if status := db.HealthCheck(context.Background()); status.Healthy() || status[0].Error == nil || !status[1].Reachable {
+			t.Errorf("Should report unreachable master after closing it, but got %+v", status)
+		}
+		db.DBSlave().Close()
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	db, _ := gorm.Open("testdb", "")
+	defer testdb.Reset()
+
+	var calls int
+	down := true
+	testdb.SetExecFunc(func(query string) (driver.Result, error) {
+		calls++
+		if down {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return driver.RowsAffected(1), nil
+	})
+
+	breaker := &gorm.CircuitBreaker{MinRequests: 2, OpenTimeout: 20 * time.Millisecond}
+	db.UseCircuitBreaker(breaker, nil)
+
+	for i := 0; i < 2; i++ {
+		db.Exec("UPDATE users SET age = 1")
+	}
+	if err := db.Exec("UPDATE users SET age = 1").Error; err != gorm.ErrCircuitOpen || calls != 2 || breaker.State() != "open" {
+		t.Errorf("Should fail fast after too many failures, but got %v after %v calls", err, calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	down = false
+	trying, finish := make(chan bool), make(chan bool)
+	calls = 0
+	testdb.SetExecFunc(func(query string) (driver.Result, error) {
+		if calls++; calls == 1 {
+			trying <- true
+			<-finish
+		}
+		return driver.RowsAffected(1), nil
+	})
+	trial := make(chan error)
+	go func() { trial <- db.Exec("UPDATE users SET age = 1").Error }()
+	<-trying
+	if err := db.Exec("UPDATE users SET age = 1").Error; err != gorm.ErrCircuitOpen {
+		t.Errorf("Should fail fast while the half-open circuit is trying, but got %v", err)
+	}
+	close(finish)
+	if err := <-trial; err != nil || breaker.State() != "closed" {
+		t.Errorf("Should close the circuit after a successful trial, but got %v, %v", err, breaker.State())
+	}
+
+	testdb.SetQueryFunc(func(query string) (driver.Rows, error) {
+		return nil, errors.New("dial tcp: connection refused")
+	})
+	var age int
+	for i := 0; i < 2; i++ {
+		db.Table("users").Select("age").Row().Scan(&age)
+	}
+	if breaker.State() != "open" {
+		t.Errorf("Failures of querying rows should open the circuit, but got %v", breaker.State())
+	}
+}
+
 func TestDdlErrors(t *testing.T) {
 	var err error
 
@@ -1444,6 +1769,53 @@ func BenchmarkRawSql(b *testing.B) {
 	}
 }
 
+func BenchmarkChainedConditions(b *testing.B) {
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		b.Fatalf("failed to open db, got %v", err)
+	}
+
+	b.ReportAllocs()
+	for x := 0; x < b.N; x++ {
+		db.Model(&User{}).Where("name = ?", "jinzhu").Where("age > ?", 18).Where("role = ?", "admin").Not("id = ?", 1).Order("age").Limit(10)
+	}
+}
+
+func BenchmarkChainedFind(b *testing.B) {
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		b.Fatalf("failed to open db, got %v", err)
+	}
+
+	b.ReportAllocs()
+	for x := 0; x < b.N; x++ {
+		var users []User
+		db.Where("name = ?", "jinzhu").Where("age > ?", 18).Where("role = ?", "admin").Not("id = ?", 1).Order("age").Limit(10).Find(&users)
+	}
+}
+
+func BenchmarkInstanceSet(b *testing.B) {
+	scope := DB.NewScope(&User{})
+
+	b.ReportAllocs()
+	for x := 0; x < b.N; x++ {
+		scope.InstanceSet(fmt.Sprint("gorm:instance", x%100), x)
+	}
+}
+
+func BenchmarkChainedConditionsWithSettings(b *testing.B) {
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		b.Fatalf("failed to open db, got %v", err)
+	}
+	db = db.Set("gorm:query_option", "").Set("gorm:save_associations", false).Set("gorm:record_sql", false).Set("tenant", 1).Set("trace", "on")
+
+	b.ReportAllocs()
+	for x := 0; x < b.N; x++ {
+		db.Model(&User{}).Where("name = ?", "jinzhu").Where("age > ?", 18).Where("role = ?", "admin").Not("id = ?", 1).Order("age").Limit(10)
+	}
+}
+
 func parseTime(str string) *time.Time {
 	t := now.New(time.Now().UTC()).MustParse(str)
 	return &t
@@ -0,0 +1,101 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"path"
+	"reflect"
+)
+
+// sensitiveValue value masked as `***` in logs and traces, the real value is still sent to the driver
+type sensitiveValue struct {
+	value interface{}
+}
+
+// Value return the real value for the driver
+func (sensitive sensitiveValue) Value() (driver.Value, error) {
+	if valuer, ok := sensitive.value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(sensitive.value)
+}
+
+// Sensitive mark value sensitive, it will be masked as `***` in logs and traces, e.g:
+//    db.Where("password_hash = ?", gorm.Sensitive(hash)).First(&user)
+//
+// Values of fields tagged with `sensitive` or columns registered with MaskColumns are masked automatically
+//    type User struct {
+//      Password string `gorm:"sensitive"`
+//    }
+func Sensitive(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, sensitiveValue, *SqlExpr, GormValuerInterface:
+		return value
+	}
+	return sensitiveValue{value: value}
+}
+
+// MaskColumns register column patterns whose values will be masked as `***` in logs and traces,
+// patterns are matched with column names like path.Match
+//    db.MaskColumns("password", "*_token", "id_card_*")
+func (s *DB) MaskColumns(patterns ...string) *DB {
+	s.parent.maskedLock.Lock()
+	defer s.parent.maskedLock.Unlock()
+
+	maskedColumns, _ := s.parent.maskedColumns.Load().([]string)
+	s.parent.maskedColumns.Store(append(maskedColumns[:len(maskedColumns):len(maskedColumns)], patterns...))
+	return s
+}
+
+// sensitiveVar mark value of the model's column sensitive if the field is tagged with `sensitive` or the column is registered
+func sensitiveVar(model *Scope, column string, value interface{}) interface{} {
+	if field, ok := model.GetModelStruct().fieldByDBName(column); ok {
+		if _, ok := field.TagSettingsGet("SENSITIVE"); ok {
+			return Sensitive(value)
+		}
+	}
+
+	if model.db != nil && model.db.parent != nil {
+		maskedColumns, _ := model.db.parent.maskedColumns.Load().([]string)
+		for _, pattern := range maskedColumns {
+			if matched, _ := path.Match(pattern, column); matched {
+				return Sensitive(value)
+			}
+		}
+	}
+	return value
+}
+
+// expandSensitiveSlice return elements of the sensitive slice as sensitive values, so they are bound one by one like other slices,
+// e.g. `id IN (?)`, other values are returned as they are
+func expandSensitiveSlice(value interface{}) interface{} {
+	sensitive, ok := value.(sensitiveValue)
+	if !ok {
+		return value
+	}
+
+	switch sensitive.value.(type) {
+	case []byte, driver.Valuer:
+		return value
+	}
+
+	if values := reflect.ValueOf(sensitive.value); values.Kind() == reflect.Slice {
+		elements := make([]interface{}, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			elements[i] = Sensitive(values.Index(i).Interface())
+		}
+		return elements
+	}
+	return value
+}
+
+// sanitizedVars return a copy of vars with sensitive values masked as `***`
+func sanitizedVars(vars []interface{}) []interface{} {
+	sanitized := make([]interface{}, len(vars))
+	for i, value := range vars {
+		if _, ok := value.(sensitiveValue); ok {
+			value = "***"
+		}
+		sanitized[i] = value
+	}
+	return sanitized
+}
@@ -0,0 +1,105 @@
+package gorm_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+type MaskedAccount struct {
+	ID          uint
+	Name        string
+	Password    string `gorm:"sensitive"`
+	AccessToken string
+}
+
+type sqlCollector struct {
+	sqls []string
+}
+
+func (collector *sqlCollector) Print(values ...interface{}) {
+	if len(values) > 4 && values[0] == "sql" {
+		collector.sqls = append(collector.sqls, gorm.PrintSQL(values[3].(string), values[4].([]interface{})...))
+	}
+}
+
+func TestMaskSensitiveValues(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&MaskedAccount{})
+	db.AutoMigrate(&MaskedAccount{})
+
+	collector := &sqlCollector{}
+	db.MaskColumns("*_token")
+	db.SetLogger(collector)
+	db = db.LogMode(true)
+
+	account := MaskedAccount{Name: "jinzhu", Password: "secret-password", AccessToken: "secret-token"}
+	db.Create(&account)
+	db.Model(&account).Updates(map[string]interface{}{"access_token": "secret-token2"})
+
+	var found MaskedAccount
+	db.Where("name = ? AND password = ?", "jinzhu", gorm.Sensitive("secret-password")).Where(&MaskedAccount{AccessToken: "secret-token2"}).First(&found)
+	if found.ID != account.ID || found.Password != "secret-password" || found.AccessToken != "secret-token2" {
+		t.Errorf("Real values should be saved and queried, but got %#v", found)
+	}
+
+	logs := strings.Join(collector.sqls, "\n")
+	if len(collector.sqls) != 3 || strings.Contains(logs, "secret") || strings.Count(logs, "'***'") != 5 {
+		t.Errorf("Sensitive values should be masked in logs, but got %v", logs)
+	}
+
+	if !strings.Contains(logs, "'jinzhu'") {
+		t.Errorf("Other values should not be masked, but got %v", logs)
+	}
+}
+
+func TestMaskSensitiveSlice(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	collector := &sqlCollector{}
+	db.SetLogger(collector)
+	db = db.LogMode(true)
+
+	var accounts []MaskedAccount
+	db.Where("password IN (?)", gorm.Sensitive([]string{"secret1", "secret2"})).Find(&accounts)
+	if statement := recorder.LastStatement(); statement.SQL != `SELECT * FROM "masked_accounts" WHERE (password IN ($1,$2))` || fmt.Sprint(statement.Vars) != "[secret1 secret2]" {
+		t.Errorf("Sensitive slice should be bound element by element, but got %v %v", statement.SQL, statement.Vars)
+	}
+
+	if len(collector.sqls) != 1 || strings.Contains(collector.sqls[0], "secret") || strings.Count(collector.sqls[0], "'***'") != 2 {
+		t.Errorf("Elements of sensitive slice should be masked in logs, but got %v", collector.sqls)
+	}
+}
+
+func TestMaskColumnsConcurrently(t *testing.T) {
+	db, _, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			db.MaskColumns(fmt.Sprintf("secret_%v", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			db.Where(&MaskedAccount{AccessToken: "token"}).Find(&[]MaskedAccount{})
+		}()
+	}
+	wg.Wait()
+}
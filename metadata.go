@@ -0,0 +1,220 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ModelMetadata read-only metadata of a model parsed from its struct and tags, it is shared by all callers, don't modify it
+//    metadata := db.ModelMetadata(&User{})
+//    for _, field := range metadata.Fields {
+//      fmt.Println(field.Name, field.Column, field.Type)
+//    }
+type ModelMetadata struct {
+	// Name name of the model's type
+	Name string
+	// Type type of the model's struct
+	Type reflect.Type
+	// Table table name of the model with the naming strategy, prefix and tenant of the db
+	Table string
+	// PrimaryKeys column names of primary keys
+	PrimaryKeys []string
+	// Fields fields of the model, fields of embedded structs are flattened
+	Fields []*FieldMetadata
+	// Relations associations of the model
+	Relations []*RelationMetadata
+}
+
+// FieldMetadata read-only metadata of a field
+type FieldMetadata struct {
+	// Name name of the field
+	Name string
+	// Path path of the field like `Billing.Street` for fields of embedded structs, it is the name for others
+	Path string
+	// Column column name of the field, it is empty for associations
+	Column string
+	// Type type of the field
+	Type reflect.Type
+	// Tags settings of `gorm` and `sql` tags, keys are upper case
+	Tags           map[string]string
+	IsPrimaryKey   bool
+	IsIgnored      bool
+	IsNormal       bool
+	HasDefault     bool
+	IsForeignKey   bool
+	IsRelationship bool
+}
+
+// RelationMetadata read-only metadata of an association
+type RelationMetadata struct {
+	// Field name or path of the association field
+	Field string
+	// Kind `has_one`, `has_many`, `belongs_to` or `many_to_many`
+	Kind string
+	// Type type of the associated model's struct
+	Type reflect.Type
+	// ForeignKeys columns of foreign keys, they are columns of the join table for many to many associations
+	ForeignKeys []string
+	// AssociationForeignKeys columns referred by foreign keys
+	AssociationForeignKeys []string
+	// JoinTable name of the join table of many to many associations
+	JoinTable string
+	// PolymorphicType column of the polymorphic type
+	PolymorphicType string
+}
+
+// Field return the field of the name, path or column
+func (metadata *ModelMetadata) Field(name string) (*FieldMetadata, bool) {
+	for _, field := range metadata.Fields {
+		if field.Name == name || field.Path == name || field.Column == name {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// ModelMetadata return metadata of the model parsed with the naming strategy of the db, models are parsed once and cached,
+// it returns nil if the value isn't a struct, or a pointer or slice of structs
+func (s *DB) ModelMetadata(value interface{}) *ModelMetadata {
+	scope := s.NewScope(value)
+	modelStruct := scope.GetModelStruct()
+	if modelStruct.ModelType == nil {
+		return nil
+	}
+
+	metadata := *modelStruct.metadata()
+	metadata.Table = scope.TableName()
+	return &metadata
+}
+
+// Warmup parse models and their associations at startup, so the first queries don't pay the cost of reflection,
+// it should be called with the db whose naming strategy is used by queries
+//    db.Warmup(&User{}, &Order{}, &Product{})
+func (s *DB) Warmup(models ...interface{}) *DB {
+	db := s.clone()
+	for _, model := range models {
+		scope := s.NewScope(model)
+		modelStruct := scope.GetModelStruct()
+		if modelStruct.ModelType == nil {
+			db.AddError(fmt.Errorf("failed to warm up %T, models should be structs", model))
+			continue
+		}
+		modelStruct.metadata()
+		scope.TableName()
+	}
+	return db
+}
+
+// Col return the column name of the model's field parsed with TheNamingStrategy, fields are referred by names or paths like `Billing.Street`,
+// so renaming fields breaks queries loudly instead of silently, it panics if the field doesn't have a column
+//    db.Select(gorm.Col(&User{}, "Email")).Where(gorm.Col(&User{}, "Name")+" = ?", "jinzhu").Order(gorm.Col(&User{}, "Age") + " DESC").Find(&users)
+func Col(model interface{}, field string) string {
+	return columnOf((&Scope{Value: model}).GetModelStruct(), field)
+}
+
+// Col return the column name of the model's field parsed with the naming strategy of the db, refer to gorm.Col
+func (s *DB) Col(model interface{}, field string) string {
+	return columnOf(s.NewScope(model).GetModelStruct(), field)
+}
+
+// columnNames cache of column names of fields, keys are columnKey
+var columnNames sync.Map
+
+type columnKey struct {
+	modelStruct *ModelStruct
+	field       string
+}
+
+func columnOf(modelStruct *ModelStruct, field string) string {
+	key := columnKey{modelStruct: modelStruct, field: field}
+	if column, ok := columnNames.Load(key); ok {
+		return column.(string)
+	}
+
+	if modelStruct.ModelType == nil {
+		panic(fmt.Sprintf("gorm: failed to get column of field %v, models should be structs", field))
+	}
+
+	// paths are unique while names of fields of embedded structs may be duplicated
+	var found *FieldMetadata
+	for _, f := range modelStruct.metadata().Fields {
+		if f.Path == field {
+			found = f
+			break
+		}
+		if f.Name == field && found == nil {
+			found = f
+		}
+	}
+	if found == nil || found.Column == "" || found.IsIgnored {
+		panic(fmt.Sprintf("gorm: %v doesn't have a column of field %v", modelStruct.ModelType, field))
+	}
+
+	columnNames.Store(key, found.Column)
+	return found.Column
+}
+
+// metadata return the metadata of the model, it is built once as fields of model structs aren't changed after parsing
+func (s *ModelStruct) metadata() *ModelMetadata {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.cachedMetadata != nil {
+		return s.cachedMetadata
+	}
+
+	metadata := &ModelMetadata{Name: s.ModelType.Name(), Type: s.ModelType}
+	for _, field := range s.PrimaryFields {
+		metadata.PrimaryKeys = append(metadata.PrimaryKeys, field.DBName)
+	}
+
+	for _, field := range s.StructFields {
+		fieldMetadata := &FieldMetadata{
+			Name:           field.Name,
+			Path:           field.path(),
+			Type:           field.Struct.Type,
+			Tags:           map[string]string{},
+			IsPrimaryKey:   field.IsPrimaryKey,
+			IsIgnored:      field.IsIgnored,
+			IsNormal:       field.IsNormal,
+			HasDefault:     field.HasDefaultValue,
+			IsForeignKey:   field.IsForeignKey,
+			IsRelationship: field.Relationship != nil,
+		}
+		if field.Relationship == nil {
+			fieldMetadata.Column = field.DBName
+		}
+
+		field.tagSettingsLock.RLock()
+		for key, value := range field.TagSettings {
+			fieldMetadata.Tags[key] = value
+		}
+		field.tagSettingsLock.RUnlock()
+
+		metadata.Fields = append(metadata.Fields, fieldMetadata)
+
+		if relationship := field.Relationship; relationship != nil {
+			relationType := field.Struct.Type
+			for relationType.Kind() == reflect.Slice || relationType.Kind() == reflect.Ptr {
+				relationType = relationType.Elem()
+			}
+
+			relation := &RelationMetadata{
+				Field:                  fieldMetadata.Path,
+				Kind:                   relationship.Kind,
+				Type:                   relationType,
+				ForeignKeys:            relationship.ForeignDBNames,
+				AssociationForeignKeys: relationship.AssociationForeignDBNames,
+				PolymorphicType:        relationship.PolymorphicDBName,
+			}
+			if handler, ok := relationship.JoinTableHandler.(*JoinTableHandler); ok {
+				relation.JoinTable = handler.TableName
+			}
+			metadata.Relations = append(metadata.Relations, relation)
+		}
+	}
+
+	s.cachedMetadata = metadata
+	return metadata
+}
@@ -0,0 +1,89 @@
+package gorm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestModelMetadata(t *testing.T) {
+	metadata := DB.ModelMetadata(&[]User{})
+	if metadata == nil || metadata.Name != "User" || metadata.Table != "users" || !reflect.DeepEqual(metadata.PrimaryKeys, []string{"id"}) {
+		t.Fatalf("metadata of users is wrong, got %#v", metadata)
+	}
+
+	if field, ok := metadata.Field("Name"); !ok || field.Column != "name" || field.Type != reflect.TypeOf("") || !field.IsNormal {
+		t.Errorf("metadata of field Name is wrong, got %#v", field)
+	}
+
+	relations := map[string]string{}
+	for _, relation := range metadata.Relations {
+		relations[relation.Field] = relation.Kind
+		if relation.Field == "Languages" && (relation.JoinTable != "user_languages" || relation.Type != reflect.TypeOf(Language{})) {
+			t.Errorf("metadata of many to many relation is wrong, got %#v", relation)
+		}
+		if relation.Field == "BillingAddress" && !reflect.DeepEqual(relation.ForeignKeys, []string{"billing_address_id"}) {
+			t.Errorf("metadata of belongs to relation is wrong, got %#v", relation)
+		}
+	}
+	if relations["Emails"] != "has_many" || relations["CreditCard"] != "has_one" || relations["BillingAddress"] != "belongs_to" || relations["Languages"] != "many_to_many" {
+		t.Errorf("relations of users are wrong, got %v", relations)
+	}
+
+	shop := DB.ModelMetadata(&EmbeddedShop{})
+	if field, ok := shop.Field("Shipping.Street"); !ok || field.Column != "shipping_street" || field.Path != "Shipping.Street" {
+		t.Errorf("fields of embedded structs should have paths and prefixed columns, got %#v", field)
+	}
+
+	if DB.ModelMetadata("users") != nil {
+		t.Errorf("metadata of non-struct values should be nil")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	if err := DB.Warmup(&User{}, &EmbeddedShop{}).Error; err != nil {
+		t.Errorf("failed to warm up models, got %v", err)
+	}
+	if err := DB.Warmup(&User{}, 1).Error; err == nil {
+		t.Errorf("warming up non-struct values should return error")
+	}
+}
+
+func TestCol(t *testing.T) {
+	if column := gorm.Col(&CreditCard{}, "DeletedAt"); column != "deleted_time" {
+		t.Errorf("column of DeletedAt should be deleted_time, got %v", column)
+	}
+	if column := gorm.Col(&EmbeddedShop{}, "Shipping.Street"); column != "shipping_street" {
+		t.Errorf("columns of embedded structs should be resolved by paths, got %v", column)
+	}
+
+	DB.Save(&User{Name: "col", Age: 10})
+	DB.Save(&User{Name: "col", Age: 20})
+	var users []User
+	DB.Select(gorm.Col(&User{}, "Age")).Where(gorm.Col(&User{}, "Name")+" = ?", "col").Order(gorm.Col(&User{}, "Age") + " DESC").Find(&users)
+	if len(users) != 2 || users[0].Age != 20 || users[1].Name != "" {
+		t.Errorf("columns should be usable in Select, Where and Order, got %+v", users)
+	}
+
+	db, _, err := gormtest.Open("sqlite3")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	db.SetNamingStrategy(&gorm.NamingStrategy{Column: func(name string) string { return "col_" + gorm.ToColumnName(name) }})
+	if column := db.Col(&CreditCard{}, "Number"); column != "col_number" {
+		t.Errorf("columns should be named by the naming strategy of the db, got %v", column)
+	}
+
+	for _, field := range []string{"Missing", "IgnoreMe", "Emails"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Col should panic for field %v without column", field)
+				}
+			}()
+			gorm.Col(&User{}, field)
+		}()
+	}
+}
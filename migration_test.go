@@ -8,10 +8,12 @@ import (
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
 )
 
 type User struct {
@@ -292,7 +294,7 @@ func runMigration() {
 		DB.Exec(fmt.Sprintf("drop table %v;", table))
 	}
 
-	values := []interface{}{&Short{}, &ReallyLongThingThatReferencesShort{}, &ReallyLongTableNameToTestMySQLNameLengthLimit{}, &NotSoLongTableName{}, &Product{}, &Email{}, &Address{}, &CreditCard{}, &Company{}, &Role{}, &Language{}, &HNPost{}, &EngadgetPost{}, &Animal{}, &User{}, &JoinTable{}, &Post{}, &Category{}, &Comment{}, &Cat{}, &Dog{}, &Hamster{}, &Toy{}, &ElementWithIgnoredField{}, &Place{}}
+	values := []interface{}{&Short{}, &ReallyLongThingThatReferencesShort{}, &ReallyLongTableNameToTestMySQLNameLengthLimit{}, &NotSoLongTableName{}, &Product{}, &Email{}, &Address{}, &CreditCard{}, &Company{}, &Role{}, &Language{}, &HNPost{}, &EngadgetPost{}, &Animal{}, &User{}, &JoinTable{}, &Post{}, &Category{}, &Comment{}, &Cat{}, &Dog{}, &Hamster{}, &Toy{}, &ElementWithIgnoredField{}, &Place{}, &ViewUser{}}
 	for _, value := range values {
 		DB.DropTable(value)
 	}
@@ -577,3 +579,100 @@ func TestIndexWithPrefixLength(t *testing.T) {
 		})
 	}
 }
+
+type ConstraintCompany struct {
+	ID   uint
+	Name string
+}
+
+type ConstraintCard struct {
+	ID               uint
+	Number           string
+	ConstraintUserID uint
+}
+
+type ConstraintLanguage struct {
+	ID   uint
+	Name string
+}
+
+type ConstraintUser struct {
+	ID        uint
+	Name      string
+	CompanyID *uint
+	Company   *ConstraintCompany   `gorm:"constraint:OnDelete:SET NULL"`
+	Cards     []ConstraintCard     `gorm:"constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	Languages []ConstraintLanguage `gorm:"many2many:constraint_user_languages;constraint:OnDelete:CASCADE"`
+}
+
+func TestAutoMigrateForeignKeyConstraints(t *testing.T) {
+	DB.DropTableIfExists("constraint_user_languages", &ConstraintCard{}, &ConstraintUser{}, &ConstraintLanguage{}, &ConstraintCompany{})
+
+	models := []interface{}{&ConstraintCompany{}, &ConstraintLanguage{}, &ConstraintUser{}, &ConstraintCard{}}
+	if err := DB.AutoMigrate(models...).Error; err != nil {
+		t.Fatalf("Failed to auto migrate, got %v", err)
+	}
+
+	for _, constraint := range [][3]string{
+		{"constraint_users", "company_id", "constraint_companies(id)"},
+		{"constraint_cards", "constraint_user_id", "constraint_users(id)"},
+		{"constraint_user_languages", "constraint_user_id", "constraint_users(id)"},
+		{"constraint_user_languages", "constraint_language_id", "constraint_languages(id)"},
+	} {
+		keyName := DB.Dialect().BuildKeyName(constraint[0], constraint[1], constraint[2], "foreign")
+		if !DB.Dialect().HasForeignKey(constraint[0], keyName) {
+			t.Errorf("Should create foreign key %v for %v", keyName, constraint[0])
+		}
+	}
+
+	if err := DB.AutoMigrate(models...).Error; err != nil {
+		t.Errorf("Auto migrate again should not fail, got %v", err)
+	}
+
+	if dialect := DB.Dialect().GetName(); dialect == "sqlite3" || dialect == "tidb" {
+		// foreign keys are not enforced by default
+		return
+	}
+
+	user := ConstraintUser{Name: "constraint", Company: &ConstraintCompany{Name: "company"}, Cards: []ConstraintCard{{Number: "1"}, {Number: "2"}}}
+	if err := DB.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to save user, got %v", err)
+	}
+
+	DB.Delete(user.Company)
+	if DB.First(&user, user.ID); user.CompanyID != nil {
+		t.Errorf("Company id should be set to null when deleting company, got %v", *user.CompanyID)
+	}
+
+	DB.Delete(&user)
+	var count int
+	if DB.Model(&ConstraintCard{}).Where("constraint_user_id = ?", user.ID).Count(&count); count != 0 {
+		t.Errorf("Cards should be deleted with user, but got %v", count)
+	}
+}
+
+type SchemaCategory struct {
+	ID       uint
+	ParentID *uint
+	Parent   *SchemaCategory `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (SchemaCategory) TableName() string {
+	return "app.categories"
+}
+
+func TestForeignKeyConstraintsOfSchemaTables(t *testing.T) {
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	if err := db.CreateTable(&SchemaCategory{}).Error; err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+
+	sql := recorder.LastStatement().SQL
+	if !strings.HasPrefix(sql, `CREATE TABLE "app"."categories" (`) || !strings.Contains(sql, `FOREIGN KEY ("parent_id") REFERENCES "app"."categories"("id") ON DELETE CASCADE`) {
+		t.Errorf("schema and table of foreign keys should be quoted separately, but got %v", sql)
+	}
+}
@@ -3,6 +3,7 @@ package gorm
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"go/ast"
 	"reflect"
 	"strings"
@@ -30,9 +31,21 @@ type ModelStruct struct {
 	ModelType     reflect.Type
 
 	defaultTableName string
+	cachedMetadata   *ModelMetadata
+	scanners         scannerCache // compiled scanners of columns
 	l                sync.Mutex
 }
 
+// fieldByDBName return the struct field of the column
+func (s *ModelStruct) fieldByDBName(dbName string) (*StructField, bool) {
+	for _, field := range s.StructFields {
+		if field.DBName == dbName {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
 // TableName returns model's table name
 func (s *ModelStruct) TableName(db *DB) string {
 	s.l.Lock()
@@ -43,13 +56,11 @@ func (s *ModelStruct) TableName(db *DB) string {
 		if tabler, ok := reflect.New(s.ModelType).Interface().(tabler); ok {
 			s.defaultTableName = tabler.TableName()
 		} else {
-			tableName := ToTableName(s.ModelType.Name())
-			db.parent.RLock()
-			if db == nil || (db.parent != nil && !db.parent.singularTable) {
+			tableName := db.naming().TableName(s.ModelType.Name())
+			if !db.isSingularTable() {
 				tableName = inflection.Plural(tableName)
 			}
-			db.parent.RUnlock()
-			s.defaultTableName = tableName
+			s.defaultTableName = db.naming().TablePrefix + tableName
 		}
 	}
 
@@ -97,6 +108,33 @@ func (sf *StructField) TagSettingsDelete(key string) {
 	delete(sf.TagSettings, key)
 }
 
+// hasPath return true if the path like `Billing.Street` is the names of the field of an embedded struct,
+// paths of embedded structs like `Billing` are matched too if includeEmbedded is true
+func (sf *StructField) hasPath(path string, includeEmbedded bool) bool {
+	if len(sf.Names) < 2 {
+		return false
+	}
+
+	for i, name := range sf.Names {
+		if !strings.HasPrefix(path, name) {
+			return false
+		}
+		if path = path[len(name):]; path == "" {
+			return i == len(sf.Names)-1 || includeEmbedded
+		}
+		if path[0] != '.' {
+			return false
+		}
+		path = path[1:]
+	}
+	return false
+}
+
+// path return the path of the field like `Billing.Street` for fields of embedded structs
+func (sf *StructField) path() string {
+	return strings.Join(sf.Names, ".")
+}
+
 func (sf *StructField) clone() *StructField {
 	clone := &StructField{
 		DBName:          sf.DBName,
@@ -139,6 +177,90 @@ type Relationship struct {
 	AssociationForeignFieldNames []string
 	AssociationForeignDBNames    []string
 	JoinTableHandler             JoinTableHandlerInterface
+	polymorphicTypeValue         interface{}
+}
+
+// embed qualify keys of the owner declared in the embedded struct with its field name and prefix of columns,
+// keys of many to many relations are kept as join tables are set up with the model
+func (relationship *Relationship) embed(fieldName, prefix string) {
+	qualify := func(names, dbNames []string) ([]string, []string) {
+		var qualifiedNames, qualifiedDBNames []string
+		for idx, name := range names {
+			qualifiedNames = append(qualifiedNames, fieldName+"."+name)
+			qualifiedDBNames = append(qualifiedDBNames, prefix+dbNames[idx])
+		}
+		return qualifiedNames, qualifiedDBNames
+	}
+
+	switch relationship.Kind {
+	case "belongs_to":
+		relationship.ForeignFieldNames, relationship.ForeignDBNames = qualify(relationship.ForeignFieldNames, relationship.ForeignDBNames)
+	case "has_one", "has_many":
+		relationship.AssociationForeignFieldNames, relationship.AssociationForeignDBNames = qualify(relationship.AssociationForeignFieldNames, relationship.AssociationForeignDBNames)
+	}
+}
+
+// setPolymorphic set polymorphic type field and value of the relationship, the value is converted to the type of polymorphic type field,
+// so the type column could be an integer or custom type
+//    type Comment struct {
+//      OwnerID   int
+//      OwnerType OwnerKind
+//    }
+//
+//    type Post struct {
+//      Comments []Comment `gorm:"polymorphic:Owner;polymorphic_value:1"`
+//    }
+func (relationship *Relationship) setPolymorphic(polymorphicType *StructField, value string) error {
+	relationship.PolymorphicType = polymorphicType.Name
+	relationship.PolymorphicDBName = polymorphicType.DBName
+	relationship.PolymorphicValue = value
+
+	typedValue, err := convertFromString(value, polymorphicType.Struct.Type)
+	if err != nil {
+		return fmt.Errorf("invalid polymorphic value %v for field %v: %v", value, polymorphicType.Name, err)
+	}
+	relationship.polymorphicTypeValue = typedValue
+	return nil
+}
+
+// polymorphicValue return polymorphic value in the type of polymorphic type field
+func (relationship *Relationship) polymorphicValue() interface{} {
+	if relationship.polymorphicTypeValue != nil {
+		return relationship.polymorphicTypeValue
+	}
+	return relationship.PolymorphicValue
+}
+
+// isPolymorphicOwner check record's polymorphic type matches polymorphic belongs to relationship, always true for other relationships
+func (relationship *Relationship) isPolymorphicOwner(record reflect.Value) bool {
+	if relationship.Kind != "belongs_to" || relationship.PolymorphicType == "" {
+		return true
+	}
+
+	if record = indirect(record); record.Kind() == reflect.Struct {
+		if field := record.FieldByName(relationship.PolymorphicType); field.IsValid() {
+			return equalAsString(field.Interface(), relationship.polymorphicValue())
+		}
+	}
+	return false
+}
+
+// polymorphicSources return records of value whose polymorphic type matches the relationship
+func (relationship *Relationship) polymorphicSources(value interface{}) (sources []interface{}) {
+	if relationship.Kind != "belongs_to" || relationship.PolymorphicType == "" {
+		return []interface{}{value}
+	}
+
+	if reflectValue := indirect(reflect.ValueOf(value)); reflectValue.Kind() == reflect.Slice {
+		for i := 0; i < reflectValue.Len(); i++ {
+			if relationship.isPolymorphicOwner(reflectValue.Index(i)) {
+				sources = append(sources, reflectValue.Index(i).Interface())
+			}
+		}
+	} else if relationship.isPolymorphicOwner(reflectValue) {
+		sources = append(sources, value)
+	}
+	return
 }
 
 func getForeignField(column string, fields []*StructField) *StructField {
@@ -152,10 +274,9 @@ func getForeignField(column string, fields []*StructField) *StructField {
 
 // GetModelStruct get value's model struct, relationships based on struct and tag definition
 func (scope *Scope) GetModelStruct() *ModelStruct {
-	var modelStruct ModelStruct
 	// Scope value can't be nil
 	if scope.Value == nil {
-		return &modelStruct
+		return &ModelStruct{}
 	}
 
 	reflectType := reflect.ValueOf(scope.Value).Type()
@@ -165,25 +286,29 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 
 	// Scope value need to be a struct
 	if reflectType.Kind() != reflect.Struct {
-		return &modelStruct
+		return &ModelStruct{}
 	}
 
-	// Get Cached model struct
-	isSingularTable := false
+	// Get Cached model struct, names of columns and tables depend on the naming strategy of the db
+	var (
+		isSingularTable = scope.db.isSingularTable()
+		namingStrategy  *NamingStrategy
+	)
 	if scope.db != nil && scope.db.parent != nil {
-		scope.db.parent.RLock()
-		isSingularTable = scope.db.parent.singularTable
-		scope.db.parent.RUnlock()
+		namingStrategy = scope.db.parent.namingStrategy
 	}
 
 	hashKey := struct {
-		singularTable bool
-		reflectType   reflect.Type
-	}{isSingularTable, reflectType}
+		singularTable  bool
+		namingStrategy *NamingStrategy
+		reflectType    reflect.Type
+	}{isSingularTable, namingStrategy, reflectType}
 	if value, ok := modelStructsMap.Load(hashKey); ok && value != nil {
 		return value.(*ModelStruct)
 	}
 
+	// declared after loading the cache, so cached model structs are returned without allocation
+	var modelStruct ModelStruct
 	modelStruct.ModelType = reflectType
 
 	// Get all fields
@@ -240,10 +365,16 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 					for _, subField := range scope.New(fieldValue).GetModelStruct().StructFields {
 						subField = subField.clone()
 						subField.Names = append([]string{fieldStruct.Name}, subField.Names...)
-						if prefix, ok := field.TagSettingsGet("EMBEDDED_PREFIX"); ok {
+						prefix, hasPrefix := field.TagSettingsGet("EMBEDDED_PREFIX")
+						if hasPrefix {
 							subField.DBName = prefix + subField.DBName
 						}
 
+						// keys of relations declared in named or prefixed embedded structs are qualified, as fields of them may be duplicated
+						if subField.Relationship != nil && (hasPrefix || !fieldStruct.Anonymous) {
+							subField.Relationship.embed(fieldStruct.Name, prefix)
+						}
+
 						if subField.IsPrimaryKey {
 							if _, ok := subField.TagSettingsGet("PRIMARY_KEY"); ok {
 								modelStruct.PrimaryFields = append(modelStruct.PrimaryFields, subField)
@@ -318,7 +449,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 													// if defined join table's foreign key
 													relationship.ForeignDBNames = append(relationship.ForeignDBNames, joinTableDBNames[idx])
 												} else {
-													defaultJointableForeignKey := ToColumnName(reflectType.Name()) + "_" + foreignField.DBName
+													defaultJointableForeignKey := scope.db.naming().ColumnName(reflectType.Name()) + "_" + foreignField.DBName
 													relationship.ForeignDBNames = append(relationship.ForeignDBNames, defaultJointableForeignKey)
 												}
 											}
@@ -349,7 +480,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 													relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, associationJoinTableDBNames[idx])
 												} else {
 													// join table foreign keys for association
-													joinTableDBName := ToColumnName(elemType.Name()) + "_" + field.DBName
+													joinTableDBName := scope.db.naming().ColumnName(elemType.Name()) + "_" + field.DBName
 													relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, joinTableDBName)
 												}
 											}
@@ -371,13 +502,11 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 										// Toy use OwnerID, OwnerType ('dogs') as foreign key
 										if polymorphicType := getForeignField(polymorphic+"Type", toFields); polymorphicType != nil {
 											associationType = polymorphic
-											relationship.PolymorphicType = polymorphicType.Name
-											relationship.PolymorphicDBName = polymorphicType.DBName
 											// if Dog has multiple set of toys set name of the set (instead of default 'dogs')
 											if value, ok := field.TagSettingsGet("POLYMORPHIC_VALUE"); ok {
-												relationship.PolymorphicValue = value
+												scope.Err(relationship.setPolymorphic(polymorphicType, value))
 											} else {
-												relationship.PolymorphicValue = scope.TableName()
+												scope.Err(relationship.setPolymorphic(polymorphicType, scope.modelTableName()))
 											}
 											polymorphicType.IsForeignKey = true
 										}
@@ -458,6 +587,8 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 								toFields                  = toScope.GetStructFields()
 								tagForeignKeys            []string
 								tagAssociationForeignKeys []string
+								foreignKeyPrefix          = field.Name
+								isPolymorphicBelongsTo    bool
 							)
 
 							if foreignKey, _ := field.TagSettingsGet("FOREIGNKEY"); foreignKey != "" {
@@ -475,20 +606,29 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 								// Toy use OwnerID, OwnerType ('cats') as foreign key
 								if polymorphicType := getForeignField(polymorphic+"Type", toFields); polymorphicType != nil {
 									associationType = polymorphic
-									relationship.PolymorphicType = polymorphicType.Name
-									relationship.PolymorphicDBName = polymorphicType.DBName
 									// if Cat has several different types of toys set name for each (instead of default 'cats')
 									if value, ok := field.TagSettingsGet("POLYMORPHIC_VALUE"); ok {
-										relationship.PolymorphicValue = value
+										scope.Err(relationship.setPolymorphic(polymorphicType, value))
+									} else {
+										scope.Err(relationship.setPolymorphic(polymorphicType, scope.modelTableName()))
+									}
+									polymorphicType.IsForeignKey = true
+								} else if polymorphicType := getForeignField(polymorphic+"Type", modelStruct.StructFields); polymorphicType != nil {
+									// Comment belongs to post or video, tag polymorphic is Owner, then associationType is Owner
+									// Comment use OwnerID as foreign key, OwnerType ('posts') to tell which kind of owner it belongs to
+									isPolymorphicBelongsTo = true
+									foreignKeyPrefix = polymorphic
+									if value, ok := field.TagSettingsGet("POLYMORPHIC_VALUE"); ok {
+										scope.Err(relationship.setPolymorphic(polymorphicType, value))
 									} else {
-										relationship.PolymorphicValue = scope.TableName()
+										scope.Err(relationship.setPolymorphic(polymorphicType, toScope.modelTableName()))
 									}
 									polymorphicType.IsForeignKey = true
 								}
 							}
 
 							// Has One
-							{
+							if !isPolymorphicBelongsTo {
 								var foreignKeys = tagForeignKeys
 								var associationForeignKeys = tagAssociationForeignKeys
 								// if no foreign keys defined with tag
@@ -559,14 +699,14 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 									// generate foreign keys & association foreign keys
 									if len(associationForeignKeys) == 0 {
 										for _, primaryField := range toScope.PrimaryFields() {
-											foreignKeys = append(foreignKeys, field.Name+primaryField.Name)
+											foreignKeys = append(foreignKeys, foreignKeyPrefix+primaryField.Name)
 											associationForeignKeys = append(associationForeignKeys, primaryField.Name)
 										}
 									} else {
 										// generate foreign keys with association foreign keys
 										for _, associationForeignKey := range associationForeignKeys {
 											if foreignField := getForeignField(associationForeignKey, toFields); foreignField != nil {
-												foreignKeys = append(foreignKeys, field.Name+foreignField.Name)
+												foreignKeys = append(foreignKeys, foreignKeyPrefix+foreignField.Name)
 												associationForeignKeys = append(associationForeignKeys, foreignField.Name)
 											}
 										}
@@ -575,8 +715,8 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 									// generate foreign keys & association foreign keys
 									if len(associationForeignKeys) == 0 {
 										for _, foreignKey := range foreignKeys {
-											if strings.HasPrefix(foreignKey, field.Name) {
-												associationForeignKey := strings.TrimPrefix(foreignKey, field.Name)
+											if strings.HasPrefix(foreignKey, foreignKeyPrefix) {
+												associationForeignKey := strings.TrimPrefix(foreignKey, foreignKeyPrefix)
 												if foreignField := getForeignField(associationForeignKey, toFields); foreignField != nil {
 													associationForeignKeys = append(associationForeignKeys, associationForeignKey)
 												}
@@ -626,7 +766,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 			if value, ok := field.TagSettingsGet("COLUMN"); ok {
 				field.DBName = value
 			} else {
-				field.DBName = ToColumnName(fieldStruct.Name)
+				field.DBName = scope.db.naming().ColumnName(fieldStruct.Name)
 			}
 
 			modelStruct.StructFields = append(modelStruct.StructFields, field)
@@ -0,0 +1,191 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryFileReader reads SQL files of named queries, `embed.FS` and `http.Dir` based readers could implement it
+type QueryFileReader interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// QueryStats statistics of a named query
+type QueryStats struct {
+	Calls         int64
+	Errors        int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// namedQuery SQL registered with a name and its statistics
+type namedQuery struct {
+	name  string
+	sql   string
+	mutex sync.Mutex
+	stats QueryStats
+}
+
+// RegisterQuery register SQL with a name, run it with NamedQuery, parameters are written like `@name`
+//    db.RegisterQuery("user_stats", "SELECT count(*) AS total FROM users WHERE created_at > @since")
+func (s *DB) RegisterQuery(name, sql string) *DB {
+	s.parent.Lock()
+	defer s.parent.Unlock()
+
+	if s.parent.namedQueries == nil {
+		s.parent.namedQueries = map[string]*namedQuery{}
+	}
+	s.parent.namedQueries[name] = &namedQuery{name: name, sql: sql}
+	return s
+}
+
+// RegisterQueryFiles register SQL files as named queries, queries are named by file names without extension, e.g:
+//    //go:embed queries/*.sql
+//    var queries embed.FS
+//
+//    db.RegisterQueryFiles(queries, "queries/user_stats.sql", "queries/top_products.sql")
+func (s *DB) RegisterQueryFiles(files QueryFileReader, names ...string) error {
+	for _, name := range names {
+		data, err := files.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read query %v, %v", name, err)
+		}
+
+		base := path.Base(name)
+		s.RegisterQuery(strings.TrimSuffix(base, path.Ext(base)), string(data))
+	}
+	return nil
+}
+
+// NamedQuery return db to run the registered query with params, params could be a map or struct, e.g:
+//    db.NamedQuery(ctx, "user_stats", map[string]interface{}{"since": since}).Scan(&stats)
+//
+// Statements of named queries are logged and traced with their names, statistics are collected by names, get them with NamedQueryStats
+func (s *DB) NamedQuery(ctx context.Context, name string, params interface{}) *DB {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	s.parent.RLock()
+	query, ok := s.parent.namedQueries[name]
+	s.parent.RUnlock()
+
+	clone := s.clone()
+	clone.db.ctx = ctx
+	clone.db.setSource(2)
+	if !ok {
+		clone.AddError(fmt.Errorf("query %v is not registered", name))
+		return clone
+	}
+
+	sql, args, err := bindNamedParams(query.sql, params)
+	if err != nil {
+		clone.AddError(fmt.Errorf("failed to bind params of query %v, %v", name, err))
+		return clone
+	}
+
+	clone.db.namedQuery = query
+	return clone.Raw(sql, args...)
+}
+
+// NamedQueryStats return statistics of named queries by names
+func (s *DB) NamedQueryStats() map[string]QueryStats {
+	s.parent.RLock()
+	defer s.parent.RUnlock()
+
+	results := map[string]QueryStats{}
+	for name, query := range s.parent.namedQueries {
+		query.mutex.Lock()
+		results[name] = query.stats
+		query.mutex.Unlock()
+	}
+	return results
+}
+
+// observe collect statistics of the query
+func (query *namedQuery) observe(duration time.Duration, err error) {
+	if query == nil {
+		return
+	}
+
+	query.mutex.Lock()
+	defer query.mutex.Unlock()
+
+	query.stats.Calls++
+	if err != nil && err != ErrRecordNotFound {
+		query.stats.Errors++
+	}
+	query.stats.TotalDuration += duration
+	if duration > query.stats.MaxDuration {
+		query.stats.MaxDuration = duration
+	}
+}
+
+// bindNamedParams replace `@name` in SQL with `?`, and return values of the params in order
+func bindNamedParams(sql string, params interface{}) (string, []interface{}, error) {
+	var (
+		builder strings.Builder
+		args    []interface{}
+		quote   rune
+		runes   = []rune(sql)
+	)
+
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '@' && idx+1 < len(runes) && isIdentifierRune(runes[idx+1]) && (idx == 0 || runes[idx-1] != '@'):
+			end := idx + 1
+			for end < len(runes) && isIdentifierRune(runes[end]) {
+				end++
+			}
+
+			name := string(runes[idx+1 : end])
+			value, ok := namedParam(params, name)
+			if !ok {
+				return "", nil, fmt.Errorf("missing param %v", name)
+			}
+
+			args = append(args, value)
+			builder.WriteRune('?')
+			idx = end - 1
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), args, nil
+}
+
+// namedParam return value of the param from map or struct, struct fields could be matched by names or column names
+func namedParam(params interface{}, name string) (interface{}, bool) {
+	if values, ok := params.(map[string]interface{}); ok {
+		value, ok := values[name]
+		return value, ok
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(params))
+	if reflectValue.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	if field := reflectValue.FieldByName(name); field.IsValid() && field.CanInterface() {
+		return field.Interface(), true
+	}
+
+	for idx := 0; idx < reflectValue.NumField(); idx++ {
+		if fieldStruct := reflectValue.Type().Field(idx); fieldStruct.PkgPath == "" && ToColumnName(fieldStruct.Name) == name {
+			return reflectValue.Field(idx).Interface(), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,61 @@
+package gorm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type queryFiles map[string]string
+
+func (files queryFiles) ReadFile(name string) ([]byte, error) {
+	if content, ok := files[name]; ok {
+		return []byte(content), nil
+	}
+	return nil, errors.New("file not found")
+}
+
+func TestNamedQuery(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.Save(&User{Name: "named_query", Age: 18})
+	db.Save(&User{Name: "named_query", Age: 30})
+
+	files := queryFiles{"queries/user_stats.sql": "SELECT count(*) AS total, max(age) AS max_age FROM users WHERE name = @name AND age >= @min_age AND email <> '@name'"}
+	if err := db.RegisterQueryFiles(files, "queries/user_stats.sql"); err != nil {
+		t.Fatalf("Failed to register query files, got %v", err)
+	}
+
+	var stats struct {
+		Total  int
+		MaxAge int
+	}
+	ctx := context.Background()
+	if err := db.NamedQuery(ctx, "user_stats", map[string]interface{}{"name": "named_query", "min_age": 20}).Scan(&stats).Error; err != nil || stats.Total != 1 || stats.MaxAge != 30 {
+		t.Errorf("Should run named query with map params, but got %v, %+v", err, stats)
+	}
+
+	params := struct {
+		Name   string
+		MinAge int
+	}{Name: "named_query", MinAge: 10}
+	if err := db.NamedQuery(ctx, "user_stats", params).Scan(&stats).Error; err != nil || stats.Total != 2 {
+		t.Errorf("Should run named query with struct params, but got %v, %+v", err, stats)
+	}
+
+	if err := db.NamedQuery(ctx, "user_stats", map[string]interface{}{"name": "named_query"}).Scan(&stats).Error; err == nil {
+		t.Errorf("Should return error for missing params")
+	}
+
+	if err := db.NamedQuery(ctx, "not_registered", nil).Scan(&stats).Error; err == nil {
+		t.Errorf("Should return error for queries not registered")
+	}
+
+	if stats := db.NamedQueryStats()["user_stats"]; stats.Calls != 2 || stats.Errors != 0 || stats.TotalDuration <= 0 {
+		t.Errorf("Should collect statistics of named query, but got %+v", stats)
+	}
+}
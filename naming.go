@@ -8,11 +8,24 @@ import (
 // Namer is a function type which is given a string and return a string
 type Namer func(string) string
 
-// NamingStrategy represents naming strategies
+// NamingStrategy represents naming strategies, it is used by all dbs after AddNamingStrategy,
+// or by a db if it is passed with Options at Open or set with SetNamingStrategy, e.g:
+//    db, err := gorm.Open("mysql", dsn, gorm.Options{NamingStrategy: &gorm.NamingStrategy{
+//      TablePrefix: "t_",                    // User => t_users
+//      Column:      gorm.NewNamer("OAuth"),  // OAuthToken => oauth_token
+//    }})
 type NamingStrategy struct {
 	DB     Namer
 	Table  Namer
 	Column Namer
+	// JoinTable alters names of many2many join tables
+	JoinTable Namer
+	// Index builds names of indexes not named in tags, Dialect.BuildKeyName is used if it is nil
+	Index func(kind, tableName string, fields ...string) string
+
+	TablePrefix   string // prefix of table names except names returned by TableName methods, e.g. `t_` for t_users
+	Schema        string // schema qualifying table names without schemas, e.g. `billing` for billing.users
+	SingularTable bool   // use singular table names like SingularTable
 }
 
 // TheNamingStrategy is being initialized with defaultNamingStrategy
@@ -36,6 +49,40 @@ func AddNamingStrategy(ns *NamingStrategy) {
 	TheNamingStrategy = ns
 }
 
+// SetNamingStrategy set the naming strategy of the db, instead of TheNamingStrategy used by all dbs
+func (s *DB) SetNamingStrategy(ns *NamingStrategy) *DB {
+	if ns.DB == nil {
+		ns.DB = defaultNamer
+	}
+	if ns.Table == nil {
+		ns.Table = defaultNamer
+	}
+	if ns.Column == nil {
+		ns.Column = defaultNamer
+	}
+	s.parent.namingStrategy = ns
+	return s
+}
+
+// naming return the naming strategy of the db
+func (s *DB) naming() *NamingStrategy {
+	if s != nil && s.parent != nil && s.parent.namingStrategy != nil {
+		return s.parent.namingStrategy
+	}
+	return TheNamingStrategy
+}
+
+// isSingularTable return true if singular table names are used by SingularTable or the naming strategy
+func (s *DB) isSingularTable() bool {
+	if s == nil || s.parent == nil {
+		return false
+	}
+
+	s.parent.RLock()
+	defer s.parent.RUnlock()
+	return s.parent.singularTable || s.naming().SingularTable
+}
+
 // DBName alters the given name by DB
 func (ns *NamingStrategy) DBName(name string) string {
 	return ns.DB(name)
@@ -51,6 +98,30 @@ func (ns *NamingStrategy) ColumnName(name string) string {
 	return ns.Column(name)
 }
 
+// JoinTableName alters the given many2many join table name by JoinTable, with the table prefix
+func (ns *NamingStrategy) JoinTableName(name string) string {
+	if ns.JoinTable != nil {
+		name = ns.JoinTable(name)
+	}
+	return ns.TablePrefix + name
+}
+
+// IndexName return name of the index not named in tags
+func (ns *NamingStrategy) IndexName(dialect Dialect, kind, tableName string, fields ...string) string {
+	if ns.Index != nil {
+		return ns.Index(kind, tableName, fields...)
+	}
+	return dialect.BuildKeyName(kind, tableName, fields...)
+}
+
+// qualifiedTableName qualify the table name with the schema if it doesn't have one
+func (ns *NamingStrategy) qualifiedTableName(name string) string {
+	if ns.Schema == "" || name == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return ns.Schema + "." + name
+}
+
 // ToDBName convert string to db name
 func ToDBName(name string) string {
 	return TheNamingStrategy.DBName(name)
@@ -69,12 +140,33 @@ func ToColumnName(name string) string {
 var smap = newSafeMap()
 
 func defaultNamer(name string) string {
+	return snakeCase(name, commonInitialismsReplacer, smap)
+}
+
+// NewNamer return a namer converting names to snake case like the default namer,
+// treating the acronyms as words like common initialisms ID and URL
+//    gorm.NewNamer("OAuth", "SKU") // OAuthToken => oauth_token, instead of o_auth_token
+func NewNamer(acronyms ...string) Namer {
+	var oldnew []string
+	for _, initialisms := range [][]string{acronyms, commonInitialisms} {
+		for _, initialism := range initialisms {
+			oldnew = append(oldnew, initialism, strings.Title(strings.ToLower(initialism)))
+		}
+	}
+
+	replacer, cache := strings.NewReplacer(oldnew...), newSafeMap()
+	return func(name string) string {
+		return snakeCase(name, replacer, cache)
+	}
+}
+
+func snakeCase(name string, initialismsReplacer *strings.Replacer, cache *safeMap) string {
 	const (
 		lower = false
 		upper = true
 	)
 
-	if v := smap.Get(name); v != "" {
+	if v := cache.Get(name); v != "" {
 		return v
 	}
 
@@ -83,7 +175,7 @@ func defaultNamer(name string) string {
 	}
 
 	var (
-		value                                    = commonInitialismsReplacer.Replace(name)
+		value                                    = initialismsReplacer.Replace(name)
 		buf                                      = bytes.NewBufferString("")
 		lastCase, currCase, nextCase, nextNumber bool
 	)
@@ -119,6 +211,6 @@ func defaultNamer(name string) string {
 	buf.WriteByte(value[len(value)-1])
 
 	s := strings.ToLower(buf.String())
-	smap.Set(name, s)
+	cache.Set(name, s)
 	return s
 }
@@ -1,6 +1,7 @@
 package gorm_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/lun-zhang/gorm"
@@ -67,3 +68,69 @@ func TestNamingStrategy(t *testing.T) {
 	}
 
 }
+
+type NamingProduct struct {
+	Id         int64
+	OAuthToken string      `gorm:"index"`
+	Tags       []NamingTag `gorm:"many2many:product_tags"`
+}
+
+type NamingTag struct {
+	Id   int64
+	Name string
+}
+
+func TestNamingStrategyOfDB(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	db.SetNamingStrategy(&gorm.NamingStrategy{
+		Column:      gorm.NewNamer("OAuth"),
+		TablePrefix: "t_",
+		Index: func(kind, tableName string, fields ...string) string {
+			return kind + "_" + strings.Join(fields, "_")
+		},
+	})
+
+	scope := db.NewScope(&NamingProduct{})
+	if name := scope.TableName(); name != "t_naming_products" {
+		t.Errorf("table name should be prefixed, but got %v", name)
+	}
+
+	if field, ok := scope.FieldByName("OAuthToken"); !ok || field.DBName != "oauth_token" {
+		t.Errorf("column name should be named with the acronym, but got %v", field)
+	}
+
+	if field, _ := DB.NewScope(&NamingProduct{}).FieldByName("OAuthToken"); field.DBName != "o_auth_token" {
+		t.Errorf("naming strategy should not change column names of other dbs, but got %v", field.DBName)
+	}
+
+	db.DropTableIfExists(&NamingProduct{}, &NamingTag{}, "t_product_tags")
+	if err := db.AutoMigrate(&NamingProduct{}, &NamingTag{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	if !db.Dialect().HasTable("t_product_tags") {
+		t.Errorf("join table should be prefixed")
+	}
+
+	if !db.Dialect().HasIndex("t_naming_products", "idx_oauth_token") {
+		t.Errorf("index should be named by the naming strategy")
+	}
+
+	product := NamingProduct{OAuthToken: "token", Tags: []NamingTag{{Name: "tag"}}}
+	db.Save(&product)
+
+	var found NamingProduct
+	if err := db.Preload("Tags").First(&found, "oauth_token = ?", "token").Error; err != nil || len(found.Tags) != 1 {
+		t.Errorf("product should be found with tags, but got %v", err)
+	}
+
+	db.SetNamingStrategy(&gorm.NamingStrategy{Schema: "billing", SingularTable: true})
+	if name := db.NewScope(&NamingProduct{}).TableName(); name != "billing.naming_product" {
+		t.Errorf("table name should be qualified with the schema, but got %v", name)
+	}
+}
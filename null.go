@@ -0,0 +1,80 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// NullTime represents a time.Time that may be null, works like `sql.NullTime` which isn't available before go 1.13
+//    type User struct {
+//      ActivatedAt gorm.NullTime
+//    }
+type NullTime struct {
+	Time  time.Time
+	Valid bool // Valid is true if Time is not NULL
+}
+
+var nullTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Scan implements the sql.Scanner interface
+func (nt *NullTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		nt.Time, nt.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		nt.Time, nt.Valid = v, true
+		return nil
+	case []byte:
+		return nt.parse(string(v))
+	case string:
+		return nt.parse(v)
+	}
+	return fmt.Errorf("failed to scan %T into NullTime", value)
+}
+
+func (nt *NullTime) parse(value string) error {
+	for _, layout := range nullTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			nt.Time, nt.Valid = t, true
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to parse %v as NullTime", value)
+}
+
+// Value implements the driver.Valuer interface
+func (nt NullTime) Value() (driver.Value, error) {
+	if !nt.Valid {
+		return nil, nil
+	}
+	return nt.Time, nil
+}
+
+// isNullValue check if the value will be stored as NULL, e.g. nil, nil pointers, invalid `sql.NullString` or any other `driver.Valuer` returning nil
+func isNullValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil() {
+		return true
+	}
+
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil && v == nil {
+			return true
+		}
+	}
+	return false
+}
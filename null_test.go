@@ -0,0 +1,98 @@
+package gorm_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type NullableProfile struct {
+	ID          uint
+	Nickname    sql.NullString
+	Score       sql.NullInt64
+	ActivatedAt gorm.NullTime
+}
+
+func TestNullTime(t *testing.T) {
+	DB.DropTableIfExists(&NullableProfile{})
+	DB.AutoMigrate(&NullableProfile{})
+
+	now := time.Now().Round(time.Second)
+	activated := NullableProfile{Nickname: sql.NullString{String: "activated", Valid: true}, ActivatedAt: gorm.NullTime{Time: now, Valid: true}}
+	inactive := NullableProfile{Nickname: sql.NullString{String: "inactive", Valid: true}}
+	DB.Save(&activated).Save(&inactive)
+
+	var result NullableProfile
+	DB.First(&result, activated.ID)
+	if !result.ActivatedAt.Valid || !result.ActivatedAt.Time.Equal(now) {
+		t.Errorf("NullTime should be saved and scanned, but got %v", result.ActivatedAt)
+	}
+
+	var result2 NullableProfile
+	DB.First(&result2, inactive.ID)
+	if result2.ActivatedAt.Valid {
+		t.Errorf("NullTime should be NULL, but got %v", result2.ActivatedAt)
+	}
+}
+
+func TestNullValueConditions(t *testing.T) {
+	DB.DropTableIfExists(&NullableProfile{})
+	DB.AutoMigrate(&NullableProfile{})
+
+	scored := NullableProfile{Nickname: sql.NullString{String: "scored", Valid: true}, Score: sql.NullInt64{Int64: 10, Valid: true}}
+	unscored := NullableProfile{Nickname: sql.NullString{String: "unscored", Valid: true}}
+	DB.Save(&scored).Save(&unscored)
+
+	var profiles []NullableProfile
+	DB.Where(map[string]interface{}{"score": sql.NullInt64{}}).Find(&profiles)
+	if len(profiles) != 1 || profiles[0].ID != unscored.ID {
+		t.Errorf("invalid null value in map conditions should be treated as IS NULL, but got %v", profiles)
+	}
+
+	DB.Not(map[string]interface{}{"score": sql.NullInt64{}}).Find(&profiles)
+	if len(profiles) != 1 || profiles[0].ID != scored.ID {
+		t.Errorf("invalid null value in not conditions should be treated as IS NOT NULL, but got %v", profiles)
+	}
+
+	DB.Where(&NullableProfile{Nickname: sql.NullString{String: "scored", Valid: true}, Score: sql.NullInt64{Int64: 1}}).Find(&profiles)
+	if len(profiles) != 1 || profiles[0].ID != scored.ID {
+		t.Errorf("invalid null value in struct conditions should be ignored, but got %v", profiles)
+	}
+}
+
+func TestUpdateWithNullValues(t *testing.T) {
+	DB.DropTableIfExists(&NullableProfile{})
+	DB.AutoMigrate(&NullableProfile{})
+
+	profile := NullableProfile{Nickname: sql.NullString{String: "nickname", Valid: true}, Score: sql.NullInt64{Int64: 10, Valid: true}}
+	DB.Save(&profile)
+
+	DB.Model(&profile).Updates(NullableProfile{Nickname: sql.NullString{String: "", Valid: true}, Score: sql.NullInt64{Int64: 5}})
+
+	var result NullableProfile
+	DB.First(&result, profile.ID)
+	if result.Nickname.String != "" || !result.Nickname.Valid {
+		t.Errorf("valid null value should be updated even it is zero, but got %v", result.Nickname)
+	}
+
+	if result.Score.Int64 != 10 {
+		t.Errorf("invalid null value should be ignored when updating with struct, but got %v", result.Score)
+	}
+}
+
+func TestSetPointerToNullValueField(t *testing.T) {
+	profile := NullableProfile{}
+	scope := DB.NewScope(&profile)
+	nickname := "pointer"
+
+	field, _ := scope.FieldByName("Nickname")
+	if err := field.Set(&nickname); err != nil || !profile.Nickname.Valid || profile.Nickname.String != "pointer" {
+		t.Errorf("should be able to set pointer to null value field, but got %v, %v", profile.Nickname, err)
+	}
+
+	if err := field.Set((*string)(nil)); err != nil || profile.Nickname.Valid {
+		t.Errorf("should set null value field to NULL with nil pointer, but got %v, %v", profile.Nickname, err)
+	}
+}
@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Pagination metadata of the page found by FindPage
+type Pagination struct {
+	Page       int   // current page, starting from 1
+	PerPage    int   // max number of records of a page
+	Total      int64 // number of records matching conditions
+	TotalPages int
+	HasNext    bool
+}
+
+// FindWithTotal find records like Find, and count records matching the same conditions on the slave, ignoring Limit, Offset and Order,
+// the query is skipped if no records are left after the offset, e.g:
+//    var users []User
+//    var total int64
+//    db.Where("age > ?", 18).Order("id").Limit(10).Offset(20).FindWithTotal(&users, &total)
+//    // SELECT count(*) FROM users WHERE (age > 18);
+//    // SELECT * FROM users WHERE (age > 18) ORDER BY id LIMIT 10 OFFSET 20;
+func (s *DB) FindWithTotal(out interface{}, total *int64) *DB {
+	counter := s.Slave().Limit(-1).Offset(-1)
+	if counter.Value == nil {
+		counter = counter.Model(out)
+	}
+	if db := counter.Count(total); db.Error != nil {
+		return db
+	}
+
+	offset, _ := strconv.ParseInt(fmt.Sprint(s.clone().search.offset), 0, 0)
+	if offset < 0 {
+		offset = 0
+	}
+	if *total <= offset {
+		// no records left, clear results like Find
+		if value := reflect.Indirect(reflect.ValueOf(out)); value.Kind() == reflect.Slice {
+			value.Set(reflect.MakeSlice(value.Type(), 0, 0))
+		}
+		return s.clone()
+	}
+	return s.Find(out)
+}
+
+// FindPage find records of the page like FindWithTotal, pages start from 1, metadata of the page is filled to pagination, e.g:
+//    var pagination gorm.Pagination
+//    db.Where("age > ?", 18).Order("id").FindPage(&users, 3, 10, &pagination)
+//    // SELECT count(*) FROM users WHERE (age > 18);
+//    // SELECT * FROM users WHERE (age > 18) ORDER BY id LIMIT 10 OFFSET 20;
+func (s *DB) FindPage(out interface{}, page, perPage int, pagination *Pagination) *DB {
+	if page < 1 || perPage < 1 {
+		clone := s.clone()
+		clone.AddError(errors.New("page and records per page should be positive"))
+		return clone
+	}
+
+	*pagination = Pagination{Page: page, PerPage: perPage}
+	db := s.Limit(perPage).Offset((page-1)*perPage).FindWithTotal(out, &pagination.Total)
+	pagination.TotalPages = int((pagination.Total + int64(perPage) - 1) / int64(perPage))
+	pagination.HasNext = int64(page*perPage) < pagination.Total
+	return db
+}
@@ -0,0 +1,58 @@
+package gorm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type PagedItem struct {
+	ID    uint
+	Name  string
+	Group string
+}
+
+func TestFindPage(t *testing.T) {
+	DB.DropTableIfExists(&PagedItem{})
+	DB.AutoMigrate(&PagedItem{})
+	for i := 1; i <= 25; i++ {
+		DB.Create(&PagedItem{Name: fmt.Sprintf("item%02d", i), Group: "paged"})
+	}
+	DB.Create(&PagedItem{Name: "other", Group: "other"})
+
+	scoped := DB.Where(&PagedItem{Group: "paged"}).Order("name desc")
+
+	var (
+		items      []PagedItem
+		pagination gorm.Pagination
+	)
+	if err := scoped.FindPage(&items, 2, 10, &pagination).Error; err != nil {
+		t.Fatalf("Failed to find page, got %v", err)
+	}
+	if len(items) != 10 || items[0].Name != "item15" {
+		t.Errorf("should find records of the page, but got %v records", len(items))
+	}
+	if pagination != (gorm.Pagination{Page: 2, PerPage: 10, Total: 25, TotalPages: 3, HasNext: true}) {
+		t.Errorf("pagination of the second page is wrong, got %#v", pagination)
+	}
+
+	scoped.FindPage(&items, 3, 10, &pagination)
+	if len(items) != 5 || pagination.HasNext {
+		t.Errorf("the last page should have 5 records and no next page, but got %v, %#v", len(items), pagination)
+	}
+
+	scoped.FindPage(&items, 4, 10, &pagination)
+	if len(items) != 0 || pagination.Total != 25 {
+		t.Errorf("pages out of range should have no records, but got %v, %#v", len(items), pagination)
+	}
+
+	var total int64
+	if err := scoped.Limit(3).Offset(1).FindWithTotal(&items, &total).Error; err != nil || total != 25 || len(items) != 3 || items[0].Name != "item24" {
+		t.Errorf("should count records ignoring limit and offset, but got %v, %v, %v", err, total, len(items))
+	}
+
+	if err := scoped.FindPage(&items, 0, 10, &pagination).Error; err == nil {
+		t.Errorf("pages should start from 1")
+	}
+}
@@ -0,0 +1,77 @@
+package gorm
+
+import "fmt"
+
+// Plugin extends gorm with callbacks or settings, e.g. metrics, caching, sharding, encryption, register it with `Use`
+//    type MetricsPlugin struct{}
+//
+//    func (MetricsPlugin) Name() string {
+//      return "metrics"
+//    }
+//
+//    func (MetricsPlugin) Initialize(db *gorm.DB) error {
+//      db.Callback().Query().After("gorm:query").Register("metrics:after_query", collectQueryMetrics)
+//      return nil
+//    }
+//
+// A plugin could also implement `Close() error` to release its resources, which will be called when closing the db
+type Plugin interface {
+	Name() string
+	Initialize(*DB) error
+}
+
+// Use register plugin, its `Initialize` will be called with current db, the plugin won't be registered if `Initialize` returns error
+//    db.Use(MetricsPlugin{})
+func (s *DB) Use(plugin Plugin) error {
+	name := plugin.Name()
+
+	// reserve the name before initializing, so a plugin won't be initialized twice when it is registered concurrently
+	s.parent.Lock()
+	if _, ok := s.parent.plugins[name]; ok {
+		s.parent.Unlock()
+		return fmt.Errorf("plugin %v is already registered", name)
+	}
+	if s.parent.plugins == nil {
+		s.parent.plugins = map[string]Plugin{}
+	}
+	s.parent.plugins[name] = plugin
+	s.parent.Unlock()
+
+	err := plugin.Initialize(s)
+
+	s.parent.Lock()
+	defer s.parent.Unlock()
+	if err != nil {
+		delete(s.parent.plugins, name)
+		return err
+	}
+	s.parent.pluginNames = append(s.parent.pluginNames, name)
+	return nil
+}
+
+// Plugins return registered plugins by their names, plugins being initialized aren't included
+func (s *DB) Plugins() map[string]Plugin {
+	s.parent.RLock()
+	defer s.parent.RUnlock()
+
+	plugins := make(map[string]Plugin, len(s.parent.pluginNames))
+	for _, name := range s.parent.pluginNames {
+		plugins[name] = s.parent.plugins[name]
+	}
+	return plugins
+}
+
+// closePlugins close registered plugins in reverse order of registration
+func (s *DB) closePlugins() (err error) {
+	s.parent.RLock()
+	defer s.parent.RUnlock()
+
+	for i := len(s.parent.pluginNames) - 1; i >= 0; i-- {
+		if plugin, ok := s.parent.plugins[s.parent.pluginNames[i]].(closer); ok {
+			if e := plugin.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
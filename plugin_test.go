@@ -0,0 +1,125 @@
+package gorm_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type countingPlugin struct {
+	queries int
+	closed  bool
+}
+
+func (p *countingPlugin) Name() string {
+	return "counting"
+}
+
+func (p *countingPlugin) Initialize(db *gorm.DB) error {
+	db.Callback().Query().After("gorm:query").Register("counting:after_query", func(scope *gorm.Scope) {
+		p.queries++
+	})
+	return nil
+}
+
+func (p *countingPlugin) Close() error {
+	p.closed = true
+	return nil
+}
+
+type failingPlugin struct{}
+
+func (failingPlugin) Name() string {
+	return "failing"
+}
+
+func (failingPlugin) Initialize(db *gorm.DB) error {
+	return errors.New("failed to initialize")
+}
+
+func TestPlugins(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+
+	plugin := &countingPlugin{}
+	if err := db.Use(plugin); err != nil {
+		t.Fatalf("No error should happen when use plugin, but got %v", err)
+	}
+
+	if err := db.Use(&countingPlugin{}); err == nil {
+		t.Errorf("Should get error when registering plugin with same name")
+	}
+
+	if err := db.Use(failingPlugin{}); err == nil {
+		t.Errorf("Should get error when failed to initialize plugin")
+	}
+
+	plugins := db.Model(&User{}).Plugins()
+	if len(plugins) != 1 || plugins["counting"] != plugin {
+		t.Errorf("Should only find registered plugins, but got %v", plugins)
+	}
+
+	db.Find(&[]User{})
+	if plugin.queries != 1 {
+		t.Errorf("Callbacks registered by plugin should be called, but got %v", plugin.queries)
+	}
+
+	db.Close()
+	if !plugin.closed {
+		t.Errorf("Plugin should be closed with db")
+	}
+}
+
+type slowPlugin struct {
+	initialized *int32
+}
+
+func (slowPlugin) Name() string {
+	return "slow"
+}
+
+func (p slowPlugin) Initialize(db *gorm.DB) error {
+	atomic.AddInt32(p.initialized, 1)
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestUsePluginConcurrently(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	var (
+		wg          sync.WaitGroup
+		initialized int32
+		registered  int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if db.Use(slowPlugin{initialized: &initialized}) == nil {
+				atomic.AddInt32(&registered, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if initialized != 1 || registered != 1 {
+		t.Errorf("Plugin should be initialized and registered once, but got %v, %v", initialized, registered)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := db.Use(failingPlugin{}); err == nil || err.Error() != "failed to initialize" {
+			t.Errorf("Plugin failed to initialize should be able to retry, but got %v", err)
+		}
+	}
+}
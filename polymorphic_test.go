@@ -364,3 +364,107 @@ func TestNamedPolymorphic(t *testing.T) {
 		t.Errorf("Hamster's other toy should be cleared with Clear")
 	}
 }
+
+type AttachmentOwnerKind int
+
+type Attachment struct {
+	Id        int
+	Name      string
+	OwnerId   int
+	OwnerType AttachmentOwnerKind
+	Article   *AttachmentArticle `gorm:"polymorphic:Owner;polymorphic_value:1"`
+	Video     *AttachmentVideo   `gorm:"polymorphic:Owner;polymorphic_value:2"`
+}
+
+type AttachmentArticle struct {
+	Id          int
+	Title       string
+	Attachments []Attachment `gorm:"polymorphic:Owner;polymorphic_value:1"`
+}
+
+type AttachmentVideo struct {
+	Id         int
+	Title      string
+	Attachment Attachment `gorm:"polymorphic:Owner;polymorphic_value:2"`
+}
+
+func TestPolymorphicWithSharedTableAndCustomTypeValue(t *testing.T) {
+	DB.DropTableIfExists(&Attachment{}, &AttachmentArticle{}, &AttachmentVideo{})
+	if err := DB.AutoMigrate(&Attachment{}, &AttachmentArticle{}, &AttachmentVideo{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got %v", err)
+	}
+
+	article := AttachmentArticle{Title: "article", Attachments: []Attachment{{Name: "article file 1"}, {Name: "article file 2"}}}
+	video := AttachmentVideo{Title: "video", Attachment: Attachment{Name: "video file"}}
+	if err := DB.Save(&article).Save(&video).Error; err != nil {
+		t.Fatalf("Failed to save owners, got %v", err)
+	}
+
+	if article.Attachments[0].OwnerType != 1 || video.Attachment.OwnerType != 2 {
+		t.Errorf("Polymorphic type should be set with custom type value, got %v, %v", article.Attachments[0].OwnerType, video.Attachment.OwnerType)
+	}
+
+	// the article and video might have same id
+	if DB.Model(&article).Association("Attachments").Count() != 2 {
+		t.Errorf("Article's attachments count should be 2")
+	}
+
+	if DB.Model(&video).Association("Attachment").Count() != 1 {
+		t.Errorf("Video's attachment count should be 1")
+	}
+
+	// belongs to polymorphic owner
+	attachment := Attachment{Name: "another video file", Video: &AttachmentVideo{Title: "another video"}}
+	if err := DB.Save(&attachment).Error; err != nil {
+		t.Fatalf("Failed to save attachment, got %v", err)
+	}
+
+	if attachment.OwnerType != 2 || attachment.OwnerId != attachment.Video.Id {
+		t.Errorf("Polymorphic foreign key and type should be set when saving owner, got %v, %v", attachment.OwnerType, attachment.OwnerId)
+	}
+
+	var attachments []Attachment
+	if err := DB.Preload("Article").Preload("Video").Order("id").Find(&attachments).Error; err != nil {
+		t.Fatalf("Failed to preload polymorphic owners, got %v", err)
+	}
+
+	if len(attachments) != 4 {
+		t.Fatalf("Should find 4 attachments, got %v", len(attachments))
+	}
+
+	for _, attachment := range attachments {
+		switch attachment.OwnerType {
+		case 1:
+			if attachment.Article == nil || attachment.Article.Title != "article" || attachment.Video != nil {
+				t.Errorf("Should only preload article for %v, got %#v, %#v", attachment.Name, attachment.Article, attachment.Video)
+			}
+		case 2:
+			if attachment.Video == nil || attachment.Video.Id != attachment.OwnerId || attachment.Article != nil {
+				t.Errorf("Should only preload video for %v, got %#v, %#v", attachment.Name, attachment.Article, attachment.Video)
+			}
+		}
+	}
+
+	var joinedAttachments []Attachment
+	if err := DB.Joins("Video").Order("attachments.id").Find(&joinedAttachments).Error; err != nil {
+		t.Fatalf("Failed to join polymorphic owner, got %v", err)
+	}
+
+	if len(joinedAttachments) != 4 || joinedAttachments[0].Video != nil || joinedAttachments[2].Video == nil || joinedAttachments[2].Video.Title != "video" {
+		t.Errorf("Should only join video for attachments of videos, got %#v", joinedAttachments)
+	}
+
+	var articles []AttachmentArticle
+	if err := DB.Preload("Attachments").Find(&articles).Error; err != nil {
+		t.Fatalf("Failed to preload polymorphic attachments, got %v", err)
+	}
+
+	if len(articles) != 1 || len(articles[0].Attachments) != 2 {
+		t.Errorf("Should preload attachments of article, got %#v", articles)
+	}
+
+	var owner AttachmentVideo
+	if err := DB.Model(&attachments[3]).Association("Video").Find(&owner).Error; err != nil || owner.Title != "another video" {
+		t.Errorf("Should find polymorphic owner with association mode, got %v, %v", owner.Title, err)
+	}
+}
@@ -0,0 +1,58 @@
+package gorm
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolOptions connection pool settings of *sql.DB, zero values keep defaults of database/sql
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime requires go1.15 or later, it is ignored with older versions
+	ConnMaxIdleTime time.Duration
+}
+
+// Options settings applied when opening db, e.g:
+//    db, err := gorm.Open("mysql", dsn, gorm.Options{PoolOptions: gorm.PoolOptions{MaxOpenConns: 100, MaxIdleConns: 10}})
+//    db, err := gorm.OpenMasterAndSlave("mysql", masterDSN, slaveDSN, gorm.Options{
+//      PoolOptions: gorm.PoolOptions{MaxOpenConns: 50},
+//      Slave:       gorm.PoolOptions{MaxOpenConns: 200},
+//    })
+type Options struct {
+	// PoolOptions pool settings of master
+	PoolOptions
+	// Slave pool settings of slave, settings of master are used if it is blank
+	Slave PoolOptions
+	// NamingStrategy naming strategy of the db, TheNamingStrategy is used if it is nil
+	NamingStrategy *NamingStrategy
+}
+
+// slavePoolOptions return pool settings of slave
+func (options Options) slavePoolOptions() PoolOptions {
+	if options.Slave == (PoolOptions{}) {
+		return options.PoolOptions
+	}
+	return options.Slave
+}
+
+// apply apply pool settings to db
+func (options PoolOptions) apply(db *sql.DB) {
+	if options.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(options.MaxOpenConns)
+	}
+	if options.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(options.MaxIdleConns)
+	}
+	if options.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(options.ConnMaxLifetime)
+	}
+	if options.ConnMaxIdleTime != 0 {
+		if setter, ok := interface{}(db).(interface {
+			SetConnMaxIdleTime(d time.Duration)
+		}); ok {
+			setter.SetConnMaxIdleTime(options.ConnMaxIdleTime)
+		}
+	}
+}
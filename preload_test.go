@@ -1699,3 +1699,163 @@ func toJSONString(v interface{}) []byte {
 	r, _ := json.MarshalIndent(v, "", "  ")
 	return r
 }
+
+func TestPreloadWithLimitPerRecord(t *testing.T) {
+	users := []User{
+		{Name: "limit-user1", Emails: []Email{{Email: "a1@example.com"}, {Email: "a2@example.com"}, {Email: "a3@example.com"}}, Languages: []Language{{Name: "limit-EN"}, {Name: "limit-ZH"}, {Name: "limit-DE"}}},
+		{Name: "limit-user2", Emails: []Email{{Email: "b1@example.com"}}, Languages: []Language{{Name: "limit-FR"}}},
+	}
+	for i := range users {
+		DB.Save(&users[i])
+	}
+
+	latestFirst := func(db *gorm.DB) *gorm.DB {
+		return db.Order("id DESC")
+	}
+
+	var results []User
+	if err := DB.Where("name LIKE ?", "limit-user%").Order("id").
+		Preload("Emails", gorm.LimitPerRecord(2), latestFirst).
+		Preload("Languages", gorm.LimitPerRecord(2), latestFirst).
+		Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen when preloading with limit per record, but got %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("should find 2 users, but got %v", len(results))
+	}
+
+	if emails := results[0].Emails; len(emails) != 2 || emails[0].Email != "a3@example.com" || emails[1].Email != "a2@example.com" {
+		t.Errorf("should preload latest 2 emails for user1, but got %+v", emails)
+	}
+
+	if len(results[1].Emails) != 1 {
+		t.Errorf("should preload all emails for user2, but got %+v", results[1].Emails)
+	}
+
+	if languages := results[0].Languages; len(languages) != 2 || languages[0].Name != "limit-DE" || languages[1].Name != "limit-ZH" {
+		t.Errorf("should preload latest 2 languages for user1, but got %+v", languages)
+	}
+
+	if len(results[1].Languages) != 1 {
+		t.Errorf("should preload all languages for user2, but got %+v", results[1].Languages)
+	}
+
+	var user User
+	DB.Preload("Emails", gorm.LimitPerRecord(1)).First(&user, users[0].Id)
+	if len(user.Emails) != 1 || user.Emails[0].Email != "a1@example.com" {
+		t.Errorf("should preload 1 email for user, but got %+v", user.Emails)
+	}
+}
+
+func TestJoinsPreload(t *testing.T) {
+	user1 := User{Name: "joins-preload-user1", CreditCard: CreditCard{Number: "411111111111"}, Company: Company{Name: "joins-preload-company"}, BillingAddress: Address{Address1: "joins-preload-address"}}
+	user2 := User{Name: "joins-preload-user2"}
+	DB.Save(&user1).Save(&user2)
+
+	var users []User
+	if err := DB.Joins("CreditCard").Joins("Company").Joins("BillingAddress").Where("users.name LIKE ?", "joins-preload-user%").Order("users.id").Find(&users).Error; err != nil {
+		t.Fatalf("no error should happen when loading associations with joins, but got %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("should find 2 users, but got %v", len(users))
+	}
+
+	if users[0].CreditCard.Number != "411111111111" || users[0].Company.Name != "joins-preload-company" || users[0].BillingAddress.Address1 != "joins-preload-address" {
+		t.Errorf("associations should be loaded with joins, but got %+v, %+v, %+v", users[0].CreditCard, users[0].Company, users[0].BillingAddress)
+	}
+
+	if users[0].Name != "joins-preload-user1" || users[0].Id != user1.Id {
+		t.Errorf("user's own columns shouldn't be overwritten by joined columns, but got %v %v", users[0].Id, users[0].Name)
+	}
+
+	if users[1].CreditCard.ID != 0 || users[1].Company.Id != 0 {
+		t.Errorf("associations should be blank if not found, but got %+v, %+v", users[1].CreditCard, users[1].Company)
+	}
+
+	DB.Delete(&user1.CreditCard)
+	var user User
+	DB.Joins("CreditCard").First(&user, user1.Id)
+	if user.CreditCard.ID != 0 {
+		t.Errorf("soft deleted association shouldn't be loaded, but got %+v", user.CreditCard)
+	}
+
+	var count int
+	if DB.Model(&User{}).Joins("Company").Where("users.name LIKE ?", "joins-preload-user%").Count(&count); count != 2 {
+		t.Errorf("should be able to count with joins, but got %v", count)
+	}
+}
+
+type WildcardAuthor struct {
+	ID    uint
+	Name  string
+	Books []WildcardBook
+}
+
+type WildcardBook struct {
+	ID               uint
+	Title            string
+	WildcardAuthorID uint
+	WildcardAuthor   *WildcardAuthor
+	Reviews          []WildcardReview
+}
+
+type WildcardReview struct {
+	ID             uint
+	WildcardBookID uint
+	Content        string
+}
+
+func TestPreloadAssociationsWildcard(t *testing.T) {
+	DB.DropTableIfExists(&WildcardAuthor{}, &WildcardBook{}, &WildcardReview{})
+	if err := DB.AutoMigrate(&WildcardAuthor{}, &WildcardBook{}, &WildcardReview{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	author := WildcardAuthor{Name: "wildcard", Books: []WildcardBook{{Title: "book1", Reviews: []WildcardReview{{Content: "good"}}}}}
+	DB.Save(&author)
+
+	var result WildcardAuthor
+	if err := DB.Preload(gorm.Associations).First(&result, author.ID).Error; err != nil {
+		t.Fatalf("no error should happen when preloading all associations, but got %v", err)
+	}
+
+	if len(result.Books) != 1 || result.Books[0].Reviews != nil {
+		t.Errorf("should only preload associations of the first level by default, but got %+v", result.Books)
+	}
+
+	var result2 WildcardAuthor
+	DB.Set("gorm:preload_max_depth", 3).Preload(gorm.Associations).First(&result2, author.ID)
+	if len(result2.Books) != 1 || len(result2.Books[0].Reviews) != 1 {
+		t.Errorf("should preload nested associations with max depth, but got %+v", result2.Books)
+	} else if result2.Books[0].WildcardAuthor != nil {
+		t.Errorf("associations referring to models in the preload path should be skipped")
+	}
+
+	var books []WildcardBook
+	DB.Preload(gorm.Associations).Find(&books, "wildcard_author_id = ?", author.ID)
+	if len(books) != 1 || books[0].WildcardAuthor == nil || len(books[0].Reviews) != 1 {
+		t.Errorf("should preload all associations of books, but got %+v", books)
+	}
+
+	var result3 WildcardAuthor
+	DB.Preload("Books."+gorm.Associations).First(&result3, author.ID)
+	if len(result3.Books) != 1 || len(result3.Books[0].Reviews) != 1 {
+		t.Errorf("should preload associations of nested preload, but got %+v", result3.Books)
+	}
+
+	DB.Save(&WildcardBook{Title: "book2", WildcardAuthorID: author.ID, Reviews: []WildcardReview{{Content: "bad"}}})
+
+	var result4 WildcardAuthor
+	DB.Set("gorm:preload_max_depth", 2).Preload(gorm.Associations, "title = ?", "book2").First(&result4, author.ID)
+	if len(result4.Books) != 1 || result4.Books[0].Title != "book2" || len(result4.Books[0].Reviews) != 1 {
+		t.Errorf("conditions should be applied to associations of the wildcard, but got %+v", result4.Books)
+	}
+
+	var result5 WildcardAuthor
+	DB.Preload("Books."+gorm.Associations, "content = ?", "good").First(&result5, author.ID)
+	if len(result5.Books) != 2 || len(result5.Books[0].Reviews)+len(result5.Books[1].Reviews) != 1 {
+		t.Errorf("conditions should be applied to associations of nested wildcard, but got %+v", result5.Books)
+	}
+}
@@ -0,0 +1,103 @@
+package gorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ProxyMode compatibility mode of proxies like Vitess and ProxySQL, avoid features they don't support
+type ProxyMode struct {
+	// DisableLastInsertID query ids of created records with `SELECT LAST_INSERT_ID()` in their transactions,
+	// instead of the last insert id returned by the driver, creating records outside transactions returns ErrLastInsertIDOutsideTransaction
+	DisableLastInsertID bool
+	// PrimaryHint comment prepended to statements routed to master with Master(), e.g. "/* @@primary */"
+	PrimaryHint string
+	// ReplicaHint comment prepended to queries routed to slave with Slave(), e.g. "/* @@replica */"
+	ReplicaHint string
+}
+
+// SetProxyMode work with proxies in the mode, statements containing multiple statements or USE statements return ErrProxyUnsafeStatement,
+// and targets of statements routed by Master() and Slave() are hinted with comments, e.g:
+//    db.SetProxyMode(gorm.ProxyMode{DisableLastInsertID: true, ReplicaHint: "/* @@replica */"})
+//    db.Slave().Find(&users)
+//    // /* @@replica */ SELECT * FROM `users`
+//
+// DBs cloned before won't use it, so set it right after opening the db
+func (s *DB) SetProxyMode(mode ProxyMode) *DB {
+	s.parent.db.proxyMode = &mode
+	s.db.proxyMode = &mode
+	return s
+}
+
+// proxyStatement check the statement is safe for proxies, and hint its target
+func (db ctxDB) proxyStatement(query string, read bool) (string, error) {
+	if db.proxyMode == nil {
+		return query, nil
+	}
+
+	if fields := strings.Fields(query); len(fields) > 0 && strings.ToUpper(fields[0]) == "USE" || hasMultiStatements(query) {
+		return query, ErrProxyUnsafeStatement
+	}
+
+	if _, ok := db.dbSQL.(sqlTx); ok {
+		return query, nil
+	}
+
+	switch {
+	case db.target == targetMaster && db.proxyMode.PrimaryHint != "":
+		return db.proxyMode.PrimaryHint + " " + query, nil
+	case db.target == targetSlave && read && db.proxyMode.ReplicaHint != "":
+		return db.proxyMode.ReplicaHint + " " + query, nil
+	}
+	return query, nil
+}
+
+// hasMultiStatements return true if the SQL contains statements separated by `;`, except the trailing one
+func hasMultiStatements(sql string) bool {
+	var (
+		quote      rune
+		terminated bool
+		runes      = []rune(sql)
+	)
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case quote != 0:
+			if r == '\\' {
+				idx++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == ';' || unicode.IsSpace(r):
+			terminated = terminated || r == ';'
+		case r == '-' && idx+1 < len(runes) && runes[idx+1] == '-', r == '#':
+			for idx < len(runes) && runes[idx] != '\n' {
+				idx++
+			}
+		case r == '/' && idx+1 < len(runes) && runes[idx+1] == '*':
+			for idx += 2; idx < len(runes) && !(runes[idx] == '*' && idx+1 < len(runes) && runes[idx+1] == '/'); idx++ {
+			}
+			idx++
+		case terminated:
+			return true
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		}
+	}
+	return false
+}
+
+// lastInsertID return id of the created record, query it with `SELECT LAST_INSERT_ID()` if it is disabled by ProxyMode,
+// it is only queried in transactions, pooled connections may query it from other connections
+func (scope *Scope) lastInsertID(result interface{ LastInsertId() (int64, error) }) (id int64, err error) {
+	if mode := scope.db.db.proxyMode; mode == nil || !mode.DisableLastInsertID {
+		return result.LastInsertId()
+	}
+
+	if _, ok := scope.db.db.dbSQL.(sqlTx); !ok {
+		return 0, ErrLastInsertIDOutsideTransaction
+	}
+
+	err = scope.SQLDB().QueryRow("SELECT LAST_INSERT_ID()").Scan(&id)
+	return
+}
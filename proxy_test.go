@@ -0,0 +1,60 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestProxyMode(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	db.SetProxyMode(gorm.ProxyMode{DisableLastInsertID: true, PrimaryHint: "/* @@primary */", ReplicaHint: "/* @@replica */"})
+
+	db.Slave().Find(&[]User{})
+	if sql := recorder.LastStatement().SQL; sql != "/* @@replica */ SELECT * FROM `users`" {
+		t.Errorf("queries routed to slave should be hinted, but got %v", sql)
+	}
+
+	db.Master().Find(&[]User{})
+	if sql := recorder.LastStatement().SQL; sql != "/* @@primary */ SELECT * FROM `users`" {
+		t.Errorf("queries routed to master should be hinted, but got %v", sql)
+	}
+
+	db.Find(&[]User{})
+	if sql := recorder.LastStatement().SQL; sql != "SELECT * FROM `users`" {
+		t.Errorf("queries not routed shouldn't be hinted, but got %v", sql)
+	}
+
+	for _, sql := range []string{"USE other_db", "UPDATE users SET age = 1; DELETE FROM users"} {
+		if err := db.Exec(sql).Error; err != gorm.ErrProxyUnsafeStatement {
+			t.Errorf("%v should be rejected, but got %v", sql, err)
+		}
+	}
+	if err := db.Exec("UPDATE users SET name = ';' WHERE age = 1; -- comment;").Error; err != nil {
+		t.Errorf("single statement should be allowed, but got %v", err)
+	}
+
+	recorder.Reset()
+	recorder.Reply("SELECT LAST_INSERT_ID()", []string{"id"}, []interface{}{42})
+	user := User{Name: "proxy"}
+	if err := db.Create(&user).Error; err != nil || user.Id != 42 {
+		t.Errorf("id should be queried with LAST_INSERT_ID(), but got %v, %v", user.Id, err)
+	}
+	if statements := recorder.Statements(); len(statements) < 3 || statements[2].SQL != "SELECT LAST_INSERT_ID()" {
+		t.Errorf("id should be queried in the transaction, but got %v", statements)
+	}
+
+	// connections without Begin can't run creating in transactions
+	noTxDB, err := gorm.Open("mysql", struct{ gorm.SQLCommon }{db.CommonDB()})
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	noTxDB.SetProxyMode(gorm.ProxyMode{DisableLastInsertID: true})
+	if err := noTxDB.Create(&User{Name: "proxy"}).Error; err != gorm.ErrLastInsertIDOutsideTransaction {
+		t.Errorf("id should not be queried outside transactions, but got %v", err)
+	}
+}
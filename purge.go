@@ -0,0 +1,178 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// defaultPurgeInterval interval between batches of purging soft deleted records, change it with `db.Set("gorm:purge_interval", interval)`
+const defaultPurgeInterval = 100 * time.Millisecond
+
+// PurgeSoftDeleted hard delete records soft deleted before olderThan ago in batches of batchSize records, it sleeps between batches
+// to avoid locking the table and lagging replicas for long, RowsAffected is the number of purged records, e.g:
+//    db.PurgeSoftDeleted(&User{}, 30*24*time.Hour, 1000)
+//    // SELECT "users"."id" FROM "users" WHERE ("users"."deleted_at" IS NOT NULL AND "users"."deleted_at" < '...') ORDER BY "users"."id" LIMIT 1000;
+//    // DELETE FROM "users" WHERE "users"."id" IN (1,2,3...) AND ("users"."deleted_at" IS NOT NULL AND "users"."deleted_at" < '...');
+//
+// Other conditions of the db are applied when selecting keys of each batch, records are then deleted by the keys and the cutoff,
+// the interval between batches is 100ms by default, it stops when the context is done
+//    db.Set("gorm:purge_interval", time.Second).Where("tenant_id = ?", 1).PurgeSoftDeleted(&User{}, 30*24*time.Hour, 1000)
+func (s *DB) PurgeSoftDeleted(value interface{}, olderThan time.Duration, batchSize int) *DB {
+	return s.purgeSoftDeleted(value, olderThan, batchSize, "")
+}
+
+// ArchiveSoftDeleted works like PurgeSoftDeleted, but copies records of each batch to the archive table named with the suffix `_archive`
+// before deleting them in the same transaction, the archive table is created from columns of the model if it doesn't exist,
+// without indexes, unique constraints and foreign keys of the model, so records archived repeatedly won't conflict
+//    db.ArchiveSoftDeleted(&User{}, 30*24*time.Hour, 1000)
+//    // INSERT INTO "users_archive" ("id","name",...) SELECT "id","name",... FROM "users" WHERE "users"."id" IN (1,2,3...) AND ...;
+//    // DELETE FROM "users" WHERE "users"."id" IN (1,2,3...) AND ("users"."deleted_at" IS NOT NULL AND "users"."deleted_at" < '...');
+func (s *DB) ArchiveSoftDeleted(value interface{}, olderThan time.Duration, batchSize int) *DB {
+	return s.purgeSoftDeleted(value, olderThan, batchSize, s.NewScope(value).TableName()+"_archive")
+}
+
+func (s *DB) purgeSoftDeleted(value interface{}, olderThan time.Duration, batchSize int, archiveTable string) *DB {
+	var (
+		result = s.clone()
+		scope  = s.NewScope(value)
+	)
+
+	deletedAtField, ok := scope.FieldByName("DeletedAt")
+	if !ok {
+		result.AddError(fmt.Errorf("%v isn't soft deletable, it has no DeletedAt field", scope.TableName()))
+		return result
+	}
+	primaryFields := scope.PrimaryFields()
+	if len(primaryFields) == 0 {
+		result.AddError(fmt.Errorf("%v has no primary keys to delete records in batches", scope.TableName()))
+		return result
+	}
+	if batchSize <= 0 {
+		result.AddError(errors.New("batch size of purging soft deleted records should be positive"))
+		return result
+	}
+
+	if archiveTable != "" && !s.Dialect().HasTable(archiveTable) {
+		if err := s.New().Table(archiveTable).NewScope(value).createArchiveTable().db.Error; err != nil {
+			result.AddError(err)
+			return result
+		}
+	}
+
+	interval := defaultPurgeInterval
+	if v, ok := s.Get("gorm:purge_interval"); ok {
+		if d, ok := v.(time.Duration); ok {
+			interval = d
+		}
+	}
+
+	var keys []string
+	for _, field := range primaryFields {
+		keys = append(keys, fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName)))
+	}
+
+	var columns []string
+	for _, field := range scope.Fields() {
+		if field.IsNormal && !field.IsIgnored {
+			columns = append(columns, scope.Quote(field.DBName))
+		}
+	}
+
+	var (
+		quotedDeletedAt = scope.Quote(deletedAtField.DBName)
+		cutoff          = s.nowFunc().Add(-olderThan)
+		cutoffSQL       = fmt.Sprintf("%v.%v IS NOT NULL AND %v.%v < ?", scope.QuotedTableName(), quotedDeletedAt, scope.QuotedTableName(), quotedDeletedAt)
+		recordsType     = reflect.SliceOf(reflect.PtrTo(scope.GetModelStruct().ModelType))
+	)
+	for {
+		// select keys only from the master, deleting with `LIMIT` isn't supported by all dialects,
+		// and `Unscoped` is required to find soft deleted records
+		records := reflect.New(recordsType)
+		if err := s.Master().Unscoped().Select(strings.Join(keys, ",")).Where(cutoffSQL, cutoff).
+			Order(strings.Join(keys, ",")).Limit(batchSize).Find(records.Interface()).Error; err != nil {
+			result.AddError(err)
+			return result
+		}
+
+		count := records.Elem().Len()
+		if count == 0 {
+			return result
+		}
+
+		// records restored after selecting keys are kept, as the cutoff is checked again when archiving and deleting
+		err := s.Transaction(func(tx *DB) error {
+			if archiveTable != "" {
+				var (
+					archiveScope     = tx.NewScope(records.Interface())
+					primaryCondition = archiveScope.batchPrimaryCondition()
+					cutoffCondition  = strings.Replace(cutoffSQL, "?", archiveScope.AddToVars(cutoff), 1)
+				)
+				archiveScope.Raw(fmt.Sprintf("INSERT INTO %v (%v) SELECT %v FROM %v WHERE %v AND %v",
+					archiveScope.Quote(archiveTable), strings.Join(columns, ","), strings.Join(columns, ","), archiveScope.QuotedTableName(),
+					primaryCondition, cutoffCondition))
+				if err := archiveScope.Exec().db.Error; err != nil {
+					return err
+				}
+			}
+
+			deleteDB := tx.New().Unscoped().SkipHooks().Where(cutoffSQL, cutoff)
+			db := deleteDB.NewScope(records.Interface()).InstanceSet("gorm:batch_primary_keys", true).
+				callCallbacks(deleteDB.currentCallbacks().deletes).db
+			result.RowsAffected += db.RowsAffected
+			return db.Error
+		})
+		if err != nil {
+			result.AddError(err)
+			return result
+		}
+
+		if count < batchSize {
+			return result
+		}
+
+		select {
+		case <-s.Context().Done():
+			result.AddError(s.Context().Err())
+			return result
+		case <-time.After(interval):
+		}
+	}
+}
+
+// createArchiveTable create the table of the scope with columns and primary keys of the model,
+// indexes, unique constraints, foreign keys and join tables aren't created, as archived records may repeat natural keys
+func (scope *Scope) createArchiveTable() *Scope {
+	var (
+		tags        []string
+		primaryKeys []string
+		inColumn    bool
+	)
+	for _, field := range scope.GetModelStruct().StructFields {
+		if !field.IsNormal {
+			continue
+		}
+
+		field = field.clone()
+		for _, key := range []string{"UNIQUE", "UNIQUE_INDEX", "INDEX", "FULLTEXT"} {
+			field.TagSettingsDelete(key)
+		}
+		sqlTag := scope.dataTypeOf(field)
+		if strings.Contains(strings.ToLower(sqlTag), "primary key") {
+			inColumn = true
+		}
+		tags = append(tags, scope.Quote(field.DBName)+" "+sqlTag)
+
+		if field.IsPrimaryKey {
+			primaryKeys = append(primaryKeys, scope.Quote(field.DBName))
+		}
+	}
+
+	var primaryKeyStr string
+	if len(primaryKeys) > 0 && !inColumn {
+		primaryKeyStr = fmt.Sprintf(", PRIMARY KEY (%v)", strings.Join(primaryKeys, ","))
+	}
+	return scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.getTableOptions())).Exec()
+}
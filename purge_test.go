@@ -0,0 +1,125 @@
+package gorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type PurgedRecord struct {
+	gorm.Model
+	Name string
+}
+
+func TestPurgeSoftDeleted(t *testing.T) {
+	DB.DropTableIfExists(&PurgedRecord{}, "purged_records_archive")
+	DB.AutoMigrate(&PurgedRecord{})
+
+	var (
+		now = time.Now()
+		old = now.Add(-48 * time.Hour)
+	)
+	for _, name := range []string{"old1", "old2", "old3", "recent1", "live1", "live2"} {
+		record := PurgedRecord{Name: name}
+		DB.Save(&record)
+		switch name[:3] {
+		case "old":
+			DB.Unscoped().Model(&record).UpdateColumn("deleted_at", old)
+		case "rec":
+			DB.Unscoped().Model(&record).UpdateColumn("deleted_at", now)
+		}
+	}
+
+	db := DB.Set("gorm:purge_interval", time.Millisecond).ArchiveSoftDeleted(&PurgedRecord{}, 24*time.Hour, 2)
+	if db.Error != nil || db.RowsAffected != 3 {
+		t.Fatalf("3 records should be archived, but got %v, %v", db.RowsAffected, db.Error)
+	}
+
+	var names []string
+	DB.Unscoped().Model(&PurgedRecord{}).Order("id").Pluck("name", &names)
+	if len(names) != 3 || names[0] != "recent1" {
+		t.Errorf("recently deleted and live records should be kept, but got %v", names)
+	}
+
+	var archived []string
+	DB.Table("purged_records_archive").Order("id").Pluck("name", &archived)
+	if len(archived) != 3 || archived[0] != "old1" || archived[2] != "old3" {
+		t.Errorf("purged records should be archived, but got %v", archived)
+	}
+
+	DB.Delete(&PurgedRecord{}, "name = ?", "live1")
+	if db := DB.PurgeSoftDeleted(&PurgedRecord{}, 0, 10); db.Error != nil || db.RowsAffected != 2 {
+		t.Errorf("all soft deleted records should be purged, but got %v, %v", db.RowsAffected, db.Error)
+	}
+
+	names = nil
+	DB.Unscoped().Model(&PurgedRecord{}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "live2" {
+		t.Errorf("live records should be kept, but got %v", names)
+	}
+
+	if err := DB.PurgeSoftDeleted(&Company{}, 0, 10).Error; err == nil {
+		t.Errorf("models without DeletedAt can't be purged")
+	}
+}
+
+func TestPurgeSoftDeletedKeepsRestoredRecords(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&PurgedRecord{}, "purged_records_archive")
+	db.AutoMigrate(&PurgedRecord{})
+
+	record := PurgedRecord{Name: "restored"}
+	db.Save(&record)
+	db.Unscoped().Model(&record).UpdateColumn("deleted_at", time.Now().Add(-48*time.Hour))
+
+	// restore the record after its key is selected for purging
+	db.Callback().Query().After("gorm:query").Register("test:restore", func(scope *gorm.Scope) {
+		if _, ok := scope.Value.(*[]*PurgedRecord); ok {
+			scope.NewDB().Unscoped().Model(&record).UpdateColumn("deleted_at", nil)
+		}
+	})
+
+	if result := db.ArchiveSoftDeleted(&PurgedRecord{}, 24*time.Hour, 10); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("restored records should not be purged, but got %v, %v", result.RowsAffected, result.Error)
+	}
+
+	var count int
+	if db.Model(&PurgedRecord{}).Count(&count); count != 1 {
+		t.Errorf("restored record should be kept, but got %v", count)
+	}
+	if db.Table("purged_records_archive").Count(&count); count != 0 {
+		t.Errorf("restored record should not be archived, but got %v", count)
+	}
+}
+
+type PurgedItem struct {
+	gorm.Model
+	Name string `gorm:"index:idx_purged_item_name"`
+	Code string `gorm:"unique_index:uix_purged_item_code"`
+}
+
+func TestArchiveSoftDeletedWithIndexes(t *testing.T) {
+	DB.DropTableIfExists(&PurgedItem{}, "purged_items_archive")
+	DB.AutoMigrate(&PurgedItem{})
+
+	for i := 0; i < 2; i++ {
+		item := PurgedItem{Name: "item", Code: "code"}
+		DB.Save(&item)
+		DB.Unscoped().Model(&item).UpdateColumn("deleted_at", time.Now().Add(-48*time.Hour))
+
+		if db := DB.ArchiveSoftDeleted(&PurgedItem{}, 24*time.Hour, 10); db.Error != nil || db.RowsAffected != 1 {
+			t.Fatalf("record with the same code should be archived again, but got %v, %v", db.RowsAffected, db.Error)
+		}
+	}
+
+	var count int
+	if DB.Table("purged_items_archive").Count(&count); count != 2 {
+		t.Errorf("records should be archived, but got %v", count)
+	}
+}
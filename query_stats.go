@@ -0,0 +1,228 @@
+package gorm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// queryStatsSamples durations kept for each fingerprint to calculate the 95th percentile
+	queryStatsSamples = 1000
+	// queryStatsMaxFingerprints fingerprints beyond it are not collected, to bound memory of queries built with dynamic SQL
+	queryStatsMaxFingerprints = 10000
+)
+
+// FingerprintStats statistics of statements with the same fingerprint
+type FingerprintStats struct {
+	Fingerprint   string
+	Calls         int64
+	Errors        int64
+	Rows          int64 // rows affected by Exec, rows of queries are not counted
+	TotalDuration time.Duration
+	AvgDuration   time.Duration
+	P95Duration   time.Duration // 95th percentile of the latest 1000 statements
+	MaxDuration   time.Duration
+}
+
+// EnableQueryStats collect statistics of statements by their fingerprints, get them with QueryStats,
+// DBs cloned before won't collect statistics, so enable it right after opening the db
+func (s *DB) EnableQueryStats() *DB {
+	s.parent.db.queryStats = &queryStats{fingerprints: map[string]*fingerprintStats{}}
+	s.db.queryStats = s.parent.db.queryStats
+	return s
+}
+
+// QueryStats return statistics of statements by fingerprints, the heaviest fingerprints with the most total duration come first, e.g:
+//    for _, stats := range db.QueryStats()[:10] {
+//      log.Printf("%v calls, avg %v, p95 %v: %v", stats.Calls, stats.AvgDuration, stats.P95Duration, stats.Fingerprint)
+//    }
+func (s *DB) QueryStats() []FingerprintStats {
+	stats := s.parent.db.queryStats
+	if stats == nil {
+		return nil
+	}
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	results := make([]FingerprintStats, 0, len(stats.fingerprints))
+	for _, fingerprint := range stats.fingerprints {
+		results = append(results, fingerprint.snapshot())
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].TotalDuration != results[j].TotalDuration {
+			return results[i].TotalDuration > results[j].TotalDuration
+		}
+		return results[i].Fingerprint < results[j].Fingerprint
+	})
+	return results
+}
+
+// ResetQueryStats clear statistics collected
+func (s *DB) ResetQueryStats() *DB {
+	if stats := s.parent.db.queryStats; stats != nil {
+		stats.mutex.Lock()
+		stats.fingerprints = map[string]*fingerprintStats{}
+		stats.mutex.Unlock()
+	}
+	return s
+}
+
+// queryStats statistics of statements by fingerprints, nil stats collect nothing
+type queryStats struct {
+	mutex        sync.Mutex
+	fingerprints map[string]*fingerprintStats
+}
+
+type fingerprintStats struct {
+	FingerprintStats
+	samples []time.Duration
+	next    int
+}
+
+// observe collect statistics of the statement
+func (stats *queryStats) observe(sql string, duration time.Duration, rows *int64, err error) {
+	if stats == nil {
+		return
+	}
+
+	fingerprint := Fingerprint(sql)
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	current, ok := stats.fingerprints[fingerprint]
+	if !ok {
+		if len(stats.fingerprints) >= queryStatsMaxFingerprints {
+			return
+		}
+		current = &fingerprintStats{FingerprintStats: FingerprintStats{Fingerprint: fingerprint}}
+		stats.fingerprints[fingerprint] = current
+	}
+
+	current.Calls++
+	if err != nil && err != ErrRecordNotFound {
+		current.Errors++
+	}
+	if rows != nil {
+		current.Rows += *rows
+	}
+	current.TotalDuration += duration
+	if duration > current.MaxDuration {
+		current.MaxDuration = duration
+	}
+
+	if len(current.samples) < queryStatsSamples {
+		current.samples = append(current.samples, duration)
+	} else {
+		current.samples[current.next] = duration
+		current.next = (current.next + 1) % queryStatsSamples
+	}
+}
+
+func (stats *fingerprintStats) snapshot() FingerprintStats {
+	result := stats.FingerprintStats
+	if result.Calls > 0 {
+		result.AvgDuration = result.TotalDuration / time.Duration(result.Calls)
+	}
+
+	if len(stats.samples) > 0 {
+		samples := append([]time.Duration{}, stats.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		result.P95Duration = samples[(len(samples)*95+99)/100-1]
+	}
+	return result
+}
+
+var (
+	fingerprintListRegexp = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)+\s*\)`)
+	fingerprintRowsRegexp = regexp.MustCompile(`\(\?\+\)(\s*,\s*\(\?\+\))+`)
+)
+
+// Fingerprint normalize SQL into its shape, literals and placeholders are replaced with `?`, lists of values are collapsed into `(?+)`,
+// comments are stripped, and keywords are lowercased, e.g:
+//    SELECT * FROM users WHERE name = 'jinzhu' AND id IN (1, 2, 3) LIMIT 10
+//    select * from users where name = ? and id in (?+) limit ?
+func Fingerprint(sql string) string {
+	var (
+		builder strings.Builder
+		runes   = []rune(sql)
+		space   bool
+	)
+
+	write := func(r rune) {
+		if space && builder.Len() > 0 {
+			builder.WriteRune(' ')
+		}
+		space = false
+		builder.WriteRune(r)
+	}
+
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case r == '/' && idx+1 < len(runes) && runes[idx+1] == '*':
+			for idx += 2; idx < len(runes) && !(runes[idx] == '*' && idx+1 < len(runes) && runes[idx+1] == '/'); idx++ {
+			}
+			idx++
+			space = true
+		case r == '-' && idx+1 < len(runes) && runes[idx+1] == '-':
+			for idx < len(runes) && runes[idx] != '\n' {
+				idx++
+			}
+			space = true
+		case r == '\'':
+			// string literals, quotes are escaped by doubling them or with backslashes
+			for idx++; idx < len(runes); idx++ {
+				if runes[idx] == '\\' {
+					idx++
+				} else if runes[idx] == '\'' {
+					if idx+1 < len(runes) && runes[idx+1] == '\'' {
+						idx++
+					} else {
+						break
+					}
+				}
+			}
+			write('?')
+		case r == '"' || r == '`' || r == '[':
+			// quoted identifiers are kept as they are
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			write(r)
+			for idx++; idx < len(runes); idx++ {
+				builder.WriteRune(runes[idx])
+				if runes[idx] == closing {
+					break
+				}
+			}
+		case (unicode.IsDigit(r) || r == '$' && idx+1 < len(runes) && unicode.IsDigit(runes[idx+1])) &&
+			(idx == 0 || !isIdentifierRune(runes[idx-1]) && runes[idx-1] != '.'):
+			// numbers and placeholders like $1 of postgres
+			for idx++; idx < len(runes) && (isIdentifierRune(runes[idx]) || runes[idx] == '.'); idx++ {
+			}
+			idx--
+			write('?')
+		case r == '-' && idx+1 < len(runes) && unicode.IsDigit(runes[idx+1]) && builder.Len() > 0 && strings.ContainsRune("=<>(,", lastRune(builder.String())):
+			// negative numbers, the sign is dropped with the number
+		default:
+			write(unicode.ToLower(r))
+		}
+	}
+
+	fingerprint := fingerprintListRegexp.ReplaceAllString(builder.String(), "(?+)")
+	return fingerprintRowsRegexp.ReplaceAllString(fingerprint, "(?+)")
+}
+
+func lastRune(str string) rune {
+	runes := []rune(str)
+	return runes[len(runes)-1]
+}
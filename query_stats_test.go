@@ -0,0 +1,70 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestFingerprint(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM users WHERE name = 'jinzhu' AND id IN (1, 2, 3) LIMIT 10": "select * from users where name = ? and id in (?+) limit ?",
+		"select *  from users\n where name = 'it''s' and age > -1":               "select * from users where name = ? and age > ?",
+		`SELECT "users"."name2" FROM "users" WHERE id = $1 /* comment */`:        `select "users"."name2" from "users" where id = ?`,
+		"INSERT INTO users (name,age) VALUES (?,?),(?,?) -- batch":               "insert into users (name,age) values (?+)",
+		"UPDATE t1 SET a = a-1 WHERE b = 1.5e3":                                  "update t1 set a = a-? where b = ?",
+	}
+
+	for sql, fingerprint := range cases {
+		if result := gorm.Fingerprint(sql); result != fingerprint {
+			t.Errorf("fingerprint of %q should be %q, but got %q", sql, fingerprint, result)
+		}
+	}
+}
+
+func TestQueryStats(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	if stats := db.QueryStats(); stats != nil {
+		t.Errorf("statistics should not be collected before enabling them, but got %v", stats)
+	}
+
+	db.EnableQueryStats()
+	for _, name := range []string{"query-stats-1", "query-stats-2"} {
+		db.Where("name = ?", name).Find(&[]User{})
+	}
+	db.Save(&User{Name: "query-stats"})
+	db.Exec("UPDATE users SET age = 1 WHERE name = 'query-stats'")
+	db.Exec("SELECT * FROM missing_table")
+
+	var find, update, missing *gorm.FingerprintStats
+	for _, stats := range db.QueryStats() {
+		stats := stats
+		switch stats.Fingerprint {
+		case `select * from "users" where (name = ?)`:
+			find = &stats
+		case "update users set age = ? where name = ?":
+			update = &stats
+		case "select * from missing_table":
+			missing = &stats
+		}
+	}
+
+	if find == nil || find.Calls != 2 || find.AvgDuration <= 0 || find.P95Duration > find.MaxDuration {
+		t.Errorf("queries of the same shape should be collected together, but got %+v in %+v", find, db.QueryStats())
+	}
+	if update == nil || update.Calls != 1 || update.Rows != 1 {
+		t.Errorf("rows affected should be collected, but got %+v", update)
+	}
+	if missing == nil || missing.Errors != 1 {
+		t.Errorf("errors should be collected, but got %+v", missing)
+	}
+
+	if db.ResetQueryStats(); len(db.QueryStats()) != 0 {
+		t.Errorf("statistics should be reset, but got %v", db.QueryStats())
+	}
+}
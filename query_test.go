@@ -5,6 +5,7 @@ import (
 	"reflect"
 
 	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
 
 	"testing"
 	"time"
@@ -298,6 +299,34 @@ func TestSearchWithStruct(t *testing.T) {
 	}
 }
 
+type ZeroConditionTask struct {
+	ID       uint
+	Name     string
+	Priority int
+	Status   int `gorm:"include_zero"`
+}
+
+func TestWhereStructIncludeZeroFields(t *testing.T) {
+	DB.DropTableIfExists(&ZeroConditionTask{})
+	DB.AutoMigrate(&ZeroConditionTask{})
+	DB.Create(&ZeroConditionTask{Name: "zero_condition", Priority: 0, Status: 0})
+	DB.Create(&ZeroConditionTask{Name: "zero_condition", Priority: 1, Status: 0})
+	DB.Create(&ZeroConditionTask{Name: "zero_condition", Priority: 0, Status: 1})
+
+	var tasks []ZeroConditionTask
+	if DB.Where(&ZeroConditionTask{Name: "zero_condition"}).Find(&tasks); len(tasks) != 2 {
+		t.Errorf("Fields tagged with include_zero should be included in struct conditions, but found %v tasks", len(tasks))
+	}
+
+	if DB.WhereStruct(&ZeroConditionTask{Name: "zero_condition"}, gorm.IncludeZeroFields("Priority")).Find(&tasks); len(tasks) != 1 || tasks[0].Priority != 0 || tasks[0].Status != 0 {
+		t.Errorf("Should include zero fields in struct conditions, but got %+v", tasks)
+	}
+
+	if DB.WhereStruct(ZeroConditionTask{Status: 1}, gorm.IncludeZeroFields("priority")).Find(&tasks); len(tasks) != 1 || tasks[0].Status != 1 {
+		t.Errorf("Should include zero fields by column names, but got %+v", tasks)
+	}
+}
+
 func TestSearchWithMap(t *testing.T) {
 	companyID := 1
 	user1 := User{Name: "MapSearchUser1", Age: 1, Birthday: parseTime("2000-1-1")}
@@ -538,6 +567,32 @@ func TestOr(t *testing.T) {
 	}
 }
 
+func TestConditionGroups(t *testing.T) {
+	DB.Save(&User{Name: "GroupUser1", Age: 1}).Save(&User{Name: "GroupUser2", Age: 10}).Save(&User{Name: "GroupUser3", Age: 20})
+
+	var users []User
+	DB.Where(DB.Where("name = ?", "GroupUser1").Where("age = ?", 1)).
+		Or(DB.Where(map[string]interface{}{"name": "GroupUser3"}).Where("age = ?", 20)).Order("age").Find(&users)
+	if len(users) != 2 || users[0].Name != "GroupUser1" || users[1].Name != "GroupUser3" {
+		t.Errorf("should find users with grouped conditions, but got %v", len(users))
+	}
+
+	users = nil
+	DB.Where("name LIKE ?", "GroupUser%").Not(DB.Where("age = ?", 1).Or("age = ?", 20)).Find(&users)
+	if len(users) != 1 || users[0].Name != "GroupUser2" {
+		t.Errorf("should exclude users with grouped conditions, but got %v", len(users))
+	}
+
+	db, recorder, err := gormtest.Open("postgres")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	db.Where("age > ?", 1).Where(db.Where("name = ?", "a").Or("name = ?", "b")).Find(&users)
+	if sql := recorder.LastStatement().SQL; sql != `SELECT * FROM "users" WHERE (age > $1) AND ((name = $2) OR (name = $3))` {
+		t.Errorf("grouped conditions should be parenthesized with bind vars in order, but got %v", sql)
+	}
+}
+
 func TestCount(t *testing.T) {
 	user1 := User{Name: "CountUser1", Age: 1}
 	user2 := User{Name: "CountUser2", Age: 10}
@@ -570,6 +625,32 @@ func TestCount(t *testing.T) {
 	}
 }
 
+func TestAggregates(t *testing.T) {
+	DB.Save(&User{Name: "AggregateUser", Age: 10}).Save(&User{Name: "AggregateUser", Age: 20}).Save(&User{Name: "AggregateUser", Age: 45})
+	scoped := DB.Model(&User{}).Where("name = ?", "AggregateUser").Order("age")
+
+	var (
+		sum, min, max int64
+		avg           float64
+	)
+	scoped.Sum("age", &sum).Min("age", &min).Max("age", &max).Avg("age", &avg)
+	if sum != 75 || min != 10 || max != 45 || avg != 25 {
+		t.Errorf("aggregates are wrong, got sum %v, min %v, max %v, avg %v", sum, min, max, avg)
+	}
+
+	var (
+		emptyAvg *float64
+		emptyMax = int64(1)
+	)
+	empty := DB.Model(&User{}).Where("name = ?", "NoAggregateUser")
+	if err := empty.Sum("age", &sum).Error; err != nil || sum != 0 {
+		t.Errorf("sum of no records should be 0, but got %v, %v", sum, err)
+	}
+	if err := empty.Max("age", &emptyMax).Avg("age", &emptyAvg).Error; err != nil || emptyMax != 0 || emptyAvg != nil {
+		t.Errorf("aggregates of no records should be zero or nil, but got %v, %v, %v", emptyMax, emptyAvg, err)
+	}
+}
+
 func TestNot(t *testing.T) {
 	DB.Create(getPreparedUser("user1", "not"))
 	DB.Create(getPreparedUser("user2", "not"))
@@ -762,6 +843,37 @@ func TestFindOrCreate(t *testing.T) {
 	}
 }
 
+func TestReturnErrRecordNotFound(t *testing.T) {
+	db := DB.ReturnErrRecordNotFound(false)
+
+	var user User
+	if result := db.First(&user, "name = ?", "return err record not found"); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("First should not return ErrRecordNotFound when it is disabled, but got %v", result.Error)
+	}
+
+	if err := db.FirstOrError(&user, "name = ?", "return err record not found"); err != gorm.ErrRecordNotFound {
+		t.Errorf("FirstOrError should return ErrRecordNotFound, but got %v", err)
+	}
+
+	if err := db.LastOrError(&user, "name = ?", "return err record not found"); err != gorm.ErrRecordNotFound {
+		t.Errorf("LastOrError should return ErrRecordNotFound, but got %v", err)
+	}
+
+	db.Where(&User{Name: "return err record not found", Age: 21}).FirstOrCreate(&user)
+	if user.Id == 0 || user.Age != 21 {
+		t.Errorf("FirstOrCreate should create the user when ErrRecordNotFound is disabled")
+	}
+
+	var found User
+	if err := db.TakeOrError(&found, "name = ?", "return err record not found"); err != nil || found.Id != user.Id {
+		t.Errorf("TakeOrError should find the user, but got %v", err)
+	}
+
+	if err := DB.First(&User{}, "name = ?", "return err record not found 2").Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("First should return ErrRecordNotFound by default, but got %v", err)
+	}
+}
+
 func TestSelectWithEscapedFieldName(t *testing.T) {
 	user1 := User{Name: "EscapedFieldNameUser", Age: 1}
 	user2 := User{Name: "EscapedFieldNameUser", Age: 10}
@@ -839,3 +951,33 @@ func TestPluckWithSelect(t *testing.T) {
 		t.Errorf("Should correctly pluck with select, got: %s", userAges)
 	}
 }
+
+func TestPluckMap(t *testing.T) {
+	DB.Save(&User{Name: "pluck_map_1", Age: 31})
+	DB.Save(&User{Name: "pluck_map_2", Age: 32})
+
+	var results []map[string]interface{}
+	if err := DB.Model(&User{}).Where("name LIKE ?", "pluck_map%").Order("age").PluckMap([]string{"name", "age AS user_age"}, &results).Error; err != nil {
+		t.Fatalf("Failed to pluck map, got %v", err)
+	}
+
+	if len(results) != 2 || results[0]["name"] != "pluck_map_1" || fmt.Sprint(results[1]["user_age"]) != "32" || len(results[0]) != 2 {
+		t.Errorf("Should pluck columns into maps, but got %v", results)
+	}
+}
+
+func TestExists(t *testing.T) {
+	DB.Save(&User{Name: "exists_user", Age: 40})
+
+	if exists, err := DB.Model(&User{}).Where("name = ?", "exists_user").Order("age desc").Exists(); err != nil || !exists {
+		t.Errorf("Should find existing user, but got %v, %v", exists, err)
+	}
+
+	if exists, err := DB.Model(&User{}).Where("name = ?", "not_exists_user").Exists(); err != nil || exists {
+		t.Errorf("Should not find user not existing, but got %v, %v", exists, err)
+	}
+
+	if _, err := DB.Table("not_exists_table").Exists(); err == nil {
+		t.Errorf("Should return error of the query")
+	}
+}
@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// readStatements statements allowed by read-only dbs
+var readStatements = map[string]bool{"SELECT": true, "SHOW": true, "EXPLAIN": true, "DESCRIBE": true, "DESC": true, "PRAGMA": true, "VALUES": true}
+
+// ReadOnly return a db which can only read, creating, updating, deleting and executing statements return ErrReadOnlyDB,
+// statements are sent to slave if it exists, e.g. hand it to reporting or plugin code as a safety boundary
+//    report.Generate(db.ReadOnly())
+//
+// Only statements starting with SELECT, SHOW, EXPLAIN and so on are allowed, queries with common table expressions (WITH) are rejected
+// as they may write in postgres, so are locking reads like `SELECT ... FOR UPDATE` and `SELECT ... INTO`.
+// Read-only dbs in transactions still run statements in the transactions, transactions begun by read-only dbs are read-only
+func (s *DB) ReadOnly() *DB {
+	clone := s.clone()
+	clone.db.readOnly = true
+	if _, ok := clone.db.dbSQL.(sqlTx); !ok && s.parent.db.dbSQLSlave != nil {
+		clone.db.dbSQL, clone.db.dbSQLSlave = s.parent.db.dbSQLSlave, s.parent.db.dbSQLSlave
+		clone.db.masterBreaker = clone.db.slaveBreaker
+		clone.refreshDialect()
+	}
+	return clone
+}
+
+// readOnlyStatement return ErrReadOnlyDB if the db is read-only but the statement may write,
+// it should be checked before rewriting statements, as rewriters may prefix statements with comments or hints
+func (db ctxDB) readOnlyStatement(query string, read bool) error {
+	if !db.readOnly {
+		return nil
+	}
+
+	if words := statementWords(query); read && len(words) > 0 && readStatements[words[0]] && !lockingRead(words) && !hasMultiStatements(query) {
+		return nil
+	}
+	return ErrReadOnlyDB
+}
+
+// lockingRead return true if the statement locks rows or writes results, e.g. `SELECT ... FOR UPDATE`, `SELECT ... INTO OUTFILE`
+func lockingRead(words []string) bool {
+	for idx, word := range words {
+		switch word {
+		case "INTO":
+			return true
+		case "FOR", "LOCK":
+			if idx+1 < len(words) && (words[idx+1] == "UPDATE" || words[idx+1] == "SHARE" || words[idx+1] == "NO" || words[idx+1] == "KEY" || words[idx+1] == "IN") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// statementWords return upper-cased words of the statement, comments and quoted strings or identifiers are skipped
+func statementWords(query string) (words []string) {
+	var (
+		runes = []rune(query)
+		word  []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToUpper(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case r == '-' && idx+1 < len(runes) && runes[idx+1] == '-', r == '#':
+			flush()
+			for idx < len(runes) && runes[idx] != '\n' {
+				idx++
+			}
+		case r == '/' && idx+1 < len(runes) && runes[idx+1] == '*':
+			flush()
+			for idx += 2; idx < len(runes) && !(runes[idx] == '*' && idx+1 < len(runes) && runes[idx+1] == '/'); idx++ {
+			}
+			idx++
+		case r == '\'' || r == '"' || r == '`':
+			flush()
+			for idx++; idx < len(runes) && runes[idx] != r; idx++ {
+				if runes[idx] == '\\' {
+					idx++
+				}
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,90 @@
+package gorm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestReadOnly(t *testing.T) {
+	user := User{Name: "read-only"}
+	DB.Save(&user)
+
+	db := DB.ReadOnly()
+	if err := db.Create(&User{Name: "read-only-create"}).Error; err != gorm.ErrReadOnlyDB {
+		t.Errorf("creating should be rejected, but got %v", err)
+	}
+	if err := db.Model(&user).Update("age", 18).Error; err != gorm.ErrReadOnlyDB {
+		t.Errorf("updating should be rejected, but got %v", err)
+	}
+	if err := db.Delete(&user).Error; err != gorm.ErrReadOnlyDB {
+		t.Errorf("deleting should be rejected, but got %v", err)
+	}
+	if err := db.Exec("DELETE FROM users WHERE name = ?", "read-only").Error; err != gorm.ErrReadOnlyDB {
+		t.Errorf("executing should be rejected, but got %v", err)
+	}
+	if _, err := db.Raw("DELETE FROM users WHERE name = ?", "read-only").Rows(); err != gorm.ErrReadOnlyDB {
+		t.Errorf("raw statements writing should be rejected, but got %v", err)
+	}
+	if _, err := db.Raw("SELECT * FROM users; DELETE FROM users").Rows(); err != gorm.ErrReadOnlyDB {
+		t.Errorf("multiple statements should be rejected, but got %v", err)
+	}
+
+	if _, err := db.Raw("SELECT * FROM users WHERE name = ? FOR UPDATE", "read-only").Rows(); err != gorm.ErrReadOnlyDB {
+		t.Errorf("locking reads should be rejected, but got %v", err)
+	}
+	if _, err := db.Raw("SELECT * INTO OUTFILE '/tmp/users' FROM users").Rows(); err != gorm.ErrReadOnlyDB {
+		t.Errorf("reads writing results should be rejected, but got %v", err)
+	}
+
+	var users []User
+	if err := db.Where("name = ?", "read-only").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("reading should be allowed, but got %v, %v", users, err)
+	}
+
+	if err := db.Raw("/* report */ SELECT * FROM users WHERE name = ? AND 'for update' <> ''", "read-only").Scan(&users).Error; err != nil {
+		t.Errorf("reading with comments and quoted keywords should be allowed, but got %v", err)
+	}
+
+	rewritten, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer rewritten.Close()
+
+	rewritten.RegisterQueryRewriter(func(sql string, vars []interface{}) (string, []interface{}) {
+		return "/* rewritten */ " + sql, vars
+	})
+	if err := rewritten.ReadOnly().Where("name = ?", "read-only").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("reading rewritten statements should be allowed, but got %v, %v", users, err)
+	}
+}
+
+func TestReadOnlyRoutesToSlave(t *testing.T) {
+	if dialect := os.Getenv("GORM_DIALECT"); dialect != "" && dialect != "sqlite" {
+		t.Skip("master and slave are tested with sqlite files")
+	}
+
+	dir, err := ioutil.TempDir("", "gorm")
+	if err != nil {
+		t.Fatalf("failed to create dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := gorm.OpenMasterAndSlave("sqlite3", filepath.Join(dir, "master.db"), filepath.Join(dir, "slave.db"))
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	db.DBSlave().Exec("CREATE TABLE replicas (name varchar(255))")
+	db.DBSlave().Exec("INSERT INTO replicas (name) VALUES ('slave')")
+
+	var count int
+	if err := db.ReadOnly().Master().Table("replicas").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("read-only db should always query slave, but got %v, %v", count, err)
+	}
+}
@@ -0,0 +1,64 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetryConfig retry settings of connecting database
+type RetryConfig struct {
+	// Attempts max attempts to connect, connect only once if it is 0
+	Attempts int
+	// Backoff wait time before the first retry, it is doubled after each retry, defaults to 1 second
+	Backoff time.Duration
+	// MaxBackoff max wait time between retries, no limit if it is 0
+	MaxBackoff time.Duration
+}
+
+// OpenWithRetry open db and retry pinging it with exponential backoff, so applications could start before database is ready, e.g:
+//    db, err := gorm.OpenWithRetry("mysql", dsn, gorm.RetryConfig{Attempts: 10, Backoff: time.Second, MaxBackoff: 30 * time.Second})
+func OpenWithRetry(driver, source string, retry RetryConfig, options ...Options) (*DB, error) {
+	var option Options
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	dbSQL, err := openAndPing(driver, source, option.PoolOptions, retry)
+	if err != nil {
+		return nil, err
+	}
+	db, err := Open(driver, dbSQL, option)
+	if err != nil {
+		dbSQL.Close()
+		return nil, err
+	}
+	db.driverLocation = driverLocation(driver, source)
+	return db, nil
+}
+
+// OpenMasterAndSlaveWithRetry open master and slave, retry pinging them with exponential backoff
+func OpenMasterAndSlaveWithRetry(driver, master, slave string, retry RetryConfig, options ...Options) (*DB, error) {
+	return openMasterAndSlave(driver, master, slave, retry, options...)
+}
+
+// do call f until it succeeds or attempts run out, sleep with exponential backoff between attempts
+func (retry RetryConfig) do(f func() error) (err error) {
+	backoff := retry.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = f(); err == nil || attempt >= retry.Attempts {
+			return
+		}
+
+		logrus.WithError(err).Warnf("failed to connect database, retry in %v (attempt %d/%d)", backoff, attempt, retry.Attempts)
+		time.Sleep(backoff)
+
+		if backoff *= 2; retry.MaxBackoff > 0 && backoff > retry.MaxBackoff {
+			backoff = retry.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package gorm
+
+// QueryRewriter rewrite statements before sending them to database
+type QueryRewriter func(sql string, vars []interface{}) (string, []interface{})
+
+// RegisterQueryRewriter register rewriter applied to all statements after generating them, including raw SQL of Exec and Raw,
+// e.g. to add comments or rename tables for proxies, rewriters registered first are applied first, traces record rewritten statements
+//    db.RegisterQueryRewriter(func(sql string, vars []interface{}) (string, []interface{}) {
+//      return "/* shard=42 */ " + sql, vars
+//    })
+//
+// Register them before using the db like registering callbacks, DBs cloned before won't use it
+func (s *DB) RegisterQueryRewriter(rewriter QueryRewriter) *DB {
+	s.parent.db.rewriters = append(s.parent.db.rewriters, rewriter)
+	s.db.rewriters = s.parent.db.rewriters
+	return s
+}
+
+// rewrite apply rewriters to the statement in order
+func (db ctxDB) rewrite(query string, args []interface{}) (string, []interface{}) {
+	for _, rewriter := range db.rewriters {
+		query, args = rewriter(query, args)
+	}
+	return query, args
+}
@@ -0,0 +1,40 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm/gormtest"
+)
+
+func TestRegisterQueryRewriter(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.RegisterQueryRewriter(func(sql string, vars []interface{}) (string, []interface{}) {
+		return strings.Replace(sql, "`users`", "`users_v2`", -1), vars
+	}).RegisterQueryRewriter(func(sql string, vars []interface{}) (string, []interface{}) {
+		return "/* shard=42 */ " + sql, append(vars, 42)
+	})
+
+	db.Where("name = ?", "rewriter").Find(&[]User{})
+	db.Exec("UPDATE `users` SET age = ?", 18)
+	db.Raw("SELECT * FROM `users`").Rows()
+
+	expected := []gormtest.Statement{
+		{SQL: "/* shard=42 */ SELECT * FROM `users_v2` WHERE (name = ?)", Vars: []interface{}{"rewriter", int64(42)}},
+		{SQL: "/* shard=42 */ UPDATE `users_v2` SET age = ?", Vars: []interface{}{int64(18), int64(42)}},
+		{SQL: "/* shard=42 */ SELECT * FROM `users_v2`", Vars: []interface{}{int64(42)}},
+	}
+	statements := recorder.Statements()
+	if len(statements) != len(expected) {
+		t.Fatalf("statements should be rewritten, but got %v", statements)
+	}
+	for i, statement := range statements {
+		if statement.SQL != expected[i].SQL || len(statement.Vars) != len(expected[i].Vars) || statement.Vars[len(statement.Vars)-1] != int64(42) {
+			t.Errorf("statement should be rewritten as %v, but got %v", expected[i], statement)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gorm
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// savepoint savepoint started by beginning a transaction in a transaction
+type savepoint struct {
+	name string
+	done bool
+}
+
+var savepointSequence int64
+
+// SavePoint create a savepoint of the transaction, roll back to it with RollbackTo
+//    tx := db.Begin()
+//    tx.Create(&user)
+//    tx.SavePoint("before_orders")
+//    tx.Create(&order)
+//    tx.RollbackTo("before_orders")
+//    tx.Commit() // user is created without the order
+func (s *DB) SavePoint(name string) *DB {
+	sql := "SAVEPOINT %v"
+	if s.Dialect().GetName() == "mssql" {
+		sql = "SAVE TRANSACTION %v"
+	}
+	return s.savePointExec(sql, name)
+}
+
+// RollbackTo roll back the transaction to the savepoint
+func (s *DB) RollbackTo(name string) *DB {
+	sql := "ROLLBACK TO SAVEPOINT %v"
+	if s.Dialect().GetName() == "mssql" {
+		sql = "ROLLBACK TRANSACTION %v"
+	}
+	return s.savePointExec(sql, name)
+}
+
+// releaseSavePoint release the savepoint, changes after it are kept in the transaction
+func (s *DB) releaseSavePoint(name string) *DB {
+	if s.Dialect().GetName() == "mssql" {
+		// savepoints of mssql can't be released, they are released with the transaction
+		return s
+	}
+	return s.savePointExec("RELEASE SAVEPOINT %v", name)
+}
+
+func (s *DB) savePointExec(sql, name string) *DB {
+	if _, ok := s.db.dbSQL.(sqlTx); !ok {
+		s.AddError(ErrInvalidTransaction)
+		return s
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf(sql, s.Dialect().Quote(name)))
+	s.AddError(err)
+	return s
+}
+
+// beginSavePoint begin a nested transaction of the transaction with a savepoint, it is committed by releasing the savepoint,
+// and rolled back by rolling back to the savepoint
+func (s *DB) beginSavePoint() *DB {
+	name := fmt.Sprintf("gorm_savepoint_%d", atomic.AddInt64(&savepointSequence, 1))
+	if s.SavePoint(name).Error == nil {
+		s.db.savepoint = &savepoint{name: name}
+	}
+	return s
+}
+
+// endSavePoint release or roll back the savepoint of the nested transaction once
+func (s *DB) endSavePoint(commit bool) *DB {
+	if s.db.savepoint.done {
+		if commit {
+			s.AddError(ErrInvalidTransaction)
+		}
+		return s
+	}
+
+	s.db.savepoint.done = true
+	if commit {
+		return s.releaseSavePoint(s.db.savepoint.name)
+	}
+	return s.RollbackTo(s.db.savepoint.name)
+}
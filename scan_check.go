@@ -0,0 +1,92 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ScanCheck how mismatches between selected columns and fields of the destination are reported, refer CheckScan
+type ScanCheck int
+
+const (
+	// ScanCheckWarn log mismatches with the logger of the db, even if log mode is disabled
+	ScanCheckWarn ScanCheck = iota + 1
+	// ScanCheckError fail the statement with *ScanMismatchError after scanning
+	ScanCheckError
+)
+
+// ScanMismatchError occurs when selected columns don't match fields of the destination with CheckScan
+type ScanMismatchError struct {
+	// Destination type of the destination's struct
+	Destination reflect.Type
+	// UnmappedColumns selected columns without fields, their values are dropped
+	UnmappedColumns []string
+	// UnfilledFields fields of the destination not selected, they keep zero values
+	UnfilledFields []string
+}
+
+// Error return columns and fields mismatched
+func (err *ScanMismatchError) Error() string {
+	var messages []string
+	if len(err.UnmappedColumns) > 0 {
+		messages = append(messages, fmt.Sprintf("unmapped columns %v", strings.Join(err.UnmappedColumns, ", ")))
+	}
+	if len(err.UnfilledFields) > 0 {
+		messages = append(messages, fmt.Sprintf("unfilled fields %v", strings.Join(err.UnfilledFields, ", ")))
+	}
+	return fmt.Sprintf("scan mismatch of %v: %v", err.Destination, strings.Join(messages, "; "))
+}
+
+// CheckScan return a db reporting selected columns which don't map to fields of the destination, and fields which aren't selected,
+// it is useful to catch typos and renames of columns in Raw and Select, e.g:
+//    var results []struct{ Name string; Total int }
+//    err := db.CheckScan(gorm.ScanCheckError).Raw("SELECT name, count(*) AS count FROM users GROUP BY name").Scan(&results).Error
+//    // scan mismatch of struct { Name string; Total int }: unmapped columns count; unfilled fields Total
+func (s *DB) CheckScan(check ScanCheck) *DB {
+	return s.Set("gorm:check_scan", check)
+}
+
+// checkScan report mismatches of columns and fields of the destination with CheckScan
+func (scope *Scope) checkScan(destination reflect.Type, columns []string) {
+	check, ok := scope.Get("gorm:check_scan")
+	if !ok || (check != ScanCheckWarn && check != ScanCheckError) || scope.HasError() {
+		return
+	}
+
+	var (
+		mismatch = &ScanMismatchError{Destination: destination}
+		fields   = scope.New(reflect.New(destination).Interface()).Fields()
+		selected = map[string]bool{}
+	)
+	for _, column := range columns {
+		selected[column] = true
+	}
+
+	for _, column := range columns {
+		mapped := false
+		for _, field := range fields {
+			if field.DBName == column {
+				mapped = true
+				break
+			}
+		}
+		if !mapped {
+			mismatch.UnmappedColumns = append(mismatch.UnmappedColumns, column)
+		}
+	}
+	for _, field := range fields {
+		if field.IsNormal && !field.IsIgnored && !selected[field.DBName] {
+			mismatch.UnfilledFields = append(mismatch.UnfilledFields, field.path())
+		}
+	}
+
+	if len(mismatch.UnmappedColumns) == 0 && len(mismatch.UnfilledFields) == 0 {
+		return
+	}
+	if check == ScanCheckError {
+		scope.Err(mismatch)
+	} else {
+		scope.db.print("log", fileWithLineNum(), mismatch.Error())
+	}
+}
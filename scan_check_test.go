@@ -0,0 +1,54 @@
+package gorm_test
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestCheckScan(t *testing.T) {
+	DB.Save(&User{Name: "check_scan", Age: 18})
+
+	type result struct {
+		Name  string
+		Total int
+	}
+
+	var results []result
+	if err := DB.Raw("SELECT name, count(*) AS count FROM users WHERE name = ? GROUP BY name", "check_scan").Scan(&results).Error; err != nil {
+		t.Errorf("mismatches shouldn't be checked by default, got %v", err)
+	}
+
+	err := DB.CheckScan(gorm.ScanCheckError).Raw("SELECT name, count(*) AS count FROM users WHERE name = ? GROUP BY name", "check_scan").Scan(&results).Error
+	mismatch, ok := err.(*gorm.ScanMismatchError)
+	if !ok || mismatch.Destination != reflect.TypeOf(result{}) ||
+		!reflect.DeepEqual(mismatch.UnmappedColumns, []string{"count"}) || !reflect.DeepEqual(mismatch.UnfilledFields, []string{"Total"}) {
+		t.Errorf("mismatched columns and fields should be reported, got %#v", err)
+	}
+	if len(results) != 1 || results[0].Name != "check_scan" {
+		t.Errorf("records should be scanned even with mismatches, got %+v", results)
+	}
+
+	if err := DB.CheckScan(gorm.ScanCheckError).Raw("SELECT name, count(*) AS total FROM users WHERE name = ? GROUP BY name", "check_scan").Scan(&results).Error; err != nil {
+		t.Errorf("matched columns shouldn't be reported, got %v", err)
+	}
+
+	var user User
+	if err := DB.CheckScan(gorm.ScanCheckError).Select("id, name").First(&user, "name = ?", "check_scan").Error; err == nil || !strings.Contains(err.Error(), "unfilled fields Age") {
+		t.Errorf("fields not selected should be reported, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	db := DB.New()
+	db.SetLogger(gorm.Logger{LogWriter: log.New(&buf, "", 0)})
+	if err := db.CheckScan(gorm.ScanCheckWarn).Raw("SELECT name, 1 AS extra FROM users WHERE name = ?", "check_scan").Scan(&results).Error; err != nil {
+		t.Errorf("mismatches should be logged only with ScanCheckWarn, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "unmapped columns extra; unfilled fields Total") {
+		t.Errorf("mismatches should be logged, got %v", buf.String())
+	}
+}
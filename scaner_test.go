@@ -1,12 +1,19 @@
 package gorm_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lun-zhang/gorm"
+	"github.com/lun-zhang/gorm/gormtest"
 )
 
 func TestScannableSlices(t *testing.T) {
@@ -137,3 +144,158 @@ func TestScannerDataType(t *testing.T) {
 		}
 	}
 }
+
+type TicketCode [4]byte
+
+func (code *TicketCode) Value() (driver.Value, error) {
+	return hex.EncodeToString(code[:]), nil
+}
+
+type TicketStatus int
+
+const (
+	TicketOpen TicketStatus = iota + 1
+	TicketClosed
+)
+
+func (status TicketStatus) Value() (driver.Value, error) {
+	switch status {
+	case TicketOpen:
+		return "open", nil
+	case TicketClosed:
+		return "closed", nil
+	}
+	return nil, fmt.Errorf("invalid ticket status %d", status)
+}
+
+type Ticket struct {
+	ID       uint
+	Code     TicketCode
+	Status   TicketStatus
+	Assignee sql.NullString `gorm:"include_zero"`
+}
+
+func TestValuerConditions(t *testing.T) {
+	db, recorder, err := gormtest.Open("mysql")
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	db.Where(&Ticket{Code: TicketCode{1, 2, 3, 4}, Status: TicketOpen}).Find(&[]Ticket{})
+	statement := recorder.LastStatement()
+	if statement.SQL != "SELECT * FROM `tickets` WHERE (`tickets`.`code` = ?) AND (`tickets`.`status` = ?) AND (`tickets`.`assignee` IS NULL)" ||
+		!reflect.DeepEqual(statement.Vars, []interface{}{"01020304", "open"}) {
+		t.Errorf("struct conditions should use driver values, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	db.Where(map[string]interface{}{"status": TicketClosed, "assignee": sql.NullString{}}).Find(&[]Ticket{})
+	statement = recorder.LastStatement()
+	if !strings.Contains(statement.SQL, "(`tickets`.`assignee` IS NULL)") || !reflect.DeepEqual(statement.Vars, []interface{}{"closed"}) {
+		t.Errorf("map conditions should use driver values, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	db.Model(&Ticket{ID: 1}).Updates(Ticket{Status: TicketClosed, Assignee: sql.NullString{String: "jinzhu", Valid: true}})
+	statement = recorder.Statements()[len(recorder.Statements())-2]
+	if statement.SQL != "UPDATE `tickets` SET `assignee` = ?, `status` = ? WHERE `tickets`.`id` = ?" || !reflect.DeepEqual(statement.Vars, []interface{}{"jinzhu", "closed", int64(1)}) {
+		t.Errorf("updating attrs should use driver values, got %v %#v", statement.SQL, statement.Vars)
+	}
+
+	if db.Where(&Ticket{Status: TicketStatus(3)}).Find(&[]Ticket{}).Error == nil {
+		t.Errorf("errors of driver values should be returned")
+	}
+}
+
+type ScanRecordBase struct {
+	Code  string
+	Score float32
+}
+
+type ScanRecord struct {
+	ID uint
+	ScanRecordBase
+	Name     string
+	Age      int8
+	Active   bool
+	Data     []byte
+	Flags    gorm.Bits
+	Note     *string
+	Birthday time.Time
+	Ignored  string `gorm:"-"`
+}
+
+func TestCompiledScanner(t *testing.T) {
+	DB.DropTableIfExists(&ScanRecord{})
+	if err := DB.AutoMigrate(&ScanRecord{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	note := "note"
+	birthday := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	DB.Create(&ScanRecord{ScanRecordBase: ScanRecordBase{Code: "a", Score: 1.5}, Name: "first", Age: 10, Active: true, Data: []byte("data"), Flags: 5, Note: &note, Birthday: birthday})
+	DB.Create(&ScanRecord{Name: "second"})
+	DB.Exec("INSERT INTO scan_records (name, age) VALUES (?, NULL)", "null")
+
+	var records []ScanRecord
+	if err := DB.Order("id").Find(&records).Error; err != nil || len(records) != 3 {
+		t.Fatalf("failed to find records, got %v, %v", len(records), err)
+	}
+
+	rows, err := DB.Model(&ScanRecord{}).Order("id").Rows()
+	if err != nil {
+		t.Fatalf("failed to query rows, got %v", err)
+	}
+	defer rows.Close()
+	for i := 0; rows.Next(); i++ {
+		var record ScanRecord
+		if err := DB.ScanRows(rows, &record); err != nil {
+			t.Fatalf("failed to scan rows, got %v", err)
+		}
+		if !reflect.DeepEqual(record, records[i]) {
+			t.Errorf("records of the compiled scanner should be same as scanned by fields, expects %#v, got %#v", record, records[i])
+		}
+	}
+
+	first := records[0]
+	if first.Code != "a" || first.Score != 1.5 || string(first.Data) != "data" || !first.Flags.Has(4) || first.Note == nil || *first.Note != "note" || !first.Birthday.Equal(birthday) {
+		t.Errorf("fields should be scanned, got %#v", first)
+	}
+	if records[2].Age != 0 || records[2].Note != nil {
+		t.Errorf("NULL should be scanned as zero values, got %#v", records[2])
+	}
+
+	reused := []ScanRecord{{Name: "reused", Age: 99}}
+	if err := DB.Where("name = ?", "null").Find(&reused).Error; err != nil || len(reused) != 1 || reused[0].Age != 0 {
+		t.Errorf("records should be replaced, got %#v, %v", reused, err)
+	}
+
+	var result struct {
+		Name  string
+		Total int64
+	}
+	if err := DB.Raw("SELECT name, 'extra' AS extra, age AS total FROM scan_records WHERE name = ?", "first").Scan(&result).Error; err != nil || result.Name != "first" || result.Total != 10 {
+		t.Errorf("columns without fields should be skipped, got %#v, %v", result, err)
+	}
+
+	var overflow struct{ Age int8 }
+	if err := DB.Raw("SELECT 1000 AS age").Scan(&overflow).Error; err == nil {
+		t.Errorf("overflowed values should fail to scan")
+	}
+}
+
+func TestCompiledScannerOfColumnsWithCommas(t *testing.T) {
+	var result struct {
+		A int
+		B int
+	}
+	if err := DB.Raw("SELECT 1 AS a, 2 AS b").Scan(&result).Error; err != nil || result.A != 1 || result.B != 2 {
+		t.Errorf("columns should be scanned, got %#v, %v", result, err)
+	}
+
+	var other struct {
+		A int
+		B int
+	}
+	if err := DB.Raw(fmt.Sprintf("SELECT 3 AS %v", DB.Dialect().Quote("a,b"))).Scan(&other).Error; err != nil || other.A != 0 || other.B != 0 {
+		t.Errorf("scanner of columns a and b shouldn't be used for column `a,b`, got %#v, %v", other, err)
+	}
+}
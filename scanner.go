@@ -0,0 +1,331 @@
+package gorm
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structScanner scan rows of the columns into records of the model without parsing fields of each record,
+// it is compiled once for each list of columns and cached in the model struct with an LRU cache
+type structScanner struct {
+	columns []scannerColumn
+}
+
+// scannerColumn the field of a column, columns without fields are scanned and dropped
+type scannerColumn struct {
+	index  []int
+	mapped bool
+	ptr    bool // pointer fields are scanned by database/sql
+}
+
+// maxCachedScanners max compiled scanners cached for each model, least recently used scanners are dropped,
+// so models queried with columns generated dynamically won't grow the cache without limit
+const maxCachedScanners = 64
+
+// scannerCache LRU cache of compiled scanners, keyed by columns
+type scannerCache struct {
+	mutex   sync.Mutex
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+type scannerCacheEntry struct {
+	key     string
+	scanner *structScanner
+}
+
+func (cache *scannerCache) get(key string) (*structScanner, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if elem, ok := cache.items[key]; ok {
+		cache.entries.MoveToFront(elem)
+		return elem.Value.(*scannerCacheEntry).scanner, true
+	}
+	return nil, false
+}
+
+func (cache *scannerCache) set(key string, scanner *structScanner) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.items == nil {
+		cache.entries, cache.items = list.New(), map[string]*list.Element{}
+	}
+
+	if elem, ok := cache.items[key]; ok {
+		elem.Value.(*scannerCacheEntry).scanner = scanner
+		cache.entries.MoveToFront(elem)
+		return
+	}
+
+	cache.items[key] = cache.entries.PushFront(&scannerCacheEntry{key: key, scanner: scanner})
+	for cache.entries.Len() > maxCachedScanners {
+		oldest := cache.entries.Back()
+		cache.entries.Remove(oldest)
+		delete(cache.items, oldest.Value.(*scannerCacheEntry).key)
+	}
+}
+
+// scannerKey return cache key of the columns, columns are prefixed with their length, as column names may contain any characters
+func scannerKey(columns []string) string {
+	var key strings.Builder
+	for _, column := range columns {
+		key.WriteString(strconv.Itoa(len(column)))
+		key.WriteByte(':')
+		key.WriteString(column)
+	}
+	return key.String()
+}
+
+// scanner return the compiled scanner of the columns, it returns nil if records can't be scanned without parsing fields,
+// e.g. if fields are pointers of embedded structs, arrays of postgres, or columns are duplicated
+func (s *ModelStruct) scanner(columns []string) *structScanner {
+	key := scannerKey(columns)
+	if scanner, ok := s.scanners.get(key); ok {
+		return scanner
+	}
+
+	scanner := s.compileScanner(columns)
+	s.scanners.set(key, scanner)
+	return scanner
+}
+
+func (s *ModelStruct) compileScanner(columns []string) *structScanner {
+	if s.ModelType == nil {
+		return nil
+	}
+
+	scanner := &structScanner{columns: make([]scannerColumn, len(columns))}
+	selected := map[string]bool{}
+	for i, column := range columns {
+		if selected[column] {
+			return nil
+		}
+		selected[column] = true
+
+		for _, field := range s.StructFields {
+			if field.DBName != column {
+				continue
+			}
+			if !field.IsNormal || isArrayType(field.Struct.Type) {
+				return nil
+			}
+
+			var (
+				index []int
+				typ   = s.ModelType
+			)
+			for j, name := range field.Names {
+				structField, _ := typ.FieldByName(name)
+				if j < len(field.Names)-1 && structField.Type.Kind() == reflect.Ptr {
+					return nil
+				}
+				index = append(index, structField.Index...)
+				typ = structField.Type
+			}
+
+			isPtr := typ.Kind() == reflect.Ptr
+			if !isPtr && !isScannableType(typ) {
+				return nil
+			}
+			scanner.columns[i] = scannerColumn{index: index, mapped: true, ptr: isPtr}
+			break
+		}
+	}
+	return scanner
+}
+
+// isScannableType return true if values of the type are converted by fieldScanner
+func isScannableType(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(scannerType) || typ == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// scanState scan rows of a query, destinations are allocated once and reused by all records
+type scanState struct {
+	scanner  *structScanner
+	values   []interface{}
+	scanners []fieldScanner
+	ignored  interface{}
+	loc      *time.Location
+}
+
+func (scanner *structScanner) newState(loc *time.Location) *scanState {
+	state := &scanState{
+		scanner:  scanner,
+		loc:      loc,
+		values:   make([]interface{}, len(scanner.columns)),
+		scanners: make([]fieldScanner, len(scanner.columns)),
+	}
+	for i, column := range scanner.columns {
+		if !column.mapped {
+			state.values[i] = &state.ignored
+		} else if !column.ptr {
+			state.values[i] = &state.scanners[i]
+		}
+	}
+	return state
+}
+
+// scan scan the current row into the record
+func (state *scanState) scan(rows *sql.Rows, record reflect.Value) error {
+	for i, column := range state.scanner.columns {
+		if !column.mapped {
+			continue
+		}
+		if field := record.FieldByIndex(column.index); column.ptr {
+			state.values[i] = field.Addr().Interface()
+		} else {
+			state.scanners[i].field = field
+		}
+	}
+
+	if err := rows.Scan(state.values...); err != nil {
+		return err
+	}
+
+	if state.loc != nil {
+		for _, column := range state.scanner.columns {
+			if column.mapped {
+				scannedTime(state.loc, record.FieldByIndex(column.index))
+			}
+		}
+	}
+	return nil
+}
+
+// fieldScanner convert values of the driver to the field like database/sql, NULL is scanned as the zero value
+type fieldScanner struct {
+	field reflect.Value
+}
+
+// Scan set the field with the value
+func (s *fieldScanner) Scan(src interface{}) error {
+	field := s.field
+	if src == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	if value := reflect.ValueOf(src); value.Type().AssignableTo(field.Type()) {
+		switch src.(type) {
+		case []byte:
+			// bytes of the driver are reused by the next row
+			field.SetBytes(append([]byte(nil), src.([]byte)...))
+		default:
+			field.Set(value)
+		}
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			field.SetString(v)
+		case []byte:
+			field.SetString(string(v))
+		case time.Time:
+			field.SetString(v.Format(time.RFC3339Nano))
+		default:
+			field.SetString(scannedString(src))
+		}
+		return nil
+	case reflect.Slice:
+		switch v := src.(type) {
+		case string:
+			field.SetBytes([]byte(v))
+		case []byte:
+			field.SetBytes(append([]byte(nil), v...))
+		default:
+			field.SetBytes([]byte(scannedString(src)))
+		}
+		return nil
+	case reflect.Bool:
+		value, err := driver.Bool.ConvertValue(src)
+		if err != nil {
+			return s.convertError(src, err)
+		}
+		field.SetBool(value.(bool))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, ok := src.(int64); ok && !field.OverflowInt(v) {
+			field.SetInt(v)
+			return nil
+		}
+		value, err := strconv.ParseInt(scannedString(src), 10, field.Type().Bits())
+		if err != nil {
+			return s.convertError(src, err)
+		}
+		field.SetInt(value)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, ok := src.(int64); ok && v >= 0 && !field.OverflowUint(uint64(v)) {
+			field.SetUint(uint64(v))
+			return nil
+		}
+		value, err := strconv.ParseUint(scannedString(src), 10, field.Type().Bits())
+		if err != nil {
+			return s.convertError(src, err)
+		}
+		field.SetUint(value)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if v, ok := src.(float64); ok {
+			field.SetFloat(v)
+			return nil
+		}
+		value, err := strconv.ParseFloat(scannedString(src), field.Type().Bits())
+		if err != nil {
+			return s.convertError(src, err)
+		}
+		field.SetFloat(value)
+		return nil
+	}
+	return fmt.Errorf("unsupported Scan, storing driver.Value type %T into type %v", src, field.Type())
+}
+
+func (s *fieldScanner) convertError(src interface{}, err error) error {
+	return fmt.Errorf("converting driver.Value type %T (%q) to a %v: %v", src, scannedString(src), s.field.Kind(), err)
+}
+
+// scannedString return values of the driver as strings like database/sql
+func scannedString(src interface{}) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(src)
+}
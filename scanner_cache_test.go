@@ -0,0 +1,23 @@
+package gorm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScannerCacheLimit(t *testing.T) {
+	var cache scannerCache
+	for i := 0; i < maxCachedScanners*2; i++ {
+		cache.set(scannerKey([]string{fmt.Sprint("column", i)}), &structScanner{})
+		if _, ok := cache.get(scannerKey([]string{"column0"})); !ok {
+			t.Fatalf("recently used scanner should be kept")
+		}
+	}
+
+	if len(cache.items) != maxCachedScanners || cache.entries.Len() != maxCachedScanners {
+		t.Errorf("cached scanners should be limited to %v, got %v", maxCachedScanners, len(cache.items))
+	}
+	if _, ok := cache.get(scannerKey([]string{"column1"})); ok {
+		t.Errorf("least recently used scanner should be dropped")
+	}
+}
@@ -2,6 +2,7 @@ package gorm
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
@@ -24,6 +25,8 @@ type Scope struct {
 	skipLeft        bool
 	fields          *[]*Field
 	selectAttrs     *[]string
+	cancels         []context.CancelFunc
+	instanceValues  map[string]interface{} // settings of current operation, they aren't shared with dbs, so set them in place
 }
 
 // IndirectValue return scope's reflect value's indirect value
@@ -45,6 +48,19 @@ func (scope *Scope) DB() *DB {
 	return scope.db
 }
 
+// Context return the context of scope's DB, callbacks could use it to do tracing, deadline checks and so on, e.g:
+//    func traceCallback(scope *gorm.Scope) {
+//      if span := trace.FromContext(scope.Context()); span != nil {
+//        span.Annotate(nil, scope.TableName())
+//      }
+//    }
+func (scope *Scope) Context() context.Context {
+	if scope.db == nil {
+		return context.Background()
+	}
+	return scope.db.Context()
+}
+
 // NewDB create a new DB without search information
 func (scope *Scope) NewDB() *DB {
 	if scope.db != nil {
@@ -58,12 +74,14 @@ func (scope *Scope) NewDB() *DB {
 
 // SQLDB return *sql.DB
 func (scope *Scope) SQLDB() SQLCommon {
-	return scope.db.db
+	db := scope.db.db
+	db.scope = scope
+	return db
 }
 
 // Dialect get dialect
 func (scope *Scope) Dialect() Dialect {
-	return scope.db.dialect
+	return scope.db.ownDialect()
 }
 
 // Quote used to quote string to escape them for database
@@ -82,6 +100,8 @@ func (scope *Scope) Quote(str string) string {
 // Err add error to Scope
 func (scope *Scope) Err(err error) error {
 	if err != nil {
+		// errors of scanning rows after timeout
+		err = scope.db.db.timeoutError(nil, err)
 		scope.db.AddError(err)
 	}
 	return err
@@ -106,12 +126,15 @@ func (scope *Scope) SkipLeft() {
 func (scope *Scope) Fields() []*Field {
 	if scope.fields == nil {
 		var (
-			fields             []*Field
+			structFields       = scope.GetModelStruct().StructFields
+			fields             = make([]*Field, 0, len(structFields))
+			fieldValues        = make([]Field, len(structFields)) // allocate fields at once
 			indirectScopeValue = scope.IndirectValue()
 			isStruct           = indirectScopeValue.Kind() == reflect.Struct
 		)
 
-		for _, structField := range scope.GetModelStruct().StructFields {
+		for i, structField := range structFields {
+			field := &fieldValues[i]
 			if isStruct {
 				fieldValue := indirectScopeValue
 				for _, name := range structField.Names {
@@ -120,10 +143,11 @@ func (scope *Scope) Fields() []*Field {
 					}
 					fieldValue = reflect.Indirect(fieldValue).FieldByName(name)
 				}
-				fields = append(fields, &Field{StructField: structField, Field: fieldValue, IsBlank: isBlank(fieldValue)})
+				*field = Field{StructField: structField, Field: fieldValue, IsBlank: isBlank(fieldValue)}
 			} else {
-				fields = append(fields, &Field{StructField: structField, IsBlank: true})
+				*field = Field{StructField: structField, IsBlank: true}
 			}
+			fields = append(fields, field)
 		}
 		scope.fields = &fields
 	}
@@ -134,12 +158,12 @@ func (scope *Scope) Fields() []*Field {
 // FieldByName find `gorm.Field` with field name or db name
 func (scope *Scope) FieldByName(name string) (field *Field, ok bool) {
 	var (
-		dbName           = ToColumnName(name)
+		dbName           = scope.db.naming().ColumnName(name)
 		mostMatchedField *Field
 	)
 
 	for _, field := range scope.Fields() {
-		if field.Name == name || field.DBName == name {
+		if field.Name == name || field.DBName == name || field.hasPath(name, false) {
 			return field, true
 		}
 		if field.DBName == dbName {
@@ -217,11 +241,11 @@ func (scope *Scope) SetColumn(column interface{}, value interface{}) error {
 		return field.Set(value)
 	} else if name, ok := column.(string); ok {
 		var (
-			dbName           = ToDBName(name)
+			dbName           = scope.db.naming().DBName(name)
 			mostMatchedField *Field
 		)
 		for _, field := range scope.Fields() {
-			if field.DBName == value {
+			if field.DBName == value || field.hasPath(name, false) {
 				updateAttrs[field.DBName] = value
 				return field.Set(value)
 			}
@@ -238,14 +262,14 @@ func (scope *Scope) SetColumn(column interface{}, value interface{}) error {
 	return errors.New("could not convert column to field")
 }
 
-// CallMethod call scope value's method, if it is a slice, will call its element's method one by one
+// CallMethod call scope value's method, if it is a slice, will call its element's method one by one until any of them returns error
 func (scope *Scope) CallMethod(methodName string) {
 	if scope.Value == nil {
 		return
 	}
 
 	if indirectScopeValue := scope.IndirectValue(); indirectScopeValue.Kind() == reflect.Slice {
-		for i := 0; i < indirectScopeValue.Len(); i++ {
+		for i := 0; i < indirectScopeValue.Len() && !scope.HasError(); i++ {
 			scope.callMethod(methodName, indirectScopeValue.Index(i))
 		}
 	} else {
@@ -258,6 +282,10 @@ func (scope *Scope) AddToVars(value interface{}) string {
 	_, skipBindVar := scope.InstanceGet("skip_bindvar")
 
 	if expr, ok := value.(*SqlExpr); ok {
+		if expr.column {
+			return scope.Quote(expr.expr)
+		}
+
 		exp := expr.expr
 		for _, arg := range expr.args {
 			if skipBindVar {
@@ -269,7 +297,17 @@ func (scope *Scope) AddToVars(value interface{}) string {
 		return exp
 	}
 
-	scope.SQLVars = append(scope.SQLVars, value)
+	if valuer, ok := value.(GormValuerInterface); ok && !isNilPointer(value) {
+		if expr := valuer.GormValue(scope.Dialect()); expr != nil {
+			return scope.AddToVars(expr)
+		}
+	}
+
+	if value != nil && isArrayType(reflect.TypeOf(value)) && supportArray(scope.Dialect()) {
+		value = Array(value)
+	}
+
+	scope.SQLVars = append(scope.SQLVars, databaseTime(scope.db.timeLocation(), value))
 
 	if skipBindVar {
 		return "?"
@@ -316,38 +354,58 @@ func (scope *Scope) TableName() string {
 		return scope.Search.tableName
 	}
 
+	return tenantTableName(scope.db, scope.suffixedTableName(scope.modelTableName()))
+}
+
+// modelTableName return table name of the model without resolving tenant's table
+func (scope *Scope) modelTableName() string {
 	if tabler, ok := scope.Value.(tabler); ok {
-		return tabler.TableName()
+		return scope.db.naming().qualifiedTableName(tabler.TableName())
 	}
 
 	if tabler, ok := scope.Value.(dbTabler); ok {
-		return tabler.TableName(scope.db)
+		return scope.db.naming().qualifiedTableName(tabler.TableName(scope.db))
 	}
 
-	return scope.GetModelStruct().TableName(scope.db.Model(scope.Value))
+	return scope.db.naming().qualifiedTableName(scope.GetModelStruct().TableName(scope.db.Model(scope.Value)))
 }
 
 // QuotedTableName return quoted table name
 func (scope *Scope) QuotedTableName() (name string) {
 	if scope.Search != nil && len(scope.Search.tableName) > 0 {
-		if strings.Contains(scope.Search.tableName, " ") {
-			return scope.Search.tableName
-		}
-		return scope.Quote(scope.Search.tableName)
+		return scope.quoteTableName(scope.Search.tableName)
 	}
 
-	return scope.Quote(scope.TableName())
+	return scope.quoteTableName(scope.TableName())
+}
+
+// quoteTableName quote each part of table names like `schema.table`, names with spaces like `users AS u` are returned as they are
+func (scope *Scope) quoteTableName(name string) string {
+	if strings.Contains(name, " ") {
+		return name
+	}
+	return scope.Quote(name)
 }
 
 // CombinedConditionSql return combined condition sql
 func (scope *Scope) CombinedConditionSql() string {
-	joinSQL := scope.joinsSQL()
+	return scope.combinedConditionSQL("")
+}
+
+// combinedConditionSQL return combined condition sql, the clause is put after joins, like `AS OF SYSTEM TIME` of selecting
+func (scope *Scope) combinedConditionSQL(fromClause string) string {
+	joinSQL := scope.joinsSQL() + fromClause
 	whereSQL := scope.whereSQL()
 	if scope.Search.raw {
 		whereSQL = strings.TrimSuffix(strings.TrimPrefix(whereSQL, "WHERE ("), ")")
 	}
+	orderSQL := scope.orderSQL()
+	limitAndOffsetSQL := scope.limitAndOffsetSQL()
+	if orderer, ok := scope.Dialect().(paginationOrderer); ok && orderSQL == "" && limitAndOffsetSQL != "" {
+		orderSQL = " ORDER BY " + orderer.DefaultPaginationOrder()
+	}
 	return joinSQL + whereSQL + scope.groupSQL() +
-		scope.havingSQL() + scope.orderSQL() + scope.limitAndOffsetSQL()
+		scope.havingSQL() + orderSQL + limitAndOffsetSQL
 }
 
 // Raw set raw sql
@@ -391,20 +449,27 @@ func (scope *Scope) InstanceID() string {
 
 // InstanceSet set instance setting for current operation, but not for operations in callbacks, like saving associations callback
 func (scope *Scope) InstanceSet(name string, value interface{}) *Scope {
-	return scope.Set(name+scope.InstanceID(), value)
+	if scope.instanceValues == nil {
+		scope.instanceValues = map[string]interface{}{}
+	}
+	scope.instanceValues[name] = value
+	return scope
 }
 
 // InstanceGet get instance setting from current operation
 func (scope *Scope) InstanceGet(name string) (interface{}, bool) {
-	return scope.Get(name + scope.InstanceID())
+	value, ok := scope.instanceValues[name]
+	return value, ok
 }
 
 // Begin start a transaction
 func (scope *Scope) Begin() *Scope {
-	if db, ok := scope.SQLDB().(sqlDb); ok {
+	if db, ok := scope.db.db.dbSQL.(sqlDb); ok {
 		if tx, err := db.Begin(); scope.Err(err) == nil {
+			// keep the connection before the transaction, it will be restored after commit or rollback
+			scope.InstanceSet("gorm:started_transaction", scope.db.db.dbSQL)
+			scope.db.db.txSource = scope.db.db.dbSQL
 			scope.db.db.dbSQL = interface{}(tx).(SQLCommon)
-			scope.InstanceSet("gorm:started_transaction", true)
 		}
 	}
 	return scope
@@ -412,14 +477,14 @@ func (scope *Scope) Begin() *Scope {
 
 // CommitOrRollback commit current transaction if no error happened, otherwise will rollback it
 func (scope *Scope) CommitOrRollback() *Scope {
-	if _, ok := scope.InstanceGet("gorm:started_transaction"); ok {
+	if dbSQL, ok := scope.InstanceGet("gorm:started_transaction"); ok {
 		if db, ok := scope.db.db.dbSQL.(sqlTx); ok {
 			if scope.HasError() {
 				db.Rollback()
 			} else {
 				scope.Err(db.Commit())
 			}
-			scope.db.db = scope.db.parent.db
+			scope.db.db.dbSQL = dbSQL.(SQLCommon)
 		}
 	}
 	return scope
@@ -435,6 +500,15 @@ func (scope *Scope) callMethod(methodName string, reflectValue reflect.Value) {
 		reflectValue = reflectValue.Addr()
 	}
 
+	if reflectValue.CanInterface() {
+		if hook, ok := hookOf(methodName, reflectValue.Interface()); ok {
+			newDB := scope.NewDB()
+			scope.Err(hook(newDB))
+			scope.Err(newDB.Error)
+			return
+		}
+	}
+
 	if methodValue := reflectValue.MethodByName(methodName); methodValue.IsValid() {
 		switch method := methodValue.Interface().(type) {
 		case func():
@@ -496,6 +570,8 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 			if field.DBName == column {
 				if field.Field.Kind() == reflect.Ptr {
 					values[index] = field.Field.Addr().Interface()
+				} else if isArrayType(field.Struct.Type) {
+					values[index] = Array(field.Field.Addr().Interface())
 				} else {
 					reflectValue := reflect.New(reflect.PtrTo(field.Struct.Type))
 					reflectValue.Elem().Set(field.Field.Addr())
@@ -517,6 +593,15 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 	for index, field := range resetFields {
 		if v := reflect.ValueOf(values[index]).Elem().Elem(); v.IsValid() {
 			field.Field.Set(v)
+		} else {
+			// NULL value, reset field in case the destination is reused
+			field.Field.Set(reflect.Zero(field.Field.Type()))
+		}
+	}
+
+	if loc := scope.db.timeLocation(); loc != nil {
+		for _, index := range selectedColumnsMap {
+			scannedTime(loc, fields[index].Field)
 		}
 	}
 }
@@ -550,6 +635,29 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 		}
 		str = fmt.Sprintf("(%v.%v %s (?))", quotedTableName, quotedPrimaryKey, inSQL)
 		clause["args"] = []interface{}{value}
+	case *SqlExpr:
+		if include {
+			str = fmt.Sprintf("(%v)", value.expr)
+		} else {
+			str = fmt.Sprintf("NOT (%v)", value.expr)
+		}
+		clause["args"] = value.args
+	case *DB:
+		// conditions of the db are grouped in parentheses, they are built with the table of the statement
+		if value.Error != nil {
+			scope.Err(value.Error)
+			return
+		}
+		if value.search == nil {
+			return
+		}
+		if sql := scope.conditionsSQL(value.search); sql != "" {
+			if include {
+				return fmt.Sprintf("(%v)", sql)
+			}
+			return fmt.Sprintf("NOT (%v)", sql)
+		}
+		return
 	case string:
 		if isNumberRegexp.MatchString(value) {
 			return fmt.Sprintf("(%v.%v %s %v)", quotedTableName, quotedPrimaryKey, equalSQL, scope.AddToVars(value))
@@ -569,8 +677,15 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 	case map[string]interface{}:
 		var sqls []string
 		for key, value := range value {
-			if value != nil {
-				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(key), equalSQL, scope.AddToVars(value)))
+			value, err := driverValue(reflect.ValueOf(value))
+			if err != nil {
+				scope.Err(err)
+				return
+			}
+
+			if !isNullValue(value) {
+				value = scope.encryptedConditionValue(scope, key, value)
+				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(key), equalSQL, scope.AddToVars(sensitiveVar(scope, key, value))))
 			} else {
 				if !include {
 					sqls = append(sqls, fmt.Sprintf("(%v.%v IS NOT NULL)", quotedTableName, scope.Quote(key)))
@@ -581,7 +696,13 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 		}
 		return strings.Join(sqls, " AND ")
 	case interface{}:
-		var sqls []string
+		var (
+			sqls       []string
+			zeroFields []string
+		)
+		if condition, ok := value.(structCondition); ok {
+			value, zeroFields = condition.value, condition.zeroFields
+		}
 		newScope := scope.New(value)
 
 		if len(newScope.Fields()) == 0 {
@@ -589,9 +710,30 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 			return
 		}
 		scopeQuotedTableName := newScope.QuotedTableName()
+		if newScope.GetModelStruct().ModelType == scope.GetModelStruct().ModelType {
+			// conditions of current model use table of the statement, which may be changed with `Table`
+			scopeQuotedTableName = quotedTableName
+		}
 		for _, field := range newScope.Fields() {
-			if !field.IsIgnored && !field.IsBlank {
-				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", scopeQuotedTableName, scope.Quote(field.DBName), equalSQL, scope.AddToVars(field.Field.Interface())))
+			if !field.IsIgnored && (!field.IsBlank || field.IsNormal && includeZeroField(field, zeroFields)) {
+				value, err := driverValue(field.Field)
+				if err != nil {
+					scope.Err(err)
+					return
+				}
+
+				if isNullValue(value) {
+					// zero fields of nullable types are included as NULL
+					if include {
+						sqls = append(sqls, fmt.Sprintf("(%v.%v IS NULL)", scopeQuotedTableName, scope.Quote(field.DBName)))
+					} else {
+						sqls = append(sqls, fmt.Sprintf("(%v.%v IS NOT NULL)", scopeQuotedTableName, scope.Quote(field.DBName)))
+					}
+					continue
+				}
+
+				value = scope.encryptedConditionValue(newScope, field.DBName, value)
+				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", scopeQuotedTableName, scope.Quote(field.DBName), equalSQL, scope.AddToVars(sensitiveVar(newScope, field.DBName, value))))
 			}
 		}
 		return strings.Join(sqls, " AND ")
@@ -604,6 +746,7 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 	args := clause["args"].([]interface{})
 	for _, arg := range args {
 		var err error
+		arg = expandSensitiveSlice(arg)
 		switch reflect.ValueOf(arg).Kind() {
 		case reflect.Slice: // For where("id in (?)", []int64{1,2})
 			if scanner, ok := interface{}(arg).(driver.Valuer); ok {
@@ -637,8 +780,11 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 				replacements = append(replacements, scope.AddToVars(Expr("NULL")))
 			}
 		default:
-			if valuer, ok := interface{}(arg).(driver.Valuer); ok {
-				arg, err = valuer.Value()
+			// keep sensitive values to mask them in logs
+			if _, sensitive := arg.(sensitiveValue); !sensitive {
+				if valuer, ok := interface{}(arg).(driver.Valuer); ok {
+					arg, err = valuer.Value()
+				}
 			}
 
 			replacements = append(replacements, scope.AddToVars(arg))
@@ -710,9 +856,9 @@ func (scope *Scope) buildSelectQuery(clause map[string]interface{}) (str string)
 
 func (scope *Scope) whereSQL() (sql string) {
 	var (
-		quotedTableName                                = scope.QuotedTableName()
-		deletedAtField, hasDeletedAtField              = scope.FieldByName("DeletedAt")
-		primaryConditions, andConditions, orConditions []string
+		quotedTableName                   = scope.QuotedTableName()
+		deletedAtField, hasDeletedAtField = scope.FieldByName("DeletedAt")
+		primaryConditions                 []string
 	)
 
 	if !scope.Search.Unscoped && hasDeletedAtField {
@@ -727,19 +873,46 @@ func (scope *Scope) whereSQL() (sql string) {
 		}
 	}
 
-	for _, clause := range scope.Search.whereConditions {
+	if _, ok := scope.InstanceGet("gorm:batch_primary_keys"); ok {
+		primaryConditions = append(primaryConditions, scope.batchPrimaryCondition())
+	}
+
+	for _, clause := range scope.Search.joinConditions {
+		if field := scope.joinPreloadField(clause["query"]); field != nil && field.Relationship.Kind == "many_to_many" {
+			args, _ := clause["args"].([]interface{})
+			primaryConditions = append(primaryConditions, scope.manyToManyJoinCondition(field, args))
+		}
+	}
+
+	combinedSQL := scope.conditionsSQL(scope.Search)
+	if len(primaryConditions) > 0 {
+		sql = "WHERE " + strings.Join(primaryConditions, " AND ")
+		if len(combinedSQL) > 0 {
+			sql = sql + " AND (" + combinedSQL + ")"
+		}
+	} else if len(combinedSQL) > 0 {
+		sql = "WHERE " + combinedSQL
+	}
+	return
+}
+
+// conditionsSQL combine where, or and not conditions of the search, e.g: `a AND b OR c`
+func (scope *Scope) conditionsSQL(search *search) string {
+	var andConditions, orConditions []string
+
+	for _, clause := range search.whereConditions {
 		if sql := scope.buildCondition(clause, true); sql != "" {
 			andConditions = append(andConditions, sql)
 		}
 	}
 
-	for _, clause := range scope.Search.orConditions {
+	for _, clause := range search.orConditions {
 		if sql := scope.buildCondition(clause, true); sql != "" {
 			orConditions = append(orConditions, sql)
 		}
 	}
 
-	for _, clause := range scope.Search.notConditions {
+	for _, clause := range search.notConditions {
 		if sql := scope.buildCondition(clause, false); sql != "" {
 			andConditions = append(andConditions, sql)
 		}
@@ -754,22 +927,13 @@ func (scope *Scope) whereSQL() (sql string) {
 	} else {
 		combinedSQL = orSQL
 	}
-
-	if len(primaryConditions) > 0 {
-		sql = "WHERE " + strings.Join(primaryConditions, " AND ")
-		if len(combinedSQL) > 0 {
-			sql = sql + " AND (" + combinedSQL + ")"
-		}
-	} else if len(combinedSQL) > 0 {
-		sql = "WHERE " + combinedSQL
-	}
-	return
+	return combinedSQL
 }
 
 func (scope *Scope) selectSQL() string {
 	if len(scope.Search.selects) == 0 {
 		if len(scope.Search.joinConditions) > 0 {
-			return fmt.Sprintf("%v.*", scope.QuotedTableName())
+			return fmt.Sprintf("%v.*", scope.QuotedTableName()) + scope.joinPreloadSelectSQL()
 		}
 		return "*"
 	}
@@ -832,6 +996,14 @@ func (scope *Scope) havingSQL() string {
 func (scope *Scope) joinsSQL() string {
 	var joinConditions []string
 	for _, clause := range scope.Search.joinConditions {
+		if field := scope.joinPreloadField(clause["query"]); field != nil {
+			if field.Relationship.Kind == "many_to_many" {
+				continue // filtered with EXISTS in WHERE
+			}
+			args, _ := clause["args"].([]interface{})
+			clause = scope.joinPreloadCondition(field, args)
+		}
+
 		if sql := scope.buildCondition(clause, true); sql != "" {
 			joinConditions = append(joinConditions, strings.TrimSuffix(strings.TrimPrefix(sql, "("), ")"))
 		}
@@ -840,11 +1012,29 @@ func (scope *Scope) joinsSQL() string {
 	return strings.Join(joinConditions, " ") + " "
 }
 
+func (scope *Scope) asOfSystemTimeSQL() string {
+	if scope.Search.asOfSystemTime == "" {
+		return ""
+	}
+
+	if supporter, ok := scope.Dialect().(interface {
+		SupportAsOfSystemTime() bool
+	}); !ok || !supporter.SupportAsOfSystemTime() {
+		scope.Err(fmt.Errorf("AS OF SYSTEM TIME isn't supported by %v", scope.Dialect().GetName()))
+		return ""
+	}
+	if !asOfSystemTimeRegexp.MatchString(scope.Search.asOfSystemTime) {
+		scope.Err(fmt.Errorf("invalid AS OF SYSTEM TIME %q", scope.Search.asOfSystemTime))
+		return ""
+	}
+	return "AS OF SYSTEM TIME " + scope.Search.asOfSystemTime + " "
+}
+
 func (scope *Scope) prepareQuerySQL() {
 	if scope.Search.raw {
 		scope.Raw(scope.CombinedConditionSql())
 	} else {
-		scope.Raw(fmt.Sprintf("SELECT %v FROM %v %v", scope.selectSQL(), scope.QuotedTableName(), scope.CombinedConditionSql()))
+		scope.Raw(fmt.Sprintf("SELECT %v%v FROM %v%v %v", scope.optimizerHintsSQL(), scope.selectSQL(), scope.QuotedTableName(), scope.tableHintsSQL(), scope.combinedConditionSQL(scope.asOfSystemTimeSQL())))
 	}
 	return
 }
@@ -857,6 +1047,10 @@ func (scope *Scope) inlineCondition(values ...interface{}) *Scope {
 }
 
 func (scope *Scope) callCallbacks(funcs []*func(s *Scope)) *Scope {
+	// results of row queries are read by the caller, who should release their statements
+	if _, ok := scope.InstanceGet("row_query_result"); !ok {
+		defer scope.releaseStatements()
+	}
 	defer func() {
 		if err := recover(); err != nil {
 			if db, ok := scope.db.db.dbSQL.(sqlTx); ok {
@@ -892,11 +1086,12 @@ func convertInterfaceToMap(values interface{}, withIgnoredField bool, db *DB) ma
 		switch reflectValue.Kind() {
 		case reflect.Map:
 			for _, key := range reflectValue.MapKeys() {
-				attrs[ToColumnName(key.Interface().(string))] = reflectValue.MapIndex(key).Interface()
+				attrs[db.naming().ColumnName(key.Interface().(string))] = reflectValue.MapIndex(key).Interface()
 			}
 		default:
 			for _, field := range (&Scope{Value: values, db: db}).Fields() {
-				if !field.IsBlank && (withIgnoredField || !field.IsIgnored) {
+				_, updateZero := field.TagSettingsGet("UPDATE_ZERO")
+				if (!field.IsBlank || updateZero) && (withIgnoredField || !field.IsIgnored) {
 					attrs[field.DBName] = field.Field.Interface()
 				}
 			}
@@ -923,9 +1118,12 @@ func (scope *Scope) updatedAttrsWithValues(value interface{}) (results map[strin
 					if field.IsNormal && !field.IsIgnored {
 						hasUpdate = true
 						if err == ErrUnaddressable {
-							results[field.DBName] = value
+							results[field.DBName], err = driverValue(reflect.ValueOf(value))
 						} else {
-							results[field.DBName] = field.Field.Interface()
+							results[field.DBName], err = driverValue(field.Field)
+						}
+						if err != nil {
+							scope.Err(err)
 						}
 					}
 				}
@@ -942,7 +1140,7 @@ func (scope *Scope) row() *sql.Row {
 
 	result := &RowQueryResult{}
 	scope.InstanceSet("row_query_result", result)
-	scope.callCallbacks(scope.db.parent.callbacks.rowQueries)
+	scope.callCallbacks(scope.db.currentCallbacks().rowQueries)
 
 	return result.Row
 }
@@ -952,14 +1150,26 @@ func (scope *Scope) rows() (*sql.Rows, error) {
 
 	result := &RowsQueryResult{}
 	scope.InstanceSet("row_query_result", result)
-	scope.callCallbacks(scope.db.parent.callbacks.rowQueries)
+	scope.callCallbacks(scope.db.currentCallbacks().rowQueries)
 
 	return result.Rows, result.Error
 }
 
+// releaseStatements cancel the timeout contexts of statements whose results have been read
+func (scope *Scope) releaseStatements() {
+	for _, cancel := range scope.cancels {
+		cancel()
+	}
+	scope.cancels = nil
+}
+
 func (scope *Scope) initialize() *Scope {
 	for _, clause := range scope.Search.whereConditions {
-		scope.updatedAttrsWithValues(clause["query"])
+		if condition, ok := clause["query"].(structCondition); ok {
+			scope.updatedAttrsWithValues(condition.value)
+		} else {
+			scope.updatedAttrsWithValues(clause["query"])
+		}
 	}
 	scope.updatedAttrsWithValues(scope.Search.initAttrs)
 	scope.updatedAttrsWithValues(scope.Search.assignAttrs)
@@ -998,6 +1208,7 @@ func (scope *Scope) pluck(column string, value interface{}) *Scope {
 		scope.Search.Select(column)
 	}
 
+	defer scope.releaseStatements()
 	rows, err := scope.rows()
 	if scope.Err(err) == nil {
 		defer rows.Close()
@@ -1014,6 +1225,48 @@ func (scope *Scope) pluck(column string, value interface{}) *Scope {
 	return scope
 }
 
+func (scope *Scope) pluckMap(columns []string, value *[]map[string]interface{}) *Scope {
+	*value = nil
+	scope.Search.Select(strings.Join(columns, ", "))
+
+	defer scope.releaseStatements()
+	rows, err := scope.rows()
+	if scope.Err(err) == nil {
+		defer rows.Close()
+
+		names, err := rows.Columns()
+		if scope.Err(err) != nil {
+			return scope
+		}
+
+		for rows.Next() {
+			values := make([]interface{}, len(names))
+			for idx := range values {
+				values[idx] = new(interface{})
+			}
+
+			if scope.Err(rows.Scan(values...)) != nil {
+				return scope
+			}
+
+			result := make(map[string]interface{}, len(names))
+			for idx, name := range names {
+				v := *(values[idx].(*interface{}))
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				result[name] = v
+			}
+			*value = append(*value, result)
+		}
+
+		if err := rows.Err(); err != nil {
+			scope.Err(err)
+		}
+	}
+	return scope
+}
+
 func (scope *Scope) count(value interface{}) *Scope {
 	if query, ok := scope.Search.selects["query"]; !ok || !countingQueryRegexp.MatchString(fmt.Sprint(query)) {
 		if len(scope.Search.group) != 0 {
@@ -1032,6 +1285,7 @@ func (scope *Scope) count(value interface{}) *Scope {
 	}
 	scope.Search.ignoreOrderQuery = true
 	scope.Err(scope.row().Scan(value))
+	scope.releaseStatements()
 	return scope
 }
 
@@ -1048,6 +1302,7 @@ func (scope *Scope) typeName() string {
 // trace print sql log
 func (scope *Scope) trace(t time.Time) {
 	if len(scope.SQL) > 0 {
+		scope.db.recordStatement(scope.SQL, scope.SQLVars)
 		scope.db.slog(scope.SQL, t, scope.SQLVars...)
 	}
 }
@@ -1055,7 +1310,7 @@ func (scope *Scope) trace(t time.Time) {
 func (scope *Scope) changeableField(field *Field) bool {
 	if selectAttrs := scope.SelectAttrs(); len(selectAttrs) > 0 {
 		for _, attr := range selectAttrs {
-			if field.Name == attr || field.DBName == attr {
+			if field.Name == attr || field.DBName == attr || field.hasPath(attr, true) {
 				return true
 			}
 		}
@@ -1063,7 +1318,7 @@ func (scope *Scope) changeableField(field *Field) bool {
 	}
 
 	for _, attr := range scope.OmitAttrs() {
-		if field.Name == attr || field.DBName == attr {
+		if field.Name == attr || field.DBName == attr || field.hasPath(attr, true) {
 			return false
 		}
 	}
@@ -1099,7 +1354,7 @@ func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 					}
 
 					if relationship.PolymorphicType != "" {
-						tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.PolymorphicValue)
+						tx = tx.Where(fmt.Sprintf("%v = ?", scope.Quote(relationship.PolymorphicDBName)), relationship.polymorphicValue())
 					}
 					scope.Err(tx.Find(value).Error)
 				}
@@ -1121,11 +1376,22 @@ func (scope *Scope) related(value interface{}, foreignKeys ...string) *Scope {
 
 // getTableOptions return the table options string or an empty string if the table options does not exist
 func (scope *Scope) getTableOptions() string {
-	tableOptions, ok := scope.Get("gorm:table_options")
-	if !ok {
+	var options string
+	if tableOptions, ok := scope.Get("gorm:table_options"); ok {
+		options = " " + tableOptions.(string)
+	}
+	if bits, ok := scope.Get("gorm:shard_row_id_bits"); ok && supportShardRowIDBits(scope.Dialect()) {
+		options += fmt.Sprintf(" SHARD_ROW_ID_BITS = %v", bits)
+	}
+	return options
+}
+
+// dataTypeOf return data type of the field with the dialect, refused data types are reported as errors of the scope
+func (scope *Scope) dataTypeOf(field *StructField) string {
+	if scope.Err(checkDataType(scope.Dialect(), field)) != nil || scope.Err(checkEnumDataType(field)) != nil {
 		return ""
 	}
-	return " " + tableOptions.(string)
+	return scope.Dialect().DataTypeOf(field)
 }
 
 func (scope *Scope) createJoinTable(field *StructField) {
@@ -1142,7 +1408,7 @@ func (scope *Scope) createJoinTable(field *StructField) {
 					foreignKeyStruct.IsPrimaryKey = false
 					foreignKeyStruct.TagSettingsSet("IS_JOINTABLE_FOREIGNKEY", "true")
 					foreignKeyStruct.TagSettingsDelete("AUTO_INCREMENT")
-					sqlTypes = append(sqlTypes, scope.Quote(relationship.ForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
+					sqlTypes = append(sqlTypes, scope.Quote(relationship.ForeignDBNames[idx])+" "+scope.dataTypeOf(foreignKeyStruct))
 					primaryKeys = append(primaryKeys, scope.Quote(relationship.ForeignDBNames[idx]))
 				}
 			}
@@ -1153,12 +1419,17 @@ func (scope *Scope) createJoinTable(field *StructField) {
 					foreignKeyStruct.IsPrimaryKey = false
 					foreignKeyStruct.TagSettingsSet("IS_JOINTABLE_FOREIGNKEY", "true")
 					foreignKeyStruct.TagSettingsDelete("AUTO_INCREMENT")
-					sqlTypes = append(sqlTypes, scope.Quote(relationship.AssociationForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
+					sqlTypes = append(sqlTypes, scope.Quote(relationship.AssociationForeignDBNames[idx])+" "+scope.dataTypeOf(foreignKeyStruct))
 					primaryKeys = append(primaryKeys, scope.Quote(relationship.AssociationForeignDBNames[idx]))
 				}
 			}
 
-			scope.Err(scope.NewDB().Exec(fmt.Sprintf("CREATE TABLE %v (%v, PRIMARY KEY (%v))%s", scope.Quote(joinTable), strings.Join(sqlTypes, ","), strings.Join(primaryKeys, ","), scope.getTableOptions())).Error)
+			var constraintStr string
+			if constraints := scope.pendingForeignKeyConstraints(joinTable); len(constraints) > 0 {
+				constraintStr = ", " + strings.Join(constraints, ",")
+			}
+
+			scope.Err(scope.NewDB().Exec(fmt.Sprintf("CREATE TABLE %v (%v, PRIMARY KEY (%v)%v)%s", scope.Quote(joinTable), strings.Join(sqlTypes, ","), strings.Join(primaryKeys, ","), constraintStr, scope.getTableOptions())).Error)
 		}
 		scope.NewDB().Table(joinTable).AutoMigrate(joinTableHandler)
 	}
@@ -1168,9 +1439,10 @@ func (scope *Scope) createTable() *Scope {
 	var tags []string
 	var primaryKeys []string
 	var primaryKeyInColumnType = false
-	for _, field := range scope.GetModelStruct().StructFields {
+	var structFields = scope.GetModelStruct().StructFields
+	for _, field := range structFields {
 		if field.IsNormal {
-			sqlTag := scope.Dialect().DataTypeOf(field)
+			sqlTag := scope.dataTypeOf(field)
 
 			// Check if the primary key constraint was specified as
 			// part of the column type. If so, we can only support
@@ -1185,7 +1457,6 @@ func (scope *Scope) createTable() *Scope {
 		if field.IsPrimaryKey {
 			primaryKeys = append(primaryKeys, scope.Quote(field.DBName))
 		}
-		scope.createJoinTable(field)
 	}
 
 	var primaryKeyStr string
@@ -1193,7 +1464,17 @@ func (scope *Scope) createTable() *Scope {
 		primaryKeyStr = fmt.Sprintf(", PRIMARY KEY (%v)", strings.Join(primaryKeys, ","))
 	}
 
-	scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.getTableOptions())).Exec()
+	var constraintStr string
+	if constraints := scope.pendingForeignKeyConstraints(scope.TableName()); len(constraints) > 0 {
+		constraintStr = ", " + strings.Join(constraints, ",")
+	}
+
+	scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v%v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, constraintStr, scope.getTableOptions())).Exec()
+
+	// create join tables after the table, so their foreign key constraints could reference it
+	for _, field := range structFields {
+		scope.createJoinTable(field)
+	}
 
 	scope.autoIndex()
 	return scope
@@ -1231,6 +1512,11 @@ func (scope *Scope) addIndex(unique bool, indexName string, column ...string) {
 }
 
 func (scope *Scope) addForeignKey(field string, dest string, onDelete string, onUpdate string) {
+	if !supportForeignKey(scope.Dialect()) {
+		scope.db.print("warning", fileWithLineNum(), fmt.Sprintf("%v doesn't support foreign keys, ignored adding foreign key for %v", scope.Dialect().GetName(), field))
+		return
+	}
+
 	// Compatible with old generated key
 	keyName := scope.Dialect().BuildKeyName(scope.TableName(), field, dest, "foreign")
 
@@ -1242,6 +1528,11 @@ func (scope *Scope) addForeignKey(field string, dest string, onDelete string, on
 }
 
 func (scope *Scope) removeForeignKey(field string, dest string) {
+	if !supportForeignKey(scope.Dialect()) {
+		scope.db.print("warning", fileWithLineNum(), fmt.Sprintf("%v doesn't support foreign keys, ignored removing foreign key for %v", scope.Dialect().GetName(), field))
+		return
+	}
+
 	keyName := scope.Dialect().BuildKeyName(scope.TableName(), field, dest, "foreign")
 	if !scope.Dialect().HasForeignKey(scope.TableName(), keyName) {
 		return
@@ -1257,6 +1548,158 @@ func (scope *Scope) removeForeignKey(field string, dest string) {
 	scope.Raw(fmt.Sprintf(query, scope.QuotedTableName(), scope.quoteIfPossible(keyName))).Exec()
 }
 
+// foreignKeyConstraint foreign key constraint defined with `constraint` tag of relationship fields
+type foreignKeyConstraint struct {
+	table      string
+	columns    []string
+	refTable   string
+	refColumns []string
+	onDelete   string
+	onUpdate   string
+	created    bool
+}
+
+// name return constraint name, same as the one created with `AddForeignKey`
+func (constraint *foreignKeyConstraint) name(scope *Scope) string {
+	dest := fmt.Sprintf("%v(%v)", constraint.refTable, strings.Join(constraint.refColumns, ","))
+	return scope.Dialect().BuildKeyName(constraint.table, strings.Join(constraint.columns, ","), dest, "foreign")
+}
+
+// sql return constraint definition used by CREATE TABLE and ALTER TABLE
+func (constraint *foreignKeyConstraint) sql(scope *Scope) string {
+	var columns, refColumns []string
+	for _, column := range constraint.columns {
+		columns = append(columns, scope.Quote(column))
+	}
+	for _, column := range constraint.refColumns {
+		refColumns = append(refColumns, scope.Quote(column))
+	}
+
+	sql := fmt.Sprintf("CONSTRAINT %v FOREIGN KEY (%v) REFERENCES %v(%v)", scope.quoteIfPossible(constraint.name(scope)), strings.Join(columns, ","), scope.quoteTableName(constraint.refTable), strings.Join(refColumns, ","))
+	if constraint.onDelete != "" {
+		sql += " ON DELETE " + constraint.onDelete
+	}
+	if constraint.onUpdate != "" {
+		sql += " ON UPDATE " + constraint.onUpdate
+	}
+	return sql
+}
+
+// foreignKeyConstraints return foreign key constraints defined with `constraint` tag of relationship fields, e.g:
+//    type User struct {
+//      CreditCards []CreditCard `gorm:"constraint:OnDelete:CASCADE,OnUpdate:SET NULL"`
+//    }
+// the constraint is added to the table holding the foreign keys, which is the join table for many to many relationships
+func (scope *Scope) foreignKeyConstraints() (constraints []*foreignKeyConstraint) {
+	for _, field := range scope.GetModelStruct().StructFields {
+		value, ok := field.TagSettingsGet("CONSTRAINT")
+		relationship := field.Relationship
+		if !ok || relationship == nil || relationship.PolymorphicType != "" {
+			continue
+		}
+
+		var onDelete, onUpdate string
+		for _, rule := range strings.Split(value, ",") {
+			if kv := strings.SplitN(rule, ":", 2); len(kv) == 2 {
+				switch strings.ToUpper(strings.TrimSpace(kv[0])) {
+				case "ONDELETE":
+					onDelete = strings.ToUpper(strings.TrimSpace(kv[1]))
+				case "ONUPDATE":
+					onUpdate = strings.ToUpper(strings.TrimSpace(kv[1]))
+				}
+			}
+		}
+
+		toScope := scope.New(reflect.New(elemTypeOf(field.Struct.Type)).Interface())
+		switch relationship.Kind {
+		case "belongs_to":
+			constraints = append(constraints, &foreignKeyConstraint{
+				table: scope.TableName(), columns: relationship.ForeignDBNames,
+				refTable: toScope.TableName(), refColumns: relationship.AssociationForeignDBNames,
+				onDelete: onDelete, onUpdate: onUpdate,
+			})
+		case "has_one", "has_many":
+			constraints = append(constraints, &foreignKeyConstraint{
+				table: toScope.TableName(), columns: relationship.ForeignDBNames,
+				refTable: scope.TableName(), refColumns: relationship.AssociationForeignDBNames,
+				onDelete: onDelete, onUpdate: onUpdate,
+			})
+		case "many_to_many":
+			joinTableHandler := relationship.JoinTableHandler
+			joinTable := joinTableHandler.Table(scope.db)
+			for idx, foreignKeys := range [][]JoinTableForeignKey{joinTableHandler.SourceForeignKeys(), joinTableHandler.DestinationForeignKeys()} {
+				constraint := &foreignKeyConstraint{table: joinTable, refTable: scope.TableName(), onDelete: onDelete, onUpdate: onUpdate}
+				if idx == 1 {
+					constraint.refTable = toScope.TableName()
+				}
+				for _, foreignKey := range foreignKeys {
+					constraint.columns = append(constraint.columns, foreignKey.DBName)
+					constraint.refColumns = append(constraint.refColumns, foreignKey.AssociationDBName)
+				}
+				constraints = append(constraints, constraint)
+			}
+		}
+	}
+	return
+}
+
+// pendingForeignKeyConstraints return definitions of collected constraints for the creating table,
+// those referencing tables not created yet will be added after all tables created
+func (scope *Scope) pendingForeignKeyConstraints(tableName string) (results []string) {
+	if !supportForeignKey(scope.Dialect()) {
+		return
+	}
+
+	if constraints, ok := scope.Get("gorm:foreign_key_constraints"); ok {
+		for _, constraint := range constraints.([]*foreignKeyConstraint) {
+			if constraint.created || constraint.table != tableName {
+				continue
+			}
+
+			if constraint.refTable == tableName || scope.Dialect().HasTable(constraint.refTable) {
+				constraint.created = true
+				results = append(results, constraint.sql(scope))
+			}
+		}
+	}
+	return
+}
+
+// withForeignKeyConstraints collect foreign key constraints of models, so they could be created with tables
+func (s *DB) withForeignKeyConstraints(values ...interface{}) *DB {
+	var constraints []*foreignKeyConstraint
+	for _, value := range values {
+		constraints = append(constraints, s.NewScope(value).foreignKeyConstraints()...)
+	}
+	return s.Set("gorm:foreign_key_constraints", constraints)
+}
+
+// addForeignKeyConstraints add collected foreign key constraints that haven't been created with tables
+func (scope *Scope) addForeignKeyConstraints() *Scope {
+	constraints, ok := scope.Get("gorm:foreign_key_constraints")
+	if !ok || !supportForeignKey(scope.Dialect()) {
+		return scope
+	}
+
+	for _, constraint := range constraints.([]*foreignKeyConstraint) {
+		if constraint.created || !scope.Dialect().HasTable(constraint.table) || !scope.Dialect().HasTable(constraint.refTable) {
+			continue
+		}
+
+		constraint.created = true
+		if scope.Dialect().HasForeignKey(constraint.table, constraint.name(scope)) {
+			continue
+		}
+
+		if scope.Dialect().GetName() == "sqlite3" {
+			scope.db.print("warning", fileWithLineNum(), fmt.Sprintf("sqlite3 doesn't support adding foreign keys to existing tables, ignored adding foreign key for %v", constraint.table))
+			continue
+		}
+		scope.Raw(fmt.Sprintf("ALTER TABLE %v ADD %v", scope.quoteTableName(constraint.table), constraint.sql(scope))).Exec()
+	}
+	return scope
+}
+
 func (scope *Scope) removeIndex(indexName string) {
 	scope.Dialect().RemoveIndex(scope.TableName(), indexName)
 }
@@ -1271,7 +1714,7 @@ func (scope *Scope) autoMigrate() *Scope {
 		for _, field := range scope.GetModelStruct().StructFields {
 			if !scope.Dialect().HasColumn(tableName, field.DBName) {
 				if field.IsNormal {
-					sqlTag := scope.Dialect().DataTypeOf(field)
+					sqlTag := scope.dataTypeOf(field)
 					scope.Raw(fmt.Sprintf("ALTER TABLE %v ADD %v %v;", quotedTableName, scope.Quote(field.DBName), sqlTag)).Exec()
 				}
 			}
@@ -1285,6 +1728,8 @@ func (scope *Scope) autoMigrate() *Scope {
 func (scope *Scope) autoIndex() *Scope {
 	var indexes = map[string][]string{}
 	var uniqueIndexes = map[string][]string{}
+	var fullTextIndexes = map[string][]string{}
+	var ginIndexes = map[string][]string{}
 
 	for _, field := range scope.GetStructFields() {
 		if name, ok := field.TagSettingsGet("INDEX"); ok {
@@ -1292,7 +1737,7 @@ func (scope *Scope) autoIndex() *Scope {
 
 			for _, name := range names {
 				if name == "INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("idx", scope.TableName(), field.DBName)
+					name = scope.db.naming().IndexName(scope.Dialect(), "idx", scope.TableName(), field.DBName)
 				}
 				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
 				indexes[name] = append(indexes[name], column)
@@ -1304,12 +1749,36 @@ func (scope *Scope) autoIndex() *Scope {
 
 			for _, name := range names {
 				if name == "UNIQUE_INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("uix", scope.TableName(), field.DBName)
+					name = scope.db.naming().IndexName(scope.Dialect(), "uix", scope.TableName(), field.DBName)
 				}
 				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
 				uniqueIndexes[name] = append(uniqueIndexes[name], column)
 			}
 		}
+
+		if name, ok := field.TagSettingsGet("FULLTEXT"); ok {
+			names := strings.Split(name, ",")
+
+			for _, name := range names {
+				if name == "FULLTEXT" || name == "" {
+					name = scope.db.naming().IndexName(scope.Dialect(), "ftx", scope.TableName(), field.DBName)
+				}
+				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
+				fullTextIndexes[name] = append(fullTextIndexes[name], column)
+			}
+		}
+
+		if name, ok := field.TagSettingsGet("GIN_INDEX"); ok {
+			names := strings.Split(name, ",")
+
+			for _, name := range names {
+				if name == "GIN_INDEX" || name == "" {
+					name = scope.db.naming().IndexName(scope.Dialect(), "gin", scope.TableName(), field.DBName)
+				}
+				name, column := scope.Dialect().NormalizeIndexAndColumn(name, field.DBName)
+				ginIndexes[name] = append(ginIndexes[name], column)
+			}
+		}
 	}
 
 	for name, columns := range indexes {
@@ -1324,6 +1793,20 @@ func (scope *Scope) autoIndex() *Scope {
 		}
 	}
 
+	for name, columns := range fullTextIndexes {
+		fullTextScope := scope.NewDB().Table(scope.TableName()).NewScope(scope.Value)
+		if fullTextScope.addFullTextIndex(name, columns...); fullTextScope.db.Error != nil {
+			scope.db.AddError(fullTextScope.db.Error)
+		}
+	}
+
+	for name, columns := range ginIndexes {
+		ginScope := scope.NewDB().Table(scope.TableName()).NewScope(scope.Value)
+		if ginScope.addGinIndex(name, columns...); ginScope.db.Error != nil {
+			scope.db.AddError(ginScope.db.Error)
+		}
+	}
+
 	return scope
 }
 
@@ -1339,7 +1822,7 @@ func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (r
 				var object = indirect(indirectValue.Index(i))
 				var hasValue = false
 				for _, column := range columns {
-					field := object.FieldByName(column)
+					field := fieldByPath(object, column)
 					if hasValue || !isBlank(field) {
 						hasValue = true
 					}
@@ -1357,7 +1840,7 @@ func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (r
 			var result []interface{}
 			var hasValue = false
 			for _, column := range columns {
-				field := indirectValue.FieldByName(column)
+				field := fieldByPath(indirectValue, column)
 				if hasValue || !isBlank(field) {
 					hasValue = true
 				}
@@ -1383,7 +1866,7 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 
 	switch indirectScopeValue.Kind() {
 	case reflect.Slice:
-		if fieldStruct, ok := scope.GetModelStruct().ModelType.FieldByName(column); ok {
+		if fieldStruct, ok := structFieldByPath(scope.GetModelStruct().ModelType, column); ok {
 			fieldType := fieldStruct.Type
 			if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Ptr {
 				fieldType = fieldType.Elem()
@@ -1393,7 +1876,7 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 			results := reflect.New(reflect.SliceOf(reflect.PtrTo(fieldType))).Elem()
 
 			for i := 0; i < indirectScopeValue.Len(); i++ {
-				result := indirect(indirect(indirectScopeValue.Index(i)).FieldByName(column))
+				result := indirect(fieldByPath(indirect(indirectScopeValue.Index(i)), column))
 
 				if result.Kind() == reflect.Slice {
 					for j := 0; j < result.Len(); j++ {
@@ -1410,7 +1893,7 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 			return scope.New(results.Interface())
 		}
 	case reflect.Struct:
-		if field := indirectScopeValue.FieldByName(column); field.CanAddr() {
+		if field := fieldByPath(indirectScopeValue, column); field.CanAddr() {
 			return scope.New(field.Addr().Interface())
 		}
 	}
@@ -1418,8 +1901,11 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 }
 
 func (scope *Scope) hasConditions() bool {
-	return !scope.PrimaryKeyZero() ||
-		len(scope.Search.whereConditions) > 0 ||
+	return !scope.PrimaryKeyZero() || scope.hasSearchConditions()
+}
+
+func (scope *Scope) hasSearchConditions() bool {
+	return len(scope.Search.whereConditions) > 0 ||
 		len(scope.Search.orConditions) > 0 ||
 		len(scope.Search.notConditions) > 0
 }
@@ -21,6 +21,8 @@ type search struct {
 	limit            interface{}
 	group            string
 	tableName        string
+	asOfSystemTime   string
+	hints            []Hint
 	raw              bool
 	Unscoped         bool
 	ignoreOrderQuery bool
@@ -31,8 +33,21 @@ type searchPreload struct {
 	conditions []interface{}
 }
 
+// clone share conditions with the search without copying them, capacities of slices are limited,
+// so appending to the clone copies the slice instead of overwriting conditions appended by other clones
 func (s *search) clone() *search {
 	clone := *s
+	clone.whereConditions = s.whereConditions[:len(s.whereConditions):len(s.whereConditions)]
+	clone.orConditions = s.orConditions[:len(s.orConditions):len(s.orConditions)]
+	clone.notConditions = s.notConditions[:len(s.notConditions):len(s.notConditions)]
+	clone.havingConditions = s.havingConditions[:len(s.havingConditions):len(s.havingConditions)]
+	clone.joinConditions = s.joinConditions[:len(s.joinConditions):len(s.joinConditions)]
+	clone.initAttrs = s.initAttrs[:len(s.initAttrs):len(s.initAttrs)]
+	clone.assignAttrs = s.assignAttrs[:len(s.assignAttrs):len(s.assignAttrs)]
+	clone.omits = s.omits[:len(s.omits):len(s.omits)]
+	clone.orders = s.orders[:len(s.orders):len(s.orders)]
+	clone.preload = s.preload[:len(s.preload):len(s.preload)]
+	clone.hints = s.hints[:len(s.hints):len(s.hints)]
 	return &clone
 }
 
@@ -123,6 +138,11 @@ func (s *search) Preload(schema string, values ...interface{}) *search {
 	return s
 }
 
+func (s *search) AsOfSystemTime(timestamp string) *search {
+	s.asOfSystemTime = timestamp
+	return s
+}
+
 func (s *search) Raw(b bool) *search {
 	s.raw = b
 	return s
@@ -28,3 +28,18 @@ func TestCloneSearch(t *testing.T) {
 		t.Errorf("selectStr should be copied")
 	}
 }
+
+func TestCloneSearchAppendsIndependently(t *testing.T) {
+	s := new(search)
+	s.Where("a = 1").Where("b = 2").Where("c = 3").Order("a").Order("b").Order("c")
+
+	s1 := s.clone().Where("x = 1").Order("x")
+	s2 := s.clone().Where("y = 1").Order("y")
+
+	if query := s1.whereConditions[3]["query"]; query != "x = 1" || s1.orders[3] != "x" {
+		t.Errorf("conditions of clones shouldn't be overwritten by other clones, got %v, %v", query, s1.orders)
+	}
+	if query := s2.whereConditions[3]["query"]; query != "y = 1" || len(s.whereConditions) != 3 {
+		t.Errorf("conditions should be appended to the clone only, got %v", query)
+	}
+}
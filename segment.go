@@ -0,0 +1,132 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// StatementInfo information of the statement traced by the segment
+type StatementInfo struct {
+	SQL           string
+	Operation     string // the first keyword of the statement in upper case, e.g. SELECT, INSERT
+	Dialect       string
+	Table         string // table of the model, empty for raw SQL without models
+	RowsAffected  int64  // rows affected by Exec, 0 for queries
+	Master        bool   // sent to master, false if it is sent to slave
+	InTransaction bool
+	ShardID       string // index of the shard routed by Sharding, empty if it isn't routed
+	Source        string // caller who ran the statement
+	Duration      time.Duration
+	Error         error
+}
+
+// SegmentHook customize X-Ray segments of statements before closing them
+type SegmentHook func(seg *xray.Segment, info StatementInfo)
+
+// SegmentNamer name X-Ray segments of statements, RowsAffected, Duration and Error of the info are unknown when naming segments
+type SegmentNamer func(info StatementInfo) string
+
+// SetSegmentNaming name X-Ray segments of statements with the namer instead of callers, so traces of the same statements could be aggregated,
+// callers are kept as the annotation `source`, segments of named queries are still named by their names, e.g:
+//    db.SetSegmentNaming(gorm.TableSegmentName)
+//    // segments are named like mysql.users.SELECT
+//
+// Set it before using the db like registering callbacks, DBs cloned before won't use it
+func (s *DB) SetSegmentNaming(namer SegmentNamer) *DB {
+	s.parent.db.segmentNamer = namer
+	s.db.segmentNamer = namer
+	return s
+}
+
+// TableSegmentName name segments by the dialect, table and operation, e.g. mysql.users.SELECT, unknown parts are omitted
+func TableSegmentName(info StatementInfo) string {
+	var parts []string
+	for _, part := range []string{info.Dialect, info.Table, info.Operation} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// OnSegment add hook customizing X-Ray segments of statements, e.g. attach the table and the node as annotations to search traces by them:
+//    db.OnSegment(func(seg *xray.Segment, info gorm.StatementInfo) {
+//      seg.AddAnnotation("table", info.Table)
+//      seg.AddAnnotation("master", info.Master)
+//      seg.AddMetadata("rows_affected", info.RowsAffected)
+//    })
+//
+// Hooks are only called for statements traced, i.e. the context of the db carries a segment,
+// add them before using the db like registering callbacks, DBs cloned before won't use it
+func (s *DB) OnSegment(hook SegmentHook) *DB {
+	s.parent.db.segmentHooks = append(s.parent.db.segmentHooks, hook)
+	s.db.segmentHooks = s.parent.db.segmentHooks
+	return s
+}
+
+// Annotate return a db adding the annotation to X-Ray segments of its statements, values should be strings, numbers or booleans
+//    db.WithContext(ctx).Annotate("operation", "checkout").Create(&order)
+func (s *DB) Annotate(key string, value interface{}) *DB {
+	clone := s.clone()
+	annotations := make(map[string]interface{}, len(s.db.annotations)+1)
+	for k, v := range s.db.annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	clone.db.annotations = annotations
+	return clone
+}
+
+// annotate add annotations of the db to the segment, and the caller if the segment isn't named by it
+func (db ctxDB) annotate(seg *xray.Segment) {
+	if (db.namedQuery != nil || db.segmentNamer != nil) && db.source != "" {
+		seg.AddAnnotation("source", db.source)
+	}
+	for key, value := range db.annotations {
+		seg.AddAnnotation(key, value)
+	}
+}
+
+// statementInfo return information of the statement known before executing it
+func (db ctxDB) statementInfo(sql string, master bool) StatementInfo {
+	info := StatementInfo{SQL: sql, Master: master, Source: db.source}
+	// statements may be prefixed with comments or hints by rewriters, e.g. `/* trace_id */ SELECT ...`
+	if words := statementWords(sql); len(words) > 0 {
+		info.Operation = words[0]
+	}
+	_, info.InTransaction = db.dbSQL.(sqlTx)
+
+	if scope := db.scope; scope != nil {
+		info.Dialect = scope.Dialect().GetName()
+		if scope.Value != nil || scope.Search != nil && scope.Search.tableName != "" {
+			info.Table = scope.TableName()
+		}
+		if shardID, ok := scope.InstanceGet("gorm:shard_id"); ok {
+			info.ShardID = fmt.Sprint(shardID)
+		}
+	}
+	return info
+}
+
+// segmentName return name of the statement's segment, it is the caller unless named by the namer, or the name of the named query
+func (db ctxDB) segmentName(info StatementInfo) string {
+	switch {
+	case db.namedQuery != nil:
+		return db.namedQuery.name
+	case db.segmentNamer != nil:
+		if name := db.segmentNamer(info); name != "" {
+			return name
+		}
+	}
+	return db.source
+}
+
+// onSegment call segment hooks with the statement's information
+func (db ctxDB) onSegment(seg *xray.Segment, info StatementInfo) {
+	for _, hook := range db.segmentHooks {
+		hook(seg, info)
+	}
+}
@@ -0,0 +1,93 @@
+package gorm_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/lun-zhang/gorm"
+)
+
+func TestOnSegment(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	var infos []gorm.StatementInfo
+	var annotations []map[string]interface{}
+	db.OnSegment(func(seg *xray.Segment, info gorm.StatementInfo) {
+		infos = append(infos, info)
+		annotations = append(annotations, seg.Annotations)
+	})
+
+	db.Save(&User{Name: "on-segment"})
+	if len(infos) != 0 {
+		t.Errorf("hooks should not be called for statements without segments, but got %+v", infos)
+	}
+
+	ctx, seg := xray.BeginSegment(context.Background(), "test")
+	defer seg.Close(nil)
+
+	tx := db.WithContext(ctx).Annotate("operation", "on-segment")
+	tx.Model(&User{}).Where("name = ?", "on-segment").Update("age", 18)
+	tx.Where("name = ?", "on-segment").First(&User{})
+
+	if len(infos) != 2 {
+		t.Fatalf("hooks should be called for each statement, but got %+v", infos)
+	}
+
+	if info := infos[0]; info.Table != "users" || info.RowsAffected != 1 || !info.Master || info.Error != nil {
+		t.Errorf("information of updating should be passed to hooks, but got %+v", info)
+	}
+	if info := infos[1]; info.Table != "users" || info.RowsAffected != 0 || info.Duration <= 0 || info.Operation != "SELECT" {
+		t.Errorf("information of querying should be passed to hooks, but got %+v", info)
+	}
+	for _, annotation := range annotations {
+		if annotation["operation"] != "on-segment" {
+			t.Errorf("annotations should be added to segments, but got %v", annotation)
+		}
+	}
+
+	db.RegisterQueryRewriter(func(sql string, vars []interface{}) (string, []interface{}) {
+		return "/* service:test */ " + sql, vars
+	})
+	infos = nil
+	db.WithContext(ctx).Where("name = ?", "on-segment").First(&User{})
+	if len(infos) != 1 || infos[0].Operation != "SELECT" {
+		t.Errorf("operation should skip comments prefixed by rewriters, but got %+v", infos)
+	}
+}
+
+func TestSetSegmentNaming(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	var names, sources []string
+	db.SetSegmentNaming(gorm.TableSegmentName).OnSegment(func(seg *xray.Segment, info gorm.StatementInfo) {
+		names = append(names, seg.Name)
+		sources = append(sources, fmt.Sprint(seg.Annotations["source"]))
+	})
+
+	ctx, seg := xray.BeginSegment(context.Background(), "test")
+	defer seg.Close(nil)
+
+	db.WithContext(ctx).Find(&[]User{})
+	db.WithContext(ctx).Exec("DELETE FROM users WHERE name = ?", "segment-naming")
+
+	dialect := db.Dialect().GetName()
+	if len(names) != 2 || names[0] != dialect+".users.SELECT" || names[1] != dialect+".DELETE" {
+		t.Errorf("segments should be named by tables and operations, but got %v", names)
+	}
+	for _, source := range sources {
+		if !strings.Contains(source, "TestSetSegmentNaming") {
+			t.Errorf("callers should be kept as annotations, but got %v", source)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package gorm
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+)
+
+// ShardConfig how to split a logical table into shards
+type ShardConfig struct {
+	// ShardKey column used to route records to shards, e.g. `user_id`
+	ShardKey string
+	// Shards number of shards
+	Shards int
+	// Pattern format of shard table names with shard index, defaults to `<table>_%d`, e.g. `orders_%02d`
+	Pattern string
+	// ShardFunc return shard index of the shard key's value, e.g. shard by ranges of ids or months of dates,
+	// defaults to hash sharding, integers modulo shards, other values are hashed with FNV-1a
+	ShardFunc func(value interface{}) (int, error)
+}
+
+// Sharding plugin splits logical tables into shard tables, statements are routed to the shard by the shard key in conditions,
+// or in the record when creating, updating or deleting it
+//    db.Use(&gorm.Sharding{Tables: map[string]gorm.ShardConfig{
+//      "orders": {ShardKey: "user_id", Shards: 4, Pattern: "orders_%02d"},
+//    }})
+//
+//    db.Create(&Order{UserID: 5})
+//    // INSERT INTO "orders_01" ...
+//    db.Where("user_id = ?", 5).Find(&orders)
+//    // SELECT * FROM "orders_01" WHERE (user_id = 5)
+//
+// The shard key could be found in conditions like `user_id = ?`, maps and structs, statements can't be routed return error,
+// unless FanOut is enabled, then finding records queries all shards and merges results, orders and limits apply to each shard.
+// Migrate shard tables with ShardTables
+//    for _, table := range sharding.ShardTables("orders") {
+//      db.Table(table).AutoMigrate(&Order{})
+//    }
+//
+// Register Sharding before TenantGuard when using both, so tables are routed before adding tenant conditions
+type Sharding struct {
+	// Tables shard configs of logical tables
+	Tables map[string]ShardConfig
+	// FanOut query all shards when finding records without shard key
+	FanOut bool
+}
+
+// Name return plugin name
+func (sharding *Sharding) Name() string {
+	return "gorm:sharding"
+}
+
+// Initialize register callbacks to route statements to shards
+func (sharding *Sharding) Initialize(db *DB) error {
+	for table, config := range sharding.Tables {
+		if config.ShardKey == "" || config.Shards <= 0 {
+			return fmt.Errorf("shard key and shards of table %v are required", table)
+		}
+	}
+
+	callback := db.Callback()
+	callback.Create().Before("gorm:begin_transaction").Register("gorm:sharding", sharding.routeCallback)
+	callback.Query().Before("gorm:batch_load").Register("gorm:sharding", sharding.queryCallback)
+	callback.RowQuery().Before("gorm:row_query").Register("gorm:sharding", sharding.routeCallback)
+	callback.Update().Before("gorm:begin_transaction").Register("gorm:sharding", sharding.routeCallback)
+	callback.Delete().Before("gorm:begin_transaction").Register("gorm:sharding", sharding.routeCallback)
+	return nil
+}
+
+// ShardTables return all shard tables of the logical table
+func (sharding *Sharding) ShardTables(table string) []string {
+	config, ok := sharding.Tables[table]
+	if !ok {
+		return nil
+	}
+
+	tables := make([]string, config.Shards)
+	for idx := range tables {
+		tables[idx] = config.tableName(table, idx)
+	}
+	return tables
+}
+
+// ShardTable return the shard table of the logical table for the shard key's value
+func (sharding *Sharding) ShardTable(table string, value interface{}) (string, error) {
+	config, ok := sharding.Tables[table]
+	if !ok {
+		return table, nil
+	}
+
+	idx, err := config.shard(value)
+	if err != nil {
+		return "", err
+	}
+	return config.tableName(table, idx), nil
+}
+
+func (sharding *Sharding) routeCallback(scope *Scope) {
+	if !scope.HasError() {
+		sharding.route(scope)
+	}
+}
+
+func (sharding *Sharding) queryCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if _, skip := scope.InstanceGet("gorm:skip_query_callback"); skip {
+		return
+	}
+
+	table := scope.TableName()
+	config, ok := sharding.Tables[table]
+	if !ok {
+		return
+	}
+
+	if _, ok := shardKeyValue(scope, config.ShardKey, false); !ok && sharding.FanOut {
+		sharding.fanOut(scope, table)
+		return
+	}
+	sharding.route(scope)
+}
+
+// route change table of the statement to the shard, return error if the shard key is missing
+func (sharding *Sharding) route(scope *Scope) {
+	table := scope.TableName()
+	config, ok := sharding.Tables[table]
+	if !ok {
+		return
+	}
+
+	value, ok := shardKeyValue(scope, config.ShardKey, true)
+	if !ok {
+		scope.Err(fmt.Errorf("can't route %v to shard, missing shard key %v in conditions", table, config.ShardKey))
+		return
+	}
+
+	idx, err := config.shard(value)
+	if scope.Err(err) == nil {
+		scope.Search.Table(config.tableName(table, idx))
+		scope.InstanceSet("gorm:shard_id", idx)
+	}
+}
+
+// fanOut find records in all shards and merge them
+func (sharding *Sharding) fanOut(scope *Scope, table string) {
+	results := scope.IndirectValue()
+	if dest, ok := scope.Get("gorm:query_destination"); ok {
+		results = indirect(reflect.ValueOf(dest))
+	}
+
+	if results.Kind() != reflect.Slice {
+		scope.Err(fmt.Errorf("can't route %v to shard, only finding records could query all shards", table))
+		return
+	}
+
+	merged := reflect.MakeSlice(results.Type(), 0, 0)
+	for _, shardTable := range sharding.ShardTables(table) {
+		db := scope.NewDB()
+		db.search = scope.Search.clone()
+		db.search.db = db
+		db.search.Table(shardTable)
+
+		shardResults := reflect.New(results.Type())
+		if scope.Err(db.Find(shardResults.Interface()).Error) != nil {
+			return
+		}
+		merged = reflect.AppendSlice(merged, shardResults.Elem())
+	}
+
+	results.Set(merged)
+	scope.db.RowsAffected = int64(merged.Len())
+	scope.InstanceSet("gorm:skip_query_callback", true)
+}
+
+// shardKeyValue find value of the shard key in conditions, or in the record if it is saving or deleting
+func shardKeyValue(scope *Scope, key string, useRecord bool) (interface{}, bool) {
+	for _, condition := range scope.Search.whereConditions {
+		args, _ := condition["args"].([]interface{})
+		query := condition["query"]
+		if condition, ok := query.(structCondition); ok {
+			query = condition.value
+		}
+
+		switch query := query.(type) {
+		case string:
+			expr := strings.NewReplacer(" ", "", "`", "", `"`, "").Replace(query)
+			if len(args) == 1 && (expr == key+"=?" || strings.HasSuffix(expr, "."+key+"=?")) {
+				return args[0], true
+			}
+		case map[string]interface{}:
+			if value, ok := query[key]; ok {
+				return value, true
+			}
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			if key == scope.PrimaryKey() {
+				return query, true
+			}
+		default:
+			if reflect.Indirect(reflect.ValueOf(query)).Kind() == reflect.Struct {
+				if field, ok := scope.New(query).FieldByName(key); ok && !field.IsBlank {
+					return field.Field.Interface(), true
+				}
+			}
+		}
+	}
+
+	if useRecord && scope.IndirectValue().Kind() == reflect.Struct {
+		if field, ok := scope.FieldByName(key); ok && !field.IsBlank {
+			return field.Field.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func (config ShardConfig) tableName(table string, idx int) string {
+	if config.Pattern == "" {
+		return fmt.Sprintf("%v_%d", table, idx)
+	}
+	return fmt.Sprintf(config.Pattern, idx)
+}
+
+func (config ShardConfig) shard(value interface{}) (int, error) {
+	if config.ShardFunc != nil {
+		idx, err := config.ShardFunc(value)
+		if err == nil && (idx < 0 || idx >= config.Shards) {
+			err = fmt.Errorf("shard %v of %v is out of range", idx, value)
+		}
+		return idx, err
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		idx := reflectValue.Int() % int64(config.Shards)
+		if idx < 0 {
+			idx = -idx
+		}
+		return int(idx), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(reflectValue.Uint() % uint64(config.Shards)), nil
+	case reflect.Invalid:
+		return 0, fmt.Errorf("can't route nil shard key to shard")
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(toString(value)))
+	return int(hash.Sum32() % uint32(config.Shards)), nil
+}
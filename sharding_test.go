@@ -0,0 +1,79 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type ShardedOrder struct {
+	ID     uint
+	UserID uint
+	Amount int
+}
+
+func TestSharding(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	sharding := &gorm.Sharding{Tables: map[string]gorm.ShardConfig{
+		"sharded_orders": {ShardKey: "user_id", Shards: 2, Pattern: "sharded_orders_%02d"},
+	}}
+	if err := db.Use(sharding); err != nil {
+		t.Fatalf("Failed to use sharding, got %v", err)
+	}
+
+	for _, table := range sharding.ShardTables("sharded_orders") {
+		db.Table(table).DropTableIfExists(&ShardedOrder{})
+		if err := db.Table(table).AutoMigrate(&ShardedOrder{}).Error; err != nil {
+			t.Fatalf("Failed to migrate %v, got %v", table, err)
+		}
+	}
+
+	for _, userID := range []uint{1, 2, 3} {
+		if err := db.Create(&ShardedOrder{UserID: userID, Amount: int(userID) * 10}).Error; err != nil {
+			t.Errorf("Failed to create order in shard, got %v", err)
+		}
+	}
+
+	var count int
+	if db.Table("sharded_orders_01").Count(&count); count != 2 {
+		t.Errorf("Orders of user 1 and 3 should be created in shard 1, but got %v", count)
+	}
+
+	var orders []ShardedOrder
+	if err := db.Where("user_id = ?", 2).Find(&orders).Error; err != nil || len(orders) != 1 || orders[0].Amount != 20 {
+		t.Errorf("Should find orders in shard, but got %v, %+v", err, orders)
+	}
+
+	var order ShardedOrder
+	if err := db.Where(&ShardedOrder{UserID: 3}).First(&order).Error; err != nil || order.Amount != 30 {
+		t.Errorf("Should route struct conditions, but got %v, %+v", err, order)
+	}
+
+	order.Amount = 35
+	if err := db.Save(&order).Error; err != nil {
+		t.Errorf("Should update order in shard, but got %v", err)
+	}
+
+	if db.Model(&ShardedOrder{}).Where(map[string]interface{}{"user_id": 3, "amount": 35}).Count(&count); count != 1 {
+		t.Errorf("Should count orders in shard, but got %v", count)
+	}
+
+	if err := db.Where("amount > ?", 0).Find(&orders).Error; err == nil {
+		t.Errorf("Should return error if the query can't be routed")
+	}
+
+	sharding.FanOut = true
+	if err := db.Where("amount > ?", 15).Find(&orders).Error; err != nil || len(orders) != 2 {
+		t.Errorf("Should query all shards with fan out, but got %v, %+v", err, orders)
+	}
+
+	db.Delete(&order)
+	if db.Table("sharded_orders_01").Count(&count); count != 1 {
+		t.Errorf("Should delete order in shard, but got %v", count)
+	}
+}
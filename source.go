@@ -0,0 +1,80 @@
+package gorm
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// SourceStackDepth max frames above the caller searched for the first frame outside packages skipped with SkipSourcePackages
+var SourceStackDepth = 32
+
+var (
+	sourceSkipPackages []string
+	sourceSkipMutex    sync.RWMutex
+)
+
+// SkipSourcePackages skip frames of the packages when finding callers of statements for logs and traces,
+// register packages wrapping the db, e.g. repository layers, so sources point at application code calling them
+//    func init() {
+//      gorm.SkipSourcePackages("github.com/example/app/repository")
+//    }
+func SkipSourcePackages(packages ...string) {
+	sourceSkipMutex.Lock()
+	defer sourceSkipMutex.Unlock()
+	sourceSkipPackages = append(sourceSkipPackages, packages...)
+}
+
+// WithSource return a db logging and tracing its statements with the source instead of the caller,
+// the source is kept by WithContext, FromContext and so on
+//    db.WithSource("OrderService.Checkout").WithContext(ctx).Create(&order)
+func (s *DB) WithSource(source string) *DB {
+	clone := s.clone()
+	clone.db.source = xRayNameReplace(source)
+	clone.db.sourceFixed = true
+	return clone
+}
+
+// setSource set the caller as the source unless it is set with WithSource, skip frames like GetSource
+func (db *ctxDB) setSource(skip int) {
+	if !db.sourceFixed {
+		db.source = GetSource(skip + 1)
+	}
+}
+
+// callerFrame return the frame of the caller, frames of packages skipped with SkipSourcePackages are skipped
+func callerFrame(skip int) (runtime.Frame, bool) {
+	pcs := make([]uintptr, SourceStackDepth+1)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+1, pcs)])
+
+	var first runtime.Frame
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			break
+		}
+		if i == 0 {
+			first = frame
+		}
+		if !isSkippedSource(frame.Function) {
+			return frame, true
+		}
+		if !more {
+			break
+		}
+	}
+	// all frames are skipped, fall back to the caller
+	return first, first.PC != 0
+}
+
+func isSkippedSource(function string) bool {
+	sourceSkipMutex.RLock()
+	defer sourceSkipMutex.RUnlock()
+
+	for _, pkg := range sourceSkipPackages {
+		if strings.HasPrefix(function, pkg+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package gorm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type sourceRepository struct {
+	db *gorm.DB
+}
+
+func (repository sourceRepository) FindUser(ctx context.Context, name string) error {
+	return repository.db.WithContext(ctx).Where("name = ?", name).First(&User{}).Error
+}
+
+func TestSkipSourcePackages(t *testing.T) {
+	// methods of the type are matched like functions of a package
+	gorm.SkipSourcePackages("github.com/lun-zhang/gorm_test.sourceRepository")
+
+	var queryErr *gorm.QueryError
+	if err := (sourceRepository{db: DB.Table("missing_table")}).FindUser(context.Background(), "skip-source-packages"); !gorm.Errors([]error{err}).As(&queryErr) {
+		t.Fatalf("error should be wrapped, but got %v", err)
+	}
+	if !strings.Contains(queryErr.Source, "TestSkipSourcePackages") {
+		t.Errorf("source should skip the repository, but got %v", queryErr.Source)
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	var queryErr *gorm.QueryError
+	err := DB.Table("missing_table").WithSource("UserService.Find").WithContext(context.Background()).First(&User{}).Error
+	if !gorm.Errors([]error{err}).As(&queryErr) || queryErr.Source != "UserService.Find" {
+		t.Errorf("source should be overwritten, but got %v", err)
+	}
+}
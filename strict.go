@@ -0,0 +1,139 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	DefaultCallback.Create().Before("gorm:before_create").Register("gorm:strict", strictChangeCallback)
+	DefaultCallback.Update().Before("gorm:assign_updating_attributes").Register("gorm:strict", strictChangeCallback)
+	DefaultCallback.Delete().Before("gorm:before_delete").Register("gorm:strict", strictQueryCallback)
+	DefaultCallback.Query().Before("gorm:query").Register("gorm:strict", strictQueryCallback)
+}
+
+// Strict return a db validating names against fields of the model before running statements, statements fail with descriptive errors for:
+//    * keys of maps of Updates and Where, they should be fields or columns
+//    * names of Select and Omit when creating and updating, they should be fields, columns or paths of fields
+//    * columns of Order like `age desc` and `users.age`, they should be columns or aliases of Select, expressions and columns of other tables aren't validated
+//
+// Statements without models like `db.Table("users").Updates(map)` aren't validated
+//    db = db.Strict(true)
+//    db.Model(&user).Updates(map[string]interface{}{"nmae": "jinzhu"}) // error: strict mode: unknown field nmae of main.User in updates
+func (s *DB) Strict(enable bool) *DB {
+	return s.Set("gorm:strict", enable)
+}
+
+func (scope *Scope) isStrict() bool {
+	strict, ok := scope.Get("gorm:strict")
+	return ok && strict == true && scope.GetModelStruct().ModelType != nil
+}
+
+// strictChangeCallback validate keys of updating maps, names of Select and Omit, and names of queries when creating and updating records
+func strictChangeCallback(scope *Scope) {
+	if scope.HasError() || !scope.isStrict() {
+		return
+	}
+
+	if attrs, ok := scope.InstanceGet("gorm:update_interface"); ok {
+		for key := range convertInterfaceToMap(attrs, true, scope.db) {
+			if !scope.hasFieldOrPath(key) {
+				scope.Err(scope.strictError(key, "updates"))
+			}
+		}
+	}
+
+	for _, attr := range scope.SelectAttrs() {
+		if !scope.hasFieldOrPath(attr) {
+			scope.Err(scope.strictError(attr, "Select"))
+		}
+	}
+	for _, attr := range scope.OmitAttrs() {
+		if !scope.hasFieldOrPath(attr) {
+			scope.Err(scope.strictError(attr, "Omit"))
+		}
+	}
+	strictQueryCallback(scope)
+}
+
+// strictQueryCallback validate keys of map conditions and columns of orders
+func strictQueryCallback(scope *Scope) {
+	if scope.HasError() || !scope.isStrict() {
+		return
+	}
+
+	for _, conditions := range [][]map[string]interface{}{scope.Search.whereConditions, scope.Search.orConditions, scope.Search.notConditions} {
+		for _, clause := range conditions {
+			if values, ok := clause["query"].(map[string]interface{}); ok {
+				for key := range values {
+					if name, ok := scope.columnOfModel(key); ok && !scope.hasFieldOrPath(name) {
+						scope.Err(scope.strictError(key, "conditions"))
+					}
+				}
+			}
+		}
+	}
+
+	for _, order := range scope.Search.orders {
+		str, ok := order.(string)
+		if !ok {
+			continue
+		}
+		for _, item := range strings.Split(str, ",") {
+			matches := orderColumnRegexp.FindStringSubmatch(strings.TrimSpace(item))
+			if matches == nil {
+				continue
+			}
+			if name, ok := scope.columnOfModel(matches[1]); ok && !scope.hasFieldOrPath(name) && !scope.isSelectAlias(name) {
+				scope.Err(scope.strictError(matches[1], "Order"))
+			}
+		}
+	}
+}
+
+// orderColumnRegexp match orders of columns like `age`, `users.age desc` and `"users"."age" ASC`
+var orderColumnRegexp = regexp.MustCompile("(?i)^([`\"\\w]+(?:\\.[`\"\\w]+)?)(?:\\s+(?:asc|desc))?$")
+
+// columnOfModel return the unquoted column of the name, it returns false for columns of other tables
+func (scope *Scope) columnOfModel(name string) (string, bool) {
+	name = strings.NewReplacer("`", "", `"`, "").Replace(name)
+	if i := strings.Index(name, "."); i >= 0 {
+		if name[:i] != scope.TableName() {
+			return "", false
+		}
+		name = name[i+1:]
+	}
+	return name, true
+}
+
+// hasFieldOrPath return true if the name is a name, column or path of a field of the model
+func (scope *Scope) hasFieldOrPath(name string) bool {
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.Name == name || field.DBName == name || field.hasPath(name, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelectAlias return true if the name is an alias of selected expressions like `count(*) AS total`
+func (scope *Scope) isSelectAlias(name string) bool {
+	query, ok := scope.Search.selects["query"].(string)
+	if !ok {
+		return false
+	}
+	for _, matches := range selectAliasRegexp.FindAllStringSubmatch(query, -1) {
+		if strings.EqualFold(matches[1], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectAliasRegexp match aliases of selected expressions like `count(*) AS total`, `name as "user_name"`
+var selectAliasRegexp = regexp.MustCompile("(?i)\\sas\\s+[`\"]?(\\w+)[`\"]?\\s*(?:,|$)")
+
+func (scope *Scope) strictError(name, clause string) error {
+	return fmt.Errorf("strict mode: unknown field %v of %v in %v", name, scope.GetModelStruct().ModelType, clause)
+}
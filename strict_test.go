@@ -0,0 +1,68 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictMode(t *testing.T) {
+	user := User{Name: "strict", Age: 18}
+	DB.Save(&user)
+	db := DB.Strict(true)
+
+	if err := DB.Model(&user).Updates(map[string]interface{}{"nmae": "jinzhu"}).Error; err != nil && strings.Contains(err.Error(), "strict mode") {
+		t.Errorf("unknown keys shouldn't be validated without strict mode, got %v", err)
+	}
+
+	for name, fc := range map[string]func() error{
+		"updates": func() error {
+			return db.Model(&user).Updates(map[string]interface{}{"nmae": "jinzhu"}).Error
+		},
+		"update": func() error {
+			return db.Model(&user).Update("agee", 20).Error
+		},
+		"select": func() error {
+			return db.Model(&user).Select("nmae").Updates(map[string]interface{}{"name": "jinzhu"}).Error
+		},
+		"omit": func() error {
+			return db.Omit("Agee").Create(&User{Name: "strict"}).Error
+		},
+		"conditions": func() error {
+			return db.Where(map[string]interface{}{"nmae": "strict"}).Find(&[]User{}).Error
+		},
+		"order": func() error {
+			return db.Order("users.agee desc").Find(&[]User{}).Error
+		},
+	} {
+		if err := fc(); err == nil || !strings.HasPrefix(err.Error(), "strict mode: unknown field") {
+			t.Errorf("%v with unknown names should fail in strict mode, got %v", name, err)
+		}
+	}
+
+	var users []User
+	if err := db.Where(map[string]interface{}{"name": "strict", "Age": 18}).Order("age desc, \"users\".\"id\"").Order("RANDOM()").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("known columns should be valid in strict mode, got %v, %v", len(users), err)
+	}
+	if err := db.Model(&user).Select("Name", "age").Updates(map[string]interface{}{"name": "strict", "Age": 20}).Error; err != nil {
+		t.Errorf("known fields should be updated in strict mode, got %v", err)
+	}
+	if err := db.Table("users").Where("name = ?", "strict").Updates(map[string]interface{}{"age": 21}).Error; err != nil {
+		t.Errorf("statements without models shouldn't be validated, got %v", err)
+	}
+
+	var results []struct {
+		Name  string
+		Total int
+	}
+	if err := db.Model(&User{}).Select("name, count(*) AS total").Group("name").Order("total desc").Scan(&results).Error; err != nil {
+		t.Errorf("aliases of Select should be valid orders in strict mode, got %v", err)
+	}
+
+	if err := db.Model(&User{}).Select("count(*) AS total, max(age) AS oldest").Group("name").Order("oldest").Scan(&results).Error; err != nil {
+		t.Errorf("all aliases of Select should be valid orders in strict mode, got %v", err)
+	}
+
+	if err := db.Model(&User{}).Select("count(*) AS total").Group("name").Order("tot").Scan(&results).Error; err == nil {
+		t.Errorf("prefixes of aliases should not be valid orders in strict mode")
+	}
+}
@@ -0,0 +1,25 @@
+package gorm
+
+// structCondition struct condition including fields with zero values
+type structCondition struct {
+	value      interface{}
+	zeroFields []string
+}
+
+// StructConditionOption option of struct conditions built with WhereStruct
+type StructConditionOption func(condition *structCondition)
+
+// IncludeZeroFields include fields in struct conditions even if they are zero values, fields could be names or column names
+func IncludeZeroFields(fields ...string) StructConditionOption {
+	return func(condition *structCondition) {
+		condition.zeroFields = append(condition.zeroFields, fields...)
+	}
+}
+
+// includeZeroField return true if the field should be included in struct conditions when it is zero value
+func includeZeroField(field *Field, zeroFields []string) bool {
+	if _, ok := field.TagSettingsGet("INCLUDE_ZERO"); ok {
+		return true
+	}
+	return strInSlice(field.Name, zeroFields) || strInSlice(field.DBName, zeroFields)
+}
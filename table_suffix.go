@@ -0,0 +1,29 @@
+package gorm
+
+import "context"
+
+// TableSuffixFunc set the function computing suffixes of model tables for each statement, e.g. for tables partitioned by months,
+// it is called with the value of the statement, like the record being created or the pointer of the slice being found, and the db's context.
+// Tables of models, preloads, counts and migrations are suffixed, table names set with Table are used as they are
+//    db.TableSuffixFunc(func(model interface{}, ctx context.Context) string {
+//      switch model.(type) {
+//      case *Log, *[]Log:
+//        return "_" + monthFromContext(ctx)
+//      }
+//      return ""
+//    })
+//
+//    db.WithContext(ctx).Create(&Log{Message: "hello"})
+//    // INSERT INTO "logs_202501" ...
+func (s *DB) TableSuffixFunc(fc func(model interface{}, ctx context.Context) string) *DB {
+	s.parent.tableSuffixFunc = fc
+	return s
+}
+
+// suffixedTableName append the suffix of the statement to the table name
+func (scope *Scope) suffixedTableName(tableName string) string {
+	if scope.db == nil || scope.db.parent == nil || scope.db.parent.tableSuffixFunc == nil || tableName == "" {
+		return tableName
+	}
+	return tableName + scope.db.parent.tableSuffixFunc(scope.Value, scope.Context())
+}
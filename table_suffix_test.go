@@ -0,0 +1,78 @@
+package gorm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type partitionMonthKey struct{}
+
+type PartitionedLogger struct {
+	Id   int64
+	Name string
+	Logs []PartitionedLog
+}
+
+type PartitionedLog struct {
+	Id                  int64
+	PartitionedLoggerId int64
+	Message             string
+}
+
+func TestTableSuffixFunc(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+
+	db.TableSuffixFunc(func(model interface{}, ctx context.Context) string {
+		switch model.(type) {
+		case *PartitionedLog, *[]PartitionedLog:
+			if month, ok := ctx.Value(partitionMonthKey{}).(string); ok {
+				return "_" + month
+			}
+		}
+		return ""
+	})
+
+	january := db.WithContext(context.WithValue(context.Background(), partitionMonthKey{}, "202501"))
+	february := db.WithContext(context.WithValue(context.Background(), partitionMonthKey{}, "202502"))
+
+	db.DropTableIfExists(&PartitionedLogger{}, "partitioned_logs_202501", "partitioned_logs_202502")
+	if err := db.AutoMigrate(&PartitionedLogger{}).Error; err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+	for _, monthDB := range []*gorm.DB{january, february} {
+		if err := monthDB.AutoMigrate(&PartitionedLog{}).Error; err != nil {
+			t.Fatalf("failed to migrate, got %v", err)
+		}
+	}
+
+	if !db.HasTable("partitioned_loggers") || !db.HasTable("partitioned_logs_202501") || !db.HasTable("partitioned_logs_202502") {
+		t.Fatalf("tables should be migrated with suffixes of their months")
+	}
+
+	logger := PartitionedLogger{Name: "suffix"}
+	db.Save(&logger)
+	january.Create(&PartitionedLog{PartitionedLoggerId: logger.Id, Message: "january"})
+	february.Create(&PartitionedLog{PartitionedLoggerId: logger.Id, Message: "february 1"})
+	february.Create(&PartitionedLog{PartitionedLoggerId: logger.Id, Message: "february 2"})
+
+	var count int
+	if february.Model(&PartitionedLog{}).Count(&count); count != 2 {
+		t.Errorf("logs of february should be counted, but got %v", count)
+	}
+
+	var found PartitionedLogger
+	if err := january.Preload("Logs").First(&found, logger.Id).Error; err != nil || len(found.Logs) != 1 || found.Logs[0].Message != "january" {
+		t.Errorf("logs of january should be preloaded, but got %v, %v", found.Logs, err)
+	}
+
+	var logs []PartitionedLog
+	if february.Where("partitioned_logger_id = ?", logger.Id).Find(&logs); len(logs) != 2 {
+		t.Errorf("logs of february should be found, but got %v", logs)
+	}
+}
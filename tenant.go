@@ -0,0 +1,118 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var tenantIDRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+type tenantKey struct{}
+
+// TenantResolver resolves table names of tenants, so tenants' data could be saved into different tables or schemas
+type TenantResolver interface {
+	// TenantTableName return the table name of the tenant
+	TenantTableName(tenantID, tableName string) string
+}
+
+// TenantResolverFunc function used as TenantResolver
+type TenantResolverFunc func(tenantID, tableName string) string
+
+// TenantTableName call the function
+func (f TenantResolverFunc) TenantTableName(tenantID, tableName string) string {
+	return f(tenantID, tableName)
+}
+
+// TablePrefixTenantResolver save tenants' data into tables prefixed with tenant id, like `tenant1_users`, it is the default resolver
+type TablePrefixTenantResolver struct {
+	// Separator between tenant id and table name, defaults to `_`
+	Separator string
+}
+
+// TenantTableName return table name prefixed with tenant id
+func (resolver TablePrefixTenantResolver) TenantTableName(tenantID, tableName string) string {
+	separator := resolver.Separator
+	if separator == "" {
+		separator = "_"
+	}
+	return tenantID + separator + tableName
+}
+
+// SchemaTenantResolver save tenants' data into a schema per tenant, like `tenant1.users`,
+// it is the schema in postgres and mssql, the database in mysql, schemas should be created before migrating tables
+type SchemaTenantResolver struct {
+	// Prefix of schema names, e.g. `tenant_`
+	Prefix string
+}
+
+// TenantTableName return table name qualified with tenant's schema
+func (resolver SchemaTenantResolver) TenantTableName(tenantID, tableName string) string {
+	return resolver.Prefix + tenantID + "." + tableName
+}
+
+// SetTenantResolver set how to resolve table names of tenants, defaults to TablePrefixTenantResolver
+//    db.SetTenantResolver(gorm.SchemaTenantResolver{Prefix: "tenant_"})
+func (s *DB) SetTenantResolver(resolver TenantResolver) *DB {
+	s.parent.tenantResolver = resolver
+	return s
+}
+
+// ForTenant return a db working on the tenant's tables, table names of models, preloads, many2many join tables
+// and migrations are resolved with TenantResolver, e.g:
+//    tenantDB := db.ForTenant(ctx, "tenant1")
+//    tenantDB.AutoMigrate(&User{})
+//    tenantDB.Preload("Orders").Find(&users)
+//    // SELECT * FROM "tenant1_users";
+//    // SELECT * FROM "tenant1_orders" WHERE "user_id" IN (1,2,3);
+//
+// Table names set with Table and tables in SQL are used as they are, the tenant id is carried by the context too, get it with TenantFromContext.
+// Index names set in tags are not prefixed, they may conflict between tenants sharing a schema in databases like postgres and sqlite
+func (s *DB) ForTenant(ctx context.Context, tenantID string) *DB {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	clone := s.clone()
+	clone.db.ctx = WithTenant(ctx, tenantID)
+	clone.db.setSource(2)
+	if !tenantIDRegexp.MatchString(tenantID) {
+		clone.AddError(fmt.Errorf("invalid tenant id %q", tenantID))
+		return clone
+	}
+	return clone.InstantSet("gorm:tenant_id", tenantID)
+}
+
+// WithTenant return a context carrying the tenant id, tables are not resolved for the tenant unless using ForTenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext return the tenant id set with WithTenant or ForTenant
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// tenantTableName resolve table name of current tenant
+func tenantTableName(db *DB, tableName string) string {
+	if db == nil {
+		return tableName
+	}
+
+	// only resolve tables of tenants set with ForTenant, the context may carry tenant id for shared tables
+	value, ok := db.Get("gorm:tenant_id")
+	if !ok {
+		return tableName
+	}
+	tenantID := value.(string)
+
+	var resolver TenantResolver = TablePrefixTenantResolver{}
+	if db.parent != nil && db.parent.tenantResolver != nil {
+		resolver = db.parent.tenantResolver
+	}
+	return resolver.TenantTableName(tenantID, tableName)
+}
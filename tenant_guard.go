@@ -0,0 +1,321 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// TenantGuard plugin guards tables shared by tenants, models with a field tagged `tenant` are scoped to the tenant
+// carried by the context, it appends `tenant_id = ?` to every SELECT, UPDATE and DELETE of them, and fills the field when creating
+//    type Order struct {
+//      ID       uint
+//      TenantID string `gorm:"tenant"`
+//    }
+//
+//    db.Use(&gorm.TenantGuard{})
+//    db.WithContext(gorm.WithTenant(ctx, "tenant1")).Find(&orders)
+//    // SELECT * FROM "orders" WHERE ("orders"."tenant_id" = 'tenant1')
+//
+// Statements of tenant scoped models without tenant in the context are refused with ErrMissingTenant, so are raw SQL queries whose
+// WHERE clause doesn't require the tenant column to equal the tenant, e.g. `WHERE tenant_id = ? AND ...`, and changes moving records to other tenants, use `db.Set("gorm:skip_tenant_guard", true)` to query across tenants.
+// SQL executed with Exec and tables queried without models are not guarded
+type TenantGuard struct{}
+
+// Name return plugin name
+func (guard *TenantGuard) Name() string {
+	return "gorm:tenant_guard"
+}
+
+// Initialize register callbacks to guard tenant scoped models
+func (guard *TenantGuard) Initialize(db *DB) error {
+	callback := db.Callback()
+	callback.Create().Before("gorm:create").Register("gorm:tenant_guard", tenantGuardCreateCallback)
+	// guard queries before they are batched or cached
+	callback.Query().Before("gorm:batch_load").Register("gorm:tenant_guard", tenantGuardQueryCallback)
+	callback.RowQuery().Before("gorm:row_query").Register("gorm:tenant_guard", tenantGuardQueryCallback)
+	callback.Update().Before("gorm:update").Register("gorm:tenant_guard", tenantGuardUpdateCallback)
+	callback.Delete().Before("gorm:delete").Register("gorm:tenant_guard", tenantGuardQueryCallback)
+	return nil
+}
+
+// tenantField return the field tagged with `tenant` and the tenant of the context if the scope need to be guarded
+func tenantField(scope *Scope) (*StructField, interface{}, bool) {
+	model := scope
+	if dest, ok := scope.Get("gorm:query_destination"); ok && scope.Value == nil {
+		// scanning raw SQL or tables into models
+		model = scope.New(dest)
+	}
+
+	if scope.HasError() || model.Value == nil {
+		return nil, nil, false
+	}
+
+	if skip, ok := scope.Get("gorm:skip_tenant_guard"); ok && skip == true {
+		return nil, nil, false
+	}
+
+	var field *StructField
+	for _, structField := range model.GetModelStruct().StructFields {
+		if _, ok := structField.TagSettingsGet("TENANT"); ok {
+			field = structField
+			break
+		}
+	}
+	if field == nil {
+		return nil, nil, false
+	}
+
+	tenantID, ok := TenantFromContext(scope.Context())
+	if !ok {
+		scope.Err(ErrMissingTenant)
+		return nil, nil, false
+	}
+
+	// convert tenant id to type of the field, e.g. uint
+	tenant := reflect.New(indirectType(field.Struct.Type))
+	if _, err := fmt.Sscan(tenantID, tenant.Interface()); err != nil {
+		scope.Err(fmt.Errorf("invalid tenant %v for %v, %v", tenantID, field.Name, err))
+		return nil, nil, false
+	}
+	return field, tenant.Elem().Interface(), true
+}
+
+// tenantGuardQueryCallback append tenant condition to querying and deleting
+func tenantGuardQueryCallback(scope *Scope) {
+	field, tenant, ok := tenantField(scope)
+	if !ok {
+		return
+	}
+
+	if scope.Search.raw {
+		// raw SQL can't be changed, refuse it if it doesn't filter the tenant
+		if len(scope.Search.whereConditions) > 0 {
+			condition := scope.Search.whereConditions[0]
+			if sql, ok := condition["query"].(string); ok {
+				args, _ := condition["args"].([]interface{})
+				if rawTenantCondition(sql, args, field.DBName, tenant) {
+					return
+				}
+			}
+		}
+		scope.Err(fmt.Errorf("%v: raw SQL should filter %v", ErrMissingTenant, field.DBName))
+		return
+	}
+
+	scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)), tenant)
+}
+
+// rawTenantCondition return true if the WHERE clause of the outermost query requires the column to equal the tenant,
+// e.g. `WHERE tenant_id = ? AND ...` with the tenant as its argument, the condition can't be negated or combined with OR,
+// and the query can't be combined with others by UNION
+func rawTenantCondition(query string, args []interface{}, column string, tenant interface{}) bool {
+	var (
+		tokens           = sqlTokens(query)
+		depth, vars      int
+		inWhere, guarded bool
+	)
+	for idx, token := range tokens {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "?":
+			vars++
+		}
+		if depth != 0 {
+			continue
+		}
+
+		switch strings.ToUpper(token) {
+		case "WHERE":
+			inWhere = true
+		case "GROUP", "HAVING", "ORDER", "LIMIT", "WINDOW", "FOR", "RETURNING":
+			inWhere = false
+		case "UNION", "INTERSECT", "EXCEPT":
+			return false
+		case "OR":
+			if inWhere {
+				return false
+			}
+		}
+
+		if !inWhere || !strings.EqualFold(token, column) || idx+2 >= len(tokens) || tokens[idx+1] != "=" {
+			continue
+		}
+		start := idx
+		if idx >= 2 && tokens[idx-1] == "." {
+			// qualified column, e.g. orders.tenant_id
+			start = idx - 2
+		}
+		if start > 0 && strings.EqualFold(tokens[start-1], "NOT") {
+			return false
+		}
+
+		var value interface{}
+		switch operand := tokens[idx+2]; {
+		case operand == "?":
+			if vars >= len(args) {
+				return false
+			}
+			value = args[vars]
+		case strings.HasPrefix(operand, "$"):
+			var position int
+			if _, err := fmt.Sscan(operand[1:], &position); err != nil || position < 1 || position > len(args) {
+				return false
+			}
+			value = args[position-1]
+		case strings.HasPrefix(operand, "'"):
+			value = strings.Replace(strings.Trim(operand, "'"), "''", "'", -1)
+		default:
+			value = operand
+		}
+		if !equalAsString(value, tenant) {
+			return false
+		}
+		guarded = true
+	}
+	return guarded
+}
+
+// sqlTokens split the SQL into words, quoted identifiers without quotes, string literals with quotes, and other characters,
+// comments are skipped
+func sqlTokens(query string) (tokens []string) {
+	var (
+		runes = []rune(query)
+		word  []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case r == '-' && idx+1 < len(runes) && runes[idx+1] == '-':
+			flush()
+			for idx < len(runes) && runes[idx] != '\n' {
+				idx++
+			}
+		case r == '/' && idx+1 < len(runes) && runes[idx+1] == '*':
+			flush()
+			for idx += 2; idx < len(runes) && !(runes[idx] == '*' && idx+1 < len(runes) && runes[idx+1] == '/'); idx++ {
+			}
+			idx++
+		case r == '\'' || r == '"' || r == '`':
+			flush()
+			start := idx
+			for idx++; idx < len(runes); idx++ {
+				if runes[idx] == r {
+					// doubled quotes are escaped quotes
+					if idx+1 < len(runes) && runes[idx+1] == r {
+						idx++
+						continue
+					}
+					break
+				}
+			}
+			end := idx + 1
+			if end > len(runes) {
+				end = len(runes)
+			}
+			if token := string(runes[start:end]); r == '\'' {
+				tokens = append(tokens, token)
+			} else {
+				tokens = append(tokens, strings.Trim(token, string(r)))
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$':
+			word = append(word, r)
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens = append(tokens, string(r))
+		}
+	}
+	flush()
+	return tokens
+}
+
+// tenantGuardCreateCallback fill tenant of records, and refuse creating records of other tenants
+func tenantGuardCreateCallback(scope *Scope) {
+	if field, tenant, ok := tenantField(scope); ok {
+		fillTenant(scope, field, tenant)
+	}
+}
+
+// tenantGuardUpdateCallback append tenant condition to updating, and refuse moving records to other tenants
+func tenantGuardUpdateCallback(scope *Scope) {
+	field, tenant, ok := tenantField(scope)
+	if !ok {
+		return
+	}
+
+	if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+		if value, ok := attrs.(map[string]interface{})[field.DBName]; ok && !equalAsString(value, tenant) {
+			scope.Err(fmt.Errorf("can't change %v of %v to another tenant", field.DBName, scope.TableName()))
+			return
+		}
+	} else if !fillTenant(scope, field, tenant) {
+		return
+	}
+
+	scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)), tenant)
+}
+
+// fillTenant set tenant of records if it is blank, records could be a struct, maps or slices of them,
+// return false if any of them belongs to another tenant
+func fillTenant(scope *Scope, structField *StructField, tenant interface{}) bool {
+	switch value := scope.IndirectValue(); value.Kind() {
+	case reflect.Struct:
+		field, ok := scope.FieldByName(structField.Name)
+		if !ok {
+			return true
+		}
+
+		if field.IsBlank {
+			scope.Err(field.Set(tenant))
+		} else if !equalAsString(field.Field.Interface(), tenant) {
+			scope.Err(fmt.Errorf("can't save %v of another tenant %v", scope.TableName(), field.Field.Interface()))
+			return false
+		}
+	case reflect.Map:
+		attrs, ok := value.Interface().(map[string]interface{})
+		if !ok {
+			return true
+		}
+
+		key := structField.DBName
+		if _, ok := attrs[key]; !ok {
+			if _, ok := attrs[structField.Name]; ok {
+				key = structField.Name
+			}
+		}
+
+		if current, ok := attrs[key]; !ok || current == nil || isBlank(reflect.ValueOf(current)) {
+			attrs[key] = tenant
+		} else if !equalAsString(current, tenant) {
+			scope.Err(fmt.Errorf("can't save %v of another tenant %v", scope.TableName(), current))
+			return false
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			record := value.Index(i)
+			if record.Kind() != reflect.Ptr && record.Kind() != reflect.Map && record.CanAddr() {
+				record = record.Addr()
+			}
+
+			recordScope := scope.New(record.Interface())
+			filled := fillTenant(recordScope, structField, tenant)
+			if scope.Err(recordScope.db.Error) != nil || !filled {
+				return false
+			}
+		}
+	}
+	return !scope.HasError()
+}
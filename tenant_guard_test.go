@@ -0,0 +1,108 @@
+package gorm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type GuardedOrder struct {
+	ID       uint
+	TenantID string `gorm:"tenant"`
+	Amount   int
+}
+
+func TestTenantGuard(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&GuardedOrder{})
+	db.AutoMigrate(&GuardedOrder{})
+	if err := db.Use(&gorm.TenantGuard{}); err != nil {
+		t.Fatalf("Failed to use tenant guard, got %v", err)
+	}
+
+	tenant1 := db.WithContext(gorm.WithTenant(context.Background(), "tenant1"))
+	tenant2 := db.WithContext(gorm.WithTenant(context.Background(), "tenant2"))
+
+	order := GuardedOrder{Amount: 10}
+	if err := tenant1.Create(&order).Error; err != nil || order.TenantID != "tenant1" {
+		t.Errorf("Should fill tenant when creating, but got %v, %v", err, order.TenantID)
+	}
+	tenant2.Create(&GuardedOrder{Amount: 20})
+
+	if err := tenant2.Create(&GuardedOrder{TenantID: "tenant1"}).Error; err == nil {
+		t.Errorf("Should refuse creating records of other tenants")
+	}
+
+	var orders []GuardedOrder
+	if tenant1.Find(&orders); len(orders) != 1 || orders[0].Amount != 10 {
+		t.Errorf("Should only find records of the tenant, but got %+v", orders)
+	}
+
+	var count int
+	if tenant2.Model(&GuardedOrder{}).Count(&count); count != 1 {
+		t.Errorf("Should only count records of the tenant, but got %v", count)
+	}
+
+	if err := tenant2.First(&GuardedOrder{}, order.ID).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("Should not find records of other tenants, but got %v", err)
+	}
+
+	if affected := tenant2.Model(&order).Update("amount", 30).RowsAffected; affected != 0 {
+		t.Errorf("Should not update records of other tenants, but updated %v", affected)
+	}
+
+	if err := tenant1.Model(&order).Update("tenant_id", "tenant2").Error; err == nil {
+		t.Errorf("Should refuse moving records to other tenants")
+	}
+
+	if affected := tenant2.Delete(&order).RowsAffected; affected != 0 {
+		t.Errorf("Should not delete records of other tenants, but deleted %v", affected)
+	}
+
+	if err := db.Find(&orders).Error; err != gorm.ErrMissingTenant {
+		t.Errorf("Should refuse querying without tenant, but got %v", err)
+	}
+
+	if err := tenant1.Raw("SELECT * FROM guarded_orders").Scan(&orders).Error; err == nil || !strings.Contains(err.Error(), "tenant_id") {
+		t.Errorf("Should refuse raw SQL without tenant condition, but got %v", err)
+	}
+
+	if err := tenant1.Raw("SELECT * FROM guarded_orders WHERE tenant_id = ?", "tenant1").Scan(&orders).Error; err != nil || len(orders) != 1 {
+		t.Errorf("Should run raw SQL with tenant condition, but got %v, %v", err, len(orders))
+	}
+
+	if err := tenant1.Raw(`SELECT * FROM guarded_orders AS o WHERE amount > ? AND "o"."tenant_id" = 'tenant1'`, 0).Scan(&orders).Error; err != nil || len(orders) != 1 {
+		t.Errorf("Should run raw SQL with qualified tenant condition, but got %v, %v", err, len(orders))
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM guarded_orders /* tenant_id = ? */ WHERE amount > 0",
+		"SELECT * FROM guarded_orders WHERE tenant_id = ? OR 1 = 1",
+		"SELECT * FROM guarded_orders WHERE amount IN (SELECT amount FROM guarded_orders WHERE tenant_id = ?)",
+		"SELECT * FROM guarded_orders WHERE tenant_id = ? UNION SELECT * FROM guarded_orders",
+		"SELECT * FROM guarded_orders WHERE NOT tenant_id = ?",
+	} {
+		if err := tenant1.Raw(sql, "tenant1").Scan(&orders).Error; err == nil {
+			t.Errorf("Should refuse raw SQL not filtering the tenant, %v", sql)
+		}
+	}
+
+	if err := tenant1.Raw("SELECT * FROM guarded_orders WHERE tenant_id = ?", "tenant2").Scan(&orders).Error; err == nil {
+		t.Errorf("Should refuse raw SQL filtering other tenants")
+	}
+
+	if err := tenant2.Create(&[]GuardedOrder{{Amount: 40}, {TenantID: "tenant1"}}).Error; err == nil || !strings.Contains(err.Error(), "another tenant") {
+		t.Errorf("Should refuse creating slices with records of other tenants, but got %v", err)
+	}
+
+	if db.Set("gorm:skip_tenant_guard", true).Find(&orders); len(orders) != 2 {
+		t.Errorf("Should query across tenants when skipping the guard, but got %v", len(orders))
+	}
+}
@@ -0,0 +1,69 @@
+package gorm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type TenantAccount struct {
+	ID    uint
+	Name  string
+	Notes []TenantNote
+}
+
+type TenantNote struct {
+	ID              uint
+	TenantAccountID uint
+	Body            string
+}
+
+func TestForTenant(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, tenantID := range []string{"tenant1", "tenant2"} {
+		tenantDB := db.ForTenant(ctx, tenantID)
+		tenantDB.DropTableIfExists(&TenantAccount{}, &TenantNote{})
+		if err := tenantDB.AutoMigrate(&TenantAccount{}, &TenantNote{}).Error; err != nil {
+			t.Fatalf("Failed to migrate tables of %v, got %v", tenantID, err)
+		}
+
+		if !db.HasTable(tenantID+"_tenant_accounts") || !db.HasTable(tenantID+"_tenant_notes") {
+			t.Errorf("Should create tables prefixed with %v", tenantID)
+		}
+
+		tenantDB.Create(&TenantAccount{Name: tenantID, Notes: []TenantNote{{Body: tenantID + " note"}}})
+	}
+
+	var accounts []TenantAccount
+	if err := db.ForTenant(ctx, "tenant2").Preload("Notes").Find(&accounts).Error; err != nil {
+		t.Fatalf("Failed to query tenant's records, got %v", err)
+	}
+
+	if len(accounts) != 1 || accounts[0].Name != "tenant2" || len(accounts[0].Notes) != 1 || accounts[0].Notes[0].Body != "tenant2 note" {
+		t.Errorf("Should only query records of the tenant, but got %+v", accounts)
+	}
+
+	if db.HasTable(&TenantAccount{}) {
+		t.Errorf("Should not use tables of tenants without tenant")
+	}
+
+	if err := db.ForTenant(ctx, "tenant1\" OR 1=1").Find(&accounts).Error; err == nil {
+		t.Errorf("Should refuse invalid tenant id")
+	}
+
+	collector := &sqlCollector{}
+	db.SetTenantResolver(gorm.SchemaTenantResolver{Prefix: "tenant_"})
+	db.SetLogger(collector)
+	db.LogMode(true).ForTenant(ctx, "tenant1").Find(&accounts)
+	if len(collector.sqls) == 0 || !strings.Contains(collector.sqls[0], db.NewScope(nil).Quote("tenant_tenant1.tenant_accounts")) {
+		t.Errorf("Should query tables in tenant's schema, but got %v", collector.sqls)
+	}
+}
@@ -0,0 +1,88 @@
+package gorm
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SetTimeLocation set the location of time values stored in database, for databases storing time without time zone
+// and drivers reading and writing them as UTC, e.g. mysql without the `loc` parameter.
+// Time values are written as the wall clock in the location, scanned time values in UTC are read as the wall clock in the location,
+// and CreatedAt, UpdatedAt are stamped in the location, values scanned with Row, Rows are not converted
+//    loc, _ := time.LoadLocation("Asia/Shanghai")
+//    db.SetTimeLocation(loc)
+//    db.Create(&user) // created_at is saved as the time of Shanghai
+func (s *DB) SetTimeLocation(loc *time.Location) *DB {
+	s.parent.location = loc
+	return s
+}
+
+// timeLocation return the location set with SetTimeLocation
+func (s *DB) timeLocation() *time.Location {
+	if s == nil || s.parent == nil {
+		return nil
+	}
+	return s.parent.location
+}
+
+// driverLocation return the location the driver of the dialect formats times in, it is the `loc` parameter of mysql data source names,
+// and UTC for other drivers or db opened without data source names
+func driverLocation(dialect, source string) *time.Location {
+	if dialect == "mysql" || dialect == "tidb" {
+		if i := strings.LastIndex(source, "?"); i >= 0 {
+			if params, err := url.ParseQuery(source[i+1:]); err == nil && params.Get("loc") != "" {
+				if loc, err := time.LoadLocation(params.Get("loc")); err == nil {
+					return loc
+				}
+			}
+		}
+	}
+	return time.UTC
+}
+
+// databaseTime convert time.Time, *time.Time values to the wall clock in the location, other values are returned as they are
+func databaseTime(loc *time.Location, value interface{}) interface{} {
+	if loc == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return wallClock(v.In(loc), time.UTC)
+	case *time.Time:
+		if v != nil {
+			t := wallClock(v.In(loc), time.UTC)
+			return &t
+		}
+	}
+	return value
+}
+
+// scannedTime convert scanned time.Time, *time.Time fields to the location, wall clocks of UTC time are read as they are in the location
+func scannedTime(loc *time.Location, field reflect.Value) {
+	if loc == nil {
+		return
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+
+	if t, ok := field.Interface().(time.Time); ok && field.CanSet() && !t.IsZero() {
+		if t.Location() == time.UTC {
+			field.Set(reflect.ValueOf(wallClock(t, loc)))
+		} else {
+			field.Set(reflect.ValueOf(t.In(loc)))
+		}
+	}
+}
+
+// wallClock return time with the same wall clock in the location
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
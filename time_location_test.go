@@ -0,0 +1,65 @@
+package gorm_test
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type LocatedEvent struct {
+	ID        uint
+	Name      string
+	StartAt   time.Time
+	EndAt     *time.Time
+	CreatedAt time.Time
+}
+
+func TestTimeLocation(t *testing.T) {
+	if dialect := os.Getenv("GORM_DIALECT"); dialect != "" && dialect != "sqlite" {
+		t.Skip("time location is tested with sqlite driver reading and writing time as UTC")
+	}
+
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	db.SetTimeLocation(loc)
+	db.DropTableIfExists(&LocatedEvent{})
+	db.AutoMigrate(&LocatedEvent{})
+
+	startAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	endAt := startAt.Add(time.Hour)
+	event := LocatedEvent{Name: "located", StartAt: startAt, EndAt: &endAt}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("Failed to create event, got %v", err)
+	}
+
+	if event.CreatedAt.Location() != loc {
+		t.Errorf("CreatedAt should be stamped in the location, but got %v", event.CreatedAt.Location())
+	}
+
+	var stored time.Time
+	db.Table("located_events").Select("start_at").Where("id = ?", event.ID).Row().Scan(&stored)
+	if stored.Hour() != 8 {
+		t.Errorf("Time should be saved as the wall clock in the location, but got %v", stored)
+	}
+
+	var result LocatedEvent
+	db.First(&result, event.ID)
+	if !result.StartAt.Equal(startAt) || result.StartAt.Location() != loc {
+		t.Errorf("Time should be scanned in the location, but got %v", result.StartAt)
+	}
+
+	if result.EndAt == nil || !result.EndAt.Equal(endAt) || result.EndAt.Location() != loc {
+		t.Errorf("Time pointer should be scanned in the location, but got %v", result.EndAt)
+	}
+
+	var count int
+	db.Model(&LocatedEvent{}).Where("start_at = ?", startAt).Count(&count)
+	if count != 1 {
+		t.Errorf("Time of conditions should be converted to the location, but got %v records", count)
+	}
+}
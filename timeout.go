@@ -0,0 +1,49 @@
+package gorm
+
+import (
+	"context"
+	"time"
+)
+
+// Timeout cancel statements running longer than timeout, ErrQueryTimeout will be returned, e.g:
+//    db.Timeout(3 * time.Second).Find(&users)
+//
+// It requires the driver supporting context, zero timeout means no timeout.
+// Contexts of `Row` and `Rows` are released when the timeout expires, as their results are read by the caller
+func (s *DB) Timeout(timeout time.Duration) *DB {
+	clone := s.clone()
+	clone.db.timeout = timeout
+	return clone
+}
+
+// SetDefaultTimeout set default timeout of statements, it could be overwritten with Timeout,
+// DBs cloned before won't use it
+func (s *DB) SetDefaultTimeout(timeout time.Duration) *DB {
+	s.parent.db.timeout = timeout
+	s.db.timeout = timeout
+	return s
+}
+
+// statementContext return context with timeout to execute statements
+func (db ctxDB) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, db.timeout)
+}
+
+// deferCancel cancel the context after the scope executing the statement has read its results,
+// statements executed without scope release their contexts after the timeout
+func (db ctxDB) deferCancel(cancel context.CancelFunc) {
+	if db.scope != nil {
+		db.scope.cancels = append(db.scope.cancels, cancel)
+	}
+}
+
+// timeoutError return ErrQueryTimeout if the statement is cancelled because of timeout
+func (db ctxDB) timeoutError(ctx context.Context, err error) error {
+	if err != nil && db.timeout > 0 && (err == context.DeadlineExceeded || ctx != nil && ctx.Err() == context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+	return err
+}
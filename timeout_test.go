@@ -0,0 +1,82 @@
+package gorm_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lun-zhang/gorm"
+)
+
+func TestTimeout(t *testing.T) {
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" {
+		t.Skip("slow statement is written for sqlite")
+	}
+
+	slowCondition := "(WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 100000000) SELECT count(*) FROM c) > 0"
+	DB.Save(&User{Name: "timeout"})
+
+	if err := DB.Timeout(20*time.Millisecond).Exec("UPDATE users SET age = 1 WHERE name = ? AND "+slowCondition, "timeout").Error; err != gorm.ErrQueryTimeout {
+		t.Errorf("Should get timeout error when executing, but got %v", err)
+	}
+
+	var count int
+	if err := DB.Timeout(20 * time.Millisecond).Model(&User{}).Where(slowCondition).Count(&count).Error; err != gorm.ErrQueryTimeout {
+		t.Errorf("Should get timeout error when querying row, but got %v", err)
+	}
+
+	var users []User
+	if err := DB.Timeout(20 * time.Millisecond).Where(slowCondition).Find(&users).Error; err != gorm.ErrQueryTimeout {
+		t.Errorf("Should get timeout error when querying, but got %v", err)
+	}
+
+	if err := DB.Timeout(time.Minute).Where("name = ?", "timeout").Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Should not timeout for fast statements, but got %v", err)
+	}
+}
+
+type contextRecordingDB struct {
+	*sql.DB
+	contexts []context.Context
+}
+
+func (db *contextRecordingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.contexts = append(db.contexts, ctx)
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *contextRecordingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	db.contexts = append(db.contexts, ctx)
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestTimeoutReleasesContexts(t *testing.T) {
+	recorder := &contextRecordingDB{DB: DB.DB()}
+	db, err := gorm.Open(DB.Dialect().GetName(), recorder)
+	if err != nil {
+		t.Fatalf("Should wrap the existing DB connection, but got %v", err)
+	}
+	db = db.Timeout(time.Minute)
+
+	DB.Save(&User{Name: "timeout_release"})
+	var (
+		users []User
+		count int
+		names []string
+	)
+	db.Where("name = ?", "timeout_release").Find(&users)
+	db.Model(&User{}).Where("name = ?", "timeout_release").Count(&count)
+	db.Model(&User{}).Where("name = ?", "timeout_release").Pluck("name", &names)
+	var user User
+	db.Model(&User{}).Where("name = ?", "timeout_release").Iterate(&user, func() error { return nil })
+
+	if len(recorder.contexts) != 4 {
+		t.Fatalf("Should query with 4 contexts, but got %v", len(recorder.contexts))
+	}
+	for idx, ctx := range recorder.contexts {
+		if ctx.Err() != context.Canceled {
+			t.Errorf("Context #%v should be canceled after reading results, but got %v", idx, ctx.Err())
+		}
+	}
+}
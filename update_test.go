@@ -1,6 +1,7 @@
 package gorm_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -76,6 +77,69 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestSaveUpsert(t *testing.T) {
+	if dialect := DB.Dialect().GetName(); dialect != "sqlite3" && dialect != "mysql" && dialect != "postgres" {
+		t.Skip("upsert is not supported by " + dialect)
+	}
+
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	collector := &sqlCollector{}
+	db.SetLogger(collector)
+	db = db.LogMode(true).Set("gorm:save_upsert", true)
+
+	product := Product{Id: 99001, Code: "upsert", Price: 100}
+	if err := db.Save(&product).Error; err != nil || db.Save(&product).RowsAffected != 1 {
+		t.Errorf("Should insert product with primary key, but got %v", err)
+	}
+
+	var created Product
+	if db.First(&created, 99001); created.Code != "upsert" || created.CreatedAt.IsZero() {
+		t.Errorf("Product should be inserted with CreatedAt, but got %+v", created)
+	}
+
+	collector.sqls = nil
+	product = Product{Id: 99001, Code: "upsert2", Price: 200}
+	if result := db.Save(&product); result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Should update product on conflict, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if len(collector.sqls) != 1 || !strings.HasPrefix(collector.sqls[0], "INSERT INTO") {
+		t.Errorf("Should save product in one statement, but got %v", collector.sqls)
+	}
+
+	var updated Product
+	db.First(&updated, 99001)
+	if updated.Code != "upsert2" || updated.Price != 200 || !updated.CreatedAt.Equal(created.CreatedAt) || updated.BeforeUpdateCallTimes != 1 {
+		t.Errorf("Product should be updated except CreatedAt with update hooks, but got %+v", updated)
+	}
+
+	collector.sqls = nil
+	product = Product{Id: 99002, Code: "two_steps"}
+	db.Set("gorm:save_upsert", false).Save(&product)
+	if len(collector.sqls) < 2 || !strings.HasPrefix(collector.sqls[0], "UPDATE") {
+		t.Errorf("Should update then create product without upsert, but got %v", collector.sqls)
+	}
+
+	collector.sqls = nil
+	product = Product{Id: 99001, Code: "conditional", Price: 300}
+	if result := db.Where("price = ?", 100).Save(&product); result.Error != nil {
+		t.Errorf("Should save product with conditions, but got %v", result.Error)
+	}
+	if len(collector.sqls) == 0 || !strings.HasPrefix(collector.sqls[0], "UPDATE") {
+		t.Errorf("Should update product with conditions instead of upsert, but got %v", collector.sqls)
+	}
+
+	var unchanged Product
+	if db.First(&unchanged, 99001); unchanged.Code != "upsert2" {
+		t.Errorf("Product not matching conditions should not be overwritten, but got %+v", unchanged)
+	}
+}
+
 func TestUpdateWithNoStdPrimaryKeyAndDefaultValues(t *testing.T) {
 	animal := Animal{Name: "Ferdinand"}
 	DB.Save(&animal)
@@ -166,6 +230,108 @@ func TestUpdates(t *testing.T) {
 	}
 }
 
+func TestUpdatesSliceByPrimaryKeys(t *testing.T) {
+	products := []Product{{Code: "batch_update1"}, {Code: "batch_update2"}, {Code: "batch_update3"}}
+	for i := range products {
+		DB.Save(&products[i])
+	}
+
+	if result := DB.Model(products[:2]).Updates(map[string]interface{}{"price": 999}); result.Error != nil || result.RowsAffected != 2 {
+		t.Errorf("Should update records of the slice by primary keys, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if result := DB.Model(&products).UpdateColumn("price", gorm.Expr("price + ?", 1)); result.RowsAffected != 3 {
+		t.Errorf("Should update columns of records of the slice, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	var prices []int64
+	DB.Model(&Product{}).Where("code LIKE ?", "batch_update%").Order("id").Pluck("price", &prices)
+	if len(prices) != 3 || prices[0] != 1000 || prices[1] != 1000 || prices[2] != 1 {
+		t.Errorf("Only records of the slice should be updated, but got %v", prices)
+	}
+}
+
+func TestRequireRowsAffected(t *testing.T) {
+	product := Product{Code: "require_rows_affected"}
+	DB.Save(&product)
+
+	db := DB.RequireRowsAffected()
+	if err := db.Model(&product).Update("price", 10).Error; err != nil {
+		t.Errorf("Should not get error when updating existing record, but got %v", err)
+	}
+
+	if err := db.Model(&Product{}).Where("code = ?", "not_exists").Update("price", 10).Error; err != gorm.ErrNoRowsAffected {
+		t.Errorf("Should get ErrNoRowsAffected when updating nothing, but got %v", err)
+	}
+
+	if err := db.Delete(&product).Error; err != nil {
+		t.Errorf("Should not get error when deleting existing record, but got %v", err)
+	}
+
+	if err := db.Delete(&product).Error; err != gorm.ErrNoRowsAffected {
+		t.Errorf("Should get ErrNoRowsAffected when deleting nothing, but got %v", err)
+	}
+
+	if err := DB.Delete(&product).Error; err != nil {
+		t.Errorf("Should not check rows affected by default, but got %v", err)
+	}
+
+	if err := db.Save(&Product{Id: product.Id, Code: "require_rows_affected"}).Error; err != nil {
+		t.Errorf("Save should create the record if updating affects no rows, but got %v", err)
+	}
+}
+
+func TestUpdatesWithExpressionsAndColumns(t *testing.T) {
+	product := Product{Code: "expression_and_column", Price: 10, AfterFindCallTimes: 3}
+	DB.Save(&product)
+
+	updates := map[string]interface{}{
+		"code":                     "expression_and_column_updated",
+		"price":                    gorm.Expr("price + ?", 5),
+		"before_delete_call_times": gorm.Column("after_find_call_times"),
+		"after_delete_call_times":  gorm.Expr("? * ?", gorm.Column("price"), 2),
+	}
+	if affected := DB.Model(&product).Updates(updates).RowsAffected; affected != 1 {
+		t.Errorf("Should update 1 row, but got %v", affected)
+	}
+
+	var result Product
+	DB.First(&result, product.Id)
+	if result.Code != "expression_and_column_updated" || result.Price != 15 || result.BeforeDeleteCallTimes != 3 || result.AfterDeleteCallTimes != 20 {
+		t.Errorf("Should update with values, expressions and columns, but got %+v", result)
+	}
+
+	if product.Code != "expression_and_column_updated" || product.BeforeDeleteCallTimes != 0 {
+		t.Errorf("Should only set literal values to the model, but got %+v", product)
+	}
+}
+
+func TestIncrementAndDecrement(t *testing.T) {
+	product := Product{Code: "increment", Price: 10}
+	other := Product{Code: "increment_other", Price: 10}
+	DB.Save(&product).Save(&other)
+
+	updatedAt := product.UpdatedAt
+	if err := DB.Model(&product).Increment("price", 5).Error; err != nil || product.Price != 15 {
+		t.Errorf("Should increase price and set the new value, but got %v, %v", err, product.Price)
+	}
+
+	var result Product
+	if DB.First(&result, product.Id); result.Price != 15 || !result.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("Should increase price without updating UpdatedAt, but got %v, %v", result.Price, result.UpdatedAt)
+	}
+
+	if affected := DB.Model(&product).Decrement("price", 3).RowsAffected; affected != 1 || product.Price != 12 {
+		t.Errorf("Should decrease price, but got %v, %v", affected, product.Price)
+	}
+
+	DB.Model(&Product{}).Where("code LIKE ?", "increment%").Increment("price", 1)
+	var otherResult Product
+	if DB.First(&otherResult, other.Id); otherResult.Price != 11 {
+		t.Errorf("Should increase price of all matched records, but got %v", otherResult.Price)
+	}
+}
+
 func TestUpdateColumn(t *testing.T) {
 	product1 := Product{Code: "product1code", Price: 10}
 	product2 := Product{Code: "product2code", Price: 20}
@@ -1,11 +1,13 @@
 package gorm
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -60,8 +62,9 @@ func newSafeMap() *safeMap {
 
 // SQL expression
 type SqlExpr struct {
-	expr string
-	args []interface{}
+	expr   string
+	args   []interface{}
+	column bool
 }
 
 // Expr generate raw SQL expression, for example:
@@ -70,6 +73,12 @@ func Expr(expression string, args ...interface{}) *SqlExpr {
 	return &SqlExpr{expr: expression, args: args}
 }
 
+// Column generate expression referring to a column, it will be quoted, for example:
+//     DB.Model(&product).Updates(map[string]interface{}{"price": gorm.Column("original_price"), "count": gorm.Expr("count + ?", 1)})
+func Column(name string) *SqlExpr {
+	return &SqlExpr{expr: name, column: true}
+}
+
 func indirect(reflectValue reflect.Value) reflect.Value {
 	for reflectValue.Kind() == reflect.Ptr {
 		reflectValue = reflectValue.Elem()
@@ -77,6 +86,21 @@ func indirect(reflectValue reflect.Value) reflect.Value {
 	return reflectValue
 }
 
+func indirectType(reflectType reflect.Type) reflect.Type {
+	for reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	return reflectType
+}
+
+// elemTypeOf return the struct type of pointers, slices or slices of pointers
+func elemTypeOf(reflectType reflect.Type) reflect.Type {
+	for reflectType.Kind() == reflect.Ptr || reflectType.Kind() == reflect.Slice {
+		reflectType = reflectType.Elem()
+	}
+	return reflectType
+}
+
 func toQueryMarks(primaryValues [][]interface{}) string {
 	var results []string
 
@@ -127,10 +151,11 @@ func fileWithLineNum() string {
 }
 
 //这样就能外面自定义
+// GetSource return the caller as `function:line`, frames of packages skipped with SkipSourcePackages are skipped
 var GetSource = func(skip int) (name string) {
 	name = "unknown"
-	if pc, _, line, ok := runtime.Caller(skip); ok {
-		name = fmt.Sprintf("%s:%d", runtime.FuncForPC(pc).Name(), line)
+	if frame, ok := callerFrame(skip + 1); ok {
+		name = fmt.Sprintf("%s:%d", frame.Function, frame.Line)
 	}
 	return xRayNameReplace(name)
 }
@@ -192,11 +217,48 @@ func isBlank(value reflect.Value) bool {
 		return value.Float() == 0
 	case reflect.Interface, reflect.Ptr:
 		return value.IsNil()
+	case reflect.Struct:
+		// nullable types like `sql.NullString`, `gorm.NullTime` are blank if it is NULL
+		if valuer, ok := value.Interface().(driver.Valuer); ok {
+			if v, err := valuer.Value(); err == nil && v == nil {
+				return true
+			}
+		}
 	}
 
 	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
 }
 
+func isNilPointer(value interface{}) bool {
+	reflectValue := reflect.ValueOf(value)
+	return reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil()
+}
+
+// driverValue return the driver value of the value if it implements driver.Valuer, or it is addressable and its pointer implements it,
+// values implementing GormValuerInterface and nil pointers are returned as they are
+func driverValue(value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	v := value.Interface()
+	if _, ok := v.(GormValuerInterface); ok || isNilPointer(v) {
+		return v, nil
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	if value.CanAddr() {
+		switch valuer := value.Addr().Interface().(type) {
+		case GormValuerInterface:
+			return v, nil
+		case driver.Valuer:
+			return valuer.Value()
+		}
+	}
+	return v, nil
+}
+
 func toSearchableMap(attrs ...interface{}) (result interface{}) {
 	if len(attrs) > 1 {
 		if str, ok := attrs[0].(string); ok {
@@ -227,6 +289,10 @@ func toString(str interface{}) string {
 		return strings.Join(results, "_")
 	} else if bytes, ok := str.([]byte); ok {
 		return string(bytes)
+	} else if valuer, ok := str.(driver.Valuer); ok && !isNilPointer(str) {
+		if value, err := valuer.Value(); err == nil && value != nil {
+			return toString(value)
+		}
 	} else if reflectValue := reflect.Indirect(reflect.ValueOf(str)); reflectValue.IsValid() {
 		return fmt.Sprintf("%v", reflectValue.Interface())
 	}
@@ -243,6 +309,45 @@ func makeSlice(elemType reflect.Type) interface{} {
 	return slice.Interface()
 }
 
+// convertFromString convert string to value of given type, e.g. integer, bool or types implemented sql.Scanner
+func convertFromString(str string, reflectType reflect.Type) (interface{}, error) {
+	reflectType = indirectType(reflectType)
+	reflectValue := reflect.New(reflectType)
+
+	if scanner, ok := reflectValue.Interface().(sql.Scanner); ok {
+		if err := scanner.Scan(str); err != nil {
+			return nil, err
+		}
+		return reflectValue.Elem().Interface(), nil
+	}
+
+	switch reflectType.Kind() {
+	case reflect.String:
+		reflectValue.Elem().SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(str, 10, reflectType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		reflectValue.Elem().SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(str, 10, reflectType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		reflectValue.Elem().SetUint(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(str)
+		if err != nil {
+			return nil, err
+		}
+		reflectValue.Elem().SetBool(value)
+	default:
+		return str, nil
+	}
+	return reflectValue.Elem().Interface(), nil
+}
+
 func strInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -259,7 +364,7 @@ func getValueFromFields(value reflect.Value, fieldNames []string) (results []int
 	// as FieldByName could panic
 	if indirectValue := reflect.Indirect(value); indirectValue.IsValid() {
 		for _, fieldName := range fieldNames {
-			if fieldValue := reflect.Indirect(indirectValue.FieldByName(fieldName)); fieldValue.IsValid() {
+			if fieldValue := reflect.Indirect(fieldByPath(indirectValue, fieldName)); fieldValue.IsValid() {
 				result := fieldValue.Interface()
 				if r, ok := result.(driver.Valuer); ok {
 					result, _ = r.Value()
@@ -271,9 +376,51 @@ func getValueFromFields(value reflect.Value, fieldNames []string) (results []int
 	return
 }
 
+// fieldByPath return the field of the struct by its name, or its path like `Billing.Street` for fields of embedded structs,
+// the zero Value is returned if the field isn't found or an embedded pointer is nil
+func fieldByPath(value reflect.Value, path string) reflect.Value {
+	for {
+		if value = reflect.Indirect(value); value.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+
+		idx := strings.IndexByte(path, '.')
+		if idx < 0 {
+			return value.FieldByName(path)
+		}
+		value, path = value.FieldByName(path[:idx]), path[idx+1:]
+	}
+}
+
+// structFieldByPath return the struct field of the type by its name or path like fieldByPath
+func structFieldByPath(reflectType reflect.Type, path string) (field reflect.StructField, ok bool) {
+	for {
+		for reflectType.Kind() == reflect.Ptr {
+			reflectType = reflectType.Elem()
+		}
+		if reflectType.Kind() != reflect.Struct {
+			return field, false
+		}
+
+		idx := strings.IndexByte(path, '.')
+		if idx < 0 {
+			return reflectType.FieldByName(path)
+		}
+		if field, ok = reflectType.FieldByName(path[:idx]); !ok {
+			return field, false
+		}
+		reflectType, path = field.Type, path[idx+1:]
+	}
+}
+
 func addExtraSpaceIfExist(str string) string {
 	if str != "" {
 		return " " + str
 	}
 	return ""
 }
+
+// isIdentifierRune return true if the rune could be in table or column names
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,239 @@
+package gorm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ViewOption options of creating and dropping views
+type ViewOption struct {
+	// Replace replace the view if it exists
+	Replace bool
+	// Materialized create a materialized view storing results of the query, it is supported by postgres and cockroachdb
+	Materialized bool
+}
+
+// readOnlyModel models mapped to views should implement `ReadOnly() bool` returning true, so they can't be created, updated or deleted
+type readOnlyModel interface {
+	ReadOnly() bool
+}
+
+func init() {
+	DefaultCallback.Create().Before("gorm:before_create").Register("gorm:read_only_model", readOnlyModelCallback)
+	DefaultCallback.Update().Before("gorm:before_update").Register("gorm:read_only_model", readOnlyModelCallback)
+	DefaultCallback.Delete().Before("gorm:before_delete").Register("gorm:read_only_model", readOnlyModelCallback)
+}
+
+// CreateView create a view of the query, args of the query are inlined into the view's SQL, e.g:
+//    db.CreateView("active_users", db.Model(&User{}).Select("id, name").Where("active = ?", true))
+//    db.CreateView("user_stats", db.Table("orders").Select("user_id, count(*) AS orders").Group("user_id"), gorm.ViewOption{Materialized: true})
+//
+// Models mapped to views are read-only if they implement `ReadOnly() bool` returning true, creating, updating or deleting them returns ErrReadOnlyModel
+//    type ActiveUser struct {
+//      ID   int
+//      Name string
+//    }
+//
+//    func (ActiveUser) TableName() string { return "active_users" }
+//    func (ActiveUser) ReadOnly() bool    { return true }
+func (s *DB) CreateView(name string, query *DB, options ...ViewOption) *DB {
+	var option ViewOption
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	scope := s.NewScope(nil)
+	if query.Error != nil {
+		scope.Err(query.Error)
+		return scope.db
+	}
+
+	expr := query.QueryExpr()
+	viewSQL, err := inlineVars(s.Dialect(), expr.expr, expr.args)
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+
+	kind, err := viewKind(s.Dialect(), option)
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+
+	create := "CREATE"
+	if option.Replace {
+		switch dialect := s.Dialect().GetName(); {
+		case !option.Materialized && (dialect == "mysql" || dialect == "tidb" || dialect == "postgres" || dialect == "cockroachdb"):
+			create = "CREATE OR REPLACE"
+		default:
+			// materialized views and views of other dialects can't be replaced, drop them first
+			if scope.Raw(fmt.Sprintf("DROP %v IF EXISTS %v", kind, scope.Quote(name))).Exec().HasError() {
+				return scope.db
+			}
+		}
+	}
+
+	return scope.Raw(fmt.Sprintf("%v %v %v AS %v", create, kind, scope.Quote(name), viewSQL)).Exec().db
+}
+
+// DropView drop the view if it exists
+func (s *DB) DropView(name string, options ...ViewOption) *DB {
+	var option ViewOption
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	scope := s.NewScope(nil)
+	kind, err := viewKind(s.Dialect(), option)
+	if scope.Err(err) != nil {
+		return scope.db
+	}
+	return scope.Raw(fmt.Sprintf("DROP %v IF EXISTS %v", kind, scope.Quote(name))).Exec().db
+}
+
+// RefreshMaterializedView refresh results of the materialized view
+func (s *DB) RefreshMaterializedView(name string) *DB {
+	scope := s.NewScope(nil)
+	if _, err := viewKind(s.Dialect(), ViewOption{Materialized: true}); scope.Err(err) != nil {
+		return scope.db
+	}
+	return scope.Raw(fmt.Sprintf("REFRESH MATERIALIZED VIEW %v", scope.Quote(name))).Exec().db
+}
+
+func viewKind(dialect Dialect, option ViewOption) (string, error) {
+	if !option.Materialized {
+		return "VIEW", nil
+	}
+
+	switch dialect.GetName() {
+	case "postgres", "cockroachdb":
+		return "MATERIALIZED VIEW", nil
+	}
+	return "", fmt.Errorf("materialized views are not supported by %v", dialect.GetName())
+}
+
+// readOnlyModelCallback return ErrReadOnlyModel when creating, updating or deleting read-only models,
+// and ErrReadOnlyDB with dbs returned by ReadOnly
+func readOnlyModelCallback(scope *Scope) {
+	if scope.HasError() {
+		return
+	}
+
+	if scope.db.db.readOnly {
+		scope.Err(ErrReadOnlyDB)
+		return
+	}
+
+	model := scope.Value
+	if modelType := scope.GetModelStruct().ModelType; modelType != nil {
+		model = reflect.New(modelType).Interface()
+	}
+	if readOnly, ok := model.(readOnlyModel); ok && readOnly.ReadOnly() {
+		scope.Err(ErrReadOnlyModel)
+	}
+}
+
+// inlineVars replace bind variables of the query with literals of args, as views can't have bind variables,
+// question marks in quoted strings and identifiers aren't bind variables
+func inlineVars(dialect Dialect, query string, args []interface{}) (string, error) {
+	var (
+		buf   bytes.Buffer
+		quote rune
+		idx   int
+	)
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			if idx >= len(args) {
+				break
+			}
+			literal, err := sqlLiteral(dialect, args[idx])
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(literal)
+			idx++
+			continue
+		}
+		buf.WriteRune(r)
+	}
+
+	if idx < len(args) {
+		return "", errors.New("bind variables of the query are less than args")
+	}
+	return buf.String(), nil
+}
+
+// sqlLiteral return SQL literal of the value
+func sqlLiteral(dialect Dialect, value interface{}) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if isNilPointer(value) {
+			return "NULL", nil
+		}
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		value = v
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return "NULL", nil
+		}
+		return sqlLiteral(dialect, reflectValue.Elem().Interface())
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return quoteLiteral(dialect, v.Format("2006-01-02 15:04:05.999999")), nil
+	case []byte:
+		return quoteLiteral(dialect, string(v)), nil
+	case string:
+		return quoteLiteral(dialect, v), nil
+	}
+
+	switch reflectValue.Kind() {
+	case reflect.String:
+		return quoteLiteral(dialect, reflectValue.String()), nil
+	case reflect.Bool:
+		return sqlLiteral(dialect, reflectValue.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sqlLiteral(dialect, reflectValue.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sqlLiteral(dialect, reflectValue.Uint())
+	case reflect.Float32, reflect.Float64:
+		return sqlLiteral(dialect, reflectValue.Float())
+	}
+	return "", fmt.Errorf("unsupported value %v of type %T to inline into SQL", value, value)
+}
+
+// quoteLiteral quote the string as a SQL string literal
+func quoteLiteral(dialect Dialect, str string) string {
+	switch dialect.GetName() {
+	case "mysql", "tidb":
+		// backslashes escape characters in mysql strings by default
+		str = strings.Replace(str, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
@@ -0,0 +1,86 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type ViewUser struct {
+	Id     int64
+	Name   string
+	Active bool
+}
+
+type ActiveViewUser struct {
+	Id   int64
+	Name string
+}
+
+func (ActiveViewUser) TableName() string { return "active_view_users" }
+func (ActiveViewUser) ReadOnly() bool    { return true }
+
+func TestCreateView(t *testing.T) {
+	DB.DropView("active_view_users")
+
+	DB.Save(&ViewUser{Name: "it's active", Active: true})
+	DB.Save(&ViewUser{Name: "inactive", Active: false})
+
+	if err := DB.CreateView("active_view_users", DB.Model(&ViewUser{}).Select("id, name").Where("active = ?", true)).Error; err != nil {
+		t.Fatalf("failed to create view, got %v", err)
+	}
+
+	var users []ActiveViewUser
+	if DB.Find(&users); len(users) != 1 || users[0].Name != "it's active" {
+		t.Errorf("active users should be found from the view, but got %v", users)
+	}
+
+	view := DB.Model(&ViewUser{}).Select("id, name").Where("name LIKE ?", "in%")
+	if err := DB.CreateView("active_view_users", view, gorm.ViewOption{Replace: true}).Error; err != nil {
+		t.Fatalf("failed to replace view, got %v", err)
+	}
+
+	if DB.Find(&users); len(users) != 1 || users[0].Name != "inactive" {
+		t.Errorf("users should be found from the replaced view, but got %v", users)
+	}
+
+	view = DB.Model(&ViewUser{}).Select("id, name").Where("name <> 'why?' AND (name = ? OR name = ?)", "why?", "inactive")
+	if err := DB.CreateView("active_view_users", view, gorm.ViewOption{Replace: true}).Error; err != nil {
+		t.Fatalf("failed to replace view with question marks, got %v", err)
+	}
+
+	if DB.Find(&users); len(users) != 1 || users[0].Name != "inactive" {
+		t.Errorf("question marks in literals should not be bind variables, but got %v", users)
+	}
+
+	if err := DB.Create(&ActiveViewUser{Name: "created"}).Error; err != gorm.ErrReadOnlyModel {
+		t.Errorf("read-only model should not be created, but got %v", err)
+	}
+
+	if err := DB.Model(&users[0]).Update("name", "updated").Error; err != gorm.ErrReadOnlyModel {
+		t.Errorf("read-only model should not be updated, but got %v", err)
+	}
+
+	if err := DB.Delete(&users).Error; err != gorm.ErrReadOnlyModel {
+		t.Errorf("read-only model should not be deleted, but got %v", err)
+	}
+
+	if err := DB.DropView("active_view_users").Error; err != nil || DB.HasTable("active_view_users") {
+		t.Errorf("view should be dropped, but got %v", err)
+	}
+
+	switch DB.Dialect().GetName() {
+	case "postgres", "cockroachdb":
+		if err := DB.CreateView("view_user_stats", DB.Table("view_users").Select("active, count(*) AS total").Group("active"), gorm.ViewOption{Materialized: true, Replace: true}).Error; err != nil {
+			t.Errorf("failed to create materialized view, got %v", err)
+		}
+		if err := DB.RefreshMaterializedView("view_user_stats").Error; err != nil {
+			t.Errorf("failed to refresh materialized view, got %v", err)
+		}
+		DB.DropView("view_user_stats", gorm.ViewOption{Materialized: true})
+	default:
+		if err := DB.CreateView("view_user_stats", DB.Table("view_users"), gorm.ViewOption{Materialized: true}).Error; err == nil {
+			t.Errorf("materialized views should not be supported by %v", DB.Dialect().GetName())
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteOperation operations changing records of tables, combine them with `|`
+type WriteOperation int
+
+const (
+	// OperationCreate creating records
+	OperationCreate WriteOperation = 1 << iota
+	// OperationUpdate updating records
+	OperationUpdate
+	// OperationDelete deleting records, including soft deleting
+	OperationDelete
+	// OperationAll all operations
+	OperationAll = OperationCreate | OperationUpdate | OperationDelete
+)
+
+var writeOperationNames = []string{"create", "update", "delete"}
+
+func (operation WriteOperation) String() string {
+	var names []string
+	for idx, name := range writeOperationNames {
+		if operation&(1<<uint(idx)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// WriteGuardError occurs when the operation on the table isn't allowed by WriteGuard
+type WriteGuardError struct {
+	Table     string
+	Operation WriteOperation
+}
+
+func (err *WriteGuardError) Error() string {
+	return fmt.Sprintf("%v on table %v isn't allowed", err.Operation, err.Table)
+}
+
+// WriteGuard plugin guards operations changing records of tables, e.g. protect append-only tables from being updated or deleted
+//    db.Use(&gorm.WriteGuard{Tables: map[string]gorm.WriteOperation{
+//      "ledger_entries": gorm.OperationCreate,                       // insert-only
+//      "accounts":       gorm.OperationCreate | gorm.OperationUpdate, // no delete
+//      "currencies":     0,                                          // read-only
+//    }})
+//
+//    db.Delete(&entry) // returns *gorm.WriteGuardError
+//
+// Tables not configured allow all operations unless DenyUnlisted is true. Tables are matched by names after routing shards,
+// SQL executed with Exec is not guarded
+type WriteGuard struct {
+	// Tables operations allowed on tables
+	Tables map[string]WriteOperation
+	// DenyUnlisted deny all operations on tables not in Tables
+	DenyUnlisted bool
+}
+
+// Name return plugin name
+func (guard *WriteGuard) Name() string {
+	return "gorm:write_guard"
+}
+
+// Initialize register callbacks to guard tables
+func (guard *WriteGuard) Initialize(db *DB) error {
+	callback := db.Callback()
+	callback.Create().Before("gorm:before_create").Register("gorm:write_guard", guard.callback(OperationCreate))
+	callback.Update().Before("gorm:before_update").Register("gorm:write_guard", guard.callback(OperationUpdate))
+	callback.Delete().Before("gorm:before_delete").Register("gorm:write_guard", guard.callback(OperationDelete))
+	return nil
+}
+
+// Allowed return true if the operation on the table is allowed
+func (guard *WriteGuard) Allowed(table string, operation WriteOperation) bool {
+	allowed, ok := guard.Tables[table]
+	if !ok {
+		return !guard.DenyUnlisted
+	}
+	return allowed&operation == operation
+}
+
+func (guard *WriteGuard) callback(operation WriteOperation) func(scope *Scope) {
+	return func(scope *Scope) {
+		if scope.HasError() {
+			return
+		}
+
+		if table := scope.TableName(); !guard.Allowed(table, operation) {
+			scope.Err(&WriteGuardError{Table: table, Operation: operation})
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/lun-zhang/gorm"
+)
+
+type LedgerEntry struct {
+	gorm.Model
+	Amount int
+}
+
+func TestWriteGuard(t *testing.T) {
+	db, err := OpenTestConnection()
+	if err != nil {
+		t.Fatalf("Failed to open connection, got %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists(&LedgerEntry{})
+	db.AutoMigrate(&LedgerEntry{})
+	if err := db.Use(&gorm.WriteGuard{Tables: map[string]gorm.WriteOperation{"ledger_entries": gorm.OperationCreate, "users": 0}}); err != nil {
+		t.Fatalf("Failed to use write guard, got %v", err)
+	}
+
+	entry := LedgerEntry{Amount: 100}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Errorf("creating should be allowed, but got %v", err)
+	}
+
+	if err := db.Model(&entry).Update("amount", 200).Error; err == nil || err.Error() != "update on table ledger_entries isn't allowed" {
+		t.Errorf("updating should be denied, but got %v", err)
+	}
+
+	var guardErr *gorm.WriteGuardError
+	if err := db.Delete(&entry).Error; !gorm.Errors([]error{err}).As(&guardErr) || guardErr.Operation != gorm.OperationDelete {
+		t.Errorf("deleting should be denied, but got %v", err)
+	}
+
+	if err := db.Save(&User{Name: "write-guard"}).Error; err == nil {
+		t.Errorf("tables allowing nothing should be read-only")
+	}
+	if err := db.Save(&Company{Name: "write-guard"}).Error; err != nil {
+		t.Errorf("tables not configured should allow all operations, but got %v", err)
+	}
+
+	var count int
+	db.Model(&LedgerEntry{}).Where("amount = ?", 100).Count(&count)
+	if count != 1 {
+		t.Errorf("entry should be kept, but got %v", count)
+	}
+}